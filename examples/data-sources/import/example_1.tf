@@ -0,0 +1,8 @@
+data "helm_import" "monitoring" {
+  namespace = "monitoring"
+}
+
+resource "local_file" "imports" {
+  filename = "${path.module}/imports.tf"
+  content  = data.helm_import.monitoring.import_blocks
+}
@@ -0,0 +1,9 @@
+data "helm_import" "monitoring" {
+  namespace = "monitoring"
+}
+
+import {
+  for_each = data.helm_import.monitoring.ids
+  to       = helm_release.monitoring[each.key]
+  id       = each.value
+}
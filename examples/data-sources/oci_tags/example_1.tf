@@ -0,0 +1,16 @@
+data "helm_oci_tags" "podinfo" {
+  chart              = "oci://ghcr.io/stefanprodan/charts/podinfo"
+  version_constraint = "~6.5"
+  include_details    = true
+}
+
+resource "helm_release" "podinfo" {
+  name       = "podinfo"
+  repository = "oci://ghcr.io/stefanprodan/charts"
+  chart      = "podinfo"
+  version    = data.helm_oci_tags.podinfo.latest
+}
+
+output "podinfo_digest" {
+  value = data.helm_oci_tags.podinfo.versions[0].digest
+}
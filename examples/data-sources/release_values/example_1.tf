@@ -0,0 +1,13 @@
+data "helm_release_values" "ingress" {
+  name      = "ingress-nginx"
+  namespace = "ingress-nginx"
+}
+
+locals {
+  ingress_values = jsondecode(data.helm_release_values.ingress.effective_values)
+}
+
+output "ingress_class" {
+  value     = local.ingress_values.controller.ingressClassResource.name
+  sensitive = true
+}
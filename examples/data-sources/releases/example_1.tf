@@ -0,0 +1,7 @@
+data "helm_releases" "failed" {
+  statuses = ["failed", "pending-install", "pending-upgrade", "pending-rollback"]
+}
+
+output "failed_releases" {
+  value = [for r in data.helm_releases.failed.releases : "${r.namespace}/${r.name}"]
+}
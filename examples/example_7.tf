@@ -0,0 +1,5 @@
+resource "helm_release" "production" {
+  name         = "my-app"
+  chart        = "./charts/my-app"
+  kube_context = "production"
+}
@@ -0,0 +1,21 @@
+locals {
+  defaults  = file("${path.module}/values/defaults.yaml")
+  overrides = yamlencode({
+    replicaCount = 3
+    resources = {
+      limits = {
+        memory = "512Mi"
+      }
+    }
+  })
+}
+
+resource "helm_release" "example" {
+  name       = "my-redis-release"
+  repository = "https://charts.bitnami.com/bitnami"
+  chart      = "redis"
+
+  values = [
+    provider::helm::merge_values(local.defaults, local.overrides),
+  ]
+}
@@ -0,0 +1,13 @@
+resource "helm_release" "example" {
+  name       = "my-redis-release"
+  repository = "https://charts.bitnami.com/bitnami"
+  chart      = "redis"
+
+  values = [
+    provider::helm::set_path(
+      file("${path.module}/values.yaml"),
+      "master.podAnnotations.prometheus\\.io/scrape",
+      "true",
+    ),
+  ]
+}
@@ -0,0 +1,14 @@
+locals {
+  values = yamlencode({
+    auth = {
+      passwordPolicy = {
+        minLength = 16
+      }
+    }
+  })
+}
+
+resource "vault_policy" "example" {
+  name   = "my-app"
+  policy = provider::helm::template_string("${path.module}/charts/my-app", local.values, "templates/vault-policy.hcl")
+}
@@ -0,0 +1,15 @@
+data "helm_template" "redis" {
+  name       = "my-redis-release"
+  namespace  = "default"
+  repository = "https://charts.bitnami.com/bitnami"
+  chart      = "redis"
+  version    = "6.0.1"
+}
+
+resource "helm_release" "redis" {
+  name                  = "my-redis-release"
+  namespace             = "default"
+  chart                 = "redis"
+  version               = "6.0.1"
+  pre_rendered_manifest = data.helm_template.redis.manifest
+}
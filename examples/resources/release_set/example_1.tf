@@ -0,0 +1,20 @@
+resource "helm_release_set" "tenants" {
+  namespace  = "tenants"
+  repository = "https://charts.bitnami.com/bitnami"
+  chart      = "redis"
+  version    = "6.0.1"
+
+  values = [
+    yamlencode({ cluster = { enabled = false } })
+  ]
+
+  instances = {
+    "redis-tenant-a" = {}
+    "redis-tenant-b" = {
+      values = [yamlencode({ master = { persistence = { size = "16Gi" } } })]
+    }
+    "redis-tenant-c" = {
+      namespace = "tenant-c"
+    }
+  }
+}
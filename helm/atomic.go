@@ -0,0 +1,22 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+// atomicInstall reports whether a failed install of the release is uninstalled: atomic_install
+// when set, else atomic
+func atomicInstall(model *HelmReleaseModel) bool {
+	if !model.AtomicInstall.IsNull() && !model.AtomicInstall.IsUnknown() {
+		return model.AtomicInstall.ValueBool()
+	}
+	return model.Atomic.ValueBool()
+}
+
+// atomicUpgrade reports whether a failed upgrade of the release is rolled back: atomic_upgrade
+// when set, else atomic
+func atomicUpgrade(model *HelmReleaseModel) bool {
+	if !model.AtomicUpgrade.IsNull() && !model.AtomicUpgrade.IsUnknown() {
+		return model.AtomicUpgrade.ValueBool()
+	}
+	return model.Atomic.ValueBool()
+}
@@ -0,0 +1,30 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtomicInstallUpgrade(t *testing.T) {
+	tests := []struct {
+		atomic, atomicInstall, atomicUpgrade types.Bool
+		install, upgrade                     bool
+	}{
+		{atomic: types.BoolValue(false), atomicInstall: types.BoolNull(), atomicUpgrade: types.BoolNull()},
+		{atomic: types.BoolValue(true), atomicInstall: types.BoolNull(), atomicUpgrade: types.BoolNull(), install: true, upgrade: true},
+		// upgrades are rolled back, failed installs are left for debugging
+		{atomic: types.BoolValue(false), atomicInstall: types.BoolNull(), atomicUpgrade: types.BoolValue(true), upgrade: true},
+		{atomic: types.BoolValue(true), atomicInstall: types.BoolValue(false), atomicUpgrade: types.BoolNull(), upgrade: true},
+		{atomic: types.BoolValue(true), atomicInstall: types.BoolUnknown(), atomicUpgrade: types.BoolValue(false), install: true},
+	}
+	for _, tc := range tests {
+		model := &HelmReleaseModel{Atomic: tc.atomic, AtomicInstall: tc.atomicInstall, AtomicUpgrade: tc.atomicUpgrade}
+		assert.Equal(t, tc.install, atomicInstall(model))
+		assert.Equal(t, tc.upgrade, atomicUpgrade(model))
+	}
+}
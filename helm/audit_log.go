@@ -0,0 +1,107 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Operations recorded in the audit log
+const (
+	auditOperationInstall   = "install"
+	auditOperationUpgrade   = "upgrade"
+	auditOperationUninstall = "uninstall"
+)
+
+// Outcomes of the operations recorded in the audit log
+const (
+	auditOutcomeSuccess = "success"
+	auditOutcomeFailure = "failure"
+)
+
+// auditLogStdout is the audit_log_path writing the audit log to the standard output of the
+// provider
+const auditLogStdout = "-"
+
+// auditLogMutex serializes the writes of the audit log, so that records are not interleaved
+var auditLogMutex sync.Mutex
+
+// auditRecord is a line of the audit log
+type auditRecord struct {
+	Timestamp string `json:"timestamp"`
+	Operation string `json:"operation"`
+	Release   string `json:"release"`
+	Namespace string `json:"namespace"`
+	Chart     string `json:"chart"`
+	Version   string `json:"version"`
+	Outcome   string `json:"outcome"`
+	Error     string `json:"error,omitempty"`
+	// Duration of the operation in seconds
+	Duration float64 `json:"duration"`
+}
+
+// newAuditRecord returns the record of an operation started at start, failed when err is set
+func newAuditRecord(operation, namespace, name, chart, version string, start time.Time, err error) auditRecord {
+	r := auditRecord{
+		Timestamp: start.UTC().Format(time.RFC3339Nano),
+		Operation: operation,
+		Release:   name,
+		Namespace: namespace,
+		Chart:     chart,
+		Version:   version,
+		Outcome:   auditOutcomeSuccess,
+		Duration:  time.Since(start).Seconds(),
+	}
+	if err != nil {
+		r.Outcome = auditOutcomeFailure
+		r.Error = err.Error()
+	}
+	return r
+}
+
+// writeAuditRecord writes r to w as a line of JSON
+func writeAuditRecord(w io.Writer, r auditRecord) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// auditOperation appends the record of an operation to the audit log of the provider, when
+// audit_log_path is set. A failure to write the audit log does not fail the operation.
+func (m *Meta) auditOperation(ctx context.Context, operation, namespace, name, chart, version string, start time.Time, err error) {
+	if m == nil || m.AuditLogPath == "" {
+		return
+	}
+	r := newAuditRecord(operation, namespace, name, chart, version, start, err)
+
+	auditLogMutex.Lock()
+	defer auditLogMutex.Unlock()
+	if m.AuditLogPath == auditLogStdout {
+		err = writeAuditRecord(os.Stdout, r)
+	} else {
+		// the file is opened for every record, as Terraform may stop the provider at any time
+		var f *os.File
+		f, err = os.OpenFile(m.AuditLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err == nil {
+			err = writeAuditRecord(f, r)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	}
+	if err != nil {
+		tflog.Warn(ctx, fmt.Sprintf("Unable to write the %s of release %s to the audit log %s: %s", operation, name, m.AuditLogPath, err))
+	}
+}
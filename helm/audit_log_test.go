@@ -0,0 +1,48 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditOperation(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	meta := &Meta{AuditLogPath: path}
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	meta.auditOperation(ctx, auditOperationInstall, "apps", "web", "nginx", "1.2.3", start, nil)
+	meta.auditOperation(ctx, auditOperationUpgrade, "apps", "web", "nginx", "1.3.0", start, errors.New("timed out waiting for the condition"))
+	// the audit log is disabled without a path
+	(&Meta{}).auditOperation(ctx, auditOperationUninstall, "apps", "web", "nginx", "1.3.0", start, nil)
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 2)
+
+	var records []auditRecord
+	for _, l := range lines {
+		var r auditRecord
+		require.NoError(t, json.Unmarshal([]byte(l), &r))
+		assert.Greater(t, r.Duration, float64(0))
+		r.Duration = 0
+		records = append(records, r)
+	}
+	assert.Equal(t, []auditRecord{
+		{Timestamp: "2024-05-01T12:00:00Z", Operation: "install", Release: "web", Namespace: "apps", Chart: "nginx", Version: "1.2.3", Outcome: "success"},
+		{Timestamp: "2024-05-01T12:00:00Z", Operation: "upgrade", Release: "web", Namespace: "apps", Chart: "nginx", Version: "1.3.0", Outcome: "failure", Error: "timed out waiting for the condition"},
+	}, records)
+}
@@ -0,0 +1,78 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+// temporaryFiles is the temporary directory of the provider process, which holds the
+// repository cache of disableCacheWrite and the merged registry config files. It is created
+// once, only readable by the user of the provider, and removed by RemoveTemporaryFiles.
+var temporaryFiles struct {
+	sync.Mutex
+	dir string
+}
+
+// temporaryDir returns the temporary directory of the provider process, creating it on first use
+func temporaryDir() (string, error) {
+	temporaryFiles.Lock()
+	defer temporaryFiles.Unlock()
+	if temporaryFiles.dir != "" {
+		return temporaryFiles.dir, nil
+	}
+	dir, err := os.MkdirTemp("", "terraform-provider-helm-")
+	if err != nil {
+		return "", err
+	}
+	// os.MkdirTemp creates the directory with 0700 already, make sure of it whatever the platform
+	if err := os.Chmod(dir, 0o700); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	temporaryFiles.dir = dir
+	return dir, nil
+}
+
+// RemoveTemporaryFiles removes the temporary directory of the provider process, it is called
+// once the provider server stops.
+func RemoveTemporaryFiles() error {
+	temporaryFiles.Lock()
+	defer temporaryFiles.Unlock()
+	if temporaryFiles.dir == "" {
+		return nil
+	}
+	err := os.RemoveAll(temporaryFiles.dir)
+	temporaryFiles.dir = ""
+	return err
+}
+
+// disableCacheWrite points the repository cache and the registry config file of settings to the
+// temporary directory of the provider process, so that the provider does not write to the home
+// directory, e.g. ~/.cache/helm, which is read-only on some runners. Repository indexes and
+// charts are cached for the run of the provider only, and the logins to registries are written
+// to a copy of the registry config file.
+func disableCacheWrite(settings *cli.EnvSettings) error {
+	dir, err := temporaryDir()
+	if err != nil {
+		return fmt.Errorf("unable to create a temporary repository cache: %w", err)
+	}
+	cache := filepath.Join(dir, "repository")
+	if err := os.MkdirAll(cache, 0o700); err != nil {
+		return fmt.Errorf("unable to create a temporary repository cache: %w", err)
+	}
+	settings.RepositoryCache = cache
+
+	registryConfig, err := mergeRegistryConfigs(settings.RegistryConfig, nil)
+	if err != nil {
+		return fmt.Errorf("unable to copy the registry config file: %w", err)
+	}
+	settings.RegistryConfig = registryConfig
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+func TestDisableCacheWrite(t *testing.T) {
+	home := t.TempDir()
+	registryConfig := filepath.Join(home, "registry", "config.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(registryConfig), 0o755))
+	require.NoError(t, os.WriteFile(registryConfig, []byte(`{"auths":{"ghcr.io":{"auth":"dXNlcjpwYXNz"}}}`), 0o600))
+
+	settings := cli.New()
+	settings.RepositoryCache = filepath.Join(home, "cache")
+	settings.RegistryConfig = registryConfig
+	require.NoError(t, disableCacheWrite(settings))
+
+	// the cache and the registry config file are moved out of the home directory, with the
+	// logins of the registry config file
+	assert.False(t, strings.HasPrefix(settings.RepositoryCache, home))
+	assert.DirExists(t, settings.RepositoryCache)
+	assert.False(t, strings.HasPrefix(settings.RegistryConfig, home))
+	auths, _, err := readRegistryConfig(settings.RegistryConfig)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"auth":"dXNlcjpwYXNz"}`, string(auths["ghcr.io"]))
+
+	// the registry config file of the home directory may not exist
+	settings.RegistryConfig = filepath.Join(home, "missing.json")
+	require.NoError(t, disableCacheWrite(settings))
+	assert.FileExists(t, settings.RegistryConfig)
+}
+
+func TestDisableCacheWriteTemporaryFiles(t *testing.T) {
+	t.Cleanup(func() { RemoveTemporaryFiles() })
+
+	settings := cli.New()
+	settings.RegistryConfig = filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, disableCacheWrite(settings))
+	dir := filepath.Dir(settings.RepositoryCache)
+
+	// the temporary directory and the registry config file are only readable by the user
+	info, err := os.Stat(dir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+	info, err = os.Stat(settings.RegistryConfig)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// the configurations of the provider process share the temporary directory
+	other := cli.New()
+	other.RegistryConfig = settings.RegistryConfig
+	require.NoError(t, disableCacheWrite(other))
+	assert.Equal(t, settings.RepositoryCache, other.RepositoryCache)
+	assert.Equal(t, dir, filepath.Dir(other.RegistryConfig))
+	assert.NotEqual(t, settings.RegistryConfig, other.RegistryConfig)
+
+	// the temporary directory is removed when the provider stops
+	require.NoError(t, RemoveTemporaryFiles())
+	assert.NoDirExists(t, dir)
+
+	// and created again if the provider is configured afterwards
+	require.NoError(t, disableCacheWrite(settings))
+	assert.NotEqual(t, dir, filepath.Dir(settings.RepositoryCache))
+	assert.DirExists(t, filepath.Dir(settings.RepositoryCache))
+}
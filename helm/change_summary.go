@@ -0,0 +1,115 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Actions of the change_summary attribute of a release
+const (
+	changeActionInstall = "install"
+	changeActionUpgrade = "upgrade"
+	changeActionNoop    = "no-op"
+)
+
+// changeSummary describes the planned change of a release: the action, the chart version
+// transition and, when the manifests of the state and of the plan are known, the count of
+// added, changed and removed manifest documents
+func changeSummary(state, plan *HelmReleaseModel, chartName string) string {
+	newVersion := plan.Version.ValueString()
+	if state == nil {
+		return strings.TrimSpace(fmt.Sprintf("%s %s %s", changeActionInstall, chartName, newVersion))
+	}
+
+	oldVersion := state.Version.ValueString()
+	action := changeActionNoop
+	if recomputeMetadata(*plan, state) || !versionsEqual(oldVersion, newVersion) {
+		action = changeActionUpgrade
+	}
+
+	version := newVersion
+	if !versionsEqual(oldVersion, newVersion) {
+		version = fmt.Sprintf("%s -> %s", oldVersion, newVersion)
+	}
+	summary := strings.TrimSpace(fmt.Sprintf("%s %s %s", action, chartName, version))
+
+	oldManifest, err := storedManifest(state)
+	if err != nil {
+		return summary
+	}
+	newManifest, err := storedManifest(plan)
+	if err != nil || oldManifest.IsNull() || oldManifest.IsUnknown() || newManifest.IsNull() || newManifest.IsUnknown() {
+		return summary
+	}
+	added, changed, removed, err := manifestChanges(oldManifest.ValueString(), newManifest.ValueString())
+	if err != nil {
+		return summary
+	}
+	return fmt.Sprintf("%s (manifest documents: %d added, %d changed, %d removed)", summary, added, changed, removed)
+}
+
+// manifestChanges counts the documents added, changed and removed between two JSON
+// manifests, see convertYAMLManifestToJSON
+func manifestChanges(oldManifest, newManifest string) (int, int, int, error) {
+	oldDocs := map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(oldManifest), &oldDocs); err != nil {
+		return 0, 0, 0, err
+	}
+	newDocs := map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(newManifest), &newDocs); err != nil {
+		return 0, 0, 0, err
+	}
+
+	var added, changed, removed int
+	for key, doc := range newDocs {
+		old, ok := oldDocs[key]
+		if !ok {
+			added++
+		} else if string(old) != string(doc) {
+			changed++
+		}
+	}
+	for key := range oldDocs {
+		if _, ok := newDocs[key]; !ok {
+			removed++
+		}
+	}
+	return added, changed, removed, nil
+}
+
+// setPlannedChangeSummary sets the change_summary attribute of the plan of resp, also on the
+// paths of ModifyPlan which leave the rest of the proposed plan unchanged. The summary of the
+// last change is kept while the release has no changes, so that it does not cause a diff on
+// its own.
+func setPlannedChangeSummary(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, state, plan *HelmReleaseModel, chartName string) {
+	summary := types.StringNull()
+	if state != nil {
+		summary = state.ChangeSummary
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("change_summary"), summary)...)
+	if resp.Diagnostics.HasError() || (state != nil && resp.Plan.Raw.Equal(req.State.Raw)) {
+		return
+	}
+	summary = types.StringValue(changeSummary(state, plan, chartName))
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("change_summary"), summary)...)
+}
+
+// releaseChangeSummary describes the change applied by the install or upgrade of r, for the
+// changes planned without a summary
+func releaseChangeSummary(r *release.Release) string {
+	action := changeActionInstall
+	if r.Version > 1 {
+		action = changeActionUpgrade
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s %s %s", action, r.Chart.Metadata.Name, r.Chart.Metadata.Version))
+}
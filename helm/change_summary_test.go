@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestChangeSummary(t *testing.T) {
+	model := func(version, manifest string) *HelmReleaseModel {
+		m := &HelmReleaseModel{
+			Chart:    types.StringValue("test-chart"),
+			Version:  types.StringValue(version),
+			Manifest: types.StringNull(),
+
+			Values:       types.ListNull(types.StringType),
+			Set:          types.ListNull(types.StringType),
+			SetList:      types.ListNull(types.StringType),
+			SetSensitive: types.ListNull(types.StringType),
+			ValuesFrom:   types.ListNull(types.StringType),
+			ValuesURL:    types.ListNull(types.ObjectType{AttrTypes: valuesURLAttrTypes()}),
+		}
+		if manifest != "" {
+			m.Manifest = types.StringValue(manifest)
+		}
+		return m
+	}
+
+	assert.Equal(t, "install test-chart 1.2.3", changeSummary(nil, model("1.2.3", ""), "test-chart"))
+	assert.Equal(t, "upgrade test-chart 1.2.3 -> 2.0.0", changeSummary(model("1.2.3", ""), model("2.0.0", ""), "test-chart"))
+	assert.Equal(t, "no-op test-chart v1.2.3", changeSummary(model("1.2.3", ""), model("v1.2.3", ""), "test-chart"))
+
+	changedValues := model("1.2.3", "")
+	changedValues.Values = types.ListValueMust(types.StringType, []attr.Value{types.StringValue("foo: bar")})
+	assert.Equal(t, "upgrade test-chart 1.2.3", changeSummary(model("1.2.3", ""), changedValues, "test-chart"))
+
+	oldManifest := `{"a":{"x":1},"b":{"x":1},"c":{"x":1}}`
+	newManifest := `{"a":{"x":1},"b":{"x":2},"d":{"x":1},"e":{"x":1}}`
+	assert.Equal(t, "upgrade test-chart 1.2.3 -> 2.0.0 (manifest documents: 2 added, 1 changed, 1 removed)",
+		changeSummary(model("1.2.3", oldManifest), model("2.0.0", newManifest), "test-chart"))
+}
+
+func TestManifestChanges(t *testing.T) {
+	added, changed, removed, err := manifestChanges(`{}`, `{"a":{}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 0, 0}, []int{added, changed, removed})
+
+	_, _, _, err = manifestChanges(`{}`, `not json`)
+	assert.Error(t, err)
+}
+
+func TestReleaseChangeSummary(t *testing.T) {
+	r := &release.Release{Version: 1, Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "test-chart", Version: "1.2.3"}}}
+	assert.Equal(t, "install test-chart 1.2.3", releaseChangeSummary(r))
+
+	r.Version = 2
+	assert.Equal(t, "upgrade test-chart 1.2.3", releaseChangeSummary(r))
+}
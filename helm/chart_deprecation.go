@@ -0,0 +1,154 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
+)
+
+// artifactHubChangesAnnotation lists the changes of a chart version, see
+// https://artifacthub.io/docs/topics/annotations/helm/
+const artifactHubChangesAnnotation = "artifacthub.io/changes"
+
+// artifactHubChange is a change of the artifacthub.io/changes annotation
+type artifactHubChange struct {
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+}
+
+// chartChanges returns the descriptions of the changes of the artifacthub.io/changes annotation,
+// which is either a list of descriptions or a list of changes with a kind
+func chartChanges(annotations map[string]string) []string {
+	raw := annotations[artifactHubChangesAnnotation]
+	if raw == "" {
+		return nil
+	}
+	var changes []artifactHubChange
+	if err := yaml.Unmarshal([]byte(raw), &changes); err == nil {
+		descriptions := make([]string, 0, len(changes))
+		for _, c := range changes {
+			if c.Kind != "" {
+				descriptions = append(descriptions, fmt.Sprintf("%s: %s", c.Kind, c.Description))
+			} else {
+				descriptions = append(descriptions, c.Description)
+			}
+		}
+		return descriptions
+	}
+	var descriptions []string
+	if err := yaml.Unmarshal([]byte(raw), &descriptions); err != nil {
+		return nil
+	}
+	return descriptions
+}
+
+// deprecatedChartWarning reports a chart whose Chart.yaml marks it deprecated
+func deprecatedChartWarning(c *chart.Chart) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if c.Metadata == nil || !c.Metadata.Deprecated {
+		return diags
+	}
+	detail := fmt.Sprintf("Chart %s %s is deprecated and may no longer receive fixes.", c.Metadata.Name, c.Metadata.Version)
+	var maintainers []string
+	for _, m := range c.Metadata.Maintainers {
+		if m.Email != "" {
+			maintainers = append(maintainers, fmt.Sprintf("%s <%s>", m.Name, m.Email))
+		} else if m.Name != "" {
+			maintainers = append(maintainers, m.Name)
+		}
+	}
+	if len(maintainers) > 0 {
+		detail += fmt.Sprintf(" Its maintainers are %s.", strings.Join(maintainers, ", "))
+	}
+	diags.AddAttributeWarning(path.Root("chart"), "Deprecated chart", detail)
+	return diags
+}
+
+// newerMajorVersion returns the latest version of the index entries of a chart whose major
+// version is greater than the major version of current, or nil
+func newerMajorVersion(versions repo.ChartVersions, current string, prerelease bool) (*repo.ChartVersion, error) {
+	cur, err := semver.NewVersion(current)
+	if err != nil {
+		return nil, err
+	}
+	var newest *repo.ChartVersion
+	var newestVersion *semver.Version
+	for _, cv := range versions {
+		v, err := semver.NewVersion(cv.Version)
+		if err != nil || v.Major() <= cur.Major() || (v.Prerelease() != "" && !prerelease) {
+			continue
+		}
+		if newestVersion == nil || v.GreaterThan(newestVersion) {
+			newest, newestVersion = cv, v
+		}
+	}
+	return newest, nil
+}
+
+// cachedRepositoryIndex returns the path of the cached index of the repository of a chart, and
+// the name of the chart in the index, or an empty path when the index is not cached
+func (m *Meta) cachedRepositoryIndex(cpo *action.ChartPathOptions, name string) (string, string) {
+	indexName := ""
+	if cpo.RepoURL != "" {
+		indexName = repositoryURLIndexName(cpo.RepoURL)
+	} else if entry := m.namedRepository(name); entry != nil {
+		indexName = entry.Name
+		_, name, _ = strings.Cut(name, "/")
+	}
+	if indexName == "" || !m.repositoryIndexCached(indexName) {
+		return "", ""
+	}
+	return filepath.Join(m.Settings.RepositoryCache, helmpath.CacheIndexFile(indexName)), name
+}
+
+// newerMajorVersionWarning reports a newer major version of the chart c in the cached index of
+// its repository, with its artifacthub.io/changes. Indexes are not downloaded for the check.
+func newerMajorVersionWarning(m *Meta, model *HelmReleaseModel, cpo *action.ChartPathOptions, name string, c *chart.Chart) diag.Diagnostics {
+	var diags diag.Diagnostics
+	indexPath, chartName := m.cachedRepositoryIndex(cpo, name)
+	if indexPath == "" || c.Metadata == nil {
+		return diags
+	}
+	idx, err := repo.LoadIndexFile(indexPath)
+	if err != nil {
+		return diags
+	}
+	newer, err := newerMajorVersion(idx.Entries[chartName], c.Metadata.Version, model.AllowPrerelease.ValueBool() || model.Devel.ValueBool())
+	if err != nil || newer == nil {
+		return diags
+	}
+
+	detail := fmt.Sprintf("Chart %s %s has a newer major version %s, which may contain breaking changes.", c.Metadata.Name, c.Metadata.Version, newer.Version)
+	if newer.Deprecated {
+		detail += fmt.Sprintf(" Version %s is deprecated too.", newer.Version)
+	}
+	if changes := chartChanges(newer.Annotations); len(changes) > 0 {
+		detail += fmt.Sprintf(" Changes of version %s:\n\n- %s", newer.Version, strings.Join(changes, "\n- "))
+	}
+	diags.AddAttributeWarning(path.Root("version"), "Newer major chart version available", detail)
+	return diags
+}
+
+// chartDeprecationWarnings reports a deprecated chart and a newer major version of the chart,
+// see warn_on_deprecated
+func chartDeprecationWarnings(m *Meta, model *HelmReleaseModel, cpo *action.ChartPathOptions, name string, c *chart.Chart) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if !model.WarnOnDeprecated.ValueBool() || !model.PreRenderedManifest.IsNull() {
+		return diags
+	}
+	diags.Append(deprecatedChartWarning(c)...)
+	diags.Append(newerMajorVersionWarning(m, model, cpo, name, c)...)
+	return diags
+}
@@ -0,0 +1,69 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+func TestDeprecatedChartWarning(t *testing.T) {
+	c := &chart.Chart{Metadata: &chart.Metadata{Name: "redis", Version: "1.2.3"}}
+	assert.Empty(t, deprecatedChartWarning(c))
+
+	c.Metadata.Deprecated = true
+	c.Metadata.Maintainers = []*chart.Maintainer{{Name: "Jane", Email: "jane@example.com"}, {Name: "ops"}}
+	diags := deprecatedChartWarning(c)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Deprecated chart", diags[0].Summary())
+	assert.Contains(t, diags[0].Detail(), "Jane <jane@example.com>, ops")
+}
+
+func TestChartChanges(t *testing.T) {
+	assert.Equal(t, []string{"removed: drop the legacy ingress", "changed: rename values"}, chartChanges(map[string]string{
+		artifactHubChangesAnnotation: "- kind: removed\n  description: drop the legacy ingress\n- kind: changed\n  description: rename values\n",
+	}))
+	assert.Equal(t, []string{"rename values"}, chartChanges(map[string]string{artifactHubChangesAnnotation: "- rename values\n"}))
+	assert.Empty(t, chartChanges(nil))
+}
+
+func TestNewerMajorVersionWarning(t *testing.T) {
+	m := testRepositoryIndexMeta(t, 0)
+	repoURL := "https://charts.example.com"
+	idx := repo.NewIndexFile()
+	for _, v := range []string{"1.2.3", "1.3.0", "2.0.0", "2.1.0", "3.0.0-rc.1"} {
+		md := &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "redis", Version: v}
+		if v == "2.1.0" {
+			md.Annotations = map[string]string{artifactHubChangesAnnotation: "- kind: removed\n  description: drop the legacy ingress\n"}
+		}
+		require.NoError(t, idx.MustAdd(md, "redis-"+v+".tgz", repoURL, ""))
+	}
+	require.NoError(t, os.MkdirAll(m.Settings.RepositoryCache, 0o755))
+	require.NoError(t, idx.WriteFile(filepath.Join(m.Settings.RepositoryCache, helmpath.CacheIndexFile(repositoryURLIndexName(repoURL))), 0o644))
+
+	c := &chart.Chart{Metadata: &chart.Metadata{Name: "redis", Version: "1.2.3"}}
+	model := &HelmReleaseModel{AllowPrerelease: types.BoolValue(false), Devel: types.BoolValue(false)}
+	diags := newerMajorVersionWarning(m, model, &action.ChartPathOptions{RepoURL: repoURL}, "redis", c)
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Detail(), "newer major version 2.1.0")
+	assert.Contains(t, diags[0].Detail(), "removed: drop the legacy ingress")
+
+	// the prereleases are only considered with allow_prerelease or devel
+	model.Devel = types.BoolValue(true)
+	diags = newerMajorVersionWarning(m, model, &action.ChartPathOptions{RepoURL: repoURL}, "redis", c)
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Detail(), "newer major version 3.0.0-rc.1")
+
+	// indexes which are not cached are not downloaded
+	assert.Empty(t, newerMajorVersionWarning(m, model, &action.ChartPathOptions{RepoURL: "https://other.example.com"}, "redis", c))
+}
@@ -0,0 +1,141 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/downloader"
+)
+
+const defaultChartDownloadConcurrency = 4
+
+// chartFetcher is shared by every resource and data source of the provider. Concurrent
+// requests for the same chart share a single download, successful downloads are reused
+// for the rest of the run, and the number of downloads running at once is bounded.
+type chartFetcher struct {
+	slots chan struct{}
+
+	mu    sync.Mutex
+	calls map[string]*chartFetch
+}
+
+type chartFetch struct {
+	done chan struct{}
+	path string
+	err  error
+}
+
+func newChartFetcher(concurrency int) *chartFetcher {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &chartFetcher{
+		slots: make(chan struct{}, concurrency),
+		calls: map[string]*chartFetch{},
+	}
+}
+
+// do runs fn once for key. Callers asking for a key that is being fetched wait for
+// the result of the running fetch. Failed fetches are not kept, so that they are
+// retried by the next caller.
+func (f *chartFetcher) do(ctx context.Context, key string, fn func() (string, error)) (string, error) {
+	f.mu.Lock()
+	if c, ok := f.calls[key]; ok {
+		f.mu.Unlock()
+		subsystemDebug(ctx, logSubsystemDownload, fmt.Sprintf("Waiting for chart fetch %q started by another operation", key))
+		select {
+		case <-c.done:
+			return c.path, c.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	c := &chartFetch{done: make(chan struct{})}
+	f.calls[key] = c
+	f.mu.Unlock()
+
+	c.path, c.err = f.run(ctx, fn)
+	if c.err != nil {
+		f.mu.Lock()
+		delete(f.calls, key)
+		f.mu.Unlock()
+	}
+	close(c.done)
+	return c.path, c.err
+}
+
+func (f *chartFetcher) run(ctx context.Context, fn func() (string, error)) (string, error) {
+	select {
+	case f.slots <- struct{}{}:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	defer func() { <-f.slots }()
+	return fn()
+}
+
+// locateChart downloads the chart through the chart fetcher of the provider, refreshing the
+// cached index of its repository according to refresh, see locateRepositoryChart
+func (m *Meta) locateChart(ctx context.Context, cpo *action.ChartPathOptions, name, refresh string) (string, error) {
+	locate := func() (path string, err error) {
+		ctx, span := m.startSpan(ctx, "helm.chart_download",
+			attribute.String("helm.chart.name", name),
+			attribute.String("helm.chart.repository", cpo.RepoURL),
+			attribute.String("helm.chart.version", cpo.Version),
+		)
+		defer func() { endSpan(span, err) }()
+
+		if _, _, ok := splitOCIDigest(name); ok {
+			return m.pullOCIDigest(ctx, name, cpo.PlainHTTP, cpo.InsecureSkipTLSverify)
+		}
+		subsystemDebug(ctx, logSubsystemDownload, fmt.Sprintf("Locating chart %s (repository %q, version %q)", name, cpo.RepoURL, cpo.Version))
+		return m.locateRepositoryChart(ctx, cpo, name, refresh)
+	}
+	if m.ChartFetcher == nil {
+		return locate()
+	}
+	return m.ChartFetcher.do(ctx, chartFetchKey(cpo, name), locate)
+}
+
+// chartFetchKey identifies the download of chart name. Charts downloaded with other
+// credentials or TLS settings are not shared, these are hashed so that the key, which is
+// logged, does not contain the password.
+func chartFetchKey(cpo *action.ChartPathOptions, name string) string {
+	credentials := sha256.Sum256([]byte(strings.Join([]string{
+		cpo.Username,
+		cpo.Password,
+		cpo.CaFile,
+		cpo.CertFile,
+		cpo.KeyFile,
+		strconv.FormatBool(cpo.PassCredentialsAll),
+		strconv.FormatBool(cpo.InsecureSkipTLSverify),
+		strconv.FormatBool(cpo.PlainHTTP),
+	}, "\x00")))
+	return fmt.Sprintf("chart|%s|%s|%s|%t|%s|%x", cpo.RepoURL, name, cpo.Version, cpo.Verify, cpo.Keyring, credentials[:8])
+}
+
+// updateDependencies runs the dependency update of a chart through the chart fetcher of
+// the provider, so that a chart directory shared by several releases is updated once
+func (m *Meta) updateDependencies(ctx context.Context, man *downloader.Manager) error {
+	update := func() (string, error) {
+		_, span := m.startSpan(ctx, "helm.dependency_update", attribute.String("helm.chart.path", man.ChartPath))
+		err := man.Update()
+		endSpan(span, err)
+		return man.ChartPath, err
+	}
+	if m.ChartFetcher == nil {
+		_, err := update()
+		return err
+	}
+	_, err := m.ChartFetcher.do(ctx, "dependencies|"+man.ChartPath, update)
+	return err
+}
@@ -0,0 +1,120 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+func TestChartFetcherDeduplicates(t *testing.T) {
+	f := newChartFetcher(2)
+	var calls int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	paths := make([]string, 5)
+	for i := range paths {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			path, err := f.do(context.Background(), "chart", func() (string, error) {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return "/tmp/chart.tgz", nil
+			})
+			assert.NoError(t, err)
+			paths[i] = path
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls)
+	for _, p := range paths {
+		assert.Equal(t, "/tmp/chart.tgz", p)
+	}
+
+	// successful fetches are reused
+	_, err := f.do(context.Background(), "chart", func() (string, error) {
+		atomic.AddInt32(&calls, 1)
+		return "", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestChartFetcherRetriesFailures(t *testing.T) {
+	f := newChartFetcher(1)
+
+	_, err := f.do(context.Background(), "chart", func() (string, error) {
+		return "", errors.New("repository unavailable")
+	})
+	assert.EqualError(t, err, "repository unavailable")
+
+	path, err := f.do(context.Background(), "chart", func() (string, error) {
+		return "/tmp/chart.tgz", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/chart.tgz", path)
+}
+
+func TestChartFetcherConcurrency(t *testing.T) {
+	f := newChartFetcher(2)
+	var running, maxRunning int32
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b", "c", "d", "e", "f"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			_, err := f.do(context.Background(), key, func() (string, error) {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					m := atomic.LoadInt32(&maxRunning)
+					if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return key, nil
+			})
+			assert.NoError(t, err)
+		}(key)
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, maxRunning, int32(2))
+}
+
+func TestChartFetchKey(t *testing.T) {
+	options := func() *action.ChartPathOptions {
+		return &action.ChartPathOptions{RepoURL: "https://charts.example.com", Version: "1.2.3", Username: "user", Password: "secret"}
+	}
+	key := chartFetchKey(options(), "app")
+	assert.Equal(t, key, chartFetchKey(options(), "app"))
+	assert.NotContains(t, key, "secret")
+
+	// downloads with other credentials are not shared
+	for name, modify := range map[string]func(*action.ChartPathOptions){
+		"password":         func(cpo *action.ChartPathOptions) { cpo.Password = "other" },
+		"ca_file":          func(cpo *action.ChartPathOptions) { cpo.CaFile = "ca.pem" },
+		"cert_file":        func(cpo *action.ChartPathOptions) { cpo.CertFile = "cert.pem" },
+		"key_file":         func(cpo *action.ChartPathOptions) { cpo.KeyFile = "key.pem" },
+		"pass_credentials": func(cpo *action.ChartPathOptions) { cpo.PassCredentialsAll = true },
+	} {
+		cpo := options()
+		modify(cpo)
+		assert.NotEqual(t, key, chartFetchKey(cpo, "app"), name)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func metadataDependencyAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"name":        types.StringType,
+		"version":     types.StringType,
+		"app_version": types.StringType,
+		"repository":  types.StringType,
+		"home":        types.StringType,
+		"sources":     types.ListType{ElemType: types.StringType},
+	}
+}
+
+// chartSources returns the sources of the metadata of a chart
+func chartSources(ctx context.Context, md *chart.Metadata) (types.List, diag.Diagnostics) {
+	sources := []string{}
+	if md != nil {
+		sources = append(sources, md.Sources...)
+	}
+	return types.ListValueFrom(ctx, types.StringType, sources)
+}
+
+// chartDependenciesMetadata returns the provenance of the subcharts of c, sorted by name. The
+// repository of a subchart is the one of the dependencies of Chart.yaml with its name or alias.
+func chartDependenciesMetadata(ctx context.Context, c *chart.Chart) (types.List, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	objectType := types.ObjectType{AttrTypes: metadataDependencyAttrTypes()}
+
+	repositories := map[string]string{}
+	if c.Metadata != nil {
+		for _, d := range c.Metadata.Dependencies {
+			repositories[d.Name] = d.Repository
+			if d.Alias != "" {
+				repositories[d.Alias] = d.Repository
+			}
+		}
+	}
+
+	subcharts := c.Dependencies()
+	sort.SliceStable(subcharts, func(i, j int) bool { return subcharts[i].Name() < subcharts[j].Name() })
+	dependencies := make([]attr.Value, 0, len(subcharts))
+	for _, sub := range subcharts {
+		md := sub.Metadata
+		if md == nil {
+			continue
+		}
+		sources, d := chartSources(ctx, md)
+		diags.Append(d...)
+		dependency, d := types.ObjectValue(objectType.AttrTypes, map[string]attr.Value{
+			"name":        types.StringValue(md.Name),
+			"version":     types.StringValue(md.Version),
+			"app_version": types.StringValue(md.AppVersion),
+			"repository":  types.StringValue(repositories[md.Name]),
+			"home":        types.StringValue(md.Home),
+			"sources":     sources,
+		})
+		diags.Append(d...)
+		dependencies = append(dependencies, dependency)
+	}
+	if diags.HasError() {
+		return types.ListNull(objectType), diags
+	}
+	list, d := types.ListValue(objectType, dependencies)
+	diags.Append(d...)
+	return list, diags
+}
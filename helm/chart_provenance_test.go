@@ -0,0 +1,50 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestChartDependenciesMetadata(t *testing.T) {
+	c := &chart.Chart{Metadata: &chart.Metadata{
+		Name:    "app",
+		Version: "1.0.0",
+		Dependencies: []*chart.Dependency{
+			{Name: "redis", Version: "~18.0.0", Repository: "https://charts.bitnami.com/bitnami", Alias: "cache"},
+			{Name: "common", Version: "2.x.x", Repository: "oci://registry-1.docker.io/bitnamicharts"},
+		},
+	}}
+	c.AddDependency(
+		&chart.Chart{Metadata: &chart.Metadata{Name: "cache", Version: "18.0.4", AppVersion: "7.2.1", Home: "https://bitnami.com", Sources: []string{"https://github.com/bitnami/charts"}}},
+		&chart.Chart{Metadata: &chart.Metadata{Name: "common", Version: "2.13.0"}},
+	)
+
+	list, diags := chartDependenciesMetadata(context.Background(), c)
+	require.False(t, diags.HasError(), diags)
+
+	var dependencies []struct {
+		Name       string   `tfsdk:"name"`
+		Version    string   `tfsdk:"version"`
+		AppVersion string   `tfsdk:"app_version"`
+		Repository string   `tfsdk:"repository"`
+		Home       string   `tfsdk:"home"`
+		Sources    []string `tfsdk:"sources"`
+	}
+	require.False(t, list.ElementsAs(context.Background(), &dependencies, false).HasError())
+	require.Len(t, dependencies, 2)
+	assert.Equal(t, "cache", dependencies[0].Name)
+	assert.Equal(t, "18.0.4", dependencies[0].Version)
+	assert.Equal(t, "7.2.1", dependencies[0].AppVersion)
+	assert.Equal(t, "https://charts.bitnami.com/bitnami", dependencies[0].Repository)
+	assert.Equal(t, []string{"https://github.com/bitnami/charts"}, dependencies[0].Sources)
+	assert.Equal(t, "common", dependencies[1].Name)
+	assert.Equal(t, "oci://registry-1.docker.io/bitnamicharts", dependencies[1].Repository)
+	assert.Empty(t, dependencies[1].Sources)
+}
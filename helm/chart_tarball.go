@@ -0,0 +1,32 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// writeChartTarball writes the base64 encoded chart archive of chart_tarball_base64 to a file
+// of a new temporary directory, so that the chart is located and loaded like a local chart. The
+// returned function removes the directory.
+func writeChartTarball(content string) (string, func(), error) {
+	b, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return "", nil, fmt.Errorf("chart_tarball_base64 is not base64 encoded: %w", err)
+	}
+	dir, err := os.MkdirTemp("", "terraform-provider-helm-chart-")
+	if err != nil {
+		return "", nil, err
+	}
+	remove := func() { os.RemoveAll(dir) }
+	path := filepath.Join(dir, "chart.tgz")
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		remove()
+		return "", nil, err
+	}
+	return path, remove, nil
+}
@@ -0,0 +1,43 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// testChartTarball returns the base64 encoded archive of the test chart
+func testChartTarball(t *testing.T) string {
+	c, err := loader.Load("testdata/charts/test-chart")
+	require.NoError(t, err)
+	archive, err := chartutil.Save(c, t.TempDir())
+	require.NoError(t, err)
+	b, err := os.ReadFile(archive)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+func TestWriteChartTarball(t *testing.T) {
+	path, remove, err := writeChartTarball(testChartTarball(t))
+	require.NoError(t, err)
+
+	c, err := loader.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "test-chart", c.Name())
+
+	remove()
+	_, err = os.Stat(filepath.Dir(path))
+	assert.True(t, os.IsNotExist(err))
+
+	_, _, err = writeChartTarball("not base64!")
+	assert.ErrorContains(t, err, "chart_tarball_base64 is not base64 encoded")
+}
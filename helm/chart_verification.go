@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/provenance"
+)
+
+func verificationAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"signed_by":    types.ListType{ElemType: types.StringType},
+		"fingerprint":  types.StringType,
+		"chart_digest": types.StringType,
+	}
+}
+
+// verificationObject returns the signer identities, the fingerprint of the signing key and the
+// digest of the chart of the provenance verification v
+func verificationObject(ctx context.Context, v *provenance.Verification) (types.Object, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	identities := []string{}
+	fingerprint := ""
+	if v.SignedBy != nil {
+		for name := range v.SignedBy.Identities {
+			identities = append(identities, name)
+		}
+		sort.Strings(identities)
+		if v.SignedBy.PrimaryKey != nil {
+			fingerprint = fmt.Sprintf("%X", v.SignedBy.PrimaryKey.Fingerprint)
+		}
+	}
+	signedBy, d := types.ListValueFrom(ctx, types.StringType, identities)
+	diags.Append(d...)
+	if diags.HasError() {
+		return types.ObjectNull(verificationAttrTypes()), diags
+	}
+	obj, d := types.ObjectValue(verificationAttrTypes(), map[string]attr.Value{
+		"signed_by":    signedBy,
+		"fingerprint":  types.StringValue(fingerprint),
+		"chart_digest": types.StringValue(v.FileHash),
+	})
+	diags.Append(d...)
+	return obj, diags
+}
+
+// chartVerification returns the verification of the chart archive at chartPath against the
+// keyring of the release with verify, null without verify. The chart is verified when it is
+// downloaded, its provenance file is read again to export the signer.
+func chartVerification(ctx context.Context, model *HelmReleaseModel, chartPath string) (types.Object, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if !model.Verify.ValueBool() || chartPath == "" {
+		return types.ObjectNull(verificationAttrTypes()), diags
+	}
+	v, err := downloader.VerifyChart(chartPath, model.Keyring.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("verify"), "Error verifying chart", fmt.Sprintf("Unable to verify chart %s: %s", chartPath, err))
+		return types.ObjectNull(verificationAttrTypes()), diags
+	}
+	return verificationObject(ctx, v)
+}
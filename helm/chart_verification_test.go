@@ -0,0 +1,64 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp" //nolint
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/provenance"
+)
+
+func TestChartVerification(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// a keyring with the key of the signer, which signs the chart archive
+	entity, err := openpgp.NewEntity("Helm Signer", "", "signer@example.com", nil)
+	require.NoError(t, err)
+	keyring := filepath.Join(dir, "secring.gpg")
+	f, err := os.Create(keyring)
+	require.NoError(t, err)
+	require.NoError(t, entity.SerializePrivate(f, nil))
+	require.NoError(t, f.Close())
+
+	chartPath, err := chartutil.Save(&chart.Chart{Metadata: &chart.Metadata{APIVersion: "v2", Name: "app", Version: "1.2.3"}}, dir)
+	require.NoError(t, err)
+	signatory := &provenance.Signatory{Entity: entity, KeyRing: openpgp.EntityList{entity}}
+	prov, err := signatory.ClearSign(chartPath)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(chartPath+".prov", []byte(prov), 0o600))
+	digest, err := provenance.DigestFile(chartPath)
+	require.NoError(t, err)
+
+	model := &HelmReleaseModel{Verify: types.BoolValue(true), Keyring: types.StringValue(keyring)}
+	verification, diags := chartVerification(ctx, model, chartPath)
+	require.False(t, diags.HasError(), diags)
+	attrs := verification.Attributes()
+	signedBy, _ := types.ListValueFrom(ctx, types.StringType, []string{"Helm Signer <signer@example.com>"})
+	assert.Equal(t, signedBy, attrs["signed_by"])
+	assert.Equal(t, types.StringValue(fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint)), attrs["fingerprint"])
+	assert.Equal(t, types.StringValue("sha256:"+digest), attrs["chart_digest"])
+
+	// the chart is not verified without verify
+	model.Verify = types.BoolValue(false)
+	verification, diags = chartVerification(ctx, model, chartPath)
+	require.False(t, diags.HasError(), diags)
+	assert.True(t, verification.IsNull())
+
+	// a chart without provenance file cannot be verified
+	require.NoError(t, os.Remove(chartPath+".prov"))
+	model.Verify = types.BoolValue(true)
+	_, diags = chartVerification(ctx, model, chartPath)
+	assert.True(t, diags.HasError())
+}
@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"sigs.k8s.io/yaml"
+)
+
+// commonMetadata is a post-renderer setting common_labels and common_annotations on every
+// object of the rendered manifests, like commonLabels and commonAnnotations of kustomize.
+// The common labels and annotations override the ones set by the chart.
+type commonMetadata struct {
+	labels      map[string]string
+	annotations map[string]string
+}
+
+func (c commonMetadata) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	manifests := releaseutil.SplitManifests(renderedManifests.String())
+	keys := make([]string, 0, len(manifests))
+	for k := range manifests {
+		keys = append(keys, k)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	out := &bytes.Buffer{}
+	for _, k := range keys {
+		object := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(manifests[k]), &object); err != nil {
+			return nil, err
+		}
+		if len(object) == 0 {
+			continue
+		}
+		metadata, ok := object["metadata"].(map[string]interface{})
+		if !ok {
+			metadata = map[string]interface{}{}
+			object["metadata"] = metadata
+		}
+		if err := mergeMetadata(metadata, "labels", c.labels); err != nil {
+			return nil, fmt.Errorf("%s: %w", manifestObjectName(metadata), err)
+		}
+		if err := mergeMetadata(metadata, "annotations", c.annotations); err != nil {
+			return nil, fmt.Errorf("%s: %w", manifestObjectName(metadata), err)
+		}
+
+		b, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString("---\n")
+		out.Write(b)
+	}
+	return out, nil
+}
+
+// mergeMetadata sets values in the map of metadata with the given key
+func mergeMetadata(metadata map[string]interface{}, key string, values map[string]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	current, ok := metadata[key].(map[string]interface{})
+	if !ok {
+		if metadata[key] != nil {
+			return fmt.Errorf("metadata.%s is not a map", key)
+		}
+		current = map[string]interface{}{}
+		metadata[key] = current
+	}
+	for k, v := range values {
+		current[k] = v
+	}
+	return nil
+}
+
+// manifestObjectName returns the name of an object in errors
+func manifestObjectName(metadata map[string]interface{}) string {
+	name, _ := metadata["name"].(string)
+	if name == "" {
+		return "object"
+	}
+	return fmt.Sprintf("object %q", name)
+}
@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommonMetadata(t *testing.T) {
+	manifests := `---
+# Source: chart/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  labels:
+    team: chart
+    app: web
+---
+# Source: chart/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+`
+	pr := commonMetadata{
+		labels:      map[string]string{"team": "payments"},
+		annotations: map[string]string{"cost-center": "42"},
+	}
+	out, err := pr.Run(bytes.NewBufferString(manifests))
+	require.NoError(t, err)
+	assert.Equal(t, `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  annotations:
+    cost-center: "42"
+  labels:
+    app: web
+    team: payments
+  name: config
+---
+apiVersion: v1
+kind: Service
+metadata:
+  annotations:
+    cost-center: "42"
+  labels:
+    team: payments
+  name: web
+spec:
+  selector:
+    app: web
+`, out.String())
+
+	_, err = pr.Run(bytes.NewBufferString("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  labels: invalid\n"))
+	assert.ErrorContains(t, err, `object "config": metadata.labels is not a map`)
+}
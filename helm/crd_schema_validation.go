@@ -0,0 +1,71 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// crdSchemaValidationKubeClient validates the objects of the built-in kinds of a manifest
+// against the OpenAPI schema of the cluster and skips the validation of the custom resources,
+// whose CRDs may be installed by the same release and be unknown to the validation yet
+type crdSchemaValidationKubeClient struct {
+	forwardingKubeClient
+}
+
+// skipCRDSchemaValidation wraps the Kubernetes client of actionConfig to skip the OpenAPI
+// validation of the custom resources, see skip_crd_schema_validation
+func skipCRDSchemaValidation(actionConfig *action.Configuration, skip bool) {
+	if !skip {
+		return
+	}
+	actionConfig.KubeClient = &crdSchemaValidationKubeClient{forwardingKubeClient: forwardingKubeClient{Interface: actionConfig.KubeClient}}
+}
+
+func (c *crdSchemaValidationKubeClient) Build(reader io.Reader, validate bool) (kube.ResourceList, error) {
+	if !validate {
+		return c.Interface.Build(reader, false)
+	}
+	manifest, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	// the built-in objects are built a first time only to be validated, so that the resources
+	// keep the order of the manifest
+	if builtin := builtinKindDocuments(string(manifest)); builtin != "" {
+		if _, err := c.Interface.Build(strings.NewReader(builtin), true); err != nil {
+			return nil, err
+		}
+	}
+	return c.Interface.Build(bytes.NewReader(manifest), false)
+}
+
+// builtinKindDocuments returns the documents of the manifest whose kinds are known to the
+// Kubernetes client, i.e. which are not custom resources
+func builtinKindDocuments(manifest string) string {
+	var out strings.Builder
+	for _, doc := range releaseutil.SplitManifests(manifest) {
+		var meta struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+		}
+		// invalid documents are left to the validation
+		if err := yaml.Unmarshal([]byte(doc), &meta); err == nil {
+			if meta.Kind == "" || !scheme.Scheme.Recognizes(schema.FromAPIVersionAndKind(meta.APIVersion, meta.Kind)) {
+				continue
+			}
+		}
+		out.WriteString("---\n" + doc + "\n")
+	}
+	return out.String()
+}
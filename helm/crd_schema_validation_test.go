@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+)
+
+const testCRDSchemaValidationManifest = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`
+
+// buildRecordingKubeClient records the manifests built by Helm and whether they were validated
+type buildRecordingKubeClient struct {
+	kubefake.PrintingKubeClient
+	validated []string
+	built     []string
+}
+
+func (c *buildRecordingKubeClient) Build(reader io.Reader, validate bool) (kube.ResourceList, error) {
+	b, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if validate {
+		c.validated = append(c.validated, string(b))
+	} else {
+		c.built = append(c.built, string(b))
+	}
+	return kube.ResourceList{}, nil
+}
+
+func TestSkipCRDSchemaValidation(t *testing.T) {
+	recorder := &buildRecordingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}}
+	actionConfig := &action.Configuration{KubeClient: recorder}
+	skipCRDSchemaValidation(actionConfig, true)
+
+	_, err := actionConfig.KubeClient.Build(strings.NewReader(testCRDSchemaValidationManifest), true)
+	require.NoError(t, err)
+	require.Len(t, recorder.validated, 1)
+	assert.Contains(t, recorder.validated[0], "kind: Deployment")
+	assert.Contains(t, recorder.validated[0], "kind: Service\n")
+	assert.NotContains(t, recorder.validated[0], "ServiceMonitor")
+	assert.Equal(t, []string{testCRDSchemaValidationManifest}, recorder.built)
+
+	// without validation the manifest is built once
+	recorder.validated, recorder.built = nil, nil
+	_, err = actionConfig.KubeClient.Build(strings.NewReader(testCRDSchemaValidationManifest), false)
+	require.NoError(t, err)
+	assert.Empty(t, recorder.validated)
+	assert.Len(t, recorder.built, 1)
+}
+
+func TestSkipCRDSchemaValidationDisabled(t *testing.T) {
+	recorder := &buildRecordingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}}
+	actionConfig := &action.Configuration{KubeClient: recorder}
+	skipCRDSchemaValidation(actionConfig, false)
+	assert.Same(t, recorder, actionConfig.KubeClient)
+}
+
+func TestSkipCRDSchemaValidationForwardsDeletions(t *testing.T) {
+	recorder := &deleteRecordingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}}
+	actionConfig := &action.Configuration{KubeClient: recorder}
+	skipCRDSchemaValidation(actionConfig, true)
+
+	// Helm waits for the hooks with the before-hook-creation policy and the objects of
+	// uninstalls to be deleted only when the client implements these interfaces
+	ext, ok := actionConfig.KubeClient.(kube.InterfaceExt)
+	require.True(t, ok)
+	require.NoError(t, ext.WaitForDelete(nil, time.Second))
+	_, ok = actionConfig.KubeClient.(kube.InterfaceDeletionPropagation)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"wait_for_delete"}, recorder.calls)
+}
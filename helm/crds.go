@@ -0,0 +1,53 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// Values of the crd_policy attribute of a release
+const (
+	// crdPolicySkip does not install the CRDs of the crds/ directories
+	crdPolicySkip = "skip"
+	// crdPolicyInstallOnce installs the missing CRDs and never upgrades them, as Helm does
+	crdPolicyInstallOnce = "install-once"
+	// crdPolicyManage also creates or replaces the CRDs on every upgrade
+	crdPolicyManage = "manage"
+)
+
+var crdPolicies = []string{crdPolicySkip, crdPolicyInstallOnce, crdPolicyManage}
+
+// crdPolicy returns the CRD policy of a release, crd_policy defaults to skip with skip_crds
+func crdPolicy(model *HelmReleaseModel) string {
+	if p := model.CrdPolicy.ValueString(); p != "" {
+		return p
+	}
+	if model.SkipCrds.ValueBool() {
+		return crdPolicySkip
+	}
+	return crdPolicyInstallOnce
+}
+
+// upgradeCRDs creates the missing CRDs of the crds/ directories of the chart and of its
+// dependencies, and replaces the existing ones. Helm only installs them. The CRDs too large to
+// be replaced client-side are applied server-side.
+func upgradeCRDs(ctx context.Context, cfg *action.Configuration, c *chart.Chart) error {
+	kubeClient := &largeObjectKubeClient{forwardingKubeClient: forwardingKubeClient{Interface: cfg.KubeClient}, ctx: ctx, apply: applyServerSide}
+	for _, obj := range c.CRDObjects() {
+		resources, err := kubeClient.Build(bytes.NewBuffer(obj.File.Data), false)
+		if err != nil {
+			return fmt.Errorf("failed to parse CRDs of %s: %w", obj.Filename, err)
+		}
+		if _, err := kubeClient.Update(resources, resources, true); err != nil {
+			return fmt.Errorf("failed to upgrade CRDs of %s: %w", obj.Filename, err)
+		}
+	}
+	return nil
+}
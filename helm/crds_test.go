@@ -0,0 +1,53 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+)
+
+func TestCRDPolicy(t *testing.T) {
+	cases := []struct {
+		policy   types.String
+		skipCRDs bool
+		expected string
+	}{
+		{types.StringNull(), false, crdPolicyInstallOnce},
+		{types.StringNull(), true, crdPolicySkip},
+		{types.StringValue(crdPolicyManage), false, crdPolicyManage},
+		{types.StringValue(crdPolicySkip), false, crdPolicySkip},
+	}
+	for _, c := range cases {
+		model := &HelmReleaseModel{CrdPolicy: c.policy, SkipCrds: types.BoolValue(c.skipCRDs)}
+		assert.Equal(t, c.expected, crdPolicy(model))
+	}
+}
+
+func TestUpgradeCRDs(t *testing.T) {
+	c, err := loader.Load("testdata/charts/crds-chart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	updateErr := errors.New("update failed")
+	cfg := &action.Configuration{KubeClient: &kubefake.FailingKubeClient{
+		PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard},
+		UpdateError:        updateErr,
+	}}
+
+	err = upgradeCRDs(context.Background(), cfg, c)
+	assert.ErrorIs(t, err, updateErr)
+
+	// charts without CRDs have nothing to upgrade
+	assert.NoError(t, upgradeCRDs(context.Background(), cfg, &chart.Chart{Metadata: &chart.Metadata{Name: "empty"}}))
+}
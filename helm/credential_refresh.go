@@ -0,0 +1,166 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// credentialRefreshTimeout bounds the time an exec plugin may take to issue new credentials
+const credentialRefreshTimeout = time.Minute
+
+// tokenRefresher returns a new bearer token for a Kubernetes client
+type tokenRefresher func(ctx context.Context) (string, error)
+
+// credentialRefreshTransport retries once the requests rejected with 401 Unauthorized, with a
+// new bearer token, so that long applies survive the rotation of short-lived tokens. The new
+// token replaces the expired one for the following requests.
+type credentialRefreshTransport struct {
+	rt      http.RoundTripper
+	refresh tokenRefresher
+
+	mu    sync.Mutex
+	token string
+}
+
+func newCredentialRefreshTransport(refresh tokenRefresher) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &credentialRefreshTransport{rt: rt, refresh: refresh}
+	}
+}
+
+// withToken returns a copy of req authenticated with token, unless it is empty
+func withToken(req *http.Request, token string) (*http.Request, error) {
+	if token == "" {
+		return req, nil
+	}
+	r := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, errors.New("the request body cannot be sent again")
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r, nil
+}
+
+func (t *credentialRefreshTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	token := t.token
+	t.mu.Unlock()
+
+	authenticated, err := withToken(req, token)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.rt.RoundTrip(authenticated)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	t.mu.Lock()
+	if t.token == token {
+		tflog.Info(req.Context(), "Kubernetes API request unauthorized, refreshing credentials")
+		newToken, err := t.refresh(req.Context())
+		if err != nil || newToken == "" {
+			t.mu.Unlock()
+			tflog.Warn(req.Context(), fmt.Sprintf("Unable to refresh Kubernetes credentials: %v", err))
+			return resp, nil
+		}
+		t.token = newToken
+	}
+	token = t.token
+	t.mu.Unlock()
+
+	retry, err := withToken(req, token)
+	if err != nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+	return t.rt.RoundTrip(retry)
+}
+
+// credentialRefresher returns how the bearer token of config is refreshed: by requesting a new
+// token of the service account of deploy_as_service_account, running the exec plugin again or
+// reading the token file again. It is nil for static credentials.
+func (k *KubeConfig) credentialRefresher(config *rest.Config) tokenRefresher {
+	switch {
+	case k.RefreshToken != nil:
+		return k.RefreshToken
+	case config.ExecProvider != nil:
+		execConfig := config.ExecProvider
+		return func(ctx context.Context) (string, error) {
+			return execCredentialToken(ctx, execConfig)
+		}
+	case config.BearerTokenFile != "":
+		path := config.BearerTokenFile
+		return func(context.Context) (string, error) {
+			b, err := os.ReadFile(path)
+			return strings.TrimSpace(string(b)), err
+		}
+	}
+	return nil
+}
+
+// execCredentialToken runs the exec plugin of execConfig and returns the bearer token of the
+// ExecCredential it prints
+func execCredentialToken(ctx context.Context, execConfig *clientcmdapi.ExecConfig) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, credentialRefreshTimeout)
+	defer cancel()
+
+	info, err := json.Marshal(map[string]interface{}{
+		"apiVersion": execConfig.APIVersion,
+		"kind":       "ExecCredential",
+		"spec":       map[string]interface{}{"interactive": false},
+	})
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.CommandContext(ctx, execConfig.Command, execConfig.Args...)
+	cmd.Env = append(os.Environ(), "KUBERNETES_EXEC_INFO="+string(info))
+	for _, env := range execConfig.Env {
+		cmd.Env = append(cmd.Env, env.Name+"="+env.Value)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("exec plugin %s failed: %w: %s", execConfig.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var credential struct {
+		Status struct {
+			Token string `json:"token"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &credential); err != nil {
+		return "", fmt.Errorf("exec plugin %s printed an invalid ExecCredential: %w", execConfig.Command, err)
+	}
+	if credential.Status.Token == "" {
+		return "", fmt.Errorf("exec plugin %s did not issue a token", execConfig.Command)
+	}
+	return credential.Status.Token, nil
+}
@@ -0,0 +1,105 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestCredentialRefreshTransport(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	refreshes := 0
+	refresh := func(context.Context) (string, error) {
+		refreshes++
+		return "fresh", nil
+	}
+	client := &http.Client{Transport: newCredentialRefreshTransport(refresh)(http.DefaultTransport)}
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"kind":"ConfigMap"}`))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer expired")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	// the body is sent again with the new token
+	assert.Equal(t, []string{`{"kind":"ConfigMap"}`, `{"kind":"ConfigMap"}`}, bodies)
+
+	// the new token is used from now on
+	req, err = http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer expired")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, refreshes)
+	assert.Len(t, bodies, 3)
+}
+
+func TestCredentialRefreshTransportFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	refresh := func(context.Context) (string, error) {
+		return "", errors.New("no credentials")
+	}
+	client := &http.Client{Transport: newCredentialRefreshTransport(refresh)(http.DefaultTransport)}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestCredentialRefresher(t *testing.T) {
+	ctx := context.Background()
+	kc := &KubeConfig{}
+	assert.Nil(t, kc.credentialRefresher(&rest.Config{BearerToken: "static"}))
+
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("rotated\n"), 0o600))
+	token, err := kc.credentialRefresher(&rest.Config{BearerTokenFile: path})(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", token)
+
+	token, err = kc.credentialRefresher(&rest.Config{ExecProvider: &clientcmdapi.ExecConfig{
+		APIVersion: "client.authentication.k8s.io/v1",
+		Command:    "sh",
+		Args:       []string{"-c", `echo "{\"kind\":\"ExecCredential\",\"status\":{\"token\":\"$TOKEN\"}}"`},
+		Env:        []clientcmdapi.ExecEnvVar{{Name: "TOKEN", Value: "issued"}},
+	}})(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "issued", token)
+
+	kc.RefreshToken = func(context.Context) (string, error) { return "service-account", nil }
+	token, err = kc.credentialRefresher(&rest.Config{BearerTokenFile: path})(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "service-account", token)
+}
@@ -0,0 +1,202 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+var (
+	_ datasource.DataSource              = &HelmImport{}
+	_ datasource.DataSourceWithConfigure = &HelmImport{}
+)
+
+// importStatuses are the statuses of the releases helm_import returns by default. Uninstalled
+// releases kept in the history cannot be imported.
+var importStatuses = []string{
+	release.StatusDeployed.String(),
+	release.StatusFailed.String(),
+}
+
+// invalidAddressCharacters matches the characters not allowed in Terraform identifiers
+var invalidAddressCharacters = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+func NewHelmImport() datasource.DataSource {
+	return &HelmImport{}
+}
+
+// HelmImport represents the data source generating the import blocks of the releases of a
+// namespace or of the cluster
+type HelmImport struct {
+	meta *Meta
+}
+
+// HelmImportModel holds the filters and the imports of the helm_import data source
+type HelmImportModel struct {
+	ID           types.String `tfsdk:"id"`
+	Namespace    types.String `tfsdk:"namespace"`
+	Statuses     types.List   `tfsdk:"statuses"`
+	Selector     types.String `tfsdk:"selector"`
+	Filter       types.String `tfsdk:"filter"`
+	ResourceName types.String `tfsdk:"resource_name"`
+	IDs          types.Map    `tfsdk:"ids"`
+	ImportBlocks types.String `tfsdk:"import_blocks"`
+}
+
+func (d *HelmImport) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData != nil {
+		d.meta = req.ProviderData.(*Meta)
+	}
+}
+
+func (d *HelmImport) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_import"
+}
+
+func (d *HelmImport) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Data source to generate the import identifiers and import blocks of the Helm releases of a namespace or of the cluster.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"namespace": schema.StringAttribute{
+				Optional:    true,
+				Description: "Namespace to import the releases of. Defaults to all namespaces.",
+			},
+			"statuses": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Only import releases with one of these statuses. Defaults to deployed and failed releases.",
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(stringvalidator.OneOf(releaseStatuses...)),
+				},
+			},
+			"selector": schema.StringAttribute{
+				Optional:    true,
+				Description: "Label selector matched against the labels of the release storage objects, e.g. `owner=helm,team=platform`.",
+			},
+			"filter": schema.StringAttribute{
+				Optional:    true,
+				Description: "Regular expression the release names must match.",
+			},
+			"resource_name": schema.StringAttribute{
+				Optional:    true,
+				Description: "Name of the helm_release resource the releases are imported to in import_blocks. Defaults to a resource per release, named after the release.",
+			},
+			"ids": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Import identifiers of the matching releases, keyed by the name of the release, prefixed with its namespace when namespace is not set.",
+			},
+			"import_blocks": schema.StringAttribute{
+				Computed:    true,
+				Description: "Import blocks of the matching releases, to be written to a configuration file and used with `terraform plan -generate-config-out`.",
+			},
+		},
+	}
+}
+
+func (d *HelmImport) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state HelmImportModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	meta := d.meta
+	namespace := state.Namespace.ValueString()
+	ctx, span := meta.startSpan(ctx, "helm_import.read", releaseSpanAttributes(namespace, "")...)
+	defer func() { meta.endOperationSpan(ctx, span, resp.Diagnostics) }()
+
+	statuses := importStatuses
+	if !state.Statuses.IsNull() {
+		statuses = nil
+		resp.Diagnostics.Append(state.Statuses.ElementsAs(ctx, &statuses, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// an empty namespace configures the storage driver for all namespaces, and mock releases
+	// only exist in the state
+	var releases []*release.Release
+	if !meta.Mock {
+		actionConfig, err := meta.GetHelmConfiguration(ctx, namespace)
+		if err != nil {
+			resp.Diagnostics.AddError("Error getting helm configuration", fmt.Sprintf("Unable to get Helm configuration for namespace %q: %s", namespace, err))
+			return
+		}
+		releases, err = listReleases(actionConfig, statuses, state.Selector.ValueString(), state.Filter.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("Error listing releases", fmt.Sprintf("Unable to list Helm releases: %s", err))
+			return
+		}
+	}
+
+	ids := releaseImportIDs(releases, namespace == "")
+	idsMap, diags := types.MapValueFrom(ctx, types.StringType, ids)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.IDs = idsMap
+	state.ImportBlocks = types.StringValue(importBlocks(ids, state.ResourceName.ValueString()))
+	state.ID = types.StringValue(strings.Join([]string{namespace, strings.Join(statuses, ","), state.Selector.ValueString(), state.Filter.ValueString()}, "/"))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// releaseImportIDs returns the import identifiers of releases keyed by a Terraform identifier
+// made of the release name, prefixed with its namespace when the releases of several namespaces
+// are imported
+func releaseImportIDs(releases []*release.Release, prefixNamespace bool) map[string]string {
+	ids := map[string]string{}
+	for _, r := range releases {
+		key := r.Name
+		if prefixNamespace {
+			key = r.Namespace + "_" + r.Name
+		}
+		key = invalidAddressCharacters.ReplaceAllString(key, "_")
+		if key[0] >= '0' && key[0] <= '9' || key[0] == '-' {
+			key = "release_" + key
+		}
+		ids[key] = fmt.Sprintf("%s/%s", r.Namespace, r.Name)
+	}
+	return ids
+}
+
+// importBlocks generates the import blocks of ids, to a resource per key, or to the instances of
+// resourceName keyed by the keys of ids when it is set
+func importBlocks(ids map[string]string, resourceName string) string {
+	keys := make([]string, 0, len(ids))
+	for k := range ids {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		to := "helm_release." + k
+		if resourceName != "" {
+			to = fmt.Sprintf("helm_release.%s[%q]", resourceName, k)
+		}
+		fmt.Fprintf(&b, "import {\n  to = %s\n  id = %q\n}\n", to, ids[k])
+	}
+	return b.String()
+}
@@ -0,0 +1,100 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestReleaseImportIDs(t *testing.T) {
+	releases := []*release.Release{
+		{Name: "ingress-nginx", Namespace: "ingress"},
+		{Name: "redis.cache", Namespace: "data"},
+		{Name: "3scale", Namespace: "api"},
+	}
+
+	assert.Equal(t, map[string]string{
+		"ingress-nginx":  "ingress/ingress-nginx",
+		"redis_cache":    "data/redis.cache",
+		"release_3scale": "api/3scale",
+	}, releaseImportIDs(releases, false))
+
+	assert.Equal(t, map[string]string{
+		"ingress_ingress-nginx": "ingress/ingress-nginx",
+		"data_redis_cache":      "data/redis.cache",
+		"api_3scale":            "api/3scale",
+	}, releaseImportIDs(releases, true))
+}
+
+func TestImportBlocks(t *testing.T) {
+	ids := map[string]string{
+		"redis":         "data/redis",
+		"ingress-nginx": "ingress/ingress-nginx",
+	}
+
+	assert.Equal(t, `import {
+  to = helm_release.ingress-nginx
+  id = "ingress/ingress-nginx"
+}
+
+import {
+  to = helm_release.redis
+  id = "data/redis"
+}
+`, importBlocks(ids, ""))
+
+	assert.Equal(t, `import {
+  to = helm_release.this["ingress-nginx"]
+  id = "ingress/ingress-nginx"
+}
+
+import {
+  to = helm_release.this["redis"]
+  id = "data/redis"
+}
+`, importBlocks(ids, "this"))
+
+	assert.Empty(t, importBlocks(nil, ""))
+}
+
+func TestAccDataImport_basic(t *testing.T) {
+	name := randName("import")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	datasourceAddress := fmt.Sprintf("data.helm_import.%s", testResourceName)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{{
+			Config: testAccDataHelmImportConfigBasic(testResourceName, namespace, name),
+			Check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr(datasourceAddress, "ids.%", "1"),
+				resource.TestCheckResourceAttr(datasourceAddress, fmt.Sprintf("ids.%s", name), fmt.Sprintf("%s/%s", namespace, name)),
+				resource.TestCheckResourceAttr(datasourceAddress, "import_blocks", fmt.Sprintf("import {\n  to = helm_release.%s\n  id = \"%s/%s\"\n}\n", name, namespace, name)),
+			),
+		}},
+	})
+}
+
+func testAccDataHelmImportConfigBasic(resource, ns, name string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name       = %q
+			namespace  = %q
+			repository = %q
+			chart      = "test-chart"
+			version    = "1.2.3"
+		}
+
+		data "helm_import" "%s" {
+			namespace = helm_release.%s.namespace
+		}
+	`, resource, name, ns, testRepositoryURL, resource, resource)
+}
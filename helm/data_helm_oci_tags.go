@@ -0,0 +1,264 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"go.opentelemetry.io/otel/attribute"
+	"helm.sh/helm/v3/pkg/registry"
+)
+
+var (
+	_ datasource.DataSource              = &HelmOCITags{}
+	_ datasource.DataSourceWithConfigure = &HelmOCITags{}
+)
+
+// ociReferencePattern matches the OCI references of charts without a tag or a digest
+var ociReferencePattern = regexp.MustCompile(`^oci://[^@]+/[^:@/]+/?$`)
+
+func NewHelmOCITags() datasource.DataSource {
+	return &HelmOCITags{}
+}
+
+// HelmOCITags represents the data source listing the tags of a chart of an OCI registry
+type HelmOCITags struct {
+	meta *Meta
+}
+
+// HelmOCITagsModel holds the chart and the tags of the helm_oci_tags data source
+type HelmOCITagsModel struct {
+	ID                    types.String `tfsdk:"id"`
+	Chart                 types.String `tfsdk:"chart"`
+	VersionConstraint     types.String `tfsdk:"version_constraint"`
+	AllowPrerelease       types.Bool   `tfsdk:"allow_prerelease"`
+	IncludeDetails        types.Bool   `tfsdk:"include_details"`
+	PlainHTTP             types.Bool   `tfsdk:"plain_http"`
+	InsecureSkipTLSVerify types.Bool   `tfsdk:"insecure_skip_tls_verify"`
+	Tags                  types.List   `tfsdk:"tags"`
+	Latest                types.String `tfsdk:"latest"`
+	Versions              types.List   `tfsdk:"versions"`
+}
+
+func ociTagVersionAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"tag":         types.StringType,
+		"digest":      types.StringType,
+		"app_version": types.StringType,
+		"annotations": types.MapType{ElemType: types.StringType},
+	}
+}
+
+func (d *HelmOCITags) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData != nil {
+		d.meta = req.ProviderData.(*Meta)
+	}
+}
+
+func (d *HelmOCITags) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_oci_tags"
+}
+
+func (d *HelmOCITags) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Data source to list the tags of a chart of an OCI registry.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"chart": schema.StringAttribute{
+				Required:    true,
+				Description: "OCI reference of the chart, e.g. oci://registry.example.com/charts/app.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(ociReferencePattern, "must be an oci:// reference without a tag or digest"),
+				},
+			},
+			"version_constraint": schema.StringAttribute{
+				Optional:    true,
+				Description: "Semver constraint the listed tags must satisfy, e.g. ~1.2.",
+			},
+			"allow_prerelease": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Let version_constraint match prerelease tags. Prerelease tags are always listed without version_constraint.",
+			},
+			"include_details": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Read the digest, app version and annotations of every listed tag into versions, with one request to the registry per tag.",
+			},
+			"plain_http": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Use insecure HTTP connections to the registry.",
+			},
+			"insecure_skip_tls_verify": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Skip the verification of the certificate of the registry.",
+			},
+			"tags": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Semver tags of the chart, latest first.",
+			},
+			"latest": schema.StringAttribute{
+				Computed:    true,
+				Description: "Latest tag of the chart, null without tags.",
+			},
+			"versions": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Details of the tags of the chart, latest first, with include_details.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"tag": schema.StringAttribute{
+							Computed:    true,
+							Description: "Tag of the chart.",
+						},
+						"digest": schema.StringAttribute{
+							Computed:    true,
+							Description: "Digest of the manifest of the tag, which the chart can be pinned to.",
+						},
+						"app_version": schema.StringAttribute{
+							Computed:    true,
+							Description: "App version of the chart of the tag.",
+						},
+						"annotations": schema.MapAttribute{
+							Computed:    true,
+							ElementType: types.StringType,
+							Description: "Annotations of the Chart.yaml of the tag.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *HelmOCITags) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state HelmOCITagsModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	meta := d.meta
+	chart := strings.TrimSuffix(state.Chart.ValueString(), "/")
+	ctx, span := meta.startSpan(ctx, "helm_oci_tags.read", attribute.String("helm.chart", chart))
+	defer func() { meta.endOperationSpan(ctx, span, resp.Diagnostics) }()
+
+	tags := []string{}
+	versions := []attr.Value{}
+	versionType := types.ObjectType{AttrTypes: ociTagVersionAttrTypes()}
+	// mock providers do not access registries, the charts have no tags
+	if !meta.Mock {
+		registryClient := meta.ociRegistryClient(chart, state.PlainHTTP.ValueBool(), state.InsecureSkipTLSVerify.ValueBool())
+		if registryClient == nil {
+			resp.Diagnostics.AddError("Error listing chart tags", "Registry client is not configured")
+			return
+		}
+		ref := strings.TrimPrefix(chart, fmt.Sprintf("%s://", registry.OCIScheme))
+		all, err := registryClient.Tags(ref)
+		if err != nil {
+			resp.Diagnostics.AddError("Error listing chart tags", fmt.Sprintf("Unable to list the tags of %s: %s", chart, err))
+			return
+		}
+		tags, err = filterOCITags(all, state.VersionConstraint.ValueString(), state.AllowPrerelease.ValueBool())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("version_constraint"), "Invalid version constraint", err.Error())
+			return
+		}
+
+		if state.IncludeDetails.ValueBool() {
+			for _, tag := range tags {
+				version, err := readOCITagVersion(registryClient, ref, tag)
+				if err != nil {
+					resp.Diagnostics.AddError("Error reading chart tag", fmt.Sprintf("Unable to read tag %s of %s: %s", tag, chart, err))
+					return
+				}
+				v, diags := types.ObjectValueFrom(ctx, versionType.AttrTypes, version)
+				resp.Diagnostics.Append(diags...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+				versions = append(versions, v)
+			}
+		}
+	}
+
+	tagList, diags := types.ListValueFrom(ctx, types.StringType, tags)
+	resp.Diagnostics.Append(diags...)
+	versionList, diags := types.ListValue(versionType, versions)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.ID = types.StringValue(chart)
+	state.Tags = tagList
+	state.Versions = versionList
+	state.Latest = types.StringNull()
+	if len(tags) > 0 {
+		state.Latest = types.StringValue(tags[0])
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// filterOCITags returns the tags, sorted latest first, satisfying constraint. Constraints only
+// match prereleases with allowPrerelease, see prereleaseConstraint.
+func filterOCITags(tags []string, constraint string, allowPrerelease bool) ([]string, error) {
+	filtered := []string{}
+	if strings.TrimSpace(constraint) == "" {
+		return append(filtered, tags...), nil
+	}
+	if allowPrerelease {
+		constraint = prereleaseConstraint(constraint)
+	}
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		v, err := semver.NewVersion(tag)
+		if err == nil && c.Check(v) {
+			filtered = append(filtered, tag)
+		}
+	}
+	return filtered, nil
+}
+
+// ociTagVersion is an element of the versions of the helm_oci_tags data source
+type ociTagVersion struct {
+	Tag         string            `tfsdk:"tag"`
+	Digest      string            `tfsdk:"digest"`
+	AppVersion  string            `tfsdk:"app_version"`
+	Annotations map[string]string `tfsdk:"annotations"`
+}
+
+// readOCITagVersion reads the digest and the Chart.yaml of the tag of ref. Only the manifest and
+// the config of the tag are pulled, not the chart.
+func readOCITagVersion(registryClient *registry.Client, ref, tag string) (ociTagVersion, error) {
+	result, err := registryClient.Pull(fmt.Sprintf("%s:%s", ref, tag),
+		registry.PullOptWithChart(false),
+		registry.PullOptWithProv(true),
+		registry.PullOptIgnoreMissingProv(true),
+	)
+	if err != nil {
+		return ociTagVersion{}, err
+	}
+	version := ociTagVersion{Tag: tag, Digest: result.Manifest.Digest, Annotations: map[string]string{}}
+	if md := result.Chart.Meta; md != nil {
+		version.AppVersion = md.AppVersion
+		for k, v := range md.Annotations {
+			version.Annotations[k] = v
+		}
+	}
+	return version, nil
+}
@@ -0,0 +1,139 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/registry"
+)
+
+// newTestOCIRegistry serves the chart app with the tags of versions, whose Chart.yaml has
+// annotations
+func newTestOCIRegistry(t *testing.T, versions ...string) *httptest.Server {
+	t.Helper()
+	blobs := map[string][]byte{}
+	manifests := map[string][]byte{}
+	digest := func(data []byte) string {
+		return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+	}
+	for _, v := range versions {
+		config, err := json.Marshal(map[string]interface{}{
+			"apiVersion":  "v2",
+			"name":        "app",
+			"version":     v,
+			"appVersion":  "app-" + v,
+			"annotations": map[string]string{"artifacthub.io/changes": "- release " + v},
+		})
+		require.NoError(t, err)
+		blobs[digest(config)] = config
+		manifest, err := json.Marshal(map[string]interface{}{
+			"schemaVersion": 2,
+			"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+			"config":        map[string]interface{}{"mediaType": registry.ConfigMediaType, "digest": digest(config), "size": len(config)},
+			"layers":        []interface{}{},
+		})
+		require.NoError(t, err)
+		manifests[strings.ReplaceAll(v, "+", "_")] = manifest
+		manifests[digest(manifest)] = manifest
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/charts/app/tags/list":
+			tags := append([]string{"latest"}, versions...)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"name": "charts/app", "tags": tags})
+		case strings.HasPrefix(r.URL.Path, "/v2/charts/app/manifests/"):
+			manifest, ok := manifests[strings.TrimPrefix(r.URL.Path, "/v2/charts/app/manifests/")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			w.Header().Set("Docker-Content-Digest", digest(manifest))
+			w.Header().Set("Content-Length", fmt.Sprint(len(manifest)))
+			if r.Method != http.MethodHead {
+				_, _ = w.Write(manifest)
+			}
+		case strings.HasPrefix(r.URL.Path, "/v2/charts/app/blobs/"):
+			blob, ok := blobs[strings.TrimPrefix(r.URL.Path, "/v2/charts/app/blobs/")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Length", fmt.Sprint(len(blob)))
+			if r.Method != http.MethodHead {
+				_, _ = w.Write(blob)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFilterOCITags(t *testing.T) {
+	tags := []string{"2.0.0-rc.1", "1.3.0", "1.2.10", "1.2.9", "1.2.9-rc.1", "1.1.0"}
+
+	filtered, err := filterOCITags(tags, "", false)
+	require.NoError(t, err)
+	assert.Equal(t, tags, filtered)
+
+	filtered, err = filterOCITags(tags, "~1.2", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.2.10", "1.2.9"}, filtered)
+
+	filtered, err = filterOCITags(tags, "~1.2.0", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.2.10", "1.2.9", "1.2.9-rc.1"}, filtered)
+
+	filtered, err = filterOCITags(tags, ">=3.0.0", false)
+	require.NoError(t, err)
+	assert.Empty(t, filtered)
+
+	_, err = filterOCITags(tags, "not a constraint", false)
+	assert.Error(t, err)
+}
+
+func TestOCIReferencePattern(t *testing.T) {
+	for ref, valid := range map[string]bool{
+		"oci://registry.example.com/charts/app":        true,
+		"oci://registry.example.com:5000/charts/app/":  true,
+		"oci://registry.example.com/app:1.2.3":         false,
+		"oci://registry.example.com/app@" + testDigest: false,
+		"https://charts.example.com/app":               false,
+	} {
+		assert.Equal(t, valid, ociReferencePattern.MatchString(ref), ref)
+	}
+}
+
+func TestOCITags(t *testing.T) {
+	server := newTestOCIRegistry(t, "1.2.9", "1.2.10", "1.3.0+build.1")
+	registryClient, err := registry.NewClient(registry.ClientOptPlainHTTP())
+	require.NoError(t, err)
+	ref := strings.TrimPrefix(server.URL, "http://") + "/charts/app"
+
+	tags, err := registryClient.Tags(ref)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.3.0+build.1", "1.2.10", "1.2.9"}, tags)
+
+	version, err := readOCITagVersion(registryClient, ref, "1.3.0+build.1")
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.0+build.1", version.Tag)
+	assert.Regexp(t, `^sha256:[a-f0-9]{64}$`, version.Digest)
+	assert.Equal(t, "app-1.3.0+build.1", version.AppVersion)
+	assert.Equal(t, map[string]string{"artifacthub.io/changes": "- release 1.3.0+build.1"}, version.Annotations)
+
+	_, err = readOCITagVersion(registryClient, ref, "9.9.9")
+	assert.Error(t, err)
+}
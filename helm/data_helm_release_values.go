@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+var (
+	_ datasource.DataSource              = &HelmReleaseValues{}
+	_ datasource.DataSourceWithConfigure = &HelmReleaseValues{}
+)
+
+func NewHelmReleaseValues() datasource.DataSource {
+	return &HelmReleaseValues{}
+}
+
+// HelmReleaseValues represents the data source reading the values of a deployed release
+type HelmReleaseValues struct {
+	meta *Meta
+}
+
+// HelmReleaseValuesModel holds the release and the values of the helm_release_values data source
+type HelmReleaseValuesModel struct {
+	ID              types.String `tfsdk:"id"`
+	Name            types.String `tfsdk:"name"`
+	Namespace       types.String `tfsdk:"namespace"`
+	Revision        types.Int64  `tfsdk:"revision"`
+	Values          types.String `tfsdk:"values"`
+	EffectiveValues types.String `tfsdk:"effective_values"`
+}
+
+func (d *HelmReleaseValues) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData != nil {
+		d.meta = req.ProviderData.(*Meta)
+	}
+}
+
+func (d *HelmReleaseValues) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_release_values"
+}
+
+func (d *HelmReleaseValues) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Data source to read the values of a deployed Helm release.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the release.",
+			},
+			"namespace": schema.StringAttribute{
+				Optional:    true,
+				Description: "Namespace of the release. Defaults to the HELM_NAMESPACE environment variable or `default`.",
+			},
+			"revision": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Revision of the release to read the values of. Defaults to the latest revision.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"values": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Values supplied by the user for the revision, JSON encoded.",
+			},
+			"effective_values": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Values of the revision merged with the default values of the chart, JSON encoded.",
+			},
+		},
+	}
+}
+
+func (d *HelmReleaseValues) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state HelmReleaseValuesModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	meta := d.meta
+	name := state.Name.ValueString()
+	namespace := state.Namespace.ValueString()
+	if namespace == "" {
+		namespace = os.Getenv("HELM_NAMESPACE")
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+	ctx, span := meta.startSpan(ctx, "helm_release_values.read", releaseSpanAttributes(namespace, name)...)
+	defer func() { meta.endOperationSpan(ctx, span, resp.Diagnostics) }()
+
+	revision := int(state.Revision.ValueInt64())
+	values, effectiveValues := "{}", "{}"
+	// mock releases only exist in the state, their values are empty
+	if !meta.Mock {
+		actionConfig, err := meta.GetHelmConfiguration(ctx, namespace)
+		if err != nil {
+			resp.Diagnostics.AddError("Error getting helm configuration", fmt.Sprintf("Unable to get Helm configuration for namespace %q: %s", namespace, err))
+			return
+		}
+		revision, values, effectiveValues, err = releaseValues(actionConfig, name, revision)
+		if err != nil {
+			resp.Diagnostics.AddError("Error reading release values", fmt.Sprintf("Unable to read the values of Helm release %s/%s: %s", namespace, name, err))
+			return
+		}
+	}
+
+	state.ID = types.StringValue(fmt.Sprintf("%s/%s/%d", namespace, name, revision))
+	state.Revision = types.Int64Value(int64(revision))
+	state.Values = types.StringValue(values)
+	state.EffectiveValues = types.StringValue(effectiveValues)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// releaseValues returns the revision of the release name read, the latest when revision is 0,
+// with the JSON of its user supplied values and of its values merged with the chart defaults,
+// as `helm get values` and `helm get values --all` do
+func releaseValues(cfg *action.Configuration, name string, revision int) (int, string, string, error) {
+	get := action.NewGet(cfg)
+	get.Version = revision
+	r, err := get.Run(name)
+	if err != nil {
+		return 0, "", "", err
+	}
+
+	config := r.Config
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	values, err := json.Marshal(config)
+	if err != nil {
+		return 0, "", "", err
+	}
+
+	effective, err := chartutil.CoalesceValues(r.Chart, r.Config)
+	if err != nil {
+		return 0, "", "", err
+	}
+	effectiveValues, err := json.Marshal(effective)
+	if err != nil {
+		return 0, "", "", err
+	}
+	return r.Version, string(values), string(effectiveValues), nil
+}
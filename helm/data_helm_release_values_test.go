@@ -0,0 +1,112 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func TestReleaseValues(t *testing.T) {
+	store := storage.Init(driver.NewMemory())
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "test-chart", Version: "1.2.3"},
+		Values:   map[string]interface{}{"replicaCount": 1, "image": map[string]interface{}{"tag": "latest"}},
+	}
+	for revision, config := range []map[string]interface{}{
+		nil,
+		{"replicaCount": 2},
+		{"image": map[string]interface{}{"tag": "v2"}},
+	} {
+		err := store.Create(&release.Release{
+			Name:      "app",
+			Namespace: "default",
+			Version:   revision + 1,
+			Config:    config,
+			Chart:     ch,
+			Info:      &release.Info{Status: release.StatusDeployed},
+		})
+		require.NoError(t, err)
+	}
+	cfg := &action.Configuration{
+		Releases:   store,
+		KubeClient: &kubefake.PrintingKubeClient{Out: io.Discard},
+		Log:        func(string, ...interface{}) {},
+	}
+
+	revision, values, effectiveValues, err := releaseValues(cfg, "app", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, revision)
+	assert.JSONEq(t, `{"image":{"tag":"v2"}}`, values)
+	assert.JSONEq(t, `{"replicaCount":1,"image":{"tag":"v2"}}`, effectiveValues)
+
+	revision, values, effectiveValues, err = releaseValues(cfg, "app", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, revision)
+	assert.JSONEq(t, `{"replicaCount":2}`, values)
+	assert.JSONEq(t, `{"replicaCount":2,"image":{"tag":"latest"}}`, effectiveValues)
+
+	_, values, _, err = releaseValues(cfg, "app", 1)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, values)
+
+	_, _, _, err = releaseValues(cfg, "missing", 0)
+	assert.Error(t, err)
+}
+
+func TestAccDataReleaseValues_basic(t *testing.T) {
+	name := randName("release-values")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	datasourceAddress := fmt.Sprintf("data.helm_release_values.%s", testResourceName)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{{
+			Config: testAccDataHelmReleaseValuesConfigBasic(testResourceName, namespace, name),
+			Check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr(datasourceAddress, "revision", "1"),
+				resource.TestCheckResourceAttr(datasourceAddress, "values", `{"replicaCount":2}`),
+				resource.TestMatchResourceAttr(datasourceAddress, "effective_values", regexp.MustCompile(`"replicaCount":2`)),
+			),
+		}},
+	})
+}
+
+func testAccDataHelmReleaseValuesConfigBasic(resource, ns, name string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name       = %q
+			namespace  = %q
+			repository = %q
+			chart      = "test-chart"
+			version    = "1.2.3"
+
+			set = [
+				{
+					name  = "replicaCount"
+					value = "2"
+				},
+			]
+		}
+
+		data "helm_release_values" "%s" {
+			name      = helm_release.%s.name
+			namespace = helm_release.%s.namespace
+		}
+	`, resource, name, ns, testRepositoryURL, resource, resource, resource)
+}
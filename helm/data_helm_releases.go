@@ -0,0 +1,239 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+var (
+	_ datasource.DataSource              = &HelmReleases{}
+	_ datasource.DataSourceWithConfigure = &HelmReleases{}
+)
+
+// releaseStatuses are the statuses the releases returned by helm_releases can be filtered on
+var releaseStatuses = []string{
+	release.StatusDeployed.String(),
+	release.StatusUninstalled.String(),
+	release.StatusSuperseded.String(),
+	release.StatusFailed.String(),
+	release.StatusUninstalling.String(),
+	release.StatusPendingInstall.String(),
+	release.StatusPendingUpgrade.String(),
+	release.StatusPendingRollback.String(),
+}
+
+func NewHelmReleases() datasource.DataSource {
+	return &HelmReleases{}
+}
+
+// HelmReleases represents the data source listing the releases of a namespace or of the cluster
+type HelmReleases struct {
+	meta *Meta
+}
+
+// HelmReleasesModel holds the filters and the releases of the helm_releases data source
+type HelmReleasesModel struct {
+	ID        types.String               `tfsdk:"id"`
+	Namespace types.String               `tfsdk:"namespace"`
+	Statuses  types.List                 `tfsdk:"statuses"`
+	Selector  types.String               `tfsdk:"selector"`
+	Filter    types.String               `tfsdk:"filter"`
+	Releases  []HelmReleasesReleaseModel `tfsdk:"releases"`
+}
+
+// HelmReleasesReleaseModel describes the latest revision of a release
+type HelmReleasesReleaseModel struct {
+	Name         types.String `tfsdk:"name"`
+	Namespace    types.String `tfsdk:"namespace"`
+	Revision     types.Int64  `tfsdk:"revision"`
+	Chart        types.String `tfsdk:"chart"`
+	ChartVersion types.String `tfsdk:"chart_version"`
+	AppVersion   types.String `tfsdk:"app_version"`
+	Status       types.String `tfsdk:"status"`
+	Updated      types.String `tfsdk:"updated"`
+}
+
+func (d *HelmReleases) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData != nil {
+		d.meta = req.ProviderData.(*Meta)
+	}
+}
+
+func (d *HelmReleases) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_releases"
+}
+
+func (d *HelmReleases) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Data source to list the Helm releases of a namespace or of the cluster.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"namespace": schema.StringAttribute{
+				Optional:    true,
+				Description: "Namespace to list the releases of. Defaults to all namespaces.",
+			},
+			"statuses": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Only list releases with one of these statuses. Defaults to all statuses.",
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(stringvalidator.OneOf(releaseStatuses...)),
+				},
+			},
+			"selector": schema.StringAttribute{
+				Optional:    true,
+				Description: "Label selector matched against the labels of the release storage objects, e.g. `owner=helm,team=platform`.",
+			},
+			"filter": schema.StringAttribute{
+				Optional:    true,
+				Description: "Regular expression the release names must match.",
+			},
+			"releases": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Latest revision of every matching release, sorted by namespace and name.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Computed:    true,
+							Description: "Name of the release.",
+						},
+						"namespace": schema.StringAttribute{
+							Computed:    true,
+							Description: "Namespace of the release.",
+						},
+						"revision": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Revision of the release.",
+						},
+						"chart": schema.StringAttribute{
+							Computed:    true,
+							Description: "Name of the chart.",
+						},
+						"chart_version": schema.StringAttribute{
+							Computed:    true,
+							Description: "Version of the chart.",
+						},
+						"app_version": schema.StringAttribute{
+							Computed:    true,
+							Description: "Version of the application of the chart.",
+						},
+						"status": schema.StringAttribute{
+							Computed:    true,
+							Description: "Status of the release.",
+						},
+						"updated": schema.StringAttribute{
+							Computed:    true,
+							Description: "Time of the last deployment of the release, in RFC 3339 format.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *HelmReleases) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state HelmReleasesModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	meta := d.meta
+	namespace := state.Namespace.ValueString()
+	ctx, span := meta.startSpan(ctx, "helm_releases.read", releaseSpanAttributes(namespace, "")...)
+	defer func() { meta.endOperationSpan(ctx, span, resp.Diagnostics) }()
+
+	var statuses []string
+	if !state.Statuses.IsNull() {
+		resp.Diagnostics.Append(state.Statuses.ElementsAs(ctx, &statuses, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// an empty namespace configures the storage driver for all namespaces, and mock releases
+	// only exist in the state
+	var releases []*release.Release
+	if !meta.Mock {
+		actionConfig, err := meta.GetHelmConfiguration(ctx, namespace)
+		if err != nil {
+			resp.Diagnostics.AddError("Error getting helm configuration", fmt.Sprintf("Unable to get Helm configuration for namespace %q: %s", namespace, err))
+			return
+		}
+		releases, err = listReleases(actionConfig, statuses, state.Selector.ValueString(), state.Filter.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("Error listing releases", fmt.Sprintf("Unable to list Helm releases: %s", err))
+			return
+		}
+	}
+
+	state.Releases = make([]HelmReleasesReleaseModel, 0, len(releases))
+	for _, r := range releases {
+		item := HelmReleasesReleaseModel{
+			Name:         types.StringValue(r.Name),
+			Namespace:    types.StringValue(r.Namespace),
+			Revision:     types.Int64Value(int64(r.Version)),
+			Chart:        types.StringNull(),
+			ChartVersion: types.StringNull(),
+			AppVersion:   types.StringNull(),
+			Status:       types.StringNull(),
+			Updated:      types.StringNull(),
+		}
+		if r.Chart != nil && r.Chart.Metadata != nil {
+			item.Chart = types.StringValue(r.Chart.Metadata.Name)
+			item.ChartVersion = types.StringValue(r.Chart.Metadata.Version)
+			item.AppVersion = types.StringValue(r.Chart.Metadata.AppVersion)
+		}
+		if r.Info != nil {
+			item.Status = types.StringValue(r.Info.Status.String())
+			item.Updated = types.StringValue(r.Info.LastDeployed.UTC().Format(time.RFC3339))
+		}
+		state.Releases = append(state.Releases, item)
+	}
+
+	state.ID = types.StringValue(strings.Join([]string{namespace, strings.Join(statuses, ","), state.Selector.ValueString(), state.Filter.ValueString()}, "/"))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// listReleases lists the latest revision of the releases of the storage of cfg matching the
+// statuses, label selector and name filter, sorted by namespace and name
+func listReleases(cfg *action.Configuration, statuses []string, selector, filter string) ([]*release.Release, error) {
+	list := action.NewList(cfg)
+	list.Selector = selector
+	list.Filter = filter
+
+	list.StateMask = action.ListAll
+	if len(statuses) > 0 {
+		list.StateMask = 0
+		for _, s := range statuses {
+			list.StateMask |= action.ListStates(0).FromName(s)
+		}
+	}
+
+	releases, err := list.Run()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].Namespace < releases[j].Namespace
+	})
+	return releases, nil
+}
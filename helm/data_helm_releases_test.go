@@ -0,0 +1,119 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func TestListReleases(t *testing.T) {
+	memory := driver.NewMemory()
+	store := storage.Init(memory)
+	for _, r := range []struct {
+		namespace, name string
+		version         int
+		status          release.Status
+		labels          map[string]string
+	}{
+		{"b", "app", 1, release.StatusSuperseded, nil},
+		{"b", "app", 2, release.StatusDeployed, nil},
+		{"a", "db", 1, release.StatusFailed, map[string]string{"team": "data"}},
+		{"a", "app", 1, release.StatusDeployed, map[string]string{"team": "web"}},
+	} {
+		err := store.Create(&release.Release{
+			Name:      r.name,
+			Namespace: r.namespace,
+			Version:   r.version,
+			Labels:    r.labels,
+			Info:      &release.Info{Status: r.status},
+			Chart:     &chart.Chart{Metadata: &chart.Metadata{Name: "test-chart", Version: "1.2.3"}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// an empty namespace lists the releases of all namespaces
+	memory.SetNamespace("")
+	cfg := &action.Configuration{
+		Releases:   store,
+		KubeClient: &kubefake.PrintingKubeClient{Out: io.Discard},
+		Log:        func(string, ...interface{}) {},
+	}
+
+	names := func(releases []*release.Release) []string {
+		out := []string{}
+		for _, r := range releases {
+			out = append(out, fmt.Sprintf("%s/%s/%d", r.Namespace, r.Name, r.Version))
+		}
+		return out
+	}
+
+	releases, err := listReleases(cfg, nil, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a/app/1", "a/db/1", "b/app/2"}, names(releases))
+
+	releases, err = listReleases(cfg, []string{"failed"}, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a/db/1"}, names(releases))
+
+	releases, err = listReleases(cfg, nil, "team=web", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a/app/1"}, names(releases))
+
+	releases, err = listReleases(cfg, nil, "", "^d")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a/db/1"}, names(releases))
+}
+
+func TestAccDataReleases_basic(t *testing.T) {
+	name := randName("releases")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	datasourceAddress := fmt.Sprintf("data.helm_releases.%s", testResourceName)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{{
+			Config: testAccDataHelmReleasesConfigBasic(testResourceName, namespace, name, "1.2.3"),
+			Check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr(datasourceAddress, "releases.#", "1"),
+				resource.TestCheckResourceAttr(datasourceAddress, "releases.0.name", name),
+				resource.TestCheckResourceAttr(datasourceAddress, "releases.0.namespace", namespace),
+				resource.TestCheckResourceAttr(datasourceAddress, "releases.0.revision", "1"),
+				resource.TestCheckResourceAttr(datasourceAddress, "releases.0.chart", "test-chart"),
+				resource.TestCheckResourceAttr(datasourceAddress, "releases.0.chart_version", "1.2.3"),
+				resource.TestCheckResourceAttr(datasourceAddress, "releases.0.status", release.StatusDeployed.String()),
+			),
+		}},
+	})
+}
+
+func testAccDataHelmReleasesConfigBasic(resource, ns, name, version string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name       = %q
+			namespace  = %q
+			repository = %q
+			chart      = "test-chart"
+			version    = %q
+		}
+
+		data "helm_releases" "%s" {
+			namespace = helm_release.%s.namespace
+			statuses  = ["deployed"]
+		}
+	`, resource, name, ns, testRepositoryURL, version, resource, resource)
+}
@@ -6,7 +6,6 @@ package helm
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
@@ -17,11 +16,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/boolvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -34,8 +36,6 @@ import (
 	"helm.sh/helm/v3/pkg/registry"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/releaseutil"
-	"k8s.io/helm/pkg/strvals"
-	"sigs.k8s.io/yaml"
 )
 
 var (
@@ -54,72 +54,62 @@ type HelmTemplate struct {
 
 // HelmTemplateModel holds the attributes for configuring the Helm chart templates
 type HelmTemplateModel struct {
-	APIVersions              types.List       `tfsdk:"api_versions"`
-	Atomic                   types.Bool       `tfsdk:"atomic"`
-	Chart                    types.String     `tfsdk:"chart"`
-	CreateNamespace          types.Bool       `tfsdk:"create_namespace"`
-	CRDs                     types.List       `tfsdk:"crds"`
-	DependencyUpdate         types.Bool       `tfsdk:"dependency_update"`
-	Description              types.String     `tfsdk:"description"`
-	Devel                    types.Bool       `tfsdk:"devel"`
-	DisableOpenAPIValidation types.Bool       `tfsdk:"disable_openapi_validation"`
-	DisableWebhooks          types.Bool       `tfsdk:"disable_webhooks"`
-	ID                       types.String     `tfsdk:"id"`
-	IncludeCRDs              types.Bool       `tfsdk:"include_crds"`
-	IsUpgrade                types.Bool       `tfsdk:"is_upgrade"`
-	Keyring                  types.String     `tfsdk:"keyring"`
-	KubeVersion              types.String     `tfsdk:"kube_version"`
-	Manifest                 types.String     `tfsdk:"manifest"`
-	Manifests                types.Map        `tfsdk:"manifests"`
-	Name                     types.String     `tfsdk:"name"`
-	Namespace                types.String     `tfsdk:"namespace"`
-	Notes                    types.String     `tfsdk:"notes"`
-	PassCredentials          types.Bool       `tfsdk:"pass_credentials"`
-	PostRender               *PostRenderModel `tfsdk:"postrender"`
-	RenderSubchartNotes      types.Bool       `tfsdk:"render_subchart_notes"`
-	Replace                  types.Bool       `tfsdk:"replace"`
-	Repository               types.String     `tfsdk:"repository"`
-	RepositoryCaFile         types.String     `tfsdk:"repository_ca_file"`
-	RepositoryCertFile       types.String     `tfsdk:"repository_cert_file"`
-	RepositoryKeyFile        types.String     `tfsdk:"repository_key_file"`
-	RepositoryPassword       types.String     `tfsdk:"repository_password"`
-	RepositoryUsername       types.String     `tfsdk:"repository_username"`
-	ResetValues              types.Bool       `tfsdk:"reset_values"`
-	ReuseValues              types.Bool       `tfsdk:"reuse_values"`
-	Set                      types.Set        `tfsdk:"set"`
-	SetList                  types.List       `tfsdk:"set_list"`
-	SetSensitive             types.Set        `tfsdk:"set_sensitive"`
-	ShowOnly                 types.List       `tfsdk:"show_only"`
-	SkipCrds                 types.Bool       `tfsdk:"skip_crds"`
-	SkipTests                types.Bool       `tfsdk:"skip_tests"`
-	Timeout                  types.Int64      `tfsdk:"timeout"`
-	Validate                 types.Bool       `tfsdk:"validate"`
-	Values                   types.List       `tfsdk:"values"`
-	Version                  types.String     `tfsdk:"version"`
-	Verify                   types.Bool       `tfsdk:"verify"`
-	Wait                     types.Bool       `tfsdk:"wait"`
+	APIVersions                     types.List       `tfsdk:"api_versions"`
+	Atomic                          types.Bool       `tfsdk:"atomic"`
+	Chart                           types.String     `tfsdk:"chart"`
+	ChartTarballBase64              types.String     `tfsdk:"chart_tarball_base64"`
+	CreateNamespace                 types.Bool       `tfsdk:"create_namespace"`
+	CRDs                            types.List       `tfsdk:"crds"`
+	DeduplicateCRDs                 types.Bool       `tfsdk:"deduplicate_crds"`
+	DependencyUpdate                types.Bool       `tfsdk:"dependency_update"`
+	Description                     types.String     `tfsdk:"description"`
+	Devel                           types.Bool       `tfsdk:"devel"`
+	DisableOpenAPIValidation        types.Bool       `tfsdk:"disable_openapi_validation"`
+	DisableWebhooks                 types.Bool       `tfsdk:"disable_webhooks"`
+	HooksOnly                       types.Bool       `tfsdk:"hooks_only"`
+	ID                              types.String     `tfsdk:"id"`
+	Images                          types.Set        `tfsdk:"images"`
+	IncludeCRDs                     types.Bool       `tfsdk:"include_crds"`
+	IsUpgrade                       types.Bool       `tfsdk:"is_upgrade"`
+	Keyring                         types.String     `tfsdk:"keyring"`
+	KubeVersion                     types.String     `tfsdk:"kube_version"`
+	Manifest                        types.String     `tfsdk:"manifest"`
+	ManifestHashes                  types.Map        `tfsdk:"manifest_hashes"`
+	Manifests                       types.Map        `tfsdk:"manifests"`
+	Name                            types.String     `tfsdk:"name"`
+	Namespace                       types.String     `tfsdk:"namespace"`
+	NoHooks                         types.Bool       `tfsdk:"no_hooks"`
+	Notes                           types.String     `tfsdk:"notes"`
+	PassCredentials                 types.Bool       `tfsdk:"pass_credentials"`
+	PostRender                      *PostRenderModel `tfsdk:"postrender"`
+	RenderSubchartNotes             types.Bool       `tfsdk:"render_subchart_notes"`
+	Replace                         types.Bool       `tfsdk:"replace"`
+	Repository                      types.String     `tfsdk:"repository"`
+	RepositoryCaFile                types.String     `tfsdk:"repository_ca_file"`
+	RepositoryCertFile              types.String     `tfsdk:"repository_cert_file"`
+	RepositoryInsecureSkipTLSVerify types.Bool       `tfsdk:"repository_insecure_skip_tls_verify"`
+	RepositoryKeyFile               types.String     `tfsdk:"repository_key_file"`
+	RepositoryPassword              types.String     `tfsdk:"repository_password"`
+	RepositoryPlainHTTP             types.Bool       `tfsdk:"repository_plain_http"`
+	RepositoryUsername              types.String     `tfsdk:"repository_username"`
+	ResetValues                     types.Bool       `tfsdk:"reset_values"`
+	ReuseValues                     types.Bool       `tfsdk:"reuse_values"`
+	Revision                        types.Int64      `tfsdk:"revision"`
+	Set                             types.Set        `tfsdk:"set"`
+	SetList                         types.List       `tfsdk:"set_list"`
+	SetSensitive                    types.Set        `tfsdk:"set_sensitive"`
+	ShowOnly                        types.List       `tfsdk:"show_only"`
+	SkipCrds                        types.Bool       `tfsdk:"skip_crds"`
+	SkipTests                       types.Bool       `tfsdk:"skip_tests"`
+	Timeout                         types.Int64      `tfsdk:"timeout"`
+	Validate                        types.Bool       `tfsdk:"validate"`
+	Values                          types.List       `tfsdk:"values"`
+	ValuesMergeStrategy             types.String     `tfsdk:"values_merge_strategy"`
+	Version                         types.String     `tfsdk:"version"`
+	Verify                          types.Bool       `tfsdk:"verify"`
+	Wait                            types.Bool       `tfsdk:"wait"`
 }
 
-// SetValue represents the custom value to be merged with the Helm chart values
-type SetValue struct {
-	Name  types.String `tfsdk:"name"`
-	Type  types.String `tfsdk:"type"`
-	Value types.String `tfsdk:"value"`
-}
-
-// SetListValue represents a custom list value to be merged with the Helm chart values.
-// This type is used to specify lists of values that should be passed to the Helm chart during deployment.
-type SetListValue struct {
-	Name  types.String `tfsdk:"name"`
-	Value types.List   `tfsdk:"value"`
-}
-
-// SetSensitiveValue represents a custom sensitive value to be merged with the Helm chart values.
-type SetSensitiveValue struct {
-	Name  types.String `tfsdk:"name"`
-	Type  types.String `tfsdk:"type"`
-	Value types.String `tfsdk:"value"`
-}
 type Postrender struct {
 	BinaryPath types.String `tfsdk:"binary_path"`
 }
@@ -148,19 +138,33 @@ func (d *HelmTemplate) Schema(ctx context.Context, req datasource.SchemaRequest,
 				Description: "If set, the installation process purges the chart on fail. The 'wait' flag will be set automatically if 'atomic' is used.",
 			},
 			"chart": schema.StringAttribute{
-				Required:    true,
-				Description: "Chart name to be installed. A path may be used.",
+				Optional:    true,
+				Description: "Chart name to be installed. A path may be used, e.g. of a .tgz archive produced earlier in the same configuration.",
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("chart_tarball_base64")),
+				},
+			},
+			"chart_tarball_base64": schema.StringAttribute{
+				Optional:    true,
+				Description: "Base64 encoded .tgz archive of the chart to render, e.g. built by another resource of the configuration, instead of chart.",
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("repository")),
+				},
 			},
 			"crds": schema.ListAttribute{
 				Optional:    true,
 				Computed:    true,
 				ElementType: types.StringType,
-				Description: "List of rendered CRDs from the chart.",
+				Description: "List of rendered CRDs from the chart and from its dependencies.",
 			},
 			"create_namespace": schema.BoolAttribute{
 				Optional:    true,
 				Description: "Create the namespace if it does not exist.",
 			},
+			"deduplicate_crds": schema.BoolAttribute{
+				Optional:    true,
+				Description: "List the CRDs one by one in `crds`, keeping the first CRD of every group and kind.",
+			},
 			"dependency_update": schema.BoolAttribute{
 				Optional:    true,
 				Description: "Run helm dependency update before installing the chart.",
@@ -181,6 +185,13 @@ func (d *HelmTemplate) Schema(ctx context.Context, req datasource.SchemaRequest,
 				Optional:    true,
 				Description: "Prevent hooks from running.",
 			},
+			"hooks_only": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Only render the hooks of the chart, leaving out the other templates and the CRDs of include_crds, e.g. to apply the hooks stripped from a deployment separately.",
+				Validators: []validator.Bool{
+					boolvalidator.ConflictsWith(path.MatchRoot("no_hooks"), path.MatchRoot("disable_webhooks")),
+				},
+			},
 			"id": schema.StringAttribute{
 				Computed: true,
 			},
@@ -190,12 +201,17 @@ func (d *HelmTemplate) Schema(ctx context.Context, req datasource.SchemaRequest,
 			},
 			"is_upgrade": schema.BoolAttribute{
 				Optional:    true,
-				Description: "Set .Release.IsUpgrade instead of .Release.IsInstall.",
+				Description: "Set .Release.IsUpgrade instead of .Release.IsInstall, to render the templates of the chart for an upgrade.",
 			},
 			"keyring": schema.StringAttribute{
 				Optional:    true,
 				Description: "Location of public keys used for verification. Used only if `verify` is true.",
 			},
+			"images": schema.SetAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Images of the containers of the Pods, Deployments, StatefulSets, DaemonSets, ReplicaSets, ReplicationControllers, Jobs and CronJobs of the rendered manifest.",
+			},
 			"kube_version": schema.StringAttribute{
 				Optional:    true,
 				Description: "Kubernetes version used for Capabilities.KubeVersion.",
@@ -205,6 +221,11 @@ func (d *HelmTemplate) Schema(ctx context.Context, req datasource.SchemaRequest,
 				Computed:    true,
 				Description: "Concatenated rendered chart templates. This corresponds to the output of the `helm template` command.",
 			},
+			"manifest_hashes": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Map of the SHA-256 hashes of the rendered objects indexed by kind/namespace/name, or kind/name for objects without a namespace. A hash only changes when its object does.",
+			},
 			"manifests": schema.MapAttribute{
 				Optional:    true,
 				Computed:    true,
@@ -219,6 +240,10 @@ func (d *HelmTemplate) Schema(ctx context.Context, req datasource.SchemaRequest,
 				Optional:    true,
 				Description: "Namespace to install the release into.",
 			},
+			"no_hooks": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Leave the hooks of the chart out of the rendered templates, as helm template --no-hooks, e.g. to match the output of deployment tools which strip hooks.",
+			},
 			"notes": schema.StringAttribute{
 				Optional:    true,
 				Computed:    true,
@@ -263,6 +288,10 @@ func (d *HelmTemplate) Schema(ctx context.Context, req datasource.SchemaRequest,
 				Optional:    true,
 				Description: "The repository's cert file",
 			},
+			"repository_insecure_skip_tls_verify": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Skip the verification of the certificate of the chart repository or OCI registry. This is insecure.",
+			},
 			"repository_key_file": schema.StringAttribute{
 				Optional:    true,
 				Description: "The repository's cert key file",
@@ -272,6 +301,10 @@ func (d *HelmTemplate) Schema(ctx context.Context, req datasource.SchemaRequest,
 				Sensitive:   true,
 				Description: "Password for HTTP basic authentication",
 			},
+			"repository_plain_http": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Use insecure HTTP connections to the OCI registry of the chart, for registries served without TLS.",
+			},
 			"repository_username": schema.StringAttribute{
 				Optional:    true,
 				Description: "Username for HTTP basic authentication",
@@ -284,6 +317,13 @@ func (d *HelmTemplate) Schema(ctx context.Context, req datasource.SchemaRequest,
 				Optional:    true,
 				Description: "When upgrading, reuse the last release's values and merge in any overrides. If 'reset_values' is specified, this is ignored.",
 			},
+			"revision": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Set .Release.Revision, to render the templates of the chart for a revision other than the first one, e.g. with is_upgrade. Defaults to 1.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
 			"set": schema.SetNestedAttribute{
 				Description: "Custom values to be merged with the values",
 				Optional:    true,
@@ -299,7 +339,7 @@ func (d *HelmTemplate) Schema(ctx context.Context, req datasource.SchemaRequest,
 							Optional: true,
 							Computed: true,
 							Validators: []validator.String{
-								stringvalidator.OneOf("auto", "string"),
+								stringvalidator.OneOf(setValueTypes...),
 							},
 						},
 					},
@@ -335,7 +375,7 @@ func (d *HelmTemplate) Schema(ctx context.Context, req datasource.SchemaRequest,
 						"type": schema.StringAttribute{
 							Optional: true,
 							Validators: []validator.String{
-								stringvalidator.OneOf("auto", "string"),
+								stringvalidator.OneOf(setValueTypes...),
 							},
 						},
 					},
@@ -367,6 +407,13 @@ func (d *HelmTemplate) Schema(ctx context.Context, req datasource.SchemaRequest,
 				ElementType: types.StringType,
 				Description: "List of values in raw yaml format to pass to helm.",
 			},
+			"values_merge_strategy": schema.StringAttribute{
+				Optional:    true,
+				Description: "How the values documents are merged: `helm` replaces lists, `strategic` merges the lists of objects by their name, mountPath, containerPort, port or key. Defaults to `helm`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(valuesMergeStrategies...),
+				},
+			},
 			"verify": schema.BoolAttribute{
 				Optional:    true,
 				Description: "Verify the package before installing it.",
@@ -415,6 +462,9 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 	if state.IsUpgrade.IsNull() || state.IsUpgrade.IsUnknown() {
 		state.IsUpgrade = types.BoolValue(false)
 	}
+	if state.Revision.IsNull() || state.Revision.IsUnknown() {
+		state.Revision = types.Int64Value(1)
+	}
 	if state.DisableWebhooks.IsNull() || state.DisableWebhooks.IsUnknown() {
 		state.DisableWebhooks = types.BoolValue(false)
 	}
@@ -433,6 +483,12 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 	if state.SkipTests.IsNull() || state.SkipTests.IsUnknown() {
 		state.SkipTests = types.BoolValue(false)
 	}
+	if state.NoHooks.IsNull() || state.NoHooks.IsUnknown() {
+		state.NoHooks = types.BoolValue(false)
+	}
+	if state.HooksOnly.IsNull() || state.HooksOnly.IsUnknown() {
+		state.HooksOnly = types.BoolValue(false)
+	}
 	if state.RenderSubchartNotes.IsNull() || state.RenderSubchartNotes.IsUnknown() {
 		state.RenderSubchartNotes = types.BoolValue(false)
 	}
@@ -469,6 +525,8 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 	}
 
 	meta := d.meta
+	ctx, span := meta.startSpan(ctx, "helm_template.read", releaseSpanAttributes(state.Namespace.ValueString(), state.Name.ValueString())...)
+	defer func() { meta.endOperationSpan(ctx, span, resp.Diagnostics) }()
 
 	var apiVersions []string
 	if !state.APIVersions.IsNull() && !state.APIVersions.IsUnknown() {
@@ -510,7 +568,7 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 		)
 		return
 	}
-	diags := OCIRegistryLogin(ctx, meta, actionConfig, meta.RegistryClient, state.Repository.ValueString(), state.Chart.ValueString(), state.RepositoryUsername.ValueString(), state.RepositoryPassword.ValueString())
+	diags := OCIRegistryLogin(ctx, meta, actionConfig, state.Repository.ValueString(), state.Chart.ValueString(), state.RepositoryUsername.ValueString(), state.RepositoryPassword.ValueString(), state.RepositoryPlainHTTP.ValueBool(), state.RepositoryInsecureSkipTLSVerify.ValueBool())
 	if diags.HasError() {
 		resp.Diagnostics.Append(diags...)
 		return
@@ -522,6 +580,15 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	if !state.ChartTarballBase64.IsNull() {
+		tarballPath, remove, err := writeChartTarball(state.ChartTarballBase64.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("chart_tarball_base64"), "Invalid chart archive", err.Error())
+			return
+		}
+		defer remove()
+		chartName = tarballPath
+	}
 
 	c, chartPath, chartDiags := getChartModel(ctx, &state, meta, chartName, cpo)
 	resp.Diagnostics.Append(chartDiags...)
@@ -561,7 +628,7 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 	client.Timeout = time.Duration(state.Timeout.ValueInt64()) * time.Second
 	client.Wait = state.Wait.ValueBool()
 	client.DependencyUpdate = state.DependencyUpdate.ValueBool()
-	client.DisableHooks = state.DisableWebhooks.ValueBool()
+	client.DisableHooks = state.DisableWebhooks.ValueBool() || state.NoHooks.ValueBool()
 	client.DisableOpenAPIValidation = state.DisableOpenAPIValidation.ValueBool()
 	client.Atomic = state.Atomic.ValueBool()
 	client.Replace = state.Replace.ValueBool()
@@ -570,6 +637,10 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 	client.Devel = state.Devel.ValueBool()
 	client.Description = state.Description.ValueString()
 	client.CreateNamespace = state.CreateNamespace.ValueBool()
+	client.IsUpgrade = state.IsUpgrade.ValueBool()
+	if revision := state.Revision.ValueInt64(); revision != 1 {
+		setTemplateRevision(c, revision)
+	}
 
 	if state.KubeVersion.ValueString() != "" {
 		parsedVer, err := chartutil.ParseKubeVersion(state.KubeVersion.ValueString())
@@ -589,7 +660,9 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 	client.APIVersions = chartutil.VersionSet(apiVersions)
 	client.IncludeCRDs = state.IncludeCRDs.ValueBool()
 
+	_, renderSpan := meta.startSpan(ctx, "helm.render", releaseSpanAttributes(state.Namespace.ValueString(), state.Name.ValueString())...)
 	rel, err := client.Run(c, values)
+	endSpan(renderSpan, err)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error running Helm install",
@@ -598,28 +671,19 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 		return
 	}
 
-	var manifests bytes.Buffer
-	fmt.Fprintln(&manifests, strings.TrimSpace(rel.Manifest))
-	if !client.DisableHooks {
-		for _, m := range rel.Hooks {
-			if state.SkipTests.ValueBool() && isTestHook(m) {
-				continue
-			}
-			fmt.Fprintf(&manifests, "---\n# Source: %s\n%s\n", m.Path, m.Manifest)
-		}
-	}
 	var manifestsToRender []string
 
-	splitManifests := releaseutil.SplitManifests(manifests.String())
+	splitManifests := releaseutil.SplitManifests(templateManifest(rel, client.DisableHooks, state.HooksOnly.ValueBool(), state.SkipTests.ValueBool()))
 	manifestsKeys := make([]string, 0, len(splitManifests))
 	for k := range splitManifests {
 		manifestsKeys = append(manifestsKeys, k)
 	}
 	sort.Sort(releaseutil.BySplitManifestsOrder(manifestsKeys))
 
-	var chartCRDs []string
-	for _, crd := range rel.Chart.CRDObjects() {
-		chartCRDs = append(chartCRDs, string(crd.File.Data))
+	chartCRDs, err := templateCRDs(rel.Chart, state.DeduplicateCRDs.ValueBool())
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading CRDs", err.Error())
+		return
 	}
 
 	// Mapping of manifest key to manifest template name
@@ -672,10 +736,12 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 	// Map from rendered manifests to data source output
 	computedManifests := make(map[string]string, 0)
 	computedManifest := &strings.Builder{}
+	documents := make([]string, 0, len(manifestsToRender))
 
 	for _, manifestKey := range manifestsToRender {
 		manifest := splitManifests[manifestKey]
 		manifestName := manifestNamesByKey[manifestKey]
+		documents = append(documents, manifest)
 
 		// Manifests
 		computedManifests[manifestName] = fmt.Sprintf("%s---\n%s\n", computedManifests[manifestName], manifest)
@@ -708,6 +774,30 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 	state.Manifests = mapValue
 
 	state.Manifest = types.StringValue(computedManifest.String())
+
+	hashes, err := manifestHashes(documents)
+	if err != nil {
+		resp.Diagnostics.AddError("Error hashing manifests", fmt.Sprintf("Unable to hash the rendered manifests: %s", err))
+		return
+	}
+	hashesValue, diags := types.MapValueFrom(ctx, types.StringType, hashes)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.ManifestHashes = hashesValue
+
+	images, err := manifestImages(computedManifest.String())
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading images", fmt.Sprintf("Unable to read the images of the rendered manifest: %s", err))
+		return
+	}
+	imagesValue, diags := types.SetValueFrom(ctx, types.StringType, images)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.Images = imagesValue
 	state.Notes = types.StringValue(rel.Info.Notes)
 	state.ID = types.StringValue(state.Name.ValueString())
 
@@ -715,93 +805,33 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 }
 
 func getValuesModel(ctx context.Context, model *HelmTemplateModel) (map[string]interface{}, diag.Diagnostics) {
-	base := map[string]interface{}{}
-	var diags diag.Diagnostics
-
-	// Process "values" attribute
-	for _, raw := range model.Values.Elements() {
-		if raw.IsNull() {
-			continue
-		}
-
-		value, ok := raw.(types.String)
-		if !ok {
-			diags.AddError("Type Error", fmt.Sprintf("Expected types.String, got %T", raw))
-			return nil, diags
-		}
-
-		values := value.ValueString()
-		if values == "" {
-			continue
-		}
-
-		currentMap := map[string]interface{}{}
-		if err := yaml.Unmarshal([]byte(values), &currentMap); err != nil {
-			diags.AddError("Error unmarshaling values", fmt.Sprintf("---> %v %s", err, values))
-			return nil, diags
-		}
-
-		base = mergeMaps(base, currentMap)
-	}
-
-	// Process "set" attribute
-	if !model.Set.IsNull() {
-		var setList []SetValue
-		setDiags := model.Set.ElementsAs(ctx, &setList, false)
-		diags.Append(setDiags...)
-		if diags.HasError() {
-			return nil, diags
-		}
+	return mergeValues(ctx, valuesInput{
+		Values:        model.Values,
+		Set:           model.Set,
+		SetList:       model.SetList,
+		SetSensitive:  model.SetSensitive,
+		MergeStrategy: model.ValuesMergeStrategy.ValueString(),
+	})
+}
 
-		for _, set := range setList {
-			setDiags := applySetValue(base, set)
-			diags.Append(setDiags...)
-			if diags.HasError() {
-				return nil, diags
-			}
-		}
+// templateManifest returns the objects of rel followed by its hooks, as helm template prints
+// them. The hooks are left out with noHooks, the objects with hooksOnly, and the test hooks
+// with skipTests.
+func templateManifest(rel *release.Release, noHooks, hooksOnly, skipTests bool) string {
+	var manifests bytes.Buffer
+	if !hooksOnly {
+		fmt.Fprintln(&manifests, strings.TrimSpace(rel.Manifest))
 	}
-
-	// Process "set_list" attribute
-	if !model.SetList.IsUnknown() {
-		var setListSlice []SetListValue
-		setListDiags := model.SetList.ElementsAs(ctx, &setListSlice, false)
-		diags.Append(setListDiags...)
-		if diags.HasError() {
-			return nil, diags
-		}
-
-		for _, setList := range setListSlice {
-			setListDiags := applySetListValue(ctx, base, setList)
-			diags.Append(setListDiags...)
-			if diags.HasError() {
-				return nil, diags
-			}
-		}
+	if noHooks {
+		return manifests.String()
 	}
-
-	// Process "set_sensitive" attribute
-	if !model.SetSensitive.IsNull() {
-		var setSensitiveList []SetSensitiveValue
-		setSensitiveDiags := model.SetSensitive.ElementsAs(ctx, &setSensitiveList, false)
-		diags.Append(setSensitiveDiags...)
-		if diags.HasError() {
-			return nil, diags
-		}
-
-		for _, setSensitive := range setSensitiveList {
-			setSensitiveDiags := applySetSensitiveValue(base, setSensitive)
-			diags.Append(setSensitiveDiags...)
-			if diags.HasError() {
-				return nil, diags
-			}
+	for _, m := range rel.Hooks {
+		if skipTests && isTestHook(m) {
+			continue
 		}
+		fmt.Fprintf(&manifests, "---\n# Source: %s\n%s\n", m.Path, m.Manifest)
 	}
-
-	tflog.Debug(ctx, fmt.Sprintf("Final merged values: %v", base))
-	logDiags := LogValuesModel(ctx, base, model)
-	diags.Append(logDiags...)
-	return base, diags
+	return manifests.String()
 }
 
 func isTestHook(h *release.Hook) bool {
@@ -851,6 +881,9 @@ func chartPathOptionsModel(model *HelmTemplateModel, meta *Meta, cpo *action.Cha
 	cpo.Username = model.RepositoryUsername.ValueString()
 	cpo.Password = model.RepositoryPassword.ValueString()
 	cpo.PassCredentialsAll = model.PassCredentials.ValueBool()
+	cpo.PlainHTTP = meta.usePlainHTTP(chartName, model.RepositoryPlainHTTP.ValueBool())
+	cpo.InsecureSkipTLSverify = model.RepositoryInsecureSkipTLSVerify.ValueBool()
+	meta.RepositoryTLS.apply(cpo)
 
 	return cpo, chartName, diags
 }
@@ -868,7 +901,7 @@ func getChartModel(ctx context.Context, model *HelmTemplateModel, meta *Meta, na
 
 	tflog.Debug(ctx, fmt.Sprintf("Helm settings: %+v", meta.Settings))
 
-	path, err := cpo.LocateChart(name, meta.Settings)
+	path, err := meta.locateChart(ctx, cpo, name, refreshRepositoryAuto)
 	if err != nil {
 		diags.AddError("Error locating chart", fmt.Sprintf("Unable to locate chart %s: %s", name, err))
 		return nil, "", diags
@@ -901,8 +934,8 @@ func checkChartDependenciesModel(ctx context.Context, model *HelmTemplateModel,
 					RepositoryCache:  meta.Settings.RepositoryCache,
 					Debug:            meta.Settings.Debug,
 				}
-				tflog.Debug(ctx, "Downloading chart dependencies...")
-				if err := man.Update(); err != nil {
+				subsystemDebug(ctx, logSubsystemDownload, "Downloading chart dependencies...")
+				if err := meta.updateDependencies(ctx, man); err != nil {
 					diags.AddError("Failed to update chart dependencies", fmt.Sprintf("Error: %s", err))
 					return true, diags
 				}
@@ -912,150 +945,7 @@ func checkChartDependenciesModel(ctx context.Context, model *HelmTemplateModel,
 			return false, diags
 		}
 	}
-	tflog.Debug(ctx, "Chart dependencies are up to date.")
+	subsystemDebug(ctx, logSubsystemDownload, "Chart dependencies are up to date.")
 	return false, diags
 }
 
-func applySetValue(base map[string]interface{}, set SetValue) diag.Diagnostics {
-	var diags diag.Diagnostics
-
-	name := set.Name.ValueString()
-	value := set.Value.ValueString()
-	valueType := set.Type.ValueString()
-
-	switch valueType {
-	case "auto", "":
-		if err := strvals.ParseInto(fmt.Sprintf("%s=%s", name, value), base); err != nil {
-			diags.AddError("Failed parsing value", fmt.Sprintf("Key %q with value %s: %s", name, value, err))
-		}
-	case "string":
-		if err := strvals.ParseIntoString(fmt.Sprintf("%s=%s", name, value), base); err != nil {
-			diags.AddError("Failed parsing string value", fmt.Sprintf("Key %q with value %s: %s", name, value, err))
-		}
-	default:
-		diags.AddError("Unexpected type", fmt.Sprintf("Unexpected type: %s", valueType))
-	}
-	return diags
-}
-
-func applySetListValue(ctx context.Context, base map[string]interface{}, setList SetListValue) diag.Diagnostics {
-	var diags diag.Diagnostics
-
-	name := setList.Name.ValueString()
-
-	if setList.Value.IsNull() {
-		diags.AddError("Null List Value", "The list value is null.")
-		return diags
-	}
-
-	// Extract elements from the list value
-	elements := setList.Value.Elements()
-
-	listStringArray := make([]string, 0, len(elements))
-	for _, element := range elements {
-		if !element.IsNull() {
-			strValue := element.(types.String).ValueString()
-			listStringArray = append(listStringArray, strValue)
-		}
-	}
-
-	listString := strings.Join(listStringArray, ",")
-
-	// Parse the joined string into the base map
-	if err := strvals.ParseInto(fmt.Sprintf("%s={%s}", name, listString), base); err != nil {
-		diags.AddError("Error parsing list value", fmt.Sprintf("Failed parsing key %q with value %s: %s", name, listString, err))
-		return diags
-	}
-
-	return diags
-}
-
-func applySetSensitiveValue(base map[string]interface{}, setSensitive SetSensitiveValue) diag.Diagnostics {
-	var diags diag.Diagnostics
-
-	name := setSensitive.Name.ValueString()
-	value := setSensitive.Value.ValueString()
-	valueType := setSensitive.Type.ValueString()
-
-	switch valueType {
-	case "auto", "":
-		if err := strvals.ParseInto(fmt.Sprintf("%s=%s", name, value), base); err != nil {
-			diags.AddError("Failed parsing sensitive value", fmt.Sprintf("Failed parsing key %q with value %s: %s", name, value, err))
-		}
-	case "string":
-		if err := strvals.ParseIntoString(fmt.Sprintf("%s=%s", name, value), base); err != nil {
-			diags.AddError("Failed parsing sensitive string value", fmt.Sprintf("Failed parsing key %q with value %s: %s", name, value, err))
-		}
-	default:
-		diags.AddError("Unexpected type", fmt.Sprintf("Unexpected type for sensitive value: %s", valueType))
-	}
-
-	return diags
-}
-
-func LogValuesModel(ctx context.Context, values map[string]interface{}, state *HelmTemplateModel) diag.Diagnostics {
-	var diags diag.Diagnostics
-
-	asJSON, err := json.Marshal(values)
-	if err != nil {
-		diags.AddError("Error marshaling values to JSON", fmt.Sprintf("Failed to marshal values to JSON: %s", err))
-		return diags
-	}
-
-	var clonedValues map[string]interface{}
-	err = json.Unmarshal(asJSON, &clonedValues)
-	if err != nil {
-		diags.AddError("Error unmarshaling JSON to map", fmt.Sprintf("Failed to unmarshal JSON to map: %s", err))
-		return diags
-	}
-
-	// Apply cloaking or masking for sensitive values
-	cloakSetValuesModel(clonedValues, state)
-
-	// Convert the modified map to YAML for logging purposes
-	yamlData, err := yaml.Marshal(clonedValues)
-	if err != nil {
-		diags.AddError("Error marshaling map to YAML", fmt.Sprintf("Failed to marshal map to YAML: %s", err))
-		return diags
-	}
-
-	// Log the final YAML representation of the values
-	tflog.Debug(ctx, fmt.Sprintf("---[ values.yaml ]-----------------------------------\n%s\n", string(yamlData)))
-
-	return diags
-}
-
-func cloakSetValuesModel(config map[string]interface{}, state *HelmTemplateModel) {
-	if !state.SetSensitive.IsNull() {
-		var setSensitiveList []SetSensitiveValue
-		diags := state.SetSensitive.ElementsAs(context.Background(), &setSensitiveList, false)
-		if diags.HasError() {
-			tflog.Warn(context.Background(), "Error parsing SetSensitive elements", map[string]interface{}{
-				"diagnostics": diags,
-			})
-			return
-		}
-
-		for _, set := range setSensitiveList {
-			cloakSetValueModel(config, set.Name.ValueString())
-		}
-	}
-}
-
-const sensitiveContentModelValue = "(sensitive value)"
-
-func cloakSetValueModel(values map[string]interface{}, valuePath string) {
-	pathKeys := strings.Split(valuePath, ".")
-	sensitiveKey := pathKeys[len(pathKeys)-1]
-	parentPathKeys := pathKeys[:len(pathKeys)-1]
-
-	currentMap := values
-	for _, key := range parentPathKeys {
-		v, ok := currentMap[key].(map[string]interface{})
-		if !ok {
-			return
-		}
-		currentMap = v
-	}
-	currentMap[sensitiveKey] = sensitiveContentModelValue
-}
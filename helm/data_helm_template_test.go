@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/release"
 )
 
 func TestAccDataTemplate_basic(t *testing.T) {
@@ -30,6 +32,9 @@ func TestAccDataTemplate_basic(t *testing.T) {
 				resource.TestCheckResourceAttrSet(datasourceAddress, "manifests.templates/tests/test-connection.yaml"),
 				resource.TestCheckResourceAttrSet(datasourceAddress, "manifest"),
 				resource.TestCheckResourceAttrSet(datasourceAddress, "notes"),
+				resource.TestCheckResourceAttrSet(datasourceAddress, fmt.Sprintf("manifest_hashes.deployment/%s-test-chart", name)),
+				resource.TestCheckResourceAttr(datasourceAddress, "images.#", "2"),
+				resource.TestCheckTypeSetElemAttr(datasourceAddress, "images.*", "busybox"),
 			),
 		}},
 	})
@@ -235,6 +240,83 @@ func TestAccDataTemplate_kubeVersion(t *testing.T) {
 	})
 }
 
+func TestAccDataTemplate_chartTarball(t *testing.T) {
+	name := randName("tarball")
+	namespace := randName(testNamespacePrefix)
+
+	datasourceAddress := fmt.Sprintf("data.helm_template.%s", testResourceName)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{{
+			Config: fmt.Sprintf(`
+				data "helm_template" "%s" {
+					name                 = %q
+					namespace            = %q
+					chart_tarball_base64 = %q
+				}
+			`, testResourceName, name, namespace, testChartTarball(t)),
+			Check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttrSet(datasourceAddress, "manifests.templates/deployment.yaml"),
+				resource.TestCheckNoResourceAttr(datasourceAddress, "chart"),
+			),
+		}},
+	})
+}
+
+func TestAccDataTemplate_hooks(t *testing.T) {
+	name := randName("hooks")
+	namespace := randName(testNamespacePrefix)
+
+	datasourceAddress := fmt.Sprintf("data.helm_template.%s", testResourceName)
+	config := func(attribute string) string {
+		return fmt.Sprintf(`
+			data "helm_template" "%s" {
+				name                 = %q
+				namespace            = %q
+				chart_tarball_base64 = %q
+				%s                   = true
+			}
+		`, testResourceName, name, namespace, testChartTarball(t), attribute)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config("no_hooks"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet(datasourceAddress, "manifests.templates/deployment.yaml"),
+					resource.TestCheckNoResourceAttr(datasourceAddress, "manifests.templates/tests/test-connection.yaml"),
+				),
+			},
+			{
+				Config: config("hooks_only"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(datasourceAddress, "manifests.%", "1"),
+					resource.TestCheckResourceAttrSet(datasourceAddress, "manifests.templates/tests/test-connection.yaml"),
+				),
+			},
+		},
+	})
+}
+
+func TestTemplateManifest(t *testing.T) {
+	rel := &release.Release{
+		Manifest: "---\n# Source: app/templates/deployment.yaml\nkind: Deployment\n",
+		Hooks: []*release.Hook{
+			{Path: "app/templates/migrate.yaml", Manifest: "kind: Job", Events: []release.HookEvent{release.HookPreUpgrade}},
+			{Path: "app/templates/tests/test.yaml", Manifest: "kind: Pod", Events: []release.HookEvent{release.HookTest}},
+		},
+	}
+
+	assert.Equal(t, "---\n# Source: app/templates/deployment.yaml\nkind: Deployment\n"+
+		"---\n# Source: app/templates/migrate.yaml\nkind: Job\n"+
+		"---\n# Source: app/templates/tests/test.yaml\nkind: Pod\n", templateManifest(rel, false, false, false))
+	assert.Equal(t, "---\n# Source: app/templates/deployment.yaml\nkind: Deployment\n", templateManifest(rel, true, false, false))
+	assert.Equal(t, "---\n# Source: app/templates/migrate.yaml\nkind: Job\n", templateManifest(rel, false, true, true))
+}
+
 func testAccDataHelmTemplateConfigBasic(resource, ns, name, version string) string {
 	return fmt.Sprintf(`
 		data "helm_template" "%s" {
@@ -0,0 +1,158 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// checkMissingDependencies returns an error when a dependency of the chart missing from its
+// charts/ directory is enabled by the values of the release. Helm skips the dependencies of
+// Chart.yaml it cannot find when rendering, so disabled dependencies may be left out.
+func checkMissingDependencies(ctx context.Context, model *HelmReleaseModel, c *chart.Chart, m *Meta) diag.Diagnostics {
+	if valuesUnknown(*model) {
+		tflog.Debug(ctx, "Values are unknown, checking the missing dependencies on apply")
+		return nil
+	}
+
+	values, diags := getValues(ctx, model, m)
+	if diags.HasError() {
+		return diags
+	}
+	vals, err := chartutil.CoalesceValues(c, values)
+	if err != nil {
+		diags.AddError("Error checking chart dependencies", fmt.Sprintf("Unable to merge the values of chart %s: %s", c.Name(), err))
+		return diags
+	}
+
+	if missing := missingDependencies(c, vals); len(missing) > 0 {
+		diags.AddError("Missing chart dependencies", fmt.Sprintf("The dependencies %s of chart %s are enabled, but missing in the charts/ directory", strings.Join(missing, ", "), c.Name()))
+		return diags
+	}
+	tflog.Debug(ctx, fmt.Sprintf("Ignoring the disabled dependencies missing from chart %s", c.Name()))
+	return diags
+}
+
+// missingDependencies returns the names of the enabled dependencies of Chart.yaml that are
+// missing from the charts/ directory
+func missingDependencies(c *chart.Chart, vals chartutil.Values) []string {
+	loaded := map[string]bool{}
+	for _, d := range c.Dependencies() {
+		loaded[d.Name()] = true
+	}
+
+	var missing []string
+	for _, d := range c.Metadata.Dependencies {
+		if d == nil || loaded[d.Name] {
+			continue
+		}
+		if dependencyEnabled(d, vals, vals) {
+			missing = append(missing, d.Name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// releaseEnabledSubcharts returns the enabled_subcharts attribute of a release of chart c
+func releaseEnabledSubcharts(ctx context.Context, c *chart.Chart, values map[string]interface{}) (types.List, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	vals, err := chartutil.CoalesceValues(c, values)
+	if err != nil {
+		diags.AddError("Error evaluating subchart conditions", fmt.Sprintf("Unable to merge the values of chart %s: %s", c.Name(), err))
+		return types.ListNull(types.StringType), diags
+	}
+	list, listDiags := types.ListValueFrom(ctx, types.StringType, enabledSubcharts(c, vals))
+	diags.Append(listDiags...)
+	return list, diags
+}
+
+// enabledSubcharts returns the dependencies of the chart enabled by the values, named by
+// their alias if they have one. The dependencies of subcharts are prefixed with the name of
+// their parent, e.g. parent.child. Dependencies missing from the charts/ directory are left
+// out, as they are not rendered.
+func enabledSubcharts(c *chart.Chart, vals chartutil.Values) []string {
+	enabled := []string{}
+	walkEnabledSubcharts(c, vals, vals, "", &enabled)
+	sort.Strings(enabled)
+	return enabled
+}
+
+func walkEnabledSubcharts(c *chart.Chart, vals, top chartutil.Values, prefix string, enabled *[]string) {
+	loaded := map[string]*chart.Chart{}
+	for _, d := range c.Dependencies() {
+		loaded[d.Name()] = d
+	}
+
+	declared := map[string]bool{}
+	for _, d := range c.Metadata.Dependencies {
+		if d == nil {
+			continue
+		}
+		declared[d.Name] = true
+		sub, ok := loaded[d.Name]
+		if !ok || !dependencyEnabled(d, vals, top) {
+			continue
+		}
+		name := d.Name
+		if d.Alias != "" {
+			name = d.Alias
+		}
+		*enabled = append(*enabled, prefix+name)
+		subVals, _ := vals.Table(name)
+		walkEnabledSubcharts(sub, subVals, top, prefix+name+".", enabled)
+	}
+
+	// charts of the charts/ directory that are not declared in Chart.yaml are always enabled
+	for name, sub := range loaded {
+		if declared[name] {
+			continue
+		}
+		*enabled = append(*enabled, prefix+name)
+		subVals, _ := vals.Table(name)
+		walkEnabledSubcharts(sub, subVals, top, prefix+name+".", enabled)
+	}
+}
+
+// dependencyEnabled evaluates the condition and the tags of a dependency the way Helm does:
+// the first condition path of the values of the parent chart holding a boolean wins, then
+// the dependency is disabled when all of its tags set in the top level values are false
+func dependencyEnabled(d *chart.Dependency, vals, top chartutil.Values) bool {
+	for _, c := range strings.Split(d.Condition, ",") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		if v, err := vals.PathValue(c); err == nil {
+			if enabled, ok := v.(bool); ok {
+				return enabled
+			}
+		}
+	}
+
+	tags, err := top.Table("tags")
+	if err != nil {
+		return true
+	}
+	hasTrue, hasFalse := false, false
+	for _, t := range d.Tags {
+		if enabled, ok := tags[t].(bool); ok {
+			if enabled {
+				hasTrue = true
+			} else {
+				hasFalse = true
+			}
+		}
+	}
+	return hasTrue || !hasFalse
+}
@@ -0,0 +1,87 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestMissingDependencies(t *testing.T) {
+	foo := &chart.Chart{Metadata: &chart.Metadata{Name: "foo", Version: "0.1.0"}}
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:    "umbrella",
+			Version: "0.1.0",
+			Dependencies: []*chart.Dependency{
+				{Name: "foo", Condition: "foo.enabled"},
+				{Name: "disabled", Condition: "missing.enabled,disabled.enabled"},
+				{Name: "enabled", Condition: "enabled.enabled"},
+				{Name: "unconditional"},
+				{Name: "tagged-off", Tags: []string{"off"}},
+				{Name: "tagged-on", Tags: []string{"off", "on"}},
+			},
+		},
+	}
+	c.SetDependencies(foo)
+
+	vals := chartutil.Values{
+		"foo":      map[string]interface{}{"enabled": false},
+		"disabled": map[string]interface{}{"enabled": false},
+		"enabled":  map[string]interface{}{"enabled": true},
+		"tags":     map[string]interface{}{"off": false, "on": true},
+	}
+	assert.Equal(t, []string{"enabled", "tagged-on", "unconditional"}, missingDependencies(c, vals))
+}
+
+func TestSkipSchemaValidation(t *testing.T) {
+	schema := []byte(`{"type": "object", "required": ["name"]}`)
+	sub := &chart.Chart{Metadata: &chart.Metadata{Name: "sub", Version: "0.1.0"}, Schema: schema}
+	c := &chart.Chart{Metadata: &chart.Metadata{Name: "parent", Version: "0.1.0"}, Schema: schema}
+	c.SetDependencies(sub)
+
+	vals := map[string]interface{}{"sub": map[string]interface{}{}}
+	assert.Error(t, chartutil.ValidateAgainstSchema(c, vals))
+
+	skipSchemaValidation(c)
+	assert.NoError(t, chartutil.ValidateAgainstSchema(c, vals))
+}
+
+func TestEnabledSubcharts(t *testing.T) {
+	nested := &chart.Chart{Metadata: &chart.Metadata{Name: "nested", Version: "0.1.0"}}
+	cache := &chart.Chart{Metadata: &chart.Metadata{
+		Name:         "cache",
+		Version:      "0.1.0",
+		Dependencies: []*chart.Dependency{{Name: "nested", Condition: "nested.enabled"}},
+	}}
+	cache.SetDependencies(nested)
+	db := &chart.Chart{Metadata: &chart.Metadata{Name: "db", Version: "0.1.0"}}
+	undeclared := &chart.Chart{Metadata: &chart.Metadata{Name: "undeclared", Version: "0.1.0"}}
+	c := &chart.Chart{Metadata: &chart.Metadata{
+		Name:    "umbrella",
+		Version: "0.1.0",
+		Dependencies: []*chart.Dependency{
+			{Name: "cache", Alias: "redis", Condition: "redis.enabled"},
+			{Name: "db", Tags: []string{"database"}},
+			{Name: "missing"},
+		},
+	}}
+	c.SetDependencies(cache, db, undeclared)
+
+	vals := chartutil.Values{
+		"redis": map[string]interface{}{
+			"enabled": true,
+			"nested":  map[string]interface{}{"enabled": true},
+		},
+		"tags": map[string]interface{}{"database": false},
+	}
+	assert.Equal(t, []string{"redis", "redis.nested", "undeclared"}, enabledSubcharts(c, vals))
+
+	vals["redis"] = map[string]interface{}{"enabled": false}
+	vals["tags"] = map[string]interface{}{"database": true}
+	assert.Equal(t, []string{"db", "undeclared"}, enabledSubcharts(c, vals))
+}
@@ -0,0 +1,106 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// dependencyRepositoryModel holds the credentials of a repository of the dependencies of the chart
+type dependencyRepositoryModel struct {
+	URL      types.String `tfsdk:"url"`
+	Username types.String `tfsdk:"username"`
+	Password types.String `tfsdk:"password"`
+	CAFile   types.String `tfsdk:"ca_file"`
+}
+
+func dependencyRepositoryAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"url":      types.StringType,
+		"username": types.StringType,
+		"password": types.StringType,
+		"ca_file":  types.StringType,
+	}
+}
+
+// dependencyRepositoryFile returns the repositories of repositoryConfig with the credentials of
+// the dependency repositories of the release. The downloader.Manager looks up the credentials of
+// the repositories of the dependencies by URL in the repository config file.
+func dependencyRepositoryFile(ctx context.Context, model *HelmReleaseModel, repositoryConfig string) (*repo.File, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var repositories []dependencyRepositoryModel
+	diags.Append(model.DependencyRepositories.ElementsAs(ctx, &repositories, false)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	f, err := repo.LoadFile(repositoryConfig)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		diags.AddError("Error reading repository config", fmt.Sprintf("Unable to read %s: %s", repositoryConfig, err))
+		return nil, diags
+	}
+	if f == nil || err != nil {
+		f = repo.NewFile()
+	}
+
+	for _, r := range repositories {
+		url := strings.TrimSuffix(r.URL.ValueString(), "/")
+		sum := sha256.Sum256([]byte(url))
+		entry := &repo.Entry{
+			Name: fmt.Sprintf("dependency-%x", sum[:6]),
+			URL:  url,
+		}
+		// keep the name of a configured repository, its cached index is named after it
+		for _, e := range f.Repositories {
+			if strings.TrimSuffix(e.URL, "/") == url {
+				entry = e
+			}
+		}
+		entry.Username = r.Username.ValueString()
+		entry.Password = r.Password.ValueString()
+		entry.CAFile = r.CAFile.ValueString()
+		if !f.Has(entry.Name) {
+			f.Add(entry)
+		}
+	}
+	return f, diags
+}
+
+// writeDependencyRepositoryConfig writes the repository config of the dependency repositories of
+// the release, see dependencyRepositoryFile, to a temporary directory. remove deletes it.
+func writeDependencyRepositoryConfig(ctx context.Context, model *HelmReleaseModel, repositoryConfig string) (string, func(), diag.Diagnostics) {
+	f, diags := dependencyRepositoryFile(ctx, model, repositoryConfig)
+	if diags.HasError() {
+		return "", nil, diags
+	}
+
+	dir, err := os.MkdirTemp("", "terraform-provider-helm-dependencies")
+	if err != nil {
+		diags.AddError("Error writing repository config", err.Error())
+		return "", nil, diags
+	}
+	remove := func() { os.RemoveAll(dir) }
+
+	// the file holds the passwords of the repositories
+	path := filepath.Join(dir, "repositories.yaml")
+	if err := f.WriteFile(path, 0600); err != nil {
+		remove()
+		diags.AddError("Error writing repository config", err.Error())
+		return "", nil, diags
+	}
+	return path, remove, diags
+}
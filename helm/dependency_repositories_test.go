@@ -0,0 +1,81 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+func TestDependencyRepositoryFile(t *testing.T) {
+	ctx := context.Background()
+	repositoryConfig := filepath.Join(t.TempDir(), "repositories.yaml")
+	f := repo.NewFile()
+	f.Add(&repo.Entry{Name: "internal", URL: "https://charts.example.com/internal/"})
+	f.Add(&repo.Entry{Name: "public", URL: "https://charts.example.com/public"})
+	if err := f.WriteFile(repositoryConfig, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	attrTypes := map[string]attr.Type{
+		"url":      types.StringType,
+		"username": types.StringType,
+		"password": types.StringType,
+		"ca_file":  types.StringType,
+	}
+	repository := func(url, username, password string) attr.Value {
+		return types.ObjectValueMust(attrTypes, map[string]attr.Value{
+			"url":      types.StringValue(url),
+			"username": types.StringValue(username),
+			"password": types.StringValue(password),
+			"ca_file":  types.StringNull(),
+		})
+	}
+	model := &HelmReleaseModel{
+		DependencyRepositories: types.ListValueMust(types.ObjectType{AttrTypes: attrTypes}, []attr.Value{
+			repository("https://charts.example.com/internal", "user", "pass"),
+			repository("https://charts.example.com/other/", "other", "secret"),
+		}),
+	}
+
+	file, diags := dependencyRepositoryFile(ctx, model, repositoryConfig)
+	assert.False(t, diags.HasError(), diags)
+	assert.Len(t, file.Repositories, 3)
+
+	internal := file.Get("internal")
+	assert.Equal(t, "user", internal.Username)
+	assert.Equal(t, "pass", internal.Password)
+	assert.Empty(t, file.Get("public").Username)
+
+	other := file.Repositories[2]
+	assert.Regexp(t, `^dependency-[a-f0-9]{12}$`, other.Name)
+	assert.Equal(t, "https://charts.example.com/other", other.URL)
+	assert.Equal(t, "other", other.Username)
+
+	// the repository config file is not modified
+	unchanged, err := repo.LoadFile(repositoryConfig)
+	assert.NoError(t, err)
+	assert.Empty(t, unchanged.Get("internal").Username)
+
+	// a missing repository config file has no repositories
+	file, diags = dependencyRepositoryFile(ctx, model, filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.False(t, diags.HasError(), diags)
+	assert.Len(t, file.Repositories, 2)
+
+	path, remove, diags := writeDependencyRepositoryConfig(ctx, model, repositoryConfig)
+	assert.False(t, diags.HasError(), diags)
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	remove()
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
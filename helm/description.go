@@ -0,0 +1,84 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"helm.sh/helm/v3/pkg/action"
+)
+
+// descriptionRunIDEnvVars are the environment variables the run ID of the description of a
+// release is read from: the ID of the HCP Terraform run, or of the run of another pipeline
+var descriptionRunIDEnvVars = []string{"TFC_RUN_ID", "TF_RUN_ID"}
+
+// descriptionData holds the placeholders of the description of a release
+type descriptionData struct {
+	Name           string
+	Namespace      string
+	Chart          string
+	Version        string
+	Revision       int
+	Timestamp      string
+	TerraformRunID string
+}
+
+// terraformRunID returns the ID of the Terraform run of the environment, empty outside of a run
+func terraformRunID() string {
+	for _, name := range descriptionRunIDEnvVars {
+		if id := os.Getenv(name); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// nextRevision returns the revision the next install or upgrade of the release name creates
+func nextRevision(cfg *action.Configuration, name string) int {
+	if cfg.Releases == nil {
+		return 1
+	}
+	last, err := cfg.Releases.Last(name)
+	if err != nil || last == nil {
+		return 1
+	}
+	return last.Version + 1
+}
+
+// expandDescription expands the placeholders of the description of a release, e.g.
+// {{.Revision}}, {{.Timestamp}} and {{.TerraformRunID}}, for its install or upgrade to revision.
+// Descriptions without placeholders are returned as is.
+func expandDescription(description string, data descriptionData) (string, error) {
+	if !strings.Contains(description, "{{") {
+		return description, nil
+	}
+	tmpl, err := template.New("description").Option("missingkey=error").Parse(description)
+	if err != nil {
+		return "", fmt.Errorf("invalid description template: %w", err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("invalid description template: %w", err)
+	}
+	return out.String(), nil
+}
+
+// releaseDescription returns the description of the install or upgrade of the release of model
+// with actionConfig to the chart version, see expandDescription
+func releaseDescription(cfg *action.Configuration, model *HelmReleaseModel, chartName, chartVersion string) (string, error) {
+	name := model.Name.ValueString()
+	return expandDescription(model.Description.ValueString(), descriptionData{
+		Name:           name,
+		Namespace:      model.Namespace.ValueString(),
+		Chart:          chartName,
+		Version:        chartVersion,
+		Revision:       nextRevision(cfg, name),
+		Timestamp:      time.Now().UTC().Format(time.RFC3339),
+		TerraformRunID: terraformRunID(),
+	})
+}
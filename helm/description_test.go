@@ -0,0 +1,60 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"io"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func TestExpandDescription(t *testing.T) {
+	data := descriptionData{Name: "app", Revision: 3, Timestamp: "2024-01-02T03:04:05Z", TerraformRunID: "run-abc"}
+
+	out, err := expandDescription("deployed by {{.TerraformRunID}} at {{.Timestamp}} (revision {{.Revision}})", data)
+	require.NoError(t, err)
+	assert.Equal(t, "deployed by run-abc at 2024-01-02T03:04:05Z (revision 3)", out)
+
+	out, err = expandDescription("no placeholders", data)
+	require.NoError(t, err)
+	assert.Equal(t, "no placeholders", out)
+
+	_, err = expandDescription("{{.Unknown}}", data)
+	assert.Error(t, err)
+	_, err = expandDescription("{{.Revision", data)
+	assert.Error(t, err)
+}
+
+func TestReleaseDescription(t *testing.T) {
+	t.Setenv("TFC_RUN_ID", "")
+	t.Setenv("TF_RUN_ID", "pipeline-42")
+
+	cfg := &action.Configuration{
+		Releases:   storage.Init(driver.NewMemory()),
+		KubeClient: &kubefake.PrintingKubeClient{Out: io.Discard},
+	}
+	model := &HelmReleaseModel{
+		Name:        types.StringValue("app"),
+		Namespace:   types.StringValue("default"),
+		Description: types.StringValue("{{.Chart}}-{{.Version}} revision {{.Revision}} run {{.TerraformRunID}}"),
+	}
+
+	out, err := releaseDescription(cfg, model, "nginx", "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "nginx-1.0.0 revision 1 run pipeline-42", out)
+
+	require.NoError(t, cfg.Releases.Create(&release.Release{Name: "app", Namespace: "default", Version: 4, Info: &release.Info{Status: release.StatusDeployed}}))
+	t.Setenv("TFC_RUN_ID", "run-xyz")
+	out, err = releaseDescription(cfg, model, "nginx", "1.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "nginx-1.0.1 revision 5 run run-xyz", out)
+}
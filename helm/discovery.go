@@ -0,0 +1,40 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"sync"
+
+	"k8s.io/client-go/discovery"
+)
+
+// discoveryCache is shared by every resource and data source of the provider, so that the
+// API resources of a cluster are discovered once per run instead of once per release.
+// Clients are keyed by kubeconfig context, as releases may set their own context.
+type discoveryCache struct {
+	mu      sync.Mutex
+	clients map[string]discovery.CachedDiscoveryInterface
+}
+
+func newDiscoveryCache() *discoveryCache {
+	return &discoveryCache{
+		clients: map[string]discovery.CachedDiscoveryInterface{},
+	}
+}
+
+// get returns the client of key, creating it with create on first use. Helm invalidates
+// the client after installing CRDs, which refreshes it for every user of the cache.
+func (c *discoveryCache) get(key string, create func() (discovery.CachedDiscoveryInterface, error)) (discovery.CachedDiscoveryInterface, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if client, ok := c.clients[key]; ok {
+		return client, nil
+	}
+	client, err := create()
+	if err != nil {
+		return nil, err
+	}
+	c.clients[key] = client
+	return client, nil
+}
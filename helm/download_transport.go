@@ -0,0 +1,212 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+var downloadProxyURLPattern = regexp.MustCompile(`^(https?|socks5)://`)
+
+// downloadTransport returns the transport of the chart downloads of a release going through
+// proxyURL, when set, and connecting to the addresses of resolve in place of the addresses of
+// their hosts. The TLS settings of cpo are applied to the transport, as Helm ignores them for
+// custom transports.
+func downloadTransport(cpo *action.ChartPathOptions, proxyURL string, resolve map[string]string) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableCompression = true
+
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid download_proxy_url: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+
+	if len(resolve) > 0 {
+		for host, ip := range resolve {
+			if net.ParseIP(ip) == nil {
+				return nil, fmt.Errorf("invalid IP address %q of host %s in resolve_overrides", ip, host)
+			}
+		}
+		dialer := &net.Dialer{Timeout: defaultKubeDialTimeout, KeepAlive: defaultKubeDialTimeout}
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, port, err := net.SplitHostPort(addr)
+			if err == nil {
+				if ip, ok := resolve[strings.ToLower(host)]; ok {
+					addr = net.JoinHostPort(ip, port)
+				}
+			}
+			return dialer.DialContext(ctx, network, addr)
+		}
+	}
+
+	if cpo.CaFile != "" || cpo.CertFile != "" || cpo.InsecureSkipTLSverify {
+		config := &tls.Config{InsecureSkipVerify: cpo.InsecureSkipTLSverify} // #nosec G402
+		if cpo.CaFile != "" {
+			ca, err := os.ReadFile(cpo.CaFile)
+			if err != nil {
+				return nil, err
+			}
+			config.RootCAs = x509.NewCertPool()
+			if !config.RootCAs.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("no PEM encoded certificate in %s", cpo.CaFile)
+			}
+		}
+		if cpo.CertFile != "" {
+			cert, err := tls.LoadX509KeyPair(cpo.CertFile, cpo.KeyFile)
+			if err != nil {
+				return nil, err
+			}
+			config.Certificates = []tls.Certificate{cert}
+		}
+		transport.TLSClientConfig = config
+	}
+	return transport, nil
+}
+
+// downloadGetters returns the getters of settings, whose HTTP getter uses transport
+func downloadGetters(settings *cli.EnvSettings, transport *http.Transport) getter.Providers {
+	providers := getter.Providers{{
+		Schemes: []string{"http", "https"},
+		New: func(options ...getter.Option) (getter.Getter, error) {
+			return getter.NewHTTPGetter(append(options, getter.WithTransport(transport))...)
+		},
+	}}
+	for _, p := range getter.All(settings) {
+		if !p.Provides("http") && !p.Provides("https") {
+			providers = append(providers, p)
+		}
+	}
+	return providers
+}
+
+// locateChartWithGetters downloads the chart of a repository like cpo.LocateChart, with getters.
+// Local and OCI charts are located by cpo.LocateChart.
+func locateChartWithGetters(cpo *action.ChartPathOptions, name string, settings *cli.EnvSettings, getters getter.Providers) (string, error) {
+	name = strings.TrimSpace(name)
+	if _, err := os.Stat(name); err == nil || registry.IsOCI(name) || filepath.IsAbs(name) || strings.HasPrefix(name, ".") {
+		return cpo.LocateChart(name, settings)
+	}
+
+	dl := downloader.ChartDownloader{
+		Out:     os.Stdout,
+		Keyring: cpo.Keyring,
+		Getters: getters,
+		Options: []getter.Option{
+			getter.WithPassCredentialsAll(cpo.PassCredentialsAll),
+			getter.WithPlainHTTP(cpo.PlainHTTP),
+		},
+		RepositoryConfig: settings.RepositoryConfig,
+		RepositoryCache:  settings.RepositoryCache,
+	}
+	if cpo.Verify {
+		dl.Verify = downloader.VerifyAlways
+	}
+	username, password := cpo.Username, cpo.Password
+	if cpo.RepoURL != "" {
+		chartURL, err := repo.FindChartInAuthAndTLSAndPassRepoURL(cpo.RepoURL, cpo.Username, cpo.Password, name, strings.TrimSpace(cpo.Version),
+			cpo.CertFile, cpo.KeyFile, cpo.CaFile, cpo.InsecureSkipTLSverify, cpo.PassCredentialsAll, getters)
+		if err != nil {
+			return "", err
+		}
+		// the credentials are only passed to the host of the repository, like Helm does
+		repoURL, err := url.Parse(cpo.RepoURL)
+		if err != nil {
+			return "", err
+		}
+		u, err := url.Parse(chartURL)
+		if err != nil {
+			return "", err
+		}
+		if !cpo.PassCredentialsAll && (repoURL.Scheme != u.Scheme || repoURL.Host != u.Host) {
+			username, password = "", ""
+		}
+		name = chartURL
+	}
+	dl.Options = append(dl.Options, getter.WithBasicAuth(username, password))
+
+	if err := os.MkdirAll(settings.RepositoryCache, 0o755); err != nil {
+		return "", err
+	}
+	filename, _, err := dl.DownloadTo(name, strings.TrimSpace(cpo.Version), settings.RepositoryCache)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(filename)
+}
+
+// releaseDownloadGetters returns the getters of the chart downloads of a release with
+// download_proxy_url or resolve_overrides, nil without them
+func releaseDownloadGetters(ctx context.Context, model *HelmReleaseModel, m *Meta, cpo *action.ChartPathOptions) (getter.Providers, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	proxyURL := model.DownloadProxyURL.ValueString()
+	resolve := map[string]string{}
+	if !model.ResolveOverrides.IsNull() && !model.ResolveOverrides.IsUnknown() {
+		var overrides map[string]types.String
+		diags.Append(model.ResolveOverrides.ElementsAs(ctx, &overrides, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		for host, ip := range overrides {
+			resolve[strings.ToLower(host)] = ip.ValueString()
+		}
+	}
+	if proxyURL == "" && len(resolve) == 0 {
+		return nil, diags
+	}
+
+	transport, err := downloadTransport(cpo, proxyURL, resolve)
+	if err != nil {
+		diags.AddError("Error configuring chart downloads", fmt.Sprintf("Unable to configure the chart downloads of release %s: %s", model.Name.ValueString(), err))
+		return nil, diags
+	}
+	return downloadGetters(m.Settings, transport), diags
+}
+
+// locateReleaseChart downloads the chart of a release through its download_proxy_url and
+// resolve_overrides, or through the chart fetcher of the provider without them, falling back to
+// its repository_mirrors
+func locateReleaseChart(ctx context.Context, model *HelmReleaseModel, m *Meta, cpo *action.ChartPathOptions, name string) (string, diag.Diagnostics) {
+	getters, diags := releaseDownloadGetters(ctx, model, m, cpo)
+	if diags.HasError() {
+		return "", diags
+	}
+	locate := func(cpo *action.ChartPathOptions, name string) (string, error) {
+		if getters != nil {
+			// the downloads through the settings of the release are not shared with other releases
+			return locateChartWithGetters(cpo, name, m.Settings, getters)
+		}
+		return m.locateChart(ctx, cpo, name, model.RefreshRepository.ValueString())
+	}
+	path, err := locate(cpo, name)
+	model.RepositoryMirror = types.StringNull()
+	if err != nil && len(model.RepositoryMirrors.Elements()) > 0 {
+		path, err = locateMirroredChart(ctx, model, cpo, locate, err)
+	}
+	if err != nil {
+		diags.AddError("Error locating chart", fmt.Sprintf("Unable to locate chart %s: %s", name, err))
+	}
+	return path, diags
+}
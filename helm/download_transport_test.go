@@ -0,0 +1,59 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+func TestLocateReleaseChartResolveOverrides(t *testing.T) {
+	server := newTestIndexServer(t, "1.0.0")
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	m := testRepositoryIndexMeta(t, 0)
+
+	model := &HelmReleaseModel{
+		Name:             types.StringValue("test"),
+		DownloadProxyURL: types.StringNull(),
+		ResolveOverrides: types.MapValueMust(types.StringType, map[string]attr.Value{
+			"charts.internal.test": types.StringValue(u.Hostname()),
+		}),
+	}
+	cpo := &action.ChartPathOptions{RepoURL: "http://charts.internal.test:" + u.Port(), Version: "1.0.0"}
+	path, diags := locateReleaseChart(context.Background(), model, m, cpo, "test-chart")
+	require.False(t, diags.HasError(), diags)
+	assert.FileExists(t, path)
+
+	model.ResolveOverrides = types.MapValueMust(types.StringType, map[string]attr.Value{
+		"charts.internal.test": types.StringValue("not-an-ip"),
+	})
+	_, diags = locateReleaseChart(context.Background(), model, m, cpo, "test-chart")
+	assert.True(t, diags.HasError())
+}
+
+func TestDownloadTransportProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer proxy.Close()
+
+	transport, err := downloadTransport(&action.ChartPathOptions{}, proxy.URL, nil)
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: transport}).Get("http://charts.internal.test/index.yaml")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, []string{"http://charts.internal.test/index.yaml"}, proxied)
+}
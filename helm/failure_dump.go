@@ -0,0 +1,193 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// failureDumpEvents is the number of recent events of the namespace of a release in the dump
+const failureDumpEvents = 50
+
+// failureSnapshot is the describe-like snapshot of a resource which is not ready after a failed
+// install or upgrade
+type failureSnapshot struct {
+	Resource string                 `json:"resource"`
+	Health   string                 `json:"health"`
+	Object   map[string]interface{} `json:"object,omitempty"`
+	Events   []failureEvent         `json:"events"`
+}
+
+// failureEvent is a Kubernetes event in the dump
+type failureEvent struct {
+	Time    string `json:"time"`
+	Type    string `json:"type"`
+	Reason  string `json:"reason"`
+	Object  string `json:"object"`
+	Message string `json:"message"`
+	Count   int32  `json:"count,omitempty"`
+}
+
+// eventTime returns the time an event was last seen
+func eventTime(e corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	}
+	return e.FirstTimestamp.Time
+}
+
+// recentEvents returns the last limit events of the namespace matching fieldSelector, oldest first
+func recentEvents(ctx context.Context, clientset kubernetes.Interface, namespace, fieldSelector string, limit int) ([]failureEvent, error) {
+	list, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: fieldSelector})
+	if err != nil {
+		return nil, err
+	}
+	items := list.Items
+	sort.SliceStable(items, func(i, j int) bool { return eventTime(items[i]).Before(eventTime(items[j])) })
+	if len(items) > limit {
+		items = items[len(items)-limit:]
+	}
+	events := make([]failureEvent, 0, len(items))
+	for _, e := range items {
+		events = append(events, failureEvent{
+			Time:    eventTime(e).UTC().Format(time.RFC3339),
+			Type:    e.Type,
+			Reason:  e.Reason,
+			Object:  fmt.Sprintf("%s/%s", strings.ToLower(e.InvolvedObject.Kind), e.InvolvedObject.Name),
+			Message: strings.TrimSpace(e.Message),
+			Count:   e.Count,
+		})
+	}
+	return events, nil
+}
+
+// snapshotObject returns the live object of a resource without its managed fields
+func snapshotObject(obj runtime.Object) map[string]interface{} {
+	if obj == nil {
+		return nil
+	}
+	var object map[string]interface{}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		object = u.DeepCopy().Object
+	} else {
+		var err error
+		if object, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj); err != nil {
+			return nil
+		}
+	}
+	unstructured.RemoveNestedField(object, "metadata", "managedFields")
+	return object
+}
+
+// failureSnapshots returns the snapshots of the resources which are not ready in health, with
+// their events
+func failureSnapshots(ctx context.Context, clientset kubernetes.Interface, resources kube.ResourceList, health map[string]string) ([]failureSnapshot, error) {
+	var snapshots []failureSnapshot
+	for _, info := range resources {
+		key := objectKey(info)
+		h, ok := health[key]
+		if !ok || h == resourceReady {
+			continue
+		}
+		selector := fields.Set{
+			"involvedObject.kind": info.Mapping.GroupVersionKind.Kind,
+			"involvedObject.name": info.Name,
+		}.AsSelector().String()
+		events, err := recentEvents(ctx, clientset, info.Namespace, selector, failureDumpEvents)
+		if err != nil {
+			return nil, fmt.Errorf("listing the events of %s: %w", key, err)
+		}
+		snapshots = append(snapshots, failureSnapshot{Resource: key, Health: h, Object: snapshotObject(info.Object), Events: events})
+	}
+	return snapshots, nil
+}
+
+// writeFailureDump writes a file per snapshot and the recent events of the namespace of the
+// release r to a directory of dir named after the release and its revision. It returns the
+// directory.
+func writeFailureDump(dir string, r *release.Release, snapshots []failureSnapshot, events []failureEvent) (string, error) {
+	dumpDir := filepath.Join(dir, fmt.Sprintf("%s-%s-%d", r.Namespace, r.Name, r.Version))
+	if err := os.MkdirAll(dumpDir, 0o755); err != nil {
+		return "", err
+	}
+	write := func(name string, v interface{}) error {
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dumpDir, name), b, 0o644)
+	}
+	for _, s := range snapshots {
+		if err := write(strings.ReplaceAll(s.Resource, "/", "_")+".yaml", s); err != nil {
+			return "", err
+		}
+	}
+	if err := write("events.yaml", events); err != nil {
+		return "", err
+	}
+	return dumpDir, nil
+}
+
+// dumpReleaseFailure writes the snapshots of the resources of the failed release r which are not
+// ready and the recent events of its namespace to dir, see failure_dump_dir
+func dumpReleaseFailure(ctx context.Context, actionConfig *action.Configuration, dir string, r *release.Release, checkJobs bool) (string, error) {
+	resources, err := actionConfig.KubeClient.Build(bytes.NewBufferString(r.Manifest), false)
+	if err != nil {
+		return "", fmt.Errorf("building the resources of the release: %w", err)
+	}
+	clientset, err := actionConfig.KubernetesClientSet()
+	if err != nil {
+		return "", err
+	}
+	checker := kube.NewReadyChecker(clientset, func(string, ...interface{}) {}, kube.PausedAsReady(true), kube.CheckJobs(checkJobs))
+	snapshots, err := failureSnapshots(ctx, clientset, resources, resourceHealth(ctx, &checker, resources))
+	if err != nil {
+		return "", err
+	}
+	events, err := recentEvents(ctx, clientset, r.Namespace, "", failureDumpEvents)
+	if err != nil {
+		return "", fmt.Errorf("listing the events of namespace %s: %w", r.Namespace, err)
+	}
+	return writeFailureDump(dir, r, snapshots, events)
+}
+
+// failureDumpDiagnostics dumps the failure of the install or upgrade of the release r when
+// failure_dump_dir is set and the release waited for its resources. Atomic installs and upgrades
+// are uninstalled or rolled back when they fail, their resources are gone.
+func failureDumpDiagnostics(ctx context.Context, actionConfig *action.Configuration, model *HelmReleaseModel, r *release.Release, atomic bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+	dir := model.FailureDumpDir.ValueString()
+	if dir == "" || r == nil || !model.Wait.ValueBool() || atomic {
+		return diags
+	}
+	dumpDir, err := dumpReleaseFailure(ctx, actionConfig, dir, r, model.WaitForJobs.ValueBool())
+	if err != nil {
+		diags.AddWarning("Error writing failure diagnostics", fmt.Sprintf("Unable to write the failure diagnostics of release %s to %s: %s", r.Name, dir, err))
+		return diags
+	}
+	diags.AddWarning("Failure diagnostics written", fmt.Sprintf("The resources of release %s which are not ready and the recent events of namespace %s were written to %s", r.Name, r.Namespace, dumpDir))
+	return diags
+}
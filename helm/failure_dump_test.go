@@ -0,0 +1,105 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+)
+
+func testEvent(name, reason string, seen time.Time) *v1.Event {
+	return &v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "web-1"},
+		Type:           v1.EventTypeWarning,
+		Reason:         reason,
+		Message:        reason + " message\n",
+		LastTimestamp:  metav1.NewTime(seen),
+		Count:          2,
+	}
+}
+
+func TestRecentEvents(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clientset := fake.NewSimpleClientset(
+		testEvent("c", "BackOff", now),
+		testEvent("a", "Pulling", now.Add(-2*time.Minute)),
+		testEvent("b", "Failed", now.Add(-time.Minute)),
+	)
+
+	events, err := recentEvents(context.Background(), clientset, "default", "", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []failureEvent{
+		{Time: "2024-05-01T11:59:00Z", Type: "Warning", Reason: "Failed", Object: "pod/web-1", Message: "Failed message", Count: 2},
+		{Time: "2024-05-01T12:00:00Z", Type: "Warning", Reason: "BackOff", Object: "pod/web-1", Message: "BackOff message", Count: 2},
+	}, events)
+}
+
+func TestFailureSnapshots(t *testing.T) {
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":          "web",
+			"namespace":     "default",
+			"managedFields": []interface{}{map[string]interface{}{"manager": "helm"}},
+		},
+	}}
+	mapping := &meta.RESTMapping{GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}}
+	resources := kube.ResourceList{
+		{Name: "web", Namespace: "default", Mapping: mapping, Object: deployment},
+		{Name: "worker", Namespace: "default", Mapping: mapping},
+	}
+	health := map[string]string{
+		"deployment/default/web":    "NotReady: 0/1 replicas ready",
+		"deployment/default/worker": resourceReady,
+	}
+
+	snapshots, err := failureSnapshots(context.Background(), fake.NewSimpleClientset(), resources, health)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "deployment/default/web", snapshots[0].Resource)
+	assert.Equal(t, "NotReady: 0/1 replicas ready", snapshots[0].Health)
+	assert.Equal(t, map[string]interface{}{"name": "web", "namespace": "default"}, snapshots[0].Object["metadata"])
+	// the object of the release is left unchanged
+	assert.Contains(t, deployment.Object["metadata"], "managedFields")
+}
+
+func TestWriteFailureDump(t *testing.T) {
+	dir := t.TempDir()
+	r := &release.Release{Name: "web", Namespace: "default", Version: 3}
+	snapshots := []failureSnapshot{{Resource: "deployment/default/web", Health: "NotReady: 0/1 replicas ready"}}
+	events := []failureEvent{{Time: "2024-05-01T12:00:00Z", Type: "Warning", Reason: "BackOff", Object: "pod/web-1"}}
+
+	dumpDir, err := writeFailureDump(dir, r, snapshots, events)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "default-web-3"), dumpDir)
+
+	b, err := os.ReadFile(filepath.Join(dumpDir, "deployment_default_web.yaml"))
+	require.NoError(t, err)
+	var snapshot failureSnapshot
+	require.NoError(t, yaml.Unmarshal(b, &snapshot))
+	assert.Equal(t, snapshots[0], snapshot)
+
+	b, err = os.ReadFile(filepath.Join(dumpDir, "events.yaml"))
+	require.NoError(t, err)
+	var dumped []failureEvent
+	require.NoError(t, yaml.Unmarshal(b, &dumped))
+	assert.Equal(t, events, dumped)
+}
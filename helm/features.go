@@ -0,0 +1,194 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// Features of the features block of the provider
+const (
+	featureDriftDetection   = "drift_detection"
+	featureManifestDiff     = "manifest_diff"
+	featureServerDryRun     = "server_dry_run"
+	featureStructuredErrors = "structured_errors"
+)
+
+// Maturity levels of the features. Experimental features may change or be removed in a minor
+// release, beta features may change, stable features are covered by the compatibility promise.
+const (
+	featureMaturityExperimental = "experimental"
+	featureMaturityBeta         = "beta"
+	featureMaturityStable       = "stable"
+)
+
+// providerFeature is a behavior of the provider that is toggled in the features block
+type providerFeature struct {
+	Maturity    string
+	Default     bool
+	Description string
+}
+
+var providerFeatures = map[string]providerFeature{
+	featureDriftDetection: {
+		Maturity:    featureMaturityStable,
+		Default:     true,
+		Description: "Detect the changes made to releases outside of Terraform on refresh. If false, releases are not refreshed, as with drift_detection = \"none\".",
+	},
+	featureManifestDiff: {
+		Maturity:    featureMaturityBeta,
+		Description: "Render the manifest of releases on plan and store it in the state, so that plans show the changes of the objects of the releases. Replaces experiments.manifest.",
+	},
+	featureServerDryRun: {
+		Maturity:    featureMaturityBeta,
+		Description: "Render the manifest on plan with a server dry run for the releases that do not set dry_run_mode.",
+	},
+	featureStructuredErrors: {
+		Maturity:    featureMaturityExperimental,
+		Description: "Add the reason, object and causes of the Kubernetes API errors to the errors of failed installs and upgrades.",
+	},
+}
+
+// FeaturesConfigModel configures the features that are enabled or disabled
+type FeaturesConfigModel struct {
+	DriftDetection   types.Bool `tfsdk:"drift_detection"`
+	ManifestDiff     types.Bool `tfsdk:"manifest_diff"`
+	ServerDryRun     types.Bool `tfsdk:"server_dry_run"`
+	StructuredErrors types.Bool `tfsdk:"structured_errors"`
+}
+
+func (f *FeaturesConfigModel) values() map[string]types.Bool {
+	return map[string]types.Bool{
+		featureDriftDetection:   f.DriftDetection,
+		featureManifestDiff:     f.ManifestDiff,
+		featureServerDryRun:     f.ServerDryRun,
+		featureStructuredErrors: f.StructuredErrors,
+	}
+}
+
+func featuresSchema() map[string]schema.Attribute {
+	attributes := map[string]schema.Attribute{}
+	for name, f := range providerFeatures {
+		attributes[name] = schema.BoolAttribute{
+			Optional:    true,
+			Description: fmt.Sprintf("%s Maturity: %s. Defaults to %t.", f.Description, f.Maturity, f.Default),
+		}
+	}
+	return attributes
+}
+
+// resolveFeatures returns whether each feature is enabled, from the features block, then the
+// deprecated experiments block, then the default of the feature
+func resolveFeatures(ctx context.Context, features *FeaturesConfigModel, experiments *ExperimentsConfigModel) map[string]bool {
+	enabled := map[string]bool{}
+	for name, f := range providerFeatures {
+		enabled[name] = f.Default
+	}
+	if experiments != nil && !experiments.Manifest.IsNull() {
+		enabled[featureManifestDiff] = experiments.Manifest.ValueBool()
+	}
+	if features != nil {
+		for name, v := range features.values() {
+			if !v.IsNull() && !v.IsUnknown() {
+				enabled[name] = v.ValueBool()
+			}
+		}
+	}
+
+	names := make([]string, 0, len(enabled))
+	for name := range enabled {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if enabled[name] && providerFeatures[name].Maturity == featureMaturityExperimental {
+			tflog.Warn(ctx, fmt.Sprintf("Experimental feature %s is enabled, it may change or be removed in a minor release", name))
+		}
+	}
+	return enabled
+}
+
+// FeatureEnabled reports whether the feature name is enabled in the features block of the
+// provider, or by default
+func (m *Meta) FeatureEnabled(name string) bool {
+	if enabled, exists := m.Features[name]; exists {
+		return enabled
+	}
+	return providerFeatures[name].Default
+}
+
+// releaseDriftDetection returns the drift detection mode of a release, none when the
+// drift_detection feature is disabled
+func releaseDriftDetection(m *Meta, model *HelmReleaseModel) string {
+	if !m.FeatureEnabled(featureDriftDetection) {
+		return driftDetectionNone
+	}
+	return model.DriftDetection.ValueString()
+}
+
+// operationErrorDetail returns the detail of the error of a failed install or upgrade. With the
+// structured_errors feature, the Kubernetes API errors it wraps are listed with their reason,
+// object and causes, e.g. the fields of an invalid object.
+func (m *Meta) operationErrorDetail(detail string, err error) string {
+	if !m.FeatureEnabled(featureStructuredErrors) {
+		return detail
+	}
+	statuses := apiErrorStatuses(err)
+	if len(statuses) == 0 {
+		return detail
+	}
+	var b strings.Builder
+	b.WriteString(detail)
+	b.WriteString("\n\nKubernetes API errors:")
+	for _, s := range statuses {
+		fmt.Fprintf(&b, "\n- %s (%d)", s.Reason, s.Code)
+		if d := s.Details; d != nil && d.Name != "" {
+			kind := d.Kind
+			if d.Group != "" {
+				kind += "." + d.Group
+			}
+			fmt.Fprintf(&b, " %s %q", kind, d.Name)
+		}
+		fmt.Fprintf(&b, ": %s", s.Message)
+		if s.Details == nil {
+			continue
+		}
+		for _, c := range s.Details.Causes {
+			if c.Field != "" {
+				fmt.Fprintf(&b, "\n  - %s: %s", c.Field, c.Message)
+			} else {
+				fmt.Fprintf(&b, "\n  - %s", c.Message)
+			}
+		}
+	}
+	return b.String()
+}
+
+// apiErrorStatuses returns the statuses of the Kubernetes API errors err wraps, or aggregates
+func apiErrorStatuses(err error) []metav1.Status {
+	var agg utilerrors.Aggregate
+	if errors.As(err, &agg) {
+		var statuses []metav1.Status
+		for _, e := range agg.Errors() {
+			statuses = append(statuses, apiErrorStatuses(e)...)
+		}
+		return statuses
+	}
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		return []metav1.Status{status.Status()}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestResolveFeatures(t *testing.T) {
+	ctx := context.Background()
+
+	defaults := resolveFeatures(ctx, nil, nil)
+	assert.True(t, defaults[featureDriftDetection])
+	assert.False(t, defaults[featureManifestDiff])
+	assert.False(t, defaults[featureServerDryRun])
+	assert.False(t, defaults[featureStructuredErrors])
+
+	// the deprecated experiments block is used when the feature is not set
+	experiments := &ExperimentsConfigModel{Manifest: types.BoolValue(true)}
+	assert.True(t, resolveFeatures(ctx, nil, experiments)[featureManifestDiff])
+
+	features := &FeaturesConfigModel{
+		DriftDetection:   types.BoolValue(false),
+		ManifestDiff:     types.BoolValue(false),
+		ServerDryRun:     types.BoolNull(),
+		StructuredErrors: types.BoolValue(true),
+	}
+	enabled := resolveFeatures(ctx, features, experiments)
+	assert.False(t, enabled[featureDriftDetection])
+	assert.False(t, enabled[featureManifestDiff])
+	assert.False(t, enabled[featureServerDryRun])
+	assert.True(t, enabled[featureStructuredErrors])
+
+	// features default when the provider is not configured
+	m := &Meta{}
+	assert.True(t, m.FeatureEnabled(featureDriftDetection))
+	assert.False(t, m.FeatureEnabled(featureManifestDiff))
+	assert.Equal(t, driftDetectionMetadata, releaseDriftDetection(m, &HelmReleaseModel{DriftDetection: types.StringValue(driftDetectionMetadata)}))
+
+	m.Features = enabled
+	assert.Equal(t, driftDetectionNone, releaseDriftDetection(m, &HelmReleaseModel{DriftDetection: types.StringValue(driftDetectionMetadata)}))
+}
+
+func TestOperationErrorDetail(t *testing.T) {
+	invalid := apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "web", field.ErrorList{
+		field.Invalid(field.NewPath("spec", "replicas"), -1, "must be greater than or equal to 0"),
+	})
+	err := fmt.Errorf("failed to create resource: %w", invalid)
+
+	m := &Meta{}
+	assert.Equal(t, "Upgrade failed", m.operationErrorDetail("Upgrade failed", err))
+
+	m.Features = map[string]bool{featureStructuredErrors: true}
+	detail := m.operationErrorDetail("Upgrade failed", err)
+	assert.Contains(t, detail, `- Invalid (422) Deployment.apps "web"`)
+	assert.Contains(t, detail, "  - spec.replicas: Invalid value: -1: must be greater than or equal to 0")
+
+	agg := utilerrors.NewAggregate([]error{invalid, apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "token", errors.New("denied"))})
+	detail = m.operationErrorDetail("Upgrade failed", agg)
+	assert.Contains(t, detail, "- Invalid (422)")
+	assert.Contains(t, detail, `- Forbidden (403) secrets "token"`)
+
+	assert.Equal(t, "Upgrade failed", m.operationErrorDetail("Upgrade failed", errors.New("timed out")))
+}
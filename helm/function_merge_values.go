@@ -0,0 +1,62 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"sigs.k8s.io/yaml"
+)
+
+var _ function.Function = &MergeValuesFunction{}
+
+func NewMergeValuesFunction() function.Function {
+	return &MergeValuesFunction{}
+}
+
+// MergeValuesFunction merges values documents the way Helm merges values files
+type MergeValuesFunction struct{}
+
+func (f *MergeValuesFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "merge_values"
+}
+
+func (f *MergeValuesFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Merge values documents",
+		Description: "Merges YAML values documents in order, the way Helm merges the values files passed with -f: maps are merged recursively and any other value of a later document replaces the earlier one. Returns the merged values as YAML.",
+		VariadicParameter: function.StringParameter{
+			Name:        "documents",
+			Description: "Values documents in YAML format, later documents take precedence.",
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *MergeValuesFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var documents []string
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &documents))
+	if resp.Error != nil {
+		return
+	}
+
+	merged := map[string]interface{}{}
+	for i, d := range documents {
+		values, err := parseValuesDocument(d)
+		if err != nil {
+			resp.Error = function.NewArgumentFuncError(int64(i), fmt.Sprintf("Unable to parse values document %d: %s", i+1, err))
+			return
+		}
+		merged = mergeMaps(merged, values)
+	}
+
+	out, err := yaml.Marshal(merged)
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("Unable to marshal merged values: %s", err))
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, string(out)))
+}
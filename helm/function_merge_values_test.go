@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/stretchr/testify/assert"
+)
+
+func runStringFunction(t *testing.T, f function.Function, args ...attr.Value) (string, *function.FuncError) {
+	t.Helper()
+	resp := function.RunResponse{Result: function.NewResultData(types.StringUnknown())}
+	f.Run(context.Background(), function.RunRequest{Arguments: function.NewArgumentsData(args)}, &resp)
+	if resp.Error != nil {
+		return "", resp.Error
+	}
+	return resp.Result.Value().(types.String).ValueString(), nil
+}
+
+func TestMergeValuesFunction(t *testing.T) {
+	documents := types.TupleValueMust([]attr.Type{types.StringType, types.StringType, types.StringType}, []attr.Value{
+		types.StringValue("a:\n  b: 1\n  c: [1, 2]\nd: x\n"),
+		types.StringValue(""),
+		types.StringValue("a:\n  c: [3]\n  e: true\nd: null\n"),
+	})
+	out, err := runStringFunction(t, NewMergeValuesFunction(), documents)
+	assert.Nil(t, err)
+	assert.Equal(t, "a:\n  b: 1\n  c:\n  - 3\n  e: true\nd: null\n", out)
+
+	invalid := types.TupleValueMust([]attr.Type{types.StringType}, []attr.Value{types.StringValue("a: [")})
+	_, err = runStringFunction(t, NewMergeValuesFunction(), invalid)
+	assert.NotNil(t, err)
+}
+
+func TestAccFunctionMergeValues(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{{
+			Config: `
+				output "values" {
+					value = provider::helm::merge_values("a:\n  b: 1\n", "a:\n  c: 2\n")
+				}
+			`,
+			Check: resource.TestCheckOutput("values", "a:\n  b: 1\n  c: 2\n"),
+		}},
+	})
+}
@@ -0,0 +1,78 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"sigs.k8s.io/yaml"
+)
+
+var _ function.Function = &SetPathFunction{}
+
+func NewSetPathFunction() function.Function {
+	return &SetPathFunction{}
+}
+
+// SetPathFunction sets a value of a values document the way Helm sets the values passed with --set
+type SetPathFunction struct{}
+
+func (f *SetPathFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "set_path"
+}
+
+func (f *SetPathFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Set a value of a values document",
+		Description: "Sets the value at a path of a YAML values document, the way Helm sets the values passed with --set and like the set block of helm_release. Returns the values as YAML.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "document",
+				Description: "Values document in YAML format.",
+			},
+			function.StringParameter{
+				Name:        "path",
+				Description: "Path of the value, e.g. `a.b` or `list[0].name`, with the escaping of --set.",
+			},
+			function.StringParameter{
+				Name:        "value",
+				Description: "Value to set, parsed like a --set value: numbers and booleans are converted, commas separate list elements unless escaped.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *SetPathFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var document, path, value string
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &document, &path, &value))
+	if resp.Error != nil {
+		return
+	}
+
+	values, err := parseValuesDocument(document)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Unable to parse values document: %s", err))
+		return
+	}
+	diags := applySetValue(values, setResourceModel{
+		Name:  types.StringValue(path),
+		Value: types.StringValue(value),
+		Type:  types.StringValue("auto"),
+	})
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+		return
+	}
+
+	out, err := yaml.Marshal(values)
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("Unable to marshal values: %s", err))
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, string(out)))
+}
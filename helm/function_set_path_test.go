@@ -0,0 +1,52 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetPathFunction(t *testing.T) {
+	cases := []struct {
+		document string
+		path     string
+		value    string
+		expected string
+	}{
+		{"", "a.b", "1", "a:\n  b: 1\n"},
+		{"a:\n  c: x\n", "a.b", "true", "a:\n  b: true\n  c: x\n"},
+		{"", "list[1]", "x", "list:\n- null\n- x\n"},
+		{"", `annotations.kubernetes\.io/name`, "app", "annotations:\n  kubernetes.io/name: app\n"},
+	}
+	for _, c := range cases {
+		out, err := runStringFunction(t, NewSetPathFunction(), types.StringValue(c.document), types.StringValue(c.path), types.StringValue(c.value))
+		assert.Nil(t, err)
+		assert.Equal(t, c.expected, out)
+	}
+
+	_, err := runStringFunction(t, NewSetPathFunction(), types.StringValue(""), types.StringValue("a[x]"), types.StringValue("1"))
+	assert.NotNil(t, err)
+}
+
+func TestAccFunctionSetPath(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{{
+			Config: `
+				output "values" {
+					value = provider::helm::set_path("a:\n  c: 2\n", "a.b", "1")
+				}
+			`,
+			Check: resource.TestCheckOutput("values", "a:\n  b: 1\n  c: 2\n"),
+		}},
+	})
+}
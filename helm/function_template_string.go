@@ -0,0 +1,117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	pathpkg "path"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+)
+
+// templateStringReleaseName is the name of the release templates are rendered for, the
+// default release name of helm template
+const templateStringReleaseName = "release-name"
+
+var _ function.Function = &TemplateStringFunction{}
+
+func NewTemplateStringFunction() function.Function {
+	return &TemplateStringFunction{}
+}
+
+// TemplateStringFunction renders a single template of a local chart
+type TemplateStringFunction struct{}
+
+func (f *TemplateStringFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "template_string"
+}
+
+func (f *TemplateStringFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Render a template of a chart",
+		Description: "Renders a single template of a local chart with the given values, without accessing the cluster, and returns the rendered text. The template is rendered for a release named release-name in the default namespace, like helm template.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "chart_path",
+				Description: "Path to a local chart directory or archive.",
+			},
+			function.StringParameter{
+				Name:        "values",
+				Description: "Values document in YAML format, merged over the values of the chart.",
+			},
+			function.StringParameter{
+				Name:        "template_name",
+				Description: "Name of the template, e.g. `templates/configmap.yaml`, or `charts/<subchart>/templates/configmap.yaml` for the template of a subchart.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *TemplateStringFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var chartPath, values, templateName string
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &chartPath, &values, &templateName))
+	if resp.Error != nil {
+		return
+	}
+
+	c, err := loader.Load(chartPath)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Unable to load chart %s: %s", chartPath, err))
+		return
+	}
+	vals, err := parseValuesDocument(values)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("Unable to parse values document: %s", err))
+		return
+	}
+
+	rendered, err := renderTemplate(c, vals, templateName)
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, rendered))
+}
+
+// renderTemplate renders the templates of c and returns the template named name, relative
+// to the chart directory
+func renderTemplate(c *chart.Chart, values map[string]interface{}, name string) (string, error) {
+	if err := chartutil.ProcessDependenciesWithMerge(c, values); err != nil {
+		return "", fmt.Errorf("unable to process the dependencies of chart %s: %w", c.Name(), err)
+	}
+	options := chartutil.ReleaseOptions{
+		Name:      templateStringReleaseName,
+		Namespace: "default",
+		Revision:  1,
+		IsInstall: true,
+	}
+	renderValues, err := chartutil.ToRenderValues(c, values, options, nil)
+	if err != nil {
+		return "", err
+	}
+	rendered, err := engine.Render(c, renderValues)
+	if err != nil {
+		return "", fmt.Errorf("unable to render chart %s: %w", c.Name(), err)
+	}
+
+	key := pathpkg.Join(c.Name(), strings.TrimPrefix(name, "./"))
+	if out, ok := rendered[key]; ok {
+		return out, nil
+	}
+
+	var available []string
+	for k := range rendered {
+		available = append(available, strings.TrimPrefix(k, c.Name()+"/"))
+	}
+	sort.Strings(available)
+	return "", fmt.Errorf("template %q not found in chart %s, available templates: %s", name, c.Name(), strings.Join(available, ", "))
+}
@@ -0,0 +1,57 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateStringFunction(t *testing.T) {
+	out, err := runStringFunction(t, NewTemplateStringFunction(),
+		types.StringValue("testdata/charts/test-chart"),
+		types.StringValue("service:\n  port: 8080\n"),
+		types.StringValue("templates/service.yaml"),
+	)
+	assert.Nil(t, err)
+	assert.Contains(t, out, "name: release-name-test-chart")
+	assert.Contains(t, out, "- port: 8080")
+
+	_, err = runStringFunction(t, NewTemplateStringFunction(),
+		types.StringValue("testdata/charts/test-chart"),
+		types.StringValue(""),
+		types.StringValue("templates/missing.yaml"),
+	)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "templates/service.yaml")
+	}
+
+	_, err = runStringFunction(t, NewTemplateStringFunction(),
+		types.StringValue("testdata/charts/missing"),
+		types.StringValue(""),
+		types.StringValue("templates/service.yaml"),
+	)
+	assert.NotNil(t, err)
+}
+
+func TestAccFunctionTemplateString(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{{
+			Config: `
+				output "port" {
+					value = yamldecode(provider::helm::template_string("./testdata/charts/test-chart", "service:\n  port: 8080\n", "templates/service.yaml")).spec.ports[0].port
+				}
+			`,
+			Check: resource.TestCheckOutput("port", "8080"),
+		}},
+	})
+}
@@ -0,0 +1,51 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// pruneReleaseHistory deletes the oldest revisions of a release until at most max revisions
+// are left, the same way Helm does on upgrade. The last deployed revision is always kept.
+// It returns the number of deleted revisions.
+func pruneReleaseHistory(ctx context.Context, cfg *action.Configuration, name string, max int) (int, error) {
+	if max <= 0 {
+		return 0, nil
+	}
+
+	history, err := cfg.Releases.History(name)
+	if err != nil {
+		return 0, err
+	}
+	excess := len(history) - max
+	if excess <= 0 {
+		return 0, nil
+	}
+	releaseutil.SortByRevision(history)
+
+	// a release without a deployed revision has nothing to protect
+	lastDeployed, _ := cfg.Releases.Deployed(name)
+
+	deleted := 0
+	for _, rel := range history {
+		if deleted == excess {
+			break
+		}
+		if lastDeployed != nil && rel.Version == lastDeployed.Version {
+			continue
+		}
+		tflog.Debug(ctx, fmt.Sprintf("Pruning revision %d of release %s", rel.Version, name))
+		if _, err := cfg.Releases.Delete(rel.Name, rel.Version); err != nil {
+			return deleted, fmt.Errorf("unable to delete revision %d: %w", rel.Version, err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
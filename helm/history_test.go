@@ -0,0 +1,72 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func testHistoryConfiguration(t *testing.T, statuses ...release.Status) *action.Configuration {
+	t.Helper()
+	store := storage.Init(driver.NewMemory())
+	for i, status := range statuses {
+		err := store.Create(&release.Release{
+			Name:      "test",
+			Namespace: "default",
+			Version:   i + 1,
+			Info:      &release.Info{Status: status},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &action.Configuration{Releases: store}
+}
+
+func testHistoryRevisions(t *testing.T, cfg *action.Configuration) []int {
+	t.Helper()
+	history, err := cfg.Releases.History("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	revisions := []int{}
+	for _, rel := range history {
+		revisions = append(revisions, rel.Version)
+	}
+	return revisions
+}
+
+func TestPruneReleaseHistory(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := testHistoryConfiguration(t,
+		release.StatusSuperseded, release.StatusSuperseded, release.StatusSuperseded,
+		release.StatusSuperseded, release.StatusDeployed)
+	deleted, err := pruneReleaseHistory(ctx, cfg, "test", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, deleted)
+	assert.ElementsMatch(t, []int{4, 5}, testHistoryRevisions(t, cfg))
+
+	// the last deployed revision is kept when later revisions failed
+	cfg = testHistoryConfiguration(t,
+		release.StatusSuperseded, release.StatusDeployed, release.StatusFailed, release.StatusFailed)
+	deleted, err = pruneReleaseHistory(ctx, cfg, "test", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.ElementsMatch(t, []int{2, 4}, testHistoryRevisions(t, cfg))
+
+	// no limit
+	cfg = testHistoryConfiguration(t, release.StatusSuperseded, release.StatusDeployed)
+	deleted, err = pruneReleaseHistory(ctx, cfg, "test", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, deleted)
+	assert.ElementsMatch(t, []int{1, 2}, testHistoryRevisions(t, cfg))
+}
@@ -0,0 +1,169 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// disableableHookEvents are the hook events of the actions of helm_release which can be disabled
+// with disable_hooks_for
+var disableableHookEvents = []string{
+	string(release.HookPreInstall),
+	string(release.HookPostInstall),
+	string(release.HookPreUpgrade),
+	string(release.HookPostUpgrade),
+	string(release.HookPreDelete),
+	string(release.HookPostDelete),
+}
+
+// hookEventsKubeClient skips the hooks of the disabled events of an action. Helm runs the hooks
+// of an event by building, creating and watching the objects of each hook with the Kubernetes
+// client: the objects annotated with the disabled event are neither deleted, created nor
+// watched. The client runs the hooks of the pre event of the action until the objects of the
+// release are created, updated or deleted, and the hooks of the post event afterwards.
+type hookEventsKubeClient struct {
+	forwardingKubeClient
+	ctx       context.Context
+	pre, post release.HookEvent
+	disabled  map[release.HookEvent]bool
+	released  bool
+}
+
+// disableHookEvents wraps the Kubernetes client of actionConfig to skip the hooks of the events
+// of disable_hooks_for among pre and post, the hook events of the action run with actionConfig
+func disableHookEvents(ctx context.Context, actionConfig *action.Configuration, model *HelmReleaseModel, pre, post release.HookEvent) {
+	disabled := map[release.HookEvent]bool{}
+	for _, e := range expandStringSlice(model.DisableHooksFor.Elements()) {
+		if e := release.HookEvent(e); e == pre || e == post {
+			disabled[e] = true
+		}
+	}
+	if len(disabled) == 0 {
+		return
+	}
+	actionConfig.KubeClient = &hookEventsKubeClient{
+		forwardingKubeClient: forwardingKubeClient{Interface: actionConfig.KubeClient},
+		ctx:                  ctx,
+		pre:                  pre,
+		post:                 post,
+		disabled:             disabled,
+	}
+}
+
+// event returns the hook event the hooks run by the action are currently run for
+func (c *hookEventsKubeClient) event() release.HookEvent {
+	if c.released {
+		return c.post
+	}
+	return c.pre
+}
+
+// hookEvents returns the events of the helm.sh/hook annotation of an object, nil for the
+// objects of the release which are not hooks
+func hookEvents(obj interface{}) []release.HookEvent {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil
+	}
+	annotation, ok := accessor.GetAnnotations()[release.HookAnnotation]
+	if !ok {
+		return nil
+	}
+	var events []release.HookEvent
+	for _, e := range strings.Split(annotation, ",") {
+		events = append(events, release.HookEvent(strings.TrimSpace(e)))
+	}
+	return events
+}
+
+// skipped reports whether resources are the objects of a hook of the current event which is
+// disabled
+func (c *hookEventsKubeClient) skipped(resources kube.ResourceList) bool {
+	event := c.event()
+	if len(resources) == 0 || !c.disabled[event] {
+		return false
+	}
+	for _, info := range resources {
+		found := false
+		for _, e := range hookEvents(info.Object) {
+			found = found || e == event
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// release records that the objects of the release are created, updated or deleted when
+// resources are not hooks. The CRDs of the crds directory of the chart and the namespace of
+// create_namespace, which installs create before the pre-install hooks, are ignored.
+func (c *hookEventsKubeClient) release(resources kube.ResourceList, create bool) {
+	for _, info := range resources {
+		if hookEvents(info.Object) != nil {
+			continue
+		}
+		if create && info.Mapping != nil {
+			if kind := info.Mapping.GroupVersionKind.Kind; kind == "CustomResourceDefinition" || kind == "Namespace" {
+				continue
+			}
+		}
+		c.released = true
+		return
+	}
+}
+
+func (c *hookEventsKubeClient) Create(resources kube.ResourceList) (*kube.Result, error) {
+	if c.skipped(resources) {
+		tflog.Info(c.ctx, fmt.Sprintf("Skipping %s hook %s", c.event(), resources[0].ObjectName()))
+		return &kube.Result{}, nil
+	}
+	c.release(resources, true)
+	return c.Interface.Create(resources)
+}
+
+func (c *hookEventsKubeClient) Update(original, target kube.ResourceList, force bool) (*kube.Result, error) {
+	c.released = true
+	return c.Interface.Update(original, target, force)
+}
+
+func (c *hookEventsKubeClient) Delete(resources kube.ResourceList) (*kube.Result, []error) {
+	if c.skipped(resources) {
+		return &kube.Result{}, nil
+	}
+	c.release(resources, false)
+	return c.Interface.Delete(resources)
+}
+
+// DeleteWithPropagationPolicy is used by uninstalls to delete the objects of the release
+func (c *hookEventsKubeClient) DeleteWithPropagationPolicy(resources kube.ResourceList, policy metav1.DeletionPropagation) (*kube.Result, []error) {
+	c.release(resources, false)
+	return c.forwardingKubeClient.DeleteWithPropagationPolicy(resources, policy)
+}
+
+func (c *hookEventsKubeClient) WatchUntilReady(resources kube.ResourceList, timeout time.Duration) error {
+	if c.skipped(resources) {
+		return nil
+	}
+	return c.Interface.WatchUntilReady(resources, timeout)
+}
+
+// WaitForDelete is used to delete hooks with the before-hook-creation policy
+func (c *hookEventsKubeClient) WaitForDelete(resources kube.ResourceList, timeout time.Duration) error {
+	if c.skipped(resources) {
+		return nil
+	}
+	return c.forwardingKubeClient.WaitForDelete(resources, timeout)
+}
@@ -0,0 +1,125 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// recordingKubeClient records the names of the objects it creates, watches and deletes
+type recordingKubeClient struct {
+	kubefake.PrintingKubeClient
+	calls []string
+}
+
+func (c *recordingKubeClient) record(call string, resources kube.ResourceList) {
+	for _, info := range resources {
+		c.calls = append(c.calls, call+" "+info.Name)
+	}
+}
+
+func (c *recordingKubeClient) Create(resources kube.ResourceList) (*kube.Result, error) {
+	c.record("create", resources)
+	return &kube.Result{Created: resources}, nil
+}
+
+func (c *recordingKubeClient) WatchUntilReady(resources kube.ResourceList, timeout time.Duration) error {
+	c.record("watch", resources)
+	return nil
+}
+
+func (c *recordingKubeClient) Delete(resources kube.ResourceList) (*kube.Result, []error) {
+	c.record("delete", resources)
+	return &kube.Result{Deleted: resources}, nil
+}
+
+func testHookObject(kind, name, hook string) *resource.Info {
+	obj := &unstructured.Unstructured{}
+	obj.SetKind(kind)
+	obj.SetName(name)
+	if hook != "" {
+		obj.SetAnnotations(map[string]string{release.HookAnnotation: hook})
+	}
+	return &resource.Info{
+		Name:    name,
+		Object:  obj,
+		Mapping: &meta.RESTMapping{GroupVersionKind: schema.GroupVersionKind{Kind: kind}},
+	}
+}
+
+func TestDisableHookEvents(t *testing.T) {
+	ctx := context.Background()
+	model := func(events ...string) *HelmReleaseModel {
+		list, _ := types.ListValueFrom(ctx, types.StringType, events)
+		return &HelmReleaseModel{DisableHooksFor: list}
+	}
+	crd := kube.ResourceList{testHookObject("CustomResourceDefinition", "crd", "")}
+	manifest := kube.ResourceList{testHookObject("ConfigMap", "config", "")}
+	preHook := kube.ResourceList{testHookObject("Job", "pre", "pre-install")}
+	bothHook := kube.ResourceList{testHookObject("Job", "both", "pre-install, post-install")}
+	postHook := kube.ResourceList{testHookObject("Job", "post", "post-install")}
+
+	// install calls the Kubernetes client like Helm does
+	install := func(client kube.Interface) {
+		_, _ = client.Create(crd)
+		for _, hook := range []kube.ResourceList{preHook, bothHook, manifest, bothHook, postHook} {
+			_, _ = client.Create(hook)
+			if hook[0].Name != "config" {
+				_ = client.WatchUntilReady(hook, time.Minute)
+				_, _ = client.Delete(hook)
+			}
+		}
+	}
+
+	t.Run("post-install", func(t *testing.T) {
+		recorder := &recordingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}}
+		actionConfig := &action.Configuration{KubeClient: recorder}
+		disableHookEvents(ctx, actionConfig, model("post-install", "post-delete"), release.HookPreInstall, release.HookPostInstall)
+		install(actionConfig.KubeClient)
+		assert.Equal(t, []string{
+			"create crd",
+			"create pre", "watch pre", "delete pre",
+			"create both", "watch both", "delete both",
+			"create config",
+		}, recorder.calls)
+	})
+
+	t.Run("pre-install", func(t *testing.T) {
+		recorder := &recordingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}}
+		actionConfig := &action.Configuration{KubeClient: recorder}
+		disableHookEvents(ctx, actionConfig, model("pre-install"), release.HookPreInstall, release.HookPostInstall)
+		install(actionConfig.KubeClient)
+		assert.Equal(t, []string{
+			"create crd",
+			"create config",
+			"create both", "watch both", "delete both",
+			"create post", "watch post", "delete post",
+		}, recorder.calls)
+	})
+
+	t.Run("other action", func(t *testing.T) {
+		recorder := &recordingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}}
+		actionConfig := &action.Configuration{KubeClient: recorder}
+		disableHookEvents(ctx, actionConfig, model("post-delete"), release.HookPreInstall, release.HookPostInstall)
+		require.Same(t, recorder, actionConfig.KubeClient)
+
+		disableHookEvents(ctx, actionConfig, model(), release.HookPreInstall, release.HookPostInstall)
+		require.Same(t, recorder, actionConfig.KubeClient)
+	})
+}
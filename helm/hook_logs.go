@@ -0,0 +1,147 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
+)
+
+// hookLogsKubeClient captures the logs of the pods of the Jobs of the hooks run by an action.
+// Helm watches the objects of each hook until its Jobs complete or fail, the logs are read
+// then, before the hook is deleted by its delete policy.
+type hookLogsKubeClient struct {
+	forwardingKubeClient
+	ctx          context.Context
+	newClientset func() (kubernetes.Interface, error)
+	maxBytes     int
+	enabled      bool
+	sensitive    bool
+	// logs of the Jobs keyed by namespace/name
+	logs map[string]string
+}
+
+// captureHookLogs wraps the Kubernetes client of actionConfig to capture the logs of the hook
+// Jobs with wait_for_jobs
+func captureHookLogs(ctx context.Context, actionConfig *action.Configuration, model *HelmReleaseModel) *hookLogsKubeClient {
+	c := &hookLogsKubeClient{
+		forwardingKubeClient: forwardingKubeClient{Interface: actionConfig.KubeClient},
+		ctx:                  ctx,
+		newClientset:         func() (kubernetes.Interface, error) { return actionConfig.KubernetesClientSet() },
+		maxBytes:             int(model.HookLogsMaxBytes.ValueInt64()),
+		enabled:              model.WaitForJobs.ValueBool(),
+		sensitive:            model.HookLogsSensitive.ValueBool(),
+		logs:                 map[string]string{},
+	}
+	if c.enabled {
+		actionConfig.KubeClient = c
+	}
+	return c
+}
+
+func (c *hookLogsKubeClient) WatchUntilReady(resources kube.ResourceList, timeout time.Duration) error {
+	err := c.Interface.WatchUntilReady(resources, timeout)
+	for _, info := range resources {
+		if info.Mapping != nil && info.Mapping.GroupVersionKind.Kind == "Job" {
+			c.capture(info)
+		}
+	}
+	return err
+}
+
+// capture records the logs of the containers of the pods of the Job info, oldest pod first.
+// The logs of the Jobs which cannot be read are left out with a warning.
+func (c *hookLogsKubeClient) capture(info *resource.Info) {
+	key := info.Namespace + "/" + info.Name
+	logs, err := c.jobLogs(info.Namespace, info.Name)
+	if err != nil {
+		tflog.Warn(c.ctx, fmt.Sprintf("Unable to read the logs of hook Job %s: %s", key, err))
+		return
+	}
+	c.logs[key] = truncateHookLogs(logs, c.maxBytes)
+}
+
+func (c *hookLogsKubeClient) jobLogs(namespace, name string) (string, error) {
+	clientset, err := c.newClientset()
+	if err != nil {
+		return "", err
+	}
+	pods, err := clientset.CoreV1().Pods(namespace).List(c.ctx, metav1.ListOptions{LabelSelector: "job-name=" + name})
+	if err != nil {
+		return "", err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		a, b := pods.Items[i], pods.Items[j]
+		if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+			return a.CreationTimestamp.Before(&b.CreationTimestamp)
+		}
+		return a.Name < b.Name
+	})
+
+	var b strings.Builder
+	for _, pod := range pods.Items {
+		containers := append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+		for _, container := range containers {
+			stream, err := clientset.CoreV1().Pods(namespace).GetLogs(pod.Name, &v1.PodLogOptions{Container: container.Name}).Stream(c.ctx)
+			if err != nil {
+				return "", err
+			}
+			logs, err := io.ReadAll(stream)
+			stream.Close()
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&b, "==> %s/%s <==\n%s", pod.Name, container.Name, logs)
+			if len(logs) > 0 && logs[len(logs)-1] != '\n' {
+				b.WriteString("\n")
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// truncateHookLogs keeps the last maxBytes bytes of logs, the end of the output of a failed
+// migration being the part that explains the failure
+func truncateHookLogs(logs string, maxBytes int) string {
+	if len(logs) <= maxBytes {
+		return logs
+	}
+	start := len(logs) - maxBytes
+	for start < len(logs) && !utf8.RuneStart(logs[start]) {
+		start++
+	}
+	return fmt.Sprintf("[%d bytes truncated]\n%s", start, logs[start:])
+}
+
+// setHookLogs records the logs of the hook Jobs in the hook_logs attribute of state, or in
+// sensitive_hook_logs with hook_logs_sensitive
+func (c *hookLogsKubeClient) setHookLogs(ctx context.Context, state *HelmReleaseModel) diag.Diagnostics {
+	state.HookLogs = types.MapNull(types.StringType)
+	state.SensitiveHookLogs = types.MapNull(types.StringType)
+	if !c.enabled {
+		return nil
+	}
+	logs, diags := types.MapValueFrom(ctx, types.StringType, c.logs)
+	if c.sensitive {
+		state.SensitiveHookLogs = logs
+	} else {
+		state.HookLogs = logs
+	}
+	return diags
+}
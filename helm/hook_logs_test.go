@@ -0,0 +1,91 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHookLogs(t *testing.T) {
+	ctx := context.Background()
+	pod := func(name, job string, created time.Time) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				Labels:            map[string]string{"job-name": job},
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: v1.PodSpec{Containers: []v1.Container{{Name: "migrate"}}},
+		}
+	}
+	now := time.Now()
+	clientset := fake.NewSimpleClientset(
+		pod("migrate-retry", "migrate", now.Add(time.Minute)),
+		pod("migrate-first", "migrate", now),
+		pod("other", "other", now),
+	)
+	newClient := func(model *HelmReleaseModel, watchErr error) *hookLogsKubeClient {
+		return &hookLogsKubeClient{
+			forwardingKubeClient: forwardingKubeClient{Interface: &kubefake.FailingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}, WatchUntilReadyError: watchErr}},
+			ctx:                  ctx,
+			newClientset:         func() (kubernetes.Interface, error) { return clientset, nil },
+			maxBytes:             int(model.HookLogsMaxBytes.ValueInt64()),
+			enabled:              model.WaitForJobs.ValueBool(),
+			sensitive:            model.HookLogsSensitive.ValueBool(),
+			logs:                 map[string]string{},
+		}
+	}
+	job := testHookObject("Job", "migrate", "pre-upgrade")
+	job.Namespace = "default"
+	hook := kube.ResourceList{job, testHookObject("ConfigMap", "config", "pre-upgrade")}
+
+	// the logs are captured when the hook fails, oldest pod first
+	model := &HelmReleaseModel{WaitForJobs: types.BoolValue(true), HookLogsSensitive: types.BoolValue(false), HookLogsMaxBytes: types.Int64Value(1024)}
+	c := newClient(model, assert.AnError)
+	assert.ErrorIs(t, c.WatchUntilReady(hook, time.Minute), assert.AnError)
+	require.False(t, c.setHookLogs(ctx, model).HasError())
+	assert.True(t, model.SensitiveHookLogs.IsNull())
+	logs := model.HookLogs.Elements()
+	require.Len(t, logs, 1)
+	assert.Equal(t, "==> migrate-first/migrate <==\nfake logs\n==> migrate-retry/migrate <==\nfake logs\n", logs["default/migrate"].(types.String).ValueString())
+
+	// the logs are recorded in sensitive_hook_logs with hook_logs_sensitive
+	model.HookLogsSensitive = types.BoolValue(true)
+	c = newClient(model, nil)
+	require.NoError(t, c.WatchUntilReady(hook, time.Minute))
+	require.False(t, c.setHookLogs(ctx, model).HasError())
+	assert.True(t, model.HookLogs.IsNull())
+	assert.Len(t, model.SensitiveHookLogs.Elements(), 1)
+
+	// the logs are not captured without wait_for_jobs
+	model.WaitForJobs = types.BoolValue(false)
+	require.False(t, newClient(model, nil).setHookLogs(ctx, model).HasError())
+	assert.True(t, model.HookLogs.IsNull())
+	assert.True(t, model.SensitiveHookLogs.IsNull())
+}
+
+func TestTruncateHookLogs(t *testing.T) {
+	assert.Equal(t, "short", truncateHookLogs("short", 10))
+
+	logs := strings.Repeat("a", 20) + "migration failed"
+	assert.Equal(t, "[20 bytes truncated]\nmigration failed", truncateHookLogs(logs, 16))
+
+	// runes are not split
+	assert.Equal(t, "[2 bytes truncated]\nb", truncateHookLogs("éb", 2))
+}
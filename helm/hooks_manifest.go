@@ -0,0 +1,76 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// hookManifest is an element of the hooks_manifest attribute of helm_release
+type hookManifest struct {
+	Name           string          `json:"name"`
+	Kind           string          `json:"kind"`
+	Path           string          `json:"path"`
+	Events         []string        `json:"events"`
+	Weight         int             `json:"weight"`
+	DeletePolicies []string        `json:"delete_policies"`
+	Manifest       json.RawMessage `json:"manifest"`
+}
+
+// hooksManifest converts the hooks of a release to the JSON of the hooks_manifest attribute,
+// in the order Helm runs them, by weight then by name. As in the manifest, the data of Secrets
+// is hashed and the set_sensitive values are redacted.
+func hooksManifest(hooks []*release.Hook, sensitiveValues map[string]string) (types.String, error) {
+	sorted := make([]*release.Hook, len(hooks))
+	copy(sorted, hooks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Weight == sorted[j].Weight {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].Weight < sorted[j].Weight
+	})
+
+	manifests := make([]hookManifest, 0, len(sorted))
+	for _, h := range sorted {
+		hm := hookManifest{
+			Name:           h.Name,
+			Kind:           h.Kind,
+			Path:           h.Path,
+			Events:         make([]string, 0, len(h.Events)),
+			Weight:         h.Weight,
+			DeletePolicies: make([]string, 0, len(h.DeletePolicies)),
+		}
+		for _, e := range h.Events {
+			hm.Events = append(hm.Events, e.String())
+		}
+		for _, p := range h.DeletePolicies {
+			hm.DeletePolicies = append(hm.DeletePolicies, string(p))
+		}
+
+		// convertYAMLManifestToJSON keys the documents of a manifest, a hook has a single one
+		jsonManifest, err := convertYAMLManifestToJSON(h.Manifest)
+		if err != nil {
+			return types.StringNull(), fmt.Errorf("hook %s: %w", h.Name, err)
+		}
+		docs := map[string]json.RawMessage{}
+		if err := json.Unmarshal([]byte(jsonManifest), &docs); err != nil {
+			return types.StringNull(), fmt.Errorf("hook %s: %w", h.Name, err)
+		}
+		for _, doc := range docs {
+			hm.Manifest = doc
+		}
+		manifests = append(manifests, hm)
+	}
+
+	b, err := json.Marshal(manifests)
+	if err != nil {
+		return types.StringNull(), err
+	}
+	return types.StringValue(redactSensitiveValues(string(b), sensitiveValues)), nil
+}
@@ -0,0 +1,60 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestHooksManifest(t *testing.T) {
+	hooks := []*release.Hook{
+		{
+			Name:           "migrate",
+			Kind:           "Job",
+			Path:           "chart/templates/migrate.yaml",
+			Manifest:       "apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: migrate\n",
+			Events:         []release.HookEvent{release.HookPreInstall, release.HookPreUpgrade},
+			Weight:         5,
+			DeletePolicies: []release.HookDeletePolicy{release.HookBeforeHookCreation},
+		},
+		{
+			Name:     "credentials",
+			Kind:     "Secret",
+			Path:     "chart/templates/credentials.yaml",
+			Manifest: "apiVersion: v1\nkind: Secret\nmetadata:\n  name: credentials\ndata:\n  password: czNjcjN0\n",
+			Events:   []release.HookEvent{release.HookPreInstall},
+			Weight:   -1,
+		},
+	}
+
+	value, err := hooksManifest(hooks, nil)
+	assert.NoError(t, err)
+
+	var manifests []map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(value.ValueString()), &manifests))
+	assert.Len(t, manifests, 2)
+
+	assert.Equal(t, "credentials", manifests[0]["name"])
+	assert.Equal(t, float64(-1), manifests[0]["weight"])
+	assert.Equal(t, []interface{}{}, manifests[0]["delete_policies"])
+	assert.NotContains(t, value.ValueString(), "czNjcjN0", "the data of Secrets is hashed")
+
+	assert.Equal(t, "migrate", manifests[1]["name"])
+	assert.Equal(t, "Job", manifests[1]["kind"])
+	assert.Equal(t, []interface{}{"pre-install", "pre-upgrade"}, manifests[1]["events"])
+	assert.Equal(t, []interface{}{"before-hook-creation"}, manifests[1]["delete_policies"])
+	assert.Equal(t, map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata":   map[string]interface{}{"name": "migrate"},
+	}, manifests[1]["manifest"])
+
+	empty, err := hooksManifest(nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", empty.ValueString())
+}
@@ -0,0 +1,124 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+// ignoredValuePaths returns the dot separated value paths of ignore_value_changes
+func ignoredValuePaths(ctx context.Context, model *HelmReleaseModel) ([]string, diag.Diagnostics) {
+	var paths []string
+	if model.IgnoreValueChanges.IsNull() || model.IgnoreValueChanges.IsUnknown() {
+		return paths, nil
+	}
+	diags := model.IgnoreValueChanges.ElementsAs(ctx, &paths, false)
+	return paths, diags
+}
+
+// lookupValuePath returns the value at a dot separated path of values
+func lookupValuePath(values map[string]interface{}, path string) (interface{}, bool) {
+	keys := strings.Split(path, ".")
+	m := values
+	for _, key := range keys[:len(keys)-1] {
+		v, ok := m[key].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		m = v
+	}
+	v, ok := m[keys[len(keys)-1]]
+	return v, ok
+}
+
+// setValuePath sets the value at a dot separated path of values, creating the missing maps
+func setValuePath(values map[string]interface{}, path string, value interface{}) {
+	keys := strings.Split(path, ".")
+	m := values
+	for _, key := range keys[:len(keys)-1] {
+		v, ok := m[key].(map[string]interface{})
+		if !ok {
+			v = map[string]interface{}{}
+			m[key] = v
+		}
+		m = v
+	}
+	m[keys[len(keys)-1]] = value
+}
+
+// preserveIgnoredValues sets the values at the ignored paths to the live values of the release,
+// so that the changes made to them outside of Terraform are not overwritten
+func preserveIgnoredValues(values, live map[string]interface{}, paths []string) {
+	for _, p := range paths {
+		if v, ok := lookupValuePath(live, p); ok {
+			setValuePath(values, p, v)
+		}
+	}
+}
+
+// deleteValuePath deletes the value at a dot separated path of values
+func deleteValuePath(values map[string]interface{}, path string) {
+	keys := strings.Split(path, ".")
+	m := values
+	for _, key := range keys[:len(keys)-1] {
+		v, ok := m[key].(map[string]interface{})
+		if !ok {
+			return
+		}
+		m = v
+	}
+	delete(m, keys[len(keys)-1])
+}
+
+// preserveReleaseIgnoredValues sets the values at the ignored paths of the release to their live
+// values, see preserveIgnoredValues
+func preserveReleaseIgnoredValues(ctx context.Context, model *HelmReleaseModel, m *Meta, cfg *action.Configuration, values map[string]interface{}) diag.Diagnostics {
+	paths, diags := ignoredValuePaths(ctx, model)
+	if diags.HasError() || len(paths) == 0 {
+		return diags
+	}
+	r, err := getRelease(ctx, m, cfg, model.Name.ValueString())
+	if err != nil {
+		diags.AddError("Error reading release values", fmt.Sprintf("Unable to read the values of Helm release %s: %s", model.Name.ValueString(), err))
+		return diags
+	}
+	preserveIgnoredValues(values, r.Config, paths)
+	return diags
+}
+
+// withoutValuePaths returns a copy of values without the values at the ignored paths
+func withoutValuePaths(values map[string]interface{}, paths []string) (map[string]interface{}, error) {
+	if len(paths) == 0 {
+		return values, nil
+	}
+	// deep copy the values, the values of the release must not be modified
+	b, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	c := map[string]interface{}{}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		deleteValuePath(c, p)
+	}
+	return c, nil
+}
+
+// ignoredValuesChecksum is the checksum of values without the values at the ignored paths, so
+// that it does not change when they are changed outside of Terraform
+func ignoredValuesChecksum(values map[string]interface{}, paths []string) (string, error) {
+	v, err := withoutValuePaths(values, paths)
+	if err != nil {
+		return "", err
+	}
+	return valuesChecksum(v)
+}
@@ -0,0 +1,62 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreserveIgnoredValues(t *testing.T) {
+	values := map[string]interface{}{
+		"controller": map[string]interface{}{"replicas": 2},
+		"global":     map[string]interface{}{"buildID": "terraform"},
+		"image":      "nginx",
+	}
+	live := map[string]interface{}{
+		"controller": map[string]interface{}{
+			"replicas":       1,
+			"podAnnotations": map[string]interface{}{"checksum": "abc"},
+		},
+		"global": map[string]interface{}{"buildID": "pipeline"},
+	}
+
+	preserveIgnoredValues(values, live, []string{"controller.podAnnotations.checksum", "global.buildID", "missing.path"})
+	assert.Equal(t, map[string]interface{}{
+		"controller": map[string]interface{}{
+			"replicas":       2,
+			"podAnnotations": map[string]interface{}{"checksum": "abc"},
+		},
+		"global": map[string]interface{}{"buildID": "pipeline"},
+		"image":  "nginx",
+	}, values)
+}
+
+func TestIgnoredValuesChecksum(t *testing.T) {
+	paths := []string{"controller.podAnnotations.checksum", "buildID"}
+	a := map[string]interface{}{
+		"controller": map[string]interface{}{"podAnnotations": map[string]interface{}{"checksum": "abc"}},
+		"buildID":    1,
+	}
+	b := map[string]interface{}{
+		"controller": map[string]interface{}{"podAnnotations": map[string]interface{}{"checksum": "def"}},
+		"buildID":    2,
+	}
+
+	checksumA, err := ignoredValuesChecksum(a, paths)
+	assert.NoError(t, err)
+	checksumB, err := ignoredValuesChecksum(b, paths)
+	assert.NoError(t, err)
+	assert.Equal(t, checksumA, checksumB)
+
+	// the values are not modified
+	assert.Equal(t, 1, a["buildID"])
+
+	checksumA, err = ignoredValuesChecksum(a, nil)
+	assert.NoError(t, err)
+	checksumB, err = ignoredValuesChecksum(b, nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, checksumA, checksumB)
+}
@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"sort"
+
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"sigs.k8s.io/yaml"
+)
+
+type containerImage struct {
+	Image string `json:"image"`
+}
+
+type podSpecImages struct {
+	Containers          []containerImage `json:"containers"`
+	InitContainers      []containerImage `json:"initContainers"`
+	EphemeralContainers []containerImage `json:"ephemeralContainers"`
+}
+
+type podTemplateImages struct {
+	Spec podSpecImages `json:"spec"`
+}
+
+type podImages struct {
+	Spec podSpecImages `json:"spec"`
+}
+
+type workloadImages struct {
+	Spec struct {
+		Template podTemplateImages `json:"template"`
+	} `json:"spec"`
+}
+
+type cronJobImages struct {
+	Spec struct {
+		JobTemplate struct {
+			Spec struct {
+				Template podTemplateImages `json:"template"`
+			} `json:"spec"`
+		} `json:"jobTemplate"`
+	} `json:"spec"`
+}
+
+// manifestImages returns the sorted images of the containers of the Pods and of the pod
+// templates of the workloads of a manifest
+func manifestImages(manifest string) ([]string, error) {
+	seen := map[string]bool{}
+	for _, resource := range releaseutil.SplitManifests(manifest) {
+		meta := resourceMeta{}
+		if err := yaml.Unmarshal([]byte(resource), &meta); err != nil {
+			return nil, err
+		}
+
+		var spec podSpecImages
+		switch meta.Kind {
+		case "Pod":
+			var p podImages
+			if err := yaml.Unmarshal([]byte(resource), &p); err != nil {
+				return nil, fmt.Errorf("unable to read the images of %s %s: %w", meta.Kind, meta.Metadata.Name, err)
+			}
+			spec = p.Spec
+		case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job":
+			var w workloadImages
+			if err := yaml.Unmarshal([]byte(resource), &w); err != nil {
+				return nil, fmt.Errorf("unable to read the images of %s %s: %w", meta.Kind, meta.Metadata.Name, err)
+			}
+			spec = w.Spec.Template.Spec
+		case "CronJob":
+			var c cronJobImages
+			if err := yaml.Unmarshal([]byte(resource), &c); err != nil {
+				return nil, fmt.Errorf("unable to read the images of %s %s: %w", meta.Kind, meta.Metadata.Name, err)
+			}
+			spec = c.Spec.JobTemplate.Spec.Template.Spec
+		default:
+			continue
+		}
+
+		for _, containers := range [][]containerImage{spec.InitContainers, spec.Containers, spec.EphemeralContainers} {
+			for _, c := range containers {
+				if c.Image != "" {
+					seen[c.Image] = true
+				}
+			}
+		}
+	}
+
+	images := make([]string, 0, len(seen))
+	for image := range seen {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	return images, nil
+}
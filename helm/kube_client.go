@@ -0,0 +1,37 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"time"
+
+	"helm.sh/helm/v3/pkg/kube"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// forwardingKubeClient is embedded by the wrappers of the Kubernetes client of the actions in
+// place of kube.Interface. Helm checks the client for optional interfaces that embedding
+// kube.Interface alone hides: without them, upgrades do not wait for the hooks with the
+// before-hook-creation policy to be deleted, and uninstalls neither wait for the objects of the
+// release to be deleted nor delete them with the deletion propagation of the release.
+type forwardingKubeClient struct {
+	kube.Interface
+}
+
+// WaitForDelete waits for resources to be deleted when the wrapped client can
+func (c forwardingKubeClient) WaitForDelete(resources kube.ResourceList, timeout time.Duration) error {
+	if ext, ok := c.Interface.(kube.InterfaceExt); ok {
+		return ext.WaitForDelete(resources, timeout)
+	}
+	return nil
+}
+
+// DeleteWithPropagationPolicy deletes resources with policy when the wrapped client can, as
+// Helm does otherwise
+func (c forwardingKubeClient) DeleteWithPropagationPolicy(resources kube.ResourceList, policy metav1.DeletionPropagation) (*kube.Result, []error) {
+	if kubeClient, ok := c.Interface.(kube.InterfaceDeletionPropagation); ok {
+		return kubeClient.DeleteWithPropagationPolicy(resources, policy)
+	}
+	return c.Interface.Delete(resources)
+}
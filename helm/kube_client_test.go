@@ -0,0 +1,46 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// deleteRecordingKubeClient records the deletions and the waits for deletions
+type deleteRecordingKubeClient struct {
+	kubefake.PrintingKubeClient
+	calls []string
+}
+
+func (c *deleteRecordingKubeClient) WaitForDelete(resources kube.ResourceList, timeout time.Duration) error {
+	c.calls = append(c.calls, "wait_for_delete")
+	return nil
+}
+
+func (c *deleteRecordingKubeClient) DeleteWithPropagationPolicy(resources kube.ResourceList, policy metav1.DeletionPropagation) (*kube.Result, []error) {
+	c.calls = append(c.calls, "delete "+string(policy))
+	return &kube.Result{Deleted: resources}, nil
+}
+
+func TestForwardingKubeClient(t *testing.T) {
+	recorder := &deleteRecordingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}}
+	var client kube.Interface = forwardingKubeClient{Interface: recorder}
+
+	ext, ok := client.(kube.InterfaceExt)
+	require.True(t, ok)
+	require.NoError(t, ext.WaitForDelete(nil, time.Second))
+	propagation, ok := client.(kube.InterfaceDeletionPropagation)
+	require.True(t, ok)
+	_, errs := propagation.DeleteWithPropagationPolicy(nil, metav1.DeletePropagationForeground)
+	assert.Empty(t, errs)
+	assert.Equal(t, []string{"wait_for_delete", "delete Foreground"}, recorder.calls)
+}
@@ -0,0 +1,61 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+const (
+	enforceKubeVersionWarn   = "warn"
+	enforceKubeVersionError  = "error"
+	enforceKubeVersionIgnore = "ignore"
+)
+
+var enforceKubeVersionModes = []string{enforceKubeVersionWarn, enforceKubeVersionError, enforceKubeVersionIgnore}
+
+// checkKubeVersion compares the version of the cluster with the kubeVersion constraint of the
+// chart when planning, as Helm only refuses to install or upgrade incompatible charts on apply.
+// The check is skipped when the version of the cluster cannot be discovered.
+func checkKubeVersion(ctx context.Context, cfg *action.Configuration, c *chart.Chart, mode string) diag.Diagnostics {
+	if mode == enforceKubeVersionIgnore || c.Metadata.KubeVersion == "" {
+		return nil
+	}
+
+	dc, err := cfg.RESTClientGetter.ToDiscoveryClient()
+	if err != nil {
+		tflog.Debug(ctx, fmt.Sprintf("Unable to check the kubeVersion of chart %s: %s", c.Name(), err))
+		return nil
+	}
+	sv, err := dc.ServerVersion()
+	if err != nil {
+		tflog.Debug(ctx, fmt.Sprintf("Unable to check the kubeVersion of chart %s: %s", c.Name(), err))
+		return nil
+	}
+	return kubeVersionDiagnostics(c, sv.GitVersion, mode)
+}
+
+func kubeVersionDiagnostics(c *chart.Chart, kubeVersion, mode string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if chartutil.IsCompatibleRange(c.Metadata.KubeVersion, kubeVersion) {
+		return diags
+	}
+
+	summary := "Chart is incompatible with the Kubernetes version of the cluster"
+	detail := fmt.Sprintf("Chart %s-%s requires kubeVersion %q, but the cluster runs Kubernetes %s. Helm will refuse to install or upgrade the release.",
+		c.Name(), c.Metadata.Version, c.Metadata.KubeVersion, kubeVersion)
+	if mode == enforceKubeVersionError {
+		diags.AddError(summary, detail)
+	} else {
+		diags.AddWarning(summary, detail)
+	}
+	return diags
+}
@@ -0,0 +1,24 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestKubeVersionDiagnostics(t *testing.T) {
+	c := &chart.Chart{Metadata: &chart.Metadata{Name: "kube-version", Version: "1.2.3", KubeVersion: ">=1.22.0-0"}}
+
+	assert.False(t, kubeVersionDiagnostics(c, "v1.29.2", enforceKubeVersionError).HasError())
+	assert.Len(t, kubeVersionDiagnostics(c, "v1.29.2", enforceKubeVersionWarn), 0)
+
+	warn := kubeVersionDiagnostics(c, "v1.21.14-eks-1234", enforceKubeVersionWarn)
+	assert.False(t, warn.HasError())
+	assert.Equal(t, 1, warn.WarningsCount())
+
+	assert.True(t, kubeVersionDiagnostics(c, "v1.21.14", enforceKubeVersionError).HasError())
+}
@@ -6,9 +6,13 @@ package helm
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -26,28 +30,87 @@ import (
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
-// Struct holding k8s client config, burst limit for api requests, and mutex for sync
+// Struct holding k8s client config, rate limits for api requests, and mutex for sync
 type KubeConfig struct {
 	ClientConfig clientcmd.ClientConfig
 	Burst        int
+	QPS          float32
+	// Discovery clients shared across resources, nil to discover the API resources every time
+	DiscoveryCache *discoveryCache
+	// Context the config was created for, the key of the discovery client in DiscoveryCache
+	KubeContext string
+	// Token replacing the credentials of ClientConfig, see deploy_as_service_account
+	BearerToken string
+	// Requests a new BearerToken when it expires, nil to refresh the credentials of ClientConfig
+	RefreshToken tokenRefresher
+	// Counter of the requests of read-only clients, nil when the clients may modify the cluster
+	ReadOnlyRequests *atomic.Int64
+	// Timeouts and keepalive interval of the connections, zero for the client-go defaults
+	RequestTimeout time.Duration
+	DialTimeout    time.Duration
+	TCPKeepAlive   time.Duration
 	sync.Mutex
 }
 
 // Converting KubeConfig to a REST config, which will be used to create k8s clients
 func (k *KubeConfig) ToRESTConfig() (*rest.Config, error) {
 	config, err := k.ToRawKubeConfigLoader().ClientConfig()
-	return config, err
+	if err != nil {
+		return nil, err
+	}
+	if k.BearerToken != "" {
+		config = rest.AnonymousClientConfig(config)
+		config.BearerToken = k.BearerToken
+	}
+	// zero values keep the client-go defaults
+	if k.Burst > 0 {
+		config.Burst = k.Burst
+	}
+	if k.QPS > 0 {
+		config.QPS = k.QPS
+	}
+	if k.RequestTimeout > 0 {
+		config.Timeout = k.RequestTimeout
+	}
+	if k.DialTimeout > 0 || k.TCPKeepAlive > 0 {
+		dialer := &net.Dialer{Timeout: defaultKubeDialTimeout, KeepAlive: defaultKubeDialTimeout}
+		if k.DialTimeout > 0 {
+			dialer.Timeout = k.DialTimeout
+		}
+		if k.TCPKeepAlive > 0 {
+			dialer.KeepAlive = k.TCPKeepAlive
+		}
+		config.Dial = dialer.DialContext
+	}
+	if refresh := k.credentialRefresher(config); refresh != nil {
+		config.Wrap(newCredentialRefreshTransport(refresh))
+	}
+	if k.ReadOnlyRequests != nil {
+		config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &readOnlyTransport{rt: rt, requests: k.ReadOnlyRequests}
+		})
+	}
+	return config, nil
 }
 
 // Converting KubeConfig to a discovery client, which will be used to find api resources
 func (k *KubeConfig) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
-	config, err := k.ToRESTConfig()
-	if err != nil {
-		return nil, err
+	create := func() (discovery.CachedDiscoveryInterface, error) {
+		config, err := k.ToRESTConfig()
+		if err != nil {
+			return nil, err
+		}
+		client, err := discovery.NewDiscoveryClientForConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		return memory.NewMemCacheClient(client), nil
 	}
-
-	config.Burst = k.Burst
-	return memory.NewMemCacheClient(discovery.NewDiscoveryClientForConfigOrDie(config)), nil
+	// cached clients would outlive the token
+	if k.DiscoveryCache == nil || k.BearerToken != "" {
+		return create()
+	}
+	return k.DiscoveryCache.get(k.KubeContext, create)
 }
 
 // Converting KubeConfig to a REST mapper, which will be used to map REST resources to their API obj
@@ -75,8 +138,9 @@ func (k *KubeConfig) ToRawKubeConfigLoader() clientcmd.ClientConfig {
 	return k.ClientConfig
 }
 
-// Generates a k8s client config, based on providers settings and namespace, which this config will be used to interact with the k8s cluster
-func (m *Meta) NewKubeConfig(ctx context.Context, namespace string) (*KubeConfig, error) {
+// Generates a k8s client config, based on providers settings and namespace, which this config will be used to interact with the k8s cluster.
+// A non-empty kubeContext selects a context of the kubeconfig in place of config_context.
+func (m *Meta) NewKubeConfig(ctx context.Context, namespace, kubeContext string) (*KubeConfig, error) {
 	overrides := &clientcmd.ConfigOverrides{}
 	loader := &clientcmd.ClientConfigLoadingRules{}
 	configPaths := []string{}
@@ -113,6 +177,10 @@ func (m *Meta) NewKubeConfig(ctx context.Context, namespace string) (*KubeConfig
 	if v := os.Getenv("KUBE_CONFIG_PATHS"); v != "" {
 		configPaths = filepath.SplitList(v)
 	}
+	if source := credentialSource(&kubernetesConfig, configPaths); source != "" {
+		tflog.Debug(ctx, "Using the credential source of credential_precedence", map[string]interface{}{"source": source})
+		configPaths = keepCredentialSource(&kubernetesConfig, source, configPaths)
+	}
 	tflog.Debug(ctx, "Initial configPaths", map[string]interface{}{"configPaths": configPaths})
 
 	if len(configPaths) > 0 {
@@ -146,6 +214,16 @@ func (m *Meta) NewKubeConfig(ctx context.Context, namespace string) (*KubeConfig
 		}
 	}
 
+	if kubeContext != "" {
+		if len(configPaths) == 0 {
+			return nil, fmt.Errorf("kube_context %q requires config_path or config_paths to be set", kubeContext)
+		}
+		// config_context_auth_info and config_context_cluster override the context of the
+		// provider configuration, the context of the release is used as is
+		overrides.CurrentContext = kubeContext
+		overrides.Context = clientcmdapi.Context{}
+	}
+
 	// Check and assign remaining fields
 	if !kubernetesConfig.Insecure.IsNull() {
 		overrides.ClusterInfo.InsecureSkipTLSVerify = kubernetesConfig.Insecure.ValueBool()
@@ -185,6 +263,15 @@ func (m *Meta) NewKubeConfig(ctx context.Context, namespace string) (*KubeConfig
 	if !kubernetesConfig.ProxyURL.IsNull() {
 		overrides.ClusterDefaults.ProxyURL = kubernetesConfig.ProxyURL.ValueString()
 	}
+	if v := kubernetesConfig.ImpersonateUser.ValueString(); v != "" {
+		overrides.AuthInfo.Impersonate = v
+	}
+	if !kubernetesConfig.ImpersonateGroups.IsNull() && !kubernetesConfig.ImpersonateGroups.IsUnknown() {
+		overrides.AuthInfo.ImpersonateGroups = expandStringSlice(kubernetesConfig.ImpersonateGroups.Elements())
+	}
+	if v := kubernetesConfig.ImpersonateUID.ValueString(); v != "" {
+		overrides.AuthInfo.ImpersonateUID = v
+	}
 
 	if kubernetesConfig.Exec != nil {
 		execConfig := kubernetesConfig.Exec
@@ -214,13 +301,68 @@ func (m *Meta) NewKubeConfig(ctx context.Context, namespace string) (*KubeConfig
 		}
 	}
 
+	// the host and the credentials of the provider configuration are the ones of the cluster of
+	// the provider, they would redirect the release to it from the context it selects
+	if kubeContext != "" {
+		if overridesCluster(overrides) {
+			tflog.Warn(ctx, fmt.Sprintf("Ignoring the host and the credentials of the kubernetes block of the provider for kube_context %q", kubeContext))
+		}
+		overrides.ClusterInfo = clientcmdapi.Cluster{}
+		overrides.AuthInfo = clientcmdapi.AuthInfo{}
+	}
+
+	var timeouts [3]time.Duration
+	for i, v := range []types.String{kubernetesConfig.RequestTimeout, kubernetesConfig.DialTimeout, kubernetesConfig.TCPKeepAlive} {
+		d, err := parseKubeDuration(v.ValueString())
+		if err != nil {
+			return nil, err
+		}
+		timeouts[i] = d
+	}
+
 	burstLimit := int(m.Data.BurstLimit.ValueInt64())
+	qps := float32(m.Data.QPS.ValueFloat64())
 	client := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loader, overrides)
 	if client == nil {
 		return nil, fmt.Errorf("failed to initialize kubernetes config")
 	}
 	tflog.Info(ctx, "Successfully initialized kubernetes config")
-	return &KubeConfig{ClientConfig: client, Burst: burstLimit}, nil
+	return &KubeConfig{
+		ClientConfig:   client,
+		Burst:          burstLimit,
+		QPS:            qps,
+		DiscoveryCache: m.DiscoveryCache,
+		KubeContext:    kubeContext,
+		RequestTimeout: timeouts[0],
+		DialTimeout:    timeouts[1],
+		TCPKeepAlive:   timeouts[2],
+	}, nil
+}
+
+// overridesCluster reports whether overrides set the cluster or the credentials of the context
+func overridesCluster(overrides *clientcmd.ConfigOverrides) bool {
+	cluster, auth := overrides.ClusterInfo, overrides.AuthInfo
+	return cluster.Server != "" || cluster.InsecureSkipTLSVerify || cluster.TLSServerName != "" || len(cluster.CertificateAuthorityData) > 0 ||
+		auth.Token != "" || auth.Username != "" || auth.Password != "" || len(auth.ClientCertificateData) > 0 || len(auth.ClientKeyData) > 0 ||
+		auth.Exec != nil || auth.Impersonate != "" || len(auth.ImpersonateGroups) > 0 || auth.ImpersonateUID != ""
+}
+
+// defaultKubeDialTimeout is the timeout and keepalive interval of the connections of client-go
+const defaultKubeDialTimeout = 30 * time.Second
+
+// parseKubeDuration parses the timeouts of the kubernetes block, an empty value is zero
+func parseKubeDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative duration %s", value)
+	}
+	return d, nil
 }
 
 func expandStringSlice(input []attr.Value) []string {
@@ -230,3 +372,68 @@ func expandStringSlice(input []attr.Value) []string {
 	}
 	return result
 }
+
+// Credential sources of the kubernetes block, see credential_precedence
+const (
+	credentialSourceClientCertificate = "client_certificate"
+	credentialSourceConfigPaths       = "config_paths"
+	credentialSourceExec              = "exec"
+	credentialSourceInCluster         = "in_cluster"
+	credentialSourceToken             = "token"
+)
+
+var credentialSources = []string{
+	credentialSourceConfigPaths,
+	credentialSourceExec,
+	credentialSourceToken,
+	credentialSourceClientCertificate,
+	credentialSourceInCluster,
+}
+
+// credentialSource returns the first source of credential_precedence configured in the
+// kubernetes block, "" to combine the configured sources like kubectl
+func credentialSource(k *KubernetesConfigModel, configPaths []string) string {
+	if k.CredentialPrecedence.IsNull() || k.CredentialPrecedence.IsUnknown() {
+		return ""
+	}
+	configured := map[string]bool{
+		credentialSourceConfigPaths:       len(configPaths) > 0,
+		credentialSourceExec:              k.Exec != nil && k.Exec.APIVersion.ValueString() != "" && k.Exec.Command.ValueString() != "",
+		credentialSourceToken:             k.Token.ValueString() != "",
+		credentialSourceClientCertificate: k.ClientCertificate.ValueString() != "",
+		// the environment variables of the service rest.InClusterConfig requires
+		credentialSourceInCluster: os.Getenv("KUBERNETES_SERVICE_HOST") != "" && os.Getenv("KUBERNETES_SERVICE_PORT") != "",
+	}
+	for _, source := range expandStringSlice(k.CredentialPrecedence.Elements()) {
+		if configured[source] {
+			return source
+		}
+	}
+	return ""
+}
+
+// keepCredentialSource removes the credentials of the other sources than source from the
+// kubernetes block and returns the config paths to load
+func keepCredentialSource(k *KubernetesConfigModel, source string, configPaths []string) []string {
+	if source != credentialSourceExec {
+		k.Exec = nil
+	}
+	if source != credentialSourceToken {
+		k.Token = types.StringNull()
+	}
+	if source != credentialSourceClientCertificate {
+		k.ClientCertificate = types.StringNull()
+		k.ClientKey = types.StringNull()
+	}
+	k.Username = types.StringNull()
+	k.Password = types.StringNull()
+	if source == credentialSourceConfigPaths || source == credentialSourceInCluster {
+		// the cluster of the kubeconfig or of the pod is used
+		k.Host = types.StringNull()
+		k.ClusterCACertificate = types.StringNull()
+	}
+	if source != credentialSourceConfigPaths {
+		return nil
+	}
+	return configPaths
+}
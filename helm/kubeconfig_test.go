@@ -0,0 +1,301 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const testMultiContextKubeConfig = `apiVersion: v1
+kind: Config
+current-context: staging
+clusters:
+- name: staging
+  cluster:
+    server: https://staging.example.com
+- name: production
+  cluster:
+    server: https://production.example.com
+users:
+- name: admin
+  user:
+    token: token
+contexts:
+- name: staging
+  context:
+    cluster: staging
+    user: admin
+- name: production
+  context:
+    cluster: production
+    user: admin
+`
+
+func testKubeConfigMeta(t *testing.T, configPath, configContext string) *Meta {
+	t.Helper()
+	kubernetes := types.ObjectValueMust(map[string]attr.Type{
+		"host":                     types.StringType,
+		"username":                 types.StringType,
+		"password":                 types.StringType,
+		"insecure":                 types.BoolType,
+		"tls_server_name":          types.StringType,
+		"client_certificate":       types.StringType,
+		"client_key":               types.StringType,
+		"cluster_ca_certificate":   types.StringType,
+		"config_paths":             types.ListType{ElemType: types.StringType},
+		"config_path":              types.StringType,
+		"config_context":           types.StringType,
+		"config_context_auth_info": types.StringType,
+		"config_context_cluster":   types.StringType,
+		"token":                    types.StringType,
+		"proxy_url":                types.StringType,
+		"impersonate_user":         types.StringType,
+		"impersonate_groups":       types.ListType{ElemType: types.StringType},
+		"impersonate_uid":          types.StringType,
+		"credential_precedence":    types.ListType{ElemType: types.StringType},
+		"request_timeout":          types.StringType,
+		"dial_timeout":             types.StringType,
+		"tcp_keepalive":            types.StringType,
+		"exec":                     types.ObjectType{AttrTypes: execSchemaAttrTypes()},
+	}, map[string]attr.Value{
+		"host":                     types.StringValue(""),
+		"username":                 types.StringValue(""),
+		"password":                 types.StringValue(""),
+		"insecure":                 types.BoolValue(false),
+		"tls_server_name":          types.StringValue(""),
+		"client_certificate":       types.StringValue(""),
+		"client_key":               types.StringValue(""),
+		"cluster_ca_certificate":   types.StringValue(""),
+		"config_paths":             types.ListValueMust(types.StringType, []attr.Value{}),
+		"config_path":              types.StringValue(configPath),
+		"config_context":           types.StringValue(configContext),
+		"config_context_auth_info": types.StringValue(""),
+		"config_context_cluster":   types.StringValue(""),
+		"token":                    types.StringValue(""),
+		"proxy_url":                types.StringValue(""),
+		"impersonate_user":         types.StringValue(""),
+		"impersonate_groups":       types.ListValueMust(types.StringType, []attr.Value{}),
+		"impersonate_uid":          types.StringValue(""),
+		"credential_precedence":    types.ListValueMust(types.StringType, []attr.Value{}),
+		"request_timeout":          types.StringValue(""),
+		"dial_timeout":             types.StringValue(""),
+		"tcp_keepalive":            types.StringValue(""),
+		"exec":                     types.ObjectNull(execSchemaAttrTypes()),
+	})
+	return &Meta{Data: &HelmProviderModel{Kubernetes: kubernetes}}
+}
+
+func TestNewKubeConfigContext(t *testing.T) {
+	t.Setenv("KUBE_CONFIG_PATHS", "")
+	configPath := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(configPath, []byte(testMultiContextKubeConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		configContext string
+		kubeContext   string
+		host          string
+	}{
+		{"", "", "https://staging.example.com"},
+		{"", "production", "https://production.example.com"},
+		{"production", "", "https://production.example.com"},
+		{"production", "staging", "https://staging.example.com"},
+	}
+	for _, c := range cases {
+		m := testKubeConfigMeta(t, configPath, c.configContext)
+		kc, err := m.NewKubeConfig(context.Background(), "default", c.kubeContext)
+		assert.NoError(t, err)
+		config, err := kc.ToRESTConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, c.host, config.Host, "config_context %q, kube_context %q", c.configContext, c.kubeContext)
+	}
+
+	m := testKubeConfigMeta(t, configPath, "")
+	kc, err := m.NewKubeConfig(context.Background(), "default", "missing")
+	assert.NoError(t, err)
+	_, err = kc.ToRESTConfig()
+	assert.Error(t, err)
+}
+
+func TestNewKubeConfigContextIgnoresProviderCluster(t *testing.T) {
+	t.Setenv("KUBE_CONFIG_PATHS", "")
+	configPath := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(configPath, []byte(testMultiContextKubeConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m := testKubeConfigMeta(t, configPath, "")
+	attrs := m.Data.Kubernetes.Attributes()
+	attrs["host"] = types.StringValue("https://provider.example.com")
+	attrs["token"] = types.StringValue("provider")
+	attrs["impersonate_user"] = types.StringValue("deployer")
+	m.Data.Kubernetes = types.ObjectValueMust(m.Data.Kubernetes.AttributeTypes(context.Background()), attrs)
+
+	// the host and the credentials of the provider apply without kube_context
+	kc, err := m.NewKubeConfig(context.Background(), "default", "")
+	assert.NoError(t, err)
+	config, err := kc.ToRESTConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://provider.example.com", config.Host)
+	assert.Equal(t, "provider", config.BearerToken)
+
+	// the release is deployed to the cluster of its context with the credentials of the context
+	kc, err = m.NewKubeConfig(context.Background(), "default", "production")
+	assert.NoError(t, err)
+	config, err = kc.ToRESTConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://production.example.com", config.Host)
+	assert.Equal(t, "token", config.BearerToken)
+	assert.Empty(t, config.Impersonate.UserName)
+}
+
+func TestNewKubeConfigContextWithoutConfigPath(t *testing.T) {
+	t.Setenv("KUBE_CONFIG_PATHS", "")
+	m := testKubeConfigMeta(t, "", "")
+	_, err := m.NewKubeConfig(context.Background(), "default", "production")
+	assert.Error(t, err)
+}
+
+func TestNewKubeConfigRateLimits(t *testing.T) {
+	t.Setenv("KUBE_CONFIG_PATHS", "")
+	configPath := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(configPath, []byte(testMultiContextKubeConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m := testKubeConfigMeta(t, configPath, "")
+	m.Data.BurstLimit = types.Int64Value(200)
+	m.Data.QPS = types.Float64Value(50)
+
+	kc, err := m.NewKubeConfig(context.Background(), "default", "")
+	assert.NoError(t, err)
+	config, err := kc.ToRESTConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, 200, config.Burst)
+	assert.Equal(t, float32(50), config.QPS)
+}
+
+func TestNewKubeConfigDiscoveryCache(t *testing.T) {
+	t.Setenv("KUBE_CONFIG_PATHS", "")
+	configPath := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(configPath, []byte(testMultiContextKubeConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m := testKubeConfigMeta(t, configPath, "")
+	m.DiscoveryCache = newDiscoveryCache()
+
+	discoveryClient := func(namespace, kubeContext string) interface{} {
+		kc, err := m.NewKubeConfig(context.Background(), namespace, kubeContext)
+		assert.NoError(t, err)
+		client, err := kc.ToDiscoveryClient()
+		assert.NoError(t, err)
+		return client
+	}
+
+	staging := discoveryClient("default", "")
+	assert.Same(t, staging, discoveryClient("other", ""))
+	assert.NotSame(t, staging, discoveryClient("default", "production"))
+}
+
+func TestNewKubeConfigImpersonation(t *testing.T) {
+	t.Setenv("KUBE_CONFIG_PATHS", "")
+	configPath := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(configPath, []byte(testMultiContextKubeConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m := testKubeConfigMeta(t, configPath, "")
+	attrs := m.Data.Kubernetes.Attributes()
+	attrs["impersonate_user"] = types.StringValue("system:serviceaccount:apps:deployer")
+	attrs["impersonate_groups"] = types.ListValueMust(types.StringType, []attr.Value{types.StringValue("deployers")})
+	attrs["impersonate_uid"] = types.StringValue("1234")
+	m.Data.Kubernetes = types.ObjectValueMust(m.Data.Kubernetes.AttributeTypes(context.Background()), attrs)
+
+	kc, err := m.NewKubeConfig(context.Background(), "default", "")
+	assert.NoError(t, err)
+	config, err := kc.ToRESTConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "system:serviceaccount:apps:deployer", config.Impersonate.UserName)
+	assert.Equal(t, []string{"deployers"}, config.Impersonate.Groups)
+	assert.Equal(t, "1234", config.Impersonate.UID)
+}
+
+func TestNewKubeConfigCredentialPrecedence(t *testing.T) {
+	t.Setenv("KUBE_CONFIG_PATHS", "")
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	configPath := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(configPath, []byte(testMultiContextKubeConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		precedence []string
+		host       string
+		token      string
+	}{
+		// without precedence the explicit settings override the kubeconfig
+		{nil, "https://explicit.example.com", "explicit"},
+		{[]string{"token", "config_paths"}, "https://explicit.example.com", "explicit"},
+		{[]string{"config_paths", "token"}, "https://staging.example.com", "token"},
+		// in_cluster is not configured outside of a pod
+		{[]string{"in_cluster", "config_paths"}, "https://staging.example.com", "token"},
+	}
+	for _, c := range cases {
+		m := testKubeConfigMeta(t, configPath, "")
+		attrs := m.Data.Kubernetes.Attributes()
+		attrs["host"] = types.StringValue("https://explicit.example.com")
+		attrs["token"] = types.StringValue("explicit")
+		precedence, _ := types.ListValueFrom(context.Background(), types.StringType, c.precedence)
+		attrs["credential_precedence"] = precedence
+		m.Data.Kubernetes = types.ObjectValueMust(m.Data.Kubernetes.AttributeTypes(context.Background()), attrs)
+
+		kc, err := m.NewKubeConfig(context.Background(), "default", "")
+		assert.NoError(t, err)
+		config, err := kc.ToRESTConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, c.host, config.Host, "credential_precedence %v", c.precedence)
+		assert.Equal(t, c.token, config.BearerToken, "credential_precedence %v", c.precedence)
+	}
+}
+
+func TestNewKubeConfigTimeouts(t *testing.T) {
+	t.Setenv("KUBE_CONFIG_PATHS", "")
+	configPath := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(configPath, []byte(testMultiContextKubeConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := testKubeConfigMeta(t, configPath, "")
+	kc, err := m.NewKubeConfig(context.Background(), "default", "")
+	assert.NoError(t, err)
+	config, err := kc.ToRESTConfig()
+	assert.NoError(t, err)
+	assert.Zero(t, config.Timeout)
+	assert.Nil(t, config.Dial)
+
+	attrs := m.Data.Kubernetes.Attributes()
+	attrs["request_timeout"] = types.StringValue("2m")
+	attrs["dial_timeout"] = types.StringValue("5s")
+	attrs["tcp_keepalive"] = types.StringValue("10s")
+	m.Data.Kubernetes = types.ObjectValueMust(m.Data.Kubernetes.AttributeTypes(context.Background()), attrs)
+	kc, err = m.NewKubeConfig(context.Background(), "default", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, kc.DialTimeout)
+	assert.Equal(t, 10*time.Second, kc.TCPKeepAlive)
+	config, err = kc.ToRESTConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, config.Timeout)
+	assert.NotNil(t, config.Dial)
+
+	attrs["dial_timeout"] = types.StringValue("-5s")
+	m.Data.Kubernetes = types.ObjectValueMust(m.Data.Kubernetes.AttributeTypes(context.Background()), attrs)
+	_, err = m.NewKubeConfig(context.Background(), "default", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,155 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/kube"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// largeObjectKubeClient applies server-side the objects of a release, typically large CRDs such
+// as the ones of Crossplane or kube-prometheus, which the API server rejects when they are
+// created or updated client-side because they are too large, e.g. with
+// "metadata.annotations: Too long"
+type largeObjectKubeClient struct {
+	forwardingKubeClient
+	ctx   context.Context
+	apply func(info *resource.Info) error
+}
+
+// largeObjectMinSize is the size of the templates, CRDs or values from which a chart may render
+// objects too large to be applied client-side. The API server limits the annotations of an object
+// to 256KiB, the margin covers the difference between the size of a YAML file and the JSON of
+// the object it renders.
+const largeObjectMinSize = 128 * 1024
+
+// applyLargeObjects wraps the Kubernetes client of actionConfig to apply the objects too large
+// to be created or updated client-side server-side, when the chart c may render such objects
+// with values
+func applyLargeObjects(ctx context.Context, actionConfig *action.Configuration, c *chart.Chart, values map[string]interface{}) {
+	if !chartHasLargeObjects(c, values) {
+		return
+	}
+	tflog.Debug(ctx, fmt.Sprintf("Chart %s may render objects too large to be applied client-side", c.Name()))
+	actionConfig.KubeClient = &largeObjectKubeClient{
+		forwardingKubeClient: forwardingKubeClient{Interface: actionConfig.KubeClient},
+		ctx:                  ctx,
+		apply:                applyServerSide,
+	}
+}
+
+// chartHasLargeObjects reports whether a CRD or a template of c or of its dependencies, or the
+// values the templates may copy into an object, are at least largeObjectMinSize
+func chartHasLargeObjects(c *chart.Chart, values map[string]interface{}) bool {
+	for _, crd := range c.CRDObjects() {
+		if len(crd.File.Data) >= largeObjectMinSize {
+			return true
+		}
+	}
+	if b, err := json.Marshal(values); err != nil || len(b) >= largeObjectMinSize {
+		return true
+	}
+	return chartHasLargeTemplates(c)
+}
+
+func chartHasLargeTemplates(c *chart.Chart) bool {
+	for _, t := range c.Templates {
+		if len(t.Data) >= largeObjectMinSize {
+			return true
+		}
+	}
+	for _, d := range c.Dependencies() {
+		if chartHasLargeTemplates(d) {
+			return true
+		}
+	}
+	return false
+}
+
+// objectTooLarge reports whether err rejects an object too large to be applied client-side
+func objectTooLarge(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "metadata.annotations: Too long") ||
+		strings.Contains(msg, "Request entity too large") ||
+		apierrors.IsRequestEntityTooLargeError(err)
+}
+
+// applyTooLarge applies info server-side, with a remediation when the API server rejects it too
+func (c *largeObjectKubeClient) applyTooLarge(info *resource.Info, cause error) error {
+	kind := info.Mapping.GroupVersionKind.Kind
+	tflog.Warn(c.ctx, fmt.Sprintf("Applying %s %s server-side, it is too large to be applied client-side: %s", kind, info.ObjectName(), cause))
+	if err := c.apply(info); err != nil {
+		return fmt.Errorf("%s %s is too large to be applied client-side (%s), and applying it server-side failed: %w. "+
+			"Install it outside of the release, e.g. with kubectl apply --server-side, and skip it with crd_policy = \"skip\" when it is a CRD of the crds directory of the chart",
+			kind, info.ObjectName(), cause, err)
+	}
+	return nil
+}
+
+func (c *largeObjectKubeClient) Create(resources kube.ResourceList) (*kube.Result, error) {
+	res, err := c.Interface.Create(resources)
+	if err == nil || !objectTooLarge(err) {
+		return res, err
+	}
+
+	// the objects are created one by one to find the ones too large, the objects created by
+	// the first attempt already exist
+	for _, info := range resources {
+		_, err := c.Interface.Create(kube.ResourceList{info})
+		if err == nil || apierrors.IsAlreadyExists(err) {
+			continue
+		}
+		if !objectTooLarge(err) {
+			return res, err
+		}
+		if err := c.applyTooLarge(info, err); err != nil {
+			return res, err
+		}
+	}
+	return &kube.Result{Created: resources}, nil
+}
+
+func (c *largeObjectKubeClient) Update(original, target kube.ResourceList, force bool) (*kube.Result, error) {
+	res, err := c.Interface.Update(original, target, force)
+	if err == nil || !objectTooLarge(err) {
+		return res, err
+	}
+
+	// the objects are updated one by one to find the ones too large
+	var applied kube.ResourceList
+	for _, info := range target {
+		var originalInfo kube.ResourceList
+		if o := original.Get(info); o != nil {
+			originalInfo = kube.ResourceList{o}
+		}
+		_, err := c.Interface.Update(originalInfo, kube.ResourceList{info}, force)
+		if err == nil {
+			continue
+		}
+		if !objectTooLarge(err) {
+			return res, err
+		}
+		if err := c.applyTooLarge(info, err); err != nil {
+			return res, err
+		}
+		applied = append(applied, info)
+	}
+
+	// update again without the objects applied server-side to delete the objects removed from
+	// the release
+	res, err = c.Interface.Update(original.Difference(applied), target.Difference(applied), force)
+	if res != nil {
+		res.Updated = append(res.Updated, applied...)
+	}
+	return res, err
+}
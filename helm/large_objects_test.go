@@ -0,0 +1,147 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// largeObjectRejectingKubeClient rejects the objects of large as the API server rejects objects
+// too large to be applied client-side
+type largeObjectRejectingKubeClient struct {
+	kubefake.PrintingKubeClient
+	large   map[string]bool
+	created []string
+	updated []string
+}
+
+func (c *largeObjectRejectingKubeClient) reject(resources kube.ResourceList) error {
+	for _, info := range resources {
+		if c.large[info.Name] {
+			return errors.New(`CustomResourceDefinition.apiextensions.k8s.io "` + info.Name + `" is invalid: metadata.annotations: Too long: must have at most 262144 bytes`)
+		}
+	}
+	return nil
+}
+
+func (c *largeObjectRejectingKubeClient) Create(resources kube.ResourceList) (*kube.Result, error) {
+	if err := c.reject(resources); err != nil {
+		return nil, err
+	}
+	for _, info := range resources {
+		c.created = append(c.created, info.Name)
+	}
+	return &kube.Result{Created: resources}, nil
+}
+
+func (c *largeObjectRejectingKubeClient) Update(original, target kube.ResourceList, force bool) (*kube.Result, error) {
+	if err := c.reject(target); err != nil {
+		return nil, err
+	}
+	for _, info := range target {
+		c.updated = append(c.updated, info.Name)
+	}
+	return &kube.Result{Updated: target, Deleted: original.Difference(target)}, nil
+}
+
+func TestLargeObjectKubeClient(t *testing.T) {
+	mapping := &meta.RESTMapping{GroupVersionKind: schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}}
+	resources := kube.ResourceList{
+		{Name: "compositions.apiextensions.crossplane.io", Mapping: mapping},
+		{Name: "small.example.com", Mapping: mapping},
+	}
+	removed := &resource.Info{Name: "removed.example.com", Mapping: mapping}
+	newClient := func(applyErr error) (*largeObjectKubeClient, *largeObjectRejectingKubeClient, *[]string) {
+		fake := &largeObjectRejectingKubeClient{
+			PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard},
+			large:              map[string]bool{"compositions.apiextensions.crossplane.io": true},
+		}
+		var applied []string
+		client := &largeObjectKubeClient{forwardingKubeClient: forwardingKubeClient{Interface: fake}, ctx: context.Background(), apply: func(info *resource.Info) error {
+			applied = append(applied, info.Name)
+			return applyErr
+		}}
+		return client, fake, &applied
+	}
+
+	t.Run("create", func(t *testing.T) {
+		client, fake, applied := newClient(nil)
+		res, err := client.Create(resources)
+		require.NoError(t, err)
+		assert.Len(t, res.Created, 2)
+		assert.Equal(t, []string{"small.example.com"}, fake.created)
+		assert.Equal(t, []string{"compositions.apiextensions.crossplane.io"}, *applied)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		client, fake, applied := newClient(nil)
+		res, err := client.Update(append(resources[:2:2], removed), resources, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"compositions.apiextensions.crossplane.io"}, *applied)
+		// the small object is updated on its own, then with the deletion of the removed object
+		assert.Equal(t, []string{"small.example.com", "small.example.com"}, fake.updated)
+		assert.Len(t, res.Updated, 2)
+		require.Len(t, res.Deleted, 1)
+		assert.Equal(t, "removed.example.com", res.Deleted[0].Name)
+	})
+
+	t.Run("server-side apply fails", func(t *testing.T) {
+		client, _, _ := newClient(errors.New("request entity too large"))
+		_, err := client.Create(resources)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "kubectl apply --server-side")
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		assert.False(t, objectTooLarge(errors.New(`field is immutable`)))
+		assert.True(t, objectTooLarge(errors.New(`Request entity too large: limit is 3145728`)))
+	})
+}
+
+func TestApplyLargeObjects(t *testing.T) {
+	ctx := context.Background()
+	newChart := func(name string) *chart.Chart {
+		return &chart.Chart{
+			Metadata:  &chart.Metadata{Name: name},
+			Templates: []*chart.File{{Name: "templates/deployment.yaml", Data: []byte("kind: Deployment")}},
+		}
+	}
+	large := []byte(strings.Repeat("x", largeObjectMinSize))
+	client := &kubefake.PrintingKubeClient{Out: io.Discard}
+
+	// the client is only wrapped when the chart may render objects too large
+	actionConfig := &action.Configuration{KubeClient: client}
+	applyLargeObjects(ctx, actionConfig, newChart("app"), map[string]interface{}{"replicas": 1})
+	assert.Same(t, client, actionConfig.KubeClient)
+
+	withCRD := newChart("app")
+	withCRD.Files = []*chart.File{{Name: "crds/crd.yaml", Data: large}}
+	assert.True(t, chartHasLargeObjects(withCRD, nil))
+
+	withDependency := newChart("app")
+	dependency := newChart("dependency")
+	dependency.Templates = append(dependency.Templates, &chart.File{Name: "templates/configmap.yaml", Data: large})
+	withDependency.AddDependency(dependency)
+	assert.True(t, chartHasLargeObjects(withDependency, nil))
+
+	assert.True(t, chartHasLargeObjects(newChart("app"), map[string]interface{}{"dashboard": string(large)}))
+
+	applyLargeObjects(ctx, actionConfig, withCRD, nil)
+	_, ok := actionConfig.KubeClient.(*largeObjectKubeClient)
+	assert.True(t, ok)
+}
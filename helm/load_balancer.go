@@ -0,0 +1,168 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// loadBalancerPollInterval is the interval the external addresses of load balancers are polled at
+var loadBalancerPollInterval = 2 * time.Second
+
+// loadBalancerObject is a Service of type LoadBalancer or an Ingress of a release manifest
+type loadBalancerObject struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// key is the key of the object in the endpoints attribute of helm_release
+func (o loadBalancerObject) key() string {
+	return fmt.Sprintf("%s/%s/%s", strings.ToLower(o.Kind), o.Namespace, o.Name)
+}
+
+// manifestLoadBalancers returns the Services of type LoadBalancer and the Ingresses of a manifest.
+// Objects without a namespace are installed in the namespace of the release.
+func manifestLoadBalancers(manifest, releaseNamespace string) ([]loadBalancerObject, error) {
+	var objects []loadBalancerObject
+	for _, resource := range releaseutil.SplitManifests(manifest) {
+		obj := struct {
+			resourceMeta
+			Spec struct {
+				Type string `json:"type"`
+			} `json:"spec"`
+		}{}
+		if err := yaml.Unmarshal([]byte(resource), &obj); err != nil {
+			return nil, err
+		}
+		switch {
+		case obj.Kind == "Service" && obj.Spec.Type == string(v1.ServiceTypeLoadBalancer):
+		case obj.Kind == "Ingress" && strings.HasPrefix(obj.APIVersion, "networking.k8s.io/"):
+		default:
+			continue
+		}
+		namespace := obj.Metadata.Namespace
+		if namespace == "" {
+			namespace = releaseNamespace
+		}
+		objects = append(objects, loadBalancerObject{Kind: obj.Kind, Namespace: namespace, Name: obj.Metadata.Name})
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].key() < objects[j].key() })
+	return objects, nil
+}
+
+// loadBalancerAddresses returns the external IPs and hostnames assigned to the object, empty
+// while none is assigned
+func loadBalancerAddresses(ctx context.Context, clientset kubernetes.Interface, o loadBalancerObject) ([]string, error) {
+	var ingress []v1.LoadBalancerIngress
+	switch o.Kind {
+	case "Service":
+		svc, err := clientset.CoreV1().Services(o.Namespace).Get(ctx, o.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		ingress = svc.Status.LoadBalancer.Ingress
+	case "Ingress":
+		ing, err := clientset.NetworkingV1().Ingresses(o.Namespace).Get(ctx, o.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range ing.Status.LoadBalancer.Ingress {
+			ingress = append(ingress, v1.LoadBalancerIngress{IP: i.IP, Hostname: i.Hostname})
+		}
+	}
+	return ingressAddresses(ingress), nil
+}
+
+// ingressAddresses returns the IPs and hostnames of the load balancer ingress points
+func ingressAddresses(ingress []v1.LoadBalancerIngress) []string {
+	var addresses []string
+	for _, i := range ingress {
+		if i.IP != "" {
+			addresses = append(addresses, i.IP)
+		}
+		if i.Hostname != "" {
+			addresses = append(addresses, i.Hostname)
+		}
+	}
+	return addresses
+}
+
+// waitForLoadBalancerEndpoints waits until every Service of type LoadBalancer and Ingress of the
+// release has an external address, and returns their addresses keyed by kind/namespace/name
+func waitForLoadBalancerEndpoints(ctx context.Context, clientset kubernetes.Interface, r *release.Release, timeout time.Duration) (map[string]string, error) {
+	objects, err := manifestLoadBalancers(r.Manifest, r.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := map[string]string{}
+	var pending []string
+	err = wait.PollUntilContextTimeout(ctx, loadBalancerPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		pending = nil
+		for _, o := range objects {
+			if _, ok := endpoints[o.key()]; ok {
+				continue
+			}
+			addresses, err := loadBalancerAddresses(ctx, clientset, o)
+			if err != nil {
+				return false, err
+			}
+			if len(addresses) == 0 {
+				pending = append(pending, o.key())
+				continue
+			}
+			endpoints[o.key()] = strings.Join(addresses, ",")
+		}
+		if len(pending) > 0 {
+			tflog.Debug(ctx, fmt.Sprintf("Waiting for the external address of %s", strings.Join(pending, ", ")))
+		}
+		return len(pending) == 0, nil
+	})
+	if err != nil && len(pending) > 0 {
+		return endpoints, fmt.Errorf("timed out waiting for the external address of %s", strings.Join(pending, ", "))
+	}
+	return endpoints, err
+}
+
+// setLoadBalancerEndpoints waits for the external addresses of the load balancers of the release
+// with wait_for_load_balancer and sets the endpoints attribute of state. The addresses assigned
+// before the timeout are set when the wait fails.
+func setLoadBalancerEndpoints(ctx context.Context, actionConfig *action.Configuration, state *HelmReleaseModel, r *release.Release) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if !state.WaitForLoadBalancer.ValueBool() {
+		state.Endpoints = types.MapNull(types.StringType)
+		return diags
+	}
+
+	clientset, err := actionConfig.KubernetesClientSet()
+	if err != nil {
+		diags.AddError("Error waiting for load balancers", fmt.Sprintf("Unable to create Kubernetes client: %s", err))
+		return diags
+	}
+	endpoints, err := waitForLoadBalancerEndpoints(ctx, clientset, r, time.Duration(state.Timeout.ValueInt64())*time.Second)
+	endpointsMap, mapDiags := types.MapValueFrom(ctx, types.StringType, endpoints)
+	diags.Append(mapDiags...)
+	state.Endpoints = endpointsMap
+	if err != nil {
+		diags.AddError("Error waiting for load balancers", fmt.Sprintf("Helm release %s was deployed but its load balancers are not ready: %s", r.Name, err))
+	}
+	return diags
+}
@@ -0,0 +1,90 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const loadBalancerManifest = `---
+# Source: test/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  type: LoadBalancer
+---
+# Source: test/templates/internal.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: internal
+spec:
+  type: ClusterIP
+---
+# Source: test/templates/ingress.yaml
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+  namespace: edge
+`
+
+func TestManifestLoadBalancers(t *testing.T) {
+	objects, err := manifestLoadBalancers(loadBalancerManifest, "default")
+	require.NoError(t, err)
+	assert.Equal(t, []loadBalancerObject{
+		{Kind: "Ingress", Namespace: "edge", Name: "web"},
+		{Kind: "Service", Namespace: "default", Name: "web"},
+	}, objects)
+}
+
+func TestWaitForLoadBalancerEndpoints(t *testing.T) {
+	interval := loadBalancerPollInterval
+	loadBalancerPollInterval = 10 * time.Millisecond
+	defer func() { loadBalancerPollInterval = interval }()
+
+	ctx := context.Background()
+	r := &release.Release{Name: "test", Namespace: "default", Manifest: loadBalancerManifest}
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+	}
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "edge"},
+		Status: networkingv1.IngressStatus{LoadBalancer: networkingv1.IngressLoadBalancerStatus{
+			Ingress: []networkingv1.IngressLoadBalancerIngress{{Hostname: "web.example.com"}},
+		}},
+	}
+
+	t.Run("pending", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(service.DeepCopy(), ingress.DeepCopy())
+		endpoints, err := waitForLoadBalancerEndpoints(ctx, clientset, r, 50*time.Millisecond)
+		assert.ErrorContains(t, err, "service/default/web")
+		assert.Equal(t, map[string]string{"ingress/edge/web": "web.example.com"}, endpoints)
+	})
+
+	t.Run("ready", func(t *testing.T) {
+		ready := service.DeepCopy()
+		ready.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{{IP: "203.0.113.10"}, {IP: "203.0.113.11"}}
+		clientset := fake.NewSimpleClientset(ready, ingress.DeepCopy())
+		endpoints, err := waitForLoadBalancerEndpoints(ctx, clientset, r, time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"ingress/edge/web":    "web.example.com",
+			"service/default/web": "203.0.113.10,203.0.113.11",
+		}, endpoints)
+	})
+}
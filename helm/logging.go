@@ -0,0 +1,154 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	"k8s.io/klog/v2"
+)
+
+// Subsystems of the logs of the provider. The level of a subsystem follows TF_LOG_PROVIDER_HELM
+// and can be set with TF_LOG_PROVIDER_HELM_<SUBSYSTEM>, e.g. TF_LOG_PROVIDER_HELM_KUBE_WAIT.
+const (
+	// debug logs of Helm actions
+	logSubsystemAction = "helm.action"
+	// downloads of charts and repository indexes
+	logSubsystemDownload = "helm.download"
+	// requests of the Kubernetes client of Helm
+	logSubsystemKube = "helm.kube"
+	// waits of the Kubernetes client of Helm for resources to be ready or deleted
+	logSubsystemKubeWait = "helm.kube.wait"
+	// messages of the Kubernetes client libraries, which log with klog
+	logSubsystemKlog = "helm.klog"
+	// rendering of the manifests of releases
+	logSubsystemRender = "helm.render"
+)
+
+// klogMaxLevel is the highest verbosity of the klog messages logged. The Kubernetes client
+// libraries log the requests they send from verbosity 6.
+const klogMaxLevel = 4
+
+// kubeWaitMessages are the phrases of the messages the Kubernetes client of Helm logs while it
+// waits for resources
+var kubeWaitMessages = []string{"wait", "ready", "watching", "event for", "pending", "completed", "not bound", "load balancer"}
+
+// logSubsystemEnv returns the environment variable the level of subsystem is read from
+func logSubsystemEnv(subsystem string) string {
+	name := strings.ReplaceAll(strings.TrimPrefix(subsystem, "helm."), ".", "_")
+	return "TF_LOG_PROVIDER_HELM_" + strings.ToUpper(name)
+}
+
+// logSubsystem returns ctx with the logger of subsystem, created with opts
+func logSubsystem(ctx context.Context, subsystem string, opts tflog.Options) context.Context {
+	if env := logSubsystemEnv(subsystem); os.Getenv(env) != "" {
+		opts = append(opts, tflog.WithLevelFromEnv(env))
+	}
+	return tflog.NewSubsystem(ctx, subsystem, opts...)
+}
+
+// subsystemDebug logs msg at the debug level in subsystem
+func subsystemDebug(ctx context.Context, subsystem, msg string) {
+	ctx = logSubsystem(ctx, subsystem, tflog.Options{tflog.WithAdditionalLocationOffset(1)})
+	tflog.SubsystemDebug(ctx, subsystem, msg)
+}
+
+// helmDebugLog returns the debug log of Helm actions, which logs in subsystem
+func helmDebugLog(ctx context.Context, subsystem string) action.DebugLog {
+	ctx = logSubsystem(ctx, subsystem, nil)
+	return func(format string, v ...interface{}) {
+		tflog.SubsystemDebug(ctx, subsystem, fmt.Sprintf(format, v...))
+	}
+}
+
+// kubeDebugLog returns the debug log of the Kubernetes client of Helm, which logs the messages
+// of its waits in the helm.kube.wait subsystem and the other ones in helm.kube
+func kubeDebugLog(ctx context.Context) func(string, ...interface{}) {
+	kubeLog := helmDebugLog(ctx, logSubsystemKube)
+	waitLog := helmDebugLog(ctx, logSubsystemKubeWait)
+	return func(format string, v ...interface{}) {
+		msg := strings.ToLower(format)
+		for _, m := range kubeWaitMessages {
+			if strings.Contains(msg, m) {
+				waitLog(format, v...)
+				return
+			}
+		}
+		kubeLog(format, v...)
+	}
+}
+
+// setKubeDebugLog logs the debug messages of the Kubernetes client of actionConfig in the
+// helm.kube and helm.kube.wait subsystems, Helm logs them with the debug log of the actions
+func setKubeDebugLog(ctx context.Context, actionConfig *action.Configuration) {
+	if c, ok := actionConfig.KubeClient.(*kube.Client); ok {
+		c.Log = kubeDebugLog(ctx)
+	}
+}
+
+// klogSink logs the messages of klog in the helm.klog subsystem, instead of the standard error
+// of the provider, where they are interleaved with the logs of Terraform
+type klogSink struct {
+	ctx    context.Context
+	name   string
+	values []interface{}
+}
+
+// redirectKlog logs the messages of klog, e.g. the warnings of the Kubernetes API server, with
+// the logger of ctx
+func redirectKlog(ctx context.Context) {
+	klog.SetLogger(logr.New(&klogSink{ctx: logSubsystem(ctx, logSubsystemKlog, nil)}))
+}
+
+func (s *klogSink) Init(logr.RuntimeInfo) {}
+
+func (s *klogSink) Enabled(level int) bool {
+	return level <= klogMaxLevel
+}
+
+// fields returns the key-value pairs of a message and of the sink as log fields
+func (s *klogSink) fields(keysAndValues []interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	kv := append(append([]interface{}{}, s.values...), keysAndValues...)
+	for i := 0; i+1 < len(kv); i += 2 {
+		fields[fmt.Sprint(kv[i])] = kv[i+1]
+	}
+	if s.name != "" {
+		fields["logger"] = s.name
+	}
+	return fields
+}
+
+func (s *klogSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	fields := s.fields(keysAndValues)
+	if level == 0 {
+		tflog.SubsystemInfo(s.ctx, logSubsystemKlog, msg, fields)
+		return
+	}
+	tflog.SubsystemDebug(s.ctx, logSubsystemKlog, msg, fields)
+}
+
+func (s *klogSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	fields := s.fields(keysAndValues)
+	fields["error"] = fmt.Sprint(err)
+	tflog.SubsystemError(s.ctx, logSubsystemKlog, msg, fields)
+}
+
+func (s *klogSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &klogSink{ctx: s.ctx, name: s.name, values: append(append([]interface{}{}, s.values...), keysAndValues...)}
+}
+
+func (s *klogSink) WithName(name string) logr.LogSink {
+	if s.name != "" {
+		name = s.name + "." + name
+	}
+	return &klogSink{ctx: s.ctx, name: name, values: s.values}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogSubsystems(t *testing.T) {
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	subsystemDebug(ctx, logSubsystemDownload, "Downloading the index of repository https://charts.example.com")
+	helmDebugLog(ctx, logSubsystemAction)("creating %d resource(s)", 3)
+	kubeLog := kubeDebugLog(ctx)
+	kubeLog("beginning wait for %d resources with timeout of %v", 2, "5m0s")
+	kubeLog("Deployment is not ready: %s/%s", "default", "web")
+	kubeLog("Patch Deployment %q in namespace %s", "web", "default")
+
+	sink := logr.New(&klogSink{ctx: logSubsystem(ctx, logSubsystemKlog, nil)})
+	sink.WithName("reflector").Info("Warning: policy/v1beta1 PodSecurityPolicy is deprecated", "resource", "psp")
+	sink.Error(errors.New("connection refused"), "Failed to watch")
+	sink.V(6).Info("GET https://kubernetes/api/v1/namespaces")
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	require.NoError(t, err)
+	var got []string
+	for _, e := range entries {
+		got = append(got, e["@module"].(string)+" "+e["@level"].(string)+" "+e["@message"].(string))
+	}
+	assert.Equal(t, []string{
+		"provider.helm.download debug Downloading the index of repository https://charts.example.com",
+		"provider.helm.action debug creating 3 resource(s)",
+		"provider.helm.kube.wait debug beginning wait for 2 resources with timeout of 5m0s",
+		"provider.helm.kube.wait debug Deployment is not ready: default/web",
+		`provider.helm.kube debug Patch Deployment "web" in namespace default`,
+		"provider.helm.klog info Warning: policy/v1beta1 PodSecurityPolicy is deprecated",
+		"provider.helm.klog error Failed to watch",
+	}, got)
+	assert.Equal(t, "psp", entries[5]["resource"])
+	assert.Equal(t, "reflector", entries[5]["logger"])
+	assert.Equal(t, "connection refused", entries[6]["error"])
+}
+
+func TestLogSubsystemEnv(t *testing.T) {
+	assert.Equal(t, "TF_LOG_PROVIDER_HELM_KUBE_WAIT", logSubsystemEnv(logSubsystemKubeWait))
+	assert.Equal(t, "TF_LOG_PROVIDER_HELM_DOWNLOAD", logSubsystemEnv(logSubsystemDownload))
+}
@@ -0,0 +1,48 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// manifestHashes returns the SHA-256 hashes of the rendered documents, keyed by the
+// kind/namespace/name of their object, e.g. configmap/default/settings, or kind/name when the
+// object has no namespace. The objects are hashed as JSON with sorted keys, so that the hashes
+// only change when the objects do, not when the comments or the formatting of the templates do.
+// Documents of the same object, e.g. of hooks, are hashed together in order.
+func manifestHashes(documents []string) (map[string]string, error) {
+	objects := map[string][]string{}
+	var keys []string
+	for _, document := range documents {
+		object := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(document), &object); err != nil {
+			return nil, err
+		}
+		if len(object) == 0 {
+			continue
+		}
+		b, err := json.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		key := policyObjectName(object)
+		if _, ok := objects[key]; !ok {
+			keys = append(keys, key)
+		}
+		objects[key] = append(objects[key], string(b))
+	}
+
+	hashes := make(map[string]string, len(keys))
+	for _, key := range keys {
+		sum := sha256.Sum256([]byte(strings.Join(objects[key], "\n")))
+		hashes[key] = hex.EncodeToString(sum[:])
+	}
+	return hashes, nil
+}
@@ -0,0 +1,39 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestHashes(t *testing.T) {
+	documents := []string{
+		"# Source: chart/templates/configmap.yaml\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n  namespace: default\ndata:\n  a: \"1\"\n  b: \"2\"\n",
+		"# Source: chart/templates/role.yaml\napiVersion: rbac.authorization.k8s.io/v1\nkind: ClusterRole\nmetadata:\n  name: reader\n",
+		"# empty\n",
+	}
+	hashes, err := manifestHashes(documents)
+	require.NoError(t, err)
+	assert.Len(t, hashes, 2)
+	assert.Contains(t, hashes, "configmap/default/settings")
+	assert.Contains(t, hashes, "clusterrole/reader")
+
+	// the comments, the formatting and the order of the keys do not change the hashes
+	reformatted, err := manifestHashes([]string{
+		"# Source: chart/templates/settings.yaml\nkind: ConfigMap\napiVersion: v1\ndata: {b: \"2\", a: \"1\"}\nmetadata: {namespace: default, name: settings}\n",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, hashes["configmap/default/settings"], reformatted["configmap/default/settings"])
+
+	changed, err := manifestHashes([]string{documents[0] + "  c: \"3\"\n", documents[1]})
+	require.NoError(t, err)
+	assert.NotEqual(t, hashes["configmap/default/settings"], changed["configmap/default/settings"])
+	assert.Equal(t, hashes["clusterrole/reader"], changed["clusterrole/reader"])
+
+	_, err = manifestHashes([]string{"kind: [invalid"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,149 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Representations of the manifest in the state
+const (
+	manifestStorageFull       = "full"
+	manifestStorageHash       = "hash"
+	manifestStorageCompressed = "compressed"
+)
+
+// setManifest sets the manifest attribute of model to the JSON manifest, represented as
+// manifest_storage sets, and manifest_sha256 to its SHA-256
+func setManifest(model *HelmReleaseModel, manifest types.String) {
+	if manifest.IsNull() || manifest.IsUnknown() {
+		model.Manifest = manifest
+		model.ManifestSHA256 = manifest
+		return
+	}
+	sum := sha256.Sum256([]byte(manifest.ValueString()))
+	model.ManifestSHA256 = types.StringValue(hex.EncodeToString(sum[:]))
+
+	switch model.ManifestStorage.ValueString() {
+	case manifestStorageHash:
+		model.Manifest = types.StringNull()
+	case manifestStorageCompressed:
+		model.Manifest = types.StringValue(compressManifest(manifest.ValueString()))
+	default:
+		model.Manifest = manifest
+	}
+}
+
+// storedManifest returns the JSON manifest of model. It is unknown when only its hash is stored.
+func storedManifest(model *HelmReleaseModel) (types.String, error) {
+	if model.Manifest.IsUnknown() {
+		return model.Manifest, nil
+	}
+	if model.ManifestStorage.ValueString() == manifestStorageHash && !model.ManifestSHA256.IsNull() {
+		return types.StringUnknown(), nil
+	}
+	if model.Manifest.IsNull() || model.ManifestStorage.ValueString() != manifestStorageCompressed {
+		return model.Manifest, nil
+	}
+	manifest, err := decompressManifest(model.Manifest.ValueString())
+	if err != nil {
+		return types.StringNull(), err
+	}
+	return types.StringValue(manifest), nil
+}
+
+// compressManifest returns the base64 encoding of the gzip compressed manifest. The output of
+// gzip does not depend on the time, so that plans and applies compress manifests identically.
+func compressManifest(manifest string) string {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	// writes to a bytes.Buffer do not fail
+	_, _ = w.Write([]byte(manifest))
+	_ = w.Close()
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// decompressManifest reverses compressManifest
+func decompressManifest(compressed string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(compressed)
+	if err != nil {
+		return "", fmt.Errorf("invalid compressed manifest: %w", err)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return "", fmt.Errorf("invalid compressed manifest: %w", err)
+	}
+	manifest, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("invalid compressed manifest: %w", err)
+	}
+	return string(manifest), nil
+}
+
+// manifestStorageChange reports whether only manifest_storage changes between state and plan,
+// so that the representation of the manifest is migrated without upgrading the release
+func manifestStorageChange(ctx context.Context, current tfsdk.State, plan, state HelmReleaseModel) bool {
+	if plan.ManifestStorage.Equal(state.ManifestStorage) {
+		return false
+	}
+	unchanged := pausedState(plan, state)
+	unchanged.ManifestStorage = state.ManifestStorage
+	unchanged.Manifest = state.Manifest
+	unchanged.ManifestSHA256 = state.ManifestSHA256
+	planned := tfsdk.State{Schema: current.Schema}
+	if diags := planned.Set(ctx, &unchanged); diags.HasError() {
+		return false
+	}
+	return planned.Raw.Equal(current.Raw)
+}
+
+// migrateManifestStorage represents the manifest of the state of the release of plan as its
+// manifest_storage sets. The manifest is read from the release when the state only holds its hash,
+// secretPaths are the paths of the values read from Secrets redacted from it.
+func migrateManifestStorage(ctx context.Context, plan, state *HelmReleaseModel, meta *Meta, actionConfig *action.Configuration, secretPaths [][]string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	manifest, err := storedManifest(state)
+	if err != nil {
+		diags.AddError("Error reading manifest", err.Error())
+		return diags
+	}
+	if manifest.IsUnknown() {
+		r, err := getRelease(ctx, meta, actionConfig, plan.Name.ValueString())
+		if err != nil {
+			diags.AddError("Error getting release", err.Error())
+			return diags
+		}
+		m, err := releaseManifest(r, releaseSensitiveValues(plan, r.Config, secretPaths))
+		if err != nil {
+			diags.AddError("Error converting manifest to JSON", fmt.Sprintf("Unable to convert manifest to JSON: %s", err))
+			return diags
+		}
+		manifest = types.StringValue(m)
+	}
+	setManifest(plan, manifest)
+	return diags
+}
+
+// releaseManifest returns the manifest of r as JSON, with sensitiveValues redacted, see
+// releaseSensitiveValues
+func releaseManifest(r *release.Release, sensitiveValues map[string]string) (string, error) {
+	jsonManifest, err := convertYAMLManifestToJSON(r.Manifest)
+	if err != nil {
+		return "", err
+	}
+	return redactSensitiveValues(string(jsonManifest), sensitiveValues), nil
+}
@@ -0,0 +1,51 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetManifest(t *testing.T) {
+	manifest := types.StringValue(`{"v1/configmap/default/web":{"data":{"a":"b"}}}`)
+
+	full := &HelmReleaseModel{ManifestStorage: types.StringValue(manifestStorageFull)}
+	setManifest(full, manifest)
+	assert.Equal(t, manifest, full.Manifest)
+	assert.Len(t, full.ManifestSHA256.ValueString(), 64)
+
+	compressed := &HelmReleaseModel{ManifestStorage: types.StringValue(manifestStorageCompressed)}
+	setManifest(compressed, manifest)
+	assert.NotEqual(t, manifest, compressed.Manifest)
+	assert.Equal(t, full.ManifestSHA256, compressed.ManifestSHA256)
+	stored, err := storedManifest(compressed)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, stored)
+	// plans and applies must compress manifests identically
+	again := &HelmReleaseModel{ManifestStorage: types.StringValue(manifestStorageCompressed)}
+	setManifest(again, manifest)
+	assert.Equal(t, compressed.Manifest, again.Manifest)
+
+	hash := &HelmReleaseModel{ManifestStorage: types.StringValue(manifestStorageHash)}
+	setManifest(hash, manifest)
+	assert.True(t, hash.Manifest.IsNull())
+	assert.Equal(t, full.ManifestSHA256, hash.ManifestSHA256)
+	stored, err = storedManifest(hash)
+	require.NoError(t, err)
+	assert.True(t, stored.IsUnknown())
+
+	setManifest(full, types.StringNull())
+	assert.True(t, full.Manifest.IsNull())
+	assert.True(t, full.ManifestSHA256.IsNull())
+	setManifest(full, types.StringUnknown())
+	assert.True(t, full.Manifest.IsUnknown())
+	assert.True(t, full.ManifestSHA256.IsUnknown())
+
+	_, err = storedManifest(&HelmReleaseModel{ManifestStorage: types.StringValue(manifestStorageCompressed), Manifest: types.StringValue("not compressed")})
+	assert.ErrorContains(t, err, "invalid compressed manifest")
+}
@@ -0,0 +1,98 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Values of the missing_namespace_policy attribute of helm_release
+const (
+	missingNamespaceRemove   = "remove"
+	missingNamespaceRecreate = "recreate"
+	missingNamespaceError    = "error"
+)
+
+var missingNamespacePolicies = []string{missingNamespaceRemove, missingNamespaceRecreate, missingNamespaceError}
+
+// handleMissingNamespace applies the missing_namespace_policy of the release of model when its
+// namespace was deleted, and reports whether the release is to be removed from the state:
+//   - remove removes the release, which is installed again on the next apply
+//   - recreate creates the namespace again, as create_namespace does, and removes the release
+//   - error fails the refresh
+func handleMissingNamespace(ctx context.Context, clientset kubernetes.Interface, model *HelmReleaseModel) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	namespace := model.Namespace.ValueString()
+
+	_, err := clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if apierrors.IsForbidden(err) {
+		// without the permission to get namespaces, the namespace is assumed to exist
+		tflog.Debug(ctx, fmt.Sprintf("Unable to check whether namespace %s exists: %s", namespace, err))
+		return false, diags
+	}
+	if err == nil || !apierrors.IsNotFound(err) {
+		if err != nil {
+			diags.AddError("Error reading release namespace", fmt.Sprintf("Unable to get namespace %s: %s", namespace, err))
+		}
+		return false, diags
+	}
+
+	switch model.MissingNamespacePolicy.ValueString() {
+	case missingNamespaceError:
+		diags.AddError(
+			"Release namespace not found",
+			fmt.Sprintf("The namespace %s of Helm release %s was deleted. Set missing_namespace_policy to remove or recreate to install the release again.", namespace, model.Name.ValueString()),
+		)
+		return false, diags
+	case missingNamespaceRecreate:
+		tflog.Debug(ctx, fmt.Sprintf("Namespace %s of release %s not found, recreating it", namespace, model.Name.ValueString()))
+		if err := createReleaseNamespace(ctx, clientset, namespace); err != nil {
+			diags.AddError("Error recreating release namespace", fmt.Sprintf("Unable to create namespace %s: %s", namespace, err))
+			return false, diags
+		}
+	default:
+		tflog.Debug(ctx, fmt.Sprintf("Namespace %s of release %s not found, removing the release from the state", namespace, model.Name.ValueString()))
+	}
+	return true, diags
+}
+
+// createReleaseNamespace creates namespace with the labels Helm sets with --create-namespace
+func createReleaseNamespace(ctx context.Context, clientset kubernetes.Interface, namespace string) error {
+	ns := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: namespace,
+			Labels: map[string]string{
+				"name": namespace,
+			},
+		},
+	}
+	_, err := clientset.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+// validateMissingNamespacePolicy checks that the namespace of the release of model can be
+// recreated by its missing_namespace_policy
+func validateMissingNamespacePolicy(model *HelmReleaseModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if model.MissingNamespacePolicy.ValueString() == missingNamespaceRecreate && !model.CreateNamespace.IsUnknown() && !model.CreateNamespace.ValueBool() {
+		diags.AddAttributeError(
+			path.Root("missing_namespace_policy"),
+			"Invalid missing_namespace_policy",
+			"missing_namespace_policy recreate requires create_namespace to be true.",
+		)
+	}
+	return diags
+}
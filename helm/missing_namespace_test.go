@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestHandleMissingNamespace(t *testing.T) {
+	ctx := context.Background()
+	model := func(policy string) *HelmReleaseModel {
+		return &HelmReleaseModel{
+			Name:                   types.StringValue("test"),
+			Namespace:              types.StringValue("apps"),
+			MissingNamespacePolicy: types.StringValue(policy),
+		}
+	}
+
+	t.Run("namespace exists", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "apps"}})
+		removed, diags := handleMissingNamespace(ctx, clientset, model(missingNamespaceError))
+		assert.False(t, diags.HasError())
+		assert.False(t, removed)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset()
+		removed, diags := handleMissingNamespace(ctx, clientset, model(missingNamespaceRemove))
+		assert.False(t, diags.HasError())
+		assert.True(t, removed)
+		_, err := clientset.CoreV1().Namespaces().Get(ctx, "apps", metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+
+	t.Run("recreate", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset()
+		removed, diags := handleMissingNamespace(ctx, clientset, model(missingNamespaceRecreate))
+		assert.False(t, diags.HasError())
+		assert.True(t, removed)
+		ns, err := clientset.CoreV1().Namespaces().Get(ctx, "apps", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"name": "apps"}, ns.Labels)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset()
+		removed, diags := handleMissingNamespace(ctx, clientset, model(missingNamespaceError))
+		assert.True(t, diags.HasError())
+		assert.False(t, removed)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset()
+		clientset.PrependReactor("get", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "apps", nil)
+		})
+		removed, diags := handleMissingNamespace(ctx, clientset, model(missingNamespaceError))
+		assert.False(t, diags.HasError())
+		assert.False(t, removed)
+	})
+}
+
+func TestValidateMissingNamespacePolicy(t *testing.T) {
+	model := &HelmReleaseModel{
+		MissingNamespacePolicy: types.StringValue(missingNamespaceRecreate),
+		CreateNamespace:        types.BoolValue(false),
+	}
+	assert.True(t, validateMissingNamespacePolicy(model).HasError())
+
+	model.CreateNamespace = types.BoolValue(true)
+	assert.False(t, validateMissingNamespacePolicy(model).HasError())
+
+	model.MissingNamespacePolicy = types.StringValue(missingNamespaceRemove)
+	model.CreateNamespace = types.BoolValue(false)
+	assert.False(t, validateMissingNamespacePolicy(model).HasError())
+}
@@ -0,0 +1,116 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
+)
+
+// mockChartVersion is the chart version of mock releases without a configured version
+const mockChartVersion = "0.0.0"
+
+// mockRelease fabricates the release of model at revision for the mock mode of the provider.
+// The release is deterministic, so that the planned and applied attributes are the same: the
+// chart is named after the chart attribute, its version is the configured version, the release
+// is deployed at the Unix epoch and has no manifest nor hooks. values_from is not read.
+func mockRelease(ctx context.Context, model *HelmReleaseModel, meta *Meta, revision int) (*release.Release, diag.Diagnostics) {
+	values, diags := getValues(ctx, model, meta)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	version := strings.TrimPrefix(model.Version.ValueString(), "v")
+	if version == "" {
+		version = mockChartVersion
+	}
+	chartName := path.Base(strings.TrimSuffix(model.Chart.ValueString(), "/"))
+	if ext := path.Ext(chartName); ext == ".tgz" {
+		chartName = strings.TrimSuffix(chartName, ext)
+	}
+
+	deployed := helmtime.Unix(0, 0).UTC()
+	return &release.Release{
+		Name:      model.Name.ValueString(),
+		Namespace: model.Namespace.ValueString(),
+		Version:   revision,
+		Chart: &chart.Chart{
+			Metadata: &chart.Metadata{
+				APIVersion: chart.APIVersionV2,
+				Name:       chartName,
+				Version:    version,
+				AppVersion: version,
+			},
+		},
+		Config: values,
+		Info: &release.Info{
+			FirstDeployed: deployed,
+			LastDeployed:  deployed,
+			Status:        release.StatusDeployed,
+			Description:   model.Description.ValueString(),
+		},
+	}, diags
+}
+
+// mockRevision returns the revision of the release of model, 0 when it has none
+func mockRevision(model *HelmReleaseModel) int {
+	if model == nil || model.Metadata.IsNull() || model.Metadata.IsUnknown() {
+		return 0
+	}
+	revision, ok := model.Metadata.Attributes()["revision"].(types.Int64)
+	if !ok {
+		return 0
+	}
+	return int(revision.ValueInt64())
+}
+
+// mockPlan plans the release of plan in mock mode. The attributes computed from the release are
+// fabricated as they are on apply, kept from the state when the chart and the values do not
+// change, and left unknown while the chart or the values are.
+func mockPlan(ctx context.Context, plan, state *HelmReleaseModel, meta *Meta) diag.Diagnostics {
+	plan.Status = types.StringValue(release.StatusDeployed.String())
+	plan.ChartDigest = chartDigest(plan)
+	if !meta.FeatureEnabled(featureManifestDiff) {
+		setManifest(plan, types.StringNull())
+	}
+
+	if state != nil {
+		changed := recomputeMetadata(*plan, state) || !plan.Version.IsUnknown() && !versionsEqual(plan.Version.ValueString(), state.Version.ValueString())
+		if plan.Paused.ValueBool() || !changed {
+			*plan = pausedState(*plan, *state)
+			return nil
+		}
+	}
+	if plan.Name.IsUnknown() || plan.Namespace.IsUnknown() || plan.Chart.IsUnknown() || valuesUnknown(*plan) {
+		return nil
+	}
+	return mockApply(ctx, plan, state, meta)
+}
+
+// mockApply fabricates the release of plan in mock mode, unless it was planned already
+func mockApply(ctx context.Context, plan, state *HelmReleaseModel, meta *Meta) diag.Diagnostics {
+	if !plan.Metadata.IsUnknown() {
+		return nil
+	}
+	r, diags := mockRelease(ctx, plan, meta, mockRevision(state)+1)
+	if diags.HasError() {
+		return diags
+	}
+	// values_from is not read in mock mode
+	diags.Append(setReleaseAttributes(ctx, plan, r, meta, nil)...)
+	if diags.HasError() {
+		return diags
+	}
+	if plan.ChangeSummary.IsUnknown() {
+		plan.ChangeSummary = types.StringValue(changeSummary(state, plan, r.Chart.Metadata.Name))
+	}
+	return diags
+}
@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestMockRelease(t *testing.T) {
+	cases := []struct {
+		chart, version     string
+		name, chartVersion string
+	}{
+		{"test-chart", "1.2.3", "test-chart", "1.2.3"},
+		{"stable/redis", "v2.0.0", "redis", "2.0.0"},
+		{"oci://registry.example.com/charts/app", "", "app", mockChartVersion},
+		{"./charts/local/", "", "local", mockChartVersion},
+		{"https://example.com/charts/app-1.0.0.tgz", "", "app-1.0.0", mockChartVersion},
+	}
+	for _, c := range cases {
+		t.Run(c.chart, func(t *testing.T) {
+			model := &HelmReleaseModel{
+				Name:      types.StringValue("test"),
+				Namespace: types.StringValue("default"),
+				Chart:     types.StringValue(c.chart),
+				Version:   types.StringValue(c.version),
+				Values:    types.ListValueMust(types.StringType, []attr.Value{types.StringValue("foo: bar\n")}),
+			}
+			r, diags := mockRelease(context.Background(), model, &Meta{Mock: true}, 3)
+			require.False(t, diags.HasError(), diags)
+
+			assert.Equal(t, "test", r.Name)
+			assert.Equal(t, "default", r.Namespace)
+			assert.Equal(t, 3, r.Version)
+			assert.Equal(t, c.name, r.Chart.Metadata.Name)
+			assert.Equal(t, c.chartVersion, r.Chart.Metadata.Version)
+			assert.Equal(t, map[string]interface{}{"foo": "bar"}, r.Config)
+			assert.Equal(t, release.StatusDeployed, r.Info.Status)
+			assert.Equal(t, int64(0), r.Info.LastDeployed.Unix())
+			assert.Empty(t, r.Manifest)
+		})
+	}
+}
+
+func TestMockRevision(t *testing.T) {
+	assert.Equal(t, 0, mockRevision(nil))
+	assert.Equal(t, 0, mockRevision(&HelmReleaseModel{Metadata: types.ObjectUnknown(metadataAttrTypes())}))
+
+	attrs := map[string]attr.Value{}
+	for k, v := range metadataAttrTypes() {
+		attrs[k] = types.StringNull()
+		if v == types.Int64Type {
+			attrs[k] = types.Int64Null()
+		}
+		if l, ok := v.(types.ListType); ok {
+			attrs[k] = types.ListNull(l.ElemType)
+		}
+	}
+	attrs["revision"] = types.Int64Value(4)
+	model := &HelmReleaseModel{Metadata: types.ObjectValueMust(metadataAttrTypes(), attrs)}
+	assert.Equal(t, 4, mockRevision(model))
+}
@@ -0,0 +1,108 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// Annotations Helm sets on the objects of a release
+const (
+	helmReleaseNameAnnotation      = "meta.helm.sh/release-name"
+	helmReleaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
+)
+
+// MoveState moves to helm_release the state of the releases of other Helm providers, and the
+// state of the kubernetes_manifest resources managing an object of a Helm release, with moved
+// blocks. The state is read from the release in the cluster, as it is imported.
+func (r *HelmRelease) MoveState(ctx context.Context) []resource.StateMover {
+	return []resource.StateMover{
+		{StateMover: r.moveHelmRelease},
+		{StateMover: r.moveKubernetesManifest},
+	}
+}
+
+// moveHelmRelease moves the state of the helm_release resources of other providers, e.g. forks
+// of this provider, which identify releases by their name and namespace
+func (r *HelmRelease) moveHelmRelease(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse) {
+	if req.SourceTypeName != "helm_release" || req.SourceRawState == nil {
+		return
+	}
+	var source struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	}
+	if err := json.Unmarshal(req.SourceRawState.JSON, &source); err != nil || source.Name == "" {
+		resp.Diagnostics.AddError("Unable to move resource state",
+			fmt.Sprintf("The state of the %s resource of %s has no release name: %v", req.SourceTypeName, req.SourceProviderAddress, err))
+		return
+	}
+	if source.Namespace == "" {
+		source.Namespace = "default"
+	}
+	resp.Diagnostics.Append(r.importRelease(ctx, "", source.Namespace, source.Name, &resp.TargetState)...)
+}
+
+// moveKubernetesManifest moves the state of a kubernetes_manifest resource managing an object
+// of a Helm release, found with the annotations Helm sets on the objects of releases, to the
+// state of the release. The other objects of the release are not managed by Terraform anymore
+// once the release is.
+func (r *HelmRelease) moveKubernetesManifest(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse) {
+	if req.SourceTypeName != "kubernetes_manifest" || req.SourceRawState == nil {
+		return
+	}
+	var source map[string]json.RawMessage
+	if err := json.Unmarshal(req.SourceRawState.JSON, &source); err != nil {
+		resp.Diagnostics.AddError("Unable to move resource state", fmt.Sprintf("Could not unmarshal the state of the kubernetes_manifest resource: %s", err))
+		return
+	}
+
+	var annotations map[string]string
+	for _, attr := range []string{"object", "manifest"} {
+		if annotations = manifestAnnotations(source[attr]); annotations[helmReleaseNameAnnotation] != "" {
+			break
+		}
+	}
+	name, namespace := annotations[helmReleaseNameAnnotation], annotations[helmReleaseNamespaceAnnotation]
+	if name == "" || namespace == "" {
+		resp.Diagnostics.AddError("Unable to move resource state",
+			fmt.Sprintf("The object of the kubernetes_manifest resource does not belong to a Helm release, it has no %s and %s annotations. "+
+				"Only the objects of an existing release can be moved to a helm_release resource, install the release with the object and remove the kubernetes_manifest resource from the state with a removed block instead.",
+				helmReleaseNameAnnotation, helmReleaseNamespaceAnnotation))
+		return
+	}
+	resp.Diagnostics.Append(r.importRelease(ctx, "", namespace, name, &resp.TargetState)...)
+}
+
+// manifestAnnotations returns the annotations of the object of the manifest or object attribute
+// of a kubernetes_manifest resource. Their values are dynamic, they are stored with their type.
+func manifestAnnotations(raw json.RawMessage) map[string]string {
+	var value struct {
+		Value json.RawMessage `json:"value"`
+		Type  json.RawMessage `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &value); err == nil && len(value.Value) > 0 && len(value.Type) > 0 {
+		raw = value.Value
+	}
+	var obj struct {
+		Metadata struct {
+			Annotations map[string]interface{} `json:"annotations"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil
+	}
+	annotations := map[string]string{}
+	for k, v := range obj.Metadata.Annotations {
+		if s, ok := v.(string); ok {
+			annotations[k] = strings.TrimSpace(s)
+		}
+	}
+	return annotations
+}
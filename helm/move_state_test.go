@@ -0,0 +1,64 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestAnnotations(t *testing.T) {
+	object := `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"config","annotations":{"meta.helm.sh/release-name":"app","meta.helm.sh/release-namespace":"apps"}}}`
+
+	// the dynamic attributes of kubernetes_manifest are stored with their type
+	dynamic := `{"value":` + object + `,"type":["object",{}]}`
+	for _, raw := range []string{object, dynamic} {
+		annotations := manifestAnnotations(json.RawMessage(raw))
+		assert.Equal(t, "app", annotations[helmReleaseNameAnnotation])
+		assert.Equal(t, "apps", annotations[helmReleaseNamespaceAnnotation])
+	}
+	assert.Empty(t, manifestAnnotations(json.RawMessage(`{"metadata":{"name":"config"}}`)))
+	assert.Empty(t, manifestAnnotations(nil))
+}
+
+func TestMoveState(t *testing.T) {
+	ctx := context.Background()
+	r := &HelmRelease{}
+	move := func(typeName, state string) *resource.MoveStateResponse {
+		resp := &resource.MoveStateResponse{}
+		req := resource.MoveStateRequest{
+			SourceTypeName:        typeName,
+			SourceProviderAddress: "registry.terraform.io/hashicorp/kubernetes",
+			SourceRawState:        &tfprotov6.RawState{JSON: []byte(state)},
+		}
+		for _, mover := range r.MoveState(ctx) {
+			mover.StateMover(ctx, req, resp)
+		}
+		return resp
+	}
+
+	// other resources are not moved by helm_release
+	resp := move("kubernetes_deployment", `{"metadata":[{"name":"web"}]}`)
+	assert.False(t, resp.Diagnostics.HasError())
+
+	// objects which do not belong to a release cannot be moved
+	resp = move("kubernetes_manifest", `{"manifest":{"value":{"metadata":{"name":"config"}},"type":["object",{}]}}`)
+	require.True(t, resp.Diagnostics.HasError())
+	assert.Contains(t, resp.Diagnostics[0].Detail(), "removed block")
+
+	// the release of the object is read from the cluster, with the provider configuration
+	resp = move("kubernetes_manifest", `{"object":{"metadata":{"name":"config","annotations":{"meta.helm.sh/release-name":"app","meta.helm.sh/release-namespace":"apps"}}}}`)
+	require.True(t, resp.Diagnostics.HasError())
+	assert.Equal(t, "Meta not set", resp.Diagnostics[0].Summary())
+
+	resp = move("helm_release", `{"name":"app","namespace":"apps"}`)
+	require.True(t, resp.Diagnostics.HasError())
+	assert.Equal(t, "Meta not set", resp.Diagnostics[0].Summary())
+}
@@ -0,0 +1,129 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"sigs.k8s.io/yaml"
+)
+
+// manifestNamespaces returns the sorted namespaces of the objects of a manifest. Objects
+// without a namespace are installed in the namespace of the release, which is always included.
+func manifestNamespaces(manifest, releaseNamespace string) ([]string, error) {
+	seen := map[string]bool{releaseNamespace: true}
+	for _, resource := range releaseutil.SplitManifests(manifest) {
+		meta := resourceMeta{}
+		if err := yaml.Unmarshal([]byte(resource), &meta); err != nil {
+			return nil, err
+		}
+		if ns := meta.Metadata.Namespace; ns != "" {
+			seen[ns] = true
+		}
+	}
+
+	namespaces := make([]string, 0, len(seen))
+	for ns := range seen {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// crossNamespaceDenied reports whether the objects of the release are restricted to its
+// namespace with allow_cross_namespace
+func crossNamespaceDenied(model *HelmReleaseModel) bool {
+	return !model.AllowCrossNamespace.IsNull() && !model.AllowCrossNamespace.ValueBool()
+}
+
+// crossNamespaceError returns an error when namespaces contain other namespaces than the
+// namespace of the release, what describes the objects found in them
+func crossNamespaceError(what string, namespaces []string, releaseNamespace string) error {
+	var others []string
+	for _, ns := range namespaces {
+		if ns != releaseNamespace {
+			others = append(others, ns)
+		}
+	}
+	if len(others) == 0 {
+		return nil
+	}
+	return fmt.Errorf("the chart renders %s in namespaces %s outside of the release namespace %q, set allow_cross_namespace to true to allow it",
+		what, strings.Join(others, ", "), releaseNamespace)
+}
+
+// namespaceGuard is a post-renderer failing when the rendered manifests contain objects
+// outside of the namespace of the release. The manifests are returned unchanged.
+type namespaceGuard struct {
+	namespace string
+}
+
+func (g namespaceGuard) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	namespaces, err := manifestNamespaces(renderedManifests.String(), g.namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := crossNamespaceError("objects", namespaces, g.namespace); err != nil {
+		return nil, err
+	}
+	return renderedManifests, nil
+}
+
+// checkHookNamespaces returns an error when the rendered hooks contain objects outside of the
+// namespace of the release. Helm does not pass the hooks to the post-renderers, so that they
+// are not seen by namespaceGuard.
+func checkHookNamespaces(hooks []*release.Hook, releaseNamespace string) error {
+	var manifests []string
+	for _, h := range hooks {
+		manifests = append(manifests, h.Manifest)
+	}
+	namespaces, err := manifestNamespaces(strings.Join(manifests, "\n---\n"), releaseNamespace)
+	if err != nil {
+		return err
+	}
+	return crossNamespaceError("hooks", namespaces, releaseNamespace)
+}
+
+// namespaceGuardKubeClient fails the creation of the objects of the hooks outside of the
+// namespace of the release, before they are run
+type namespaceGuardKubeClient struct {
+	forwardingKubeClient
+	namespace string
+}
+
+// guardHookNamespaces wraps the Kubernetes client of actionConfig to check the namespaces of
+// the hooks with allow_cross_namespace set to false
+func guardHookNamespaces(actionConfig *action.Configuration, model *HelmReleaseModel) {
+	if !crossNamespaceDenied(model) {
+		return
+	}
+	actionConfig.KubeClient = &namespaceGuardKubeClient{
+		forwardingKubeClient: forwardingKubeClient{Interface: actionConfig.KubeClient},
+		namespace:            model.Namespace.ValueString(),
+	}
+}
+
+func (c *namespaceGuardKubeClient) Create(resources kube.ResourceList) (*kube.Result, error) {
+	seen := map[string]bool{}
+	var namespaces []string
+	for _, info := range resources {
+		if hookEvents(info.Object) == nil || info.Namespace == "" || seen[info.Namespace] {
+			continue
+		}
+		seen[info.Namespace] = true
+		namespaces = append(namespaces, info.Namespace)
+	}
+	sort.Strings(namespaces)
+	if err := crossNamespaceError("hooks", namespaces, c.namespace); err != nil {
+		return nil, err
+	}
+	return c.Interface.Create(resources)
+}
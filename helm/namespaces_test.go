@@ -0,0 +1,93 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+const testCrossNamespaceManifest = `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: local
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: explicit
+  namespace: default
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: remote
+  namespace: kube-system
+`
+
+func TestManifestNamespaces(t *testing.T) {
+	namespaces, err := manifestNamespaces(testCrossNamespaceManifest, "default")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"default", "kube-system"}, namespaces)
+
+	namespaces, err = manifestNamespaces("", "default")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"default"}, namespaces)
+}
+
+func TestNamespaceGuard(t *testing.T) {
+	_, err := namespaceGuard{namespace: "default"}.Run(bytes.NewBufferString(testCrossNamespaceManifest))
+	assert.ErrorContains(t, err, "kube-system")
+
+	out, err := namespaceGuard{namespace: "kube-system"}.Run(bytes.NewBufferString("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: local\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: local\n", out.String())
+}
+
+func TestCheckHookNamespaces(t *testing.T) {
+	hooks := []*release.Hook{
+		{Name: "local", Manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: local\n"},
+		{Name: "remote", Manifest: "apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: remote\n  namespace: kube-system\n"},
+	}
+	assert.NoError(t, checkHookNamespaces(hooks[:1], "default"))
+	assert.ErrorContains(t, checkHookNamespaces(hooks, "default"), "hooks in namespaces kube-system")
+	assert.NoError(t, checkHookNamespaces(nil, "default"))
+}
+
+func TestGuardHookNamespaces(t *testing.T) {
+	newActionConfig := func() *action.Configuration {
+		return &action.Configuration{KubeClient: &kubefake.PrintingKubeClient{Out: io.Discard}}
+	}
+
+	// the client is not wrapped when objects are allowed in other namespaces
+	actionConfig := newActionConfig()
+	guardHookNamespaces(actionConfig, &HelmReleaseModel{AllowCrossNamespace: types.BoolNull()})
+	assert.IsType(t, &kubefake.PrintingKubeClient{}, actionConfig.KubeClient)
+
+	actionConfig = newActionConfig()
+	guardHookNamespaces(actionConfig, &HelmReleaseModel{AllowCrossNamespace: types.BoolValue(false), Namespace: types.StringValue("default")})
+	local := testHookObject("Job", "local", "pre-install")
+	local.Namespace = "default"
+	remote := testHookObject("Job", "remote", "pre-install")
+	remote.Namespace = "kube-system"
+	object := testHookObject("ConfigMap", "object", "")
+	object.Namespace = "kube-system"
+
+	_, err := actionConfig.KubeClient.Create(kube.ResourceList{local})
+	assert.NoError(t, err)
+	_, err = actionConfig.KubeClient.Create(kube.ResourceList{local, remote})
+	assert.ErrorContains(t, err, "hooks in namespaces kube-system")
+	// the objects of the release are checked by namespaceGuard
+	_, err = actionConfig.KubeClient.Create(kube.ResourceList{object})
+	assert.NoError(t, err)
+}
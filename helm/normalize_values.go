@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"sigs.k8s.io/yaml"
+)
+
+// normalizeValuesDocument parses a values document into its canonical form: the key order,
+// comments, whitespace and number formats of the YAML are not kept
+func normalizeValuesDocument(doc string) (interface{}, error) {
+	var normalized interface{}
+	if err := yaml.Unmarshal([]byte(doc), &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// normalizedValuesEqual reports whether two values lists hold the same values documents once
+// normalized. The order of the documents matters, later documents override earlier ones.
+func normalizedValuesEqual(a, b types.List) bool {
+	if a.IsUnknown() || b.IsUnknown() || a.IsNull() != b.IsNull() {
+		return false
+	}
+	ae, be := a.Elements(), b.Elements()
+	if len(ae) != len(be) {
+		return false
+	}
+	for i := range ae {
+		as, aok := ae[i].(types.String)
+		bs, bok := be[i].(types.String)
+		if !aok || !bok || as.IsUnknown() || bs.IsUnknown() {
+			return false
+		}
+		an, err := normalizeValuesDocument(as.ValueString())
+		if err != nil {
+			return false
+		}
+		bn, err := normalizeValuesDocument(bs.ValueString())
+		if err != nil {
+			return false
+		}
+		if !reflect.DeepEqual(an, bn) {
+			return false
+		}
+	}
+	return true
+}
+
+// cosmeticValuesChange reports whether the only change of a release with normalize_values is
+// to the formatting of its values documents. Such a change is recorded in the state without
+// upgrading the release, and the attributes computed from the release keep their values.
+func cosmeticValuesChange(ctx context.Context, current tfsdk.State, plan, state HelmReleaseModel) bool {
+	if !plan.NormalizeValues.ValueBool() || plan.Values.Equal(state.Values) || !normalizedValuesEqual(plan.Values, state.Values) {
+		return false
+	}
+	unchanged := pausedState(plan, state)
+	unchanged.Values = state.Values
+	planned := tfsdk.State{Schema: current.Schema}
+	if diags := planned.Set(ctx, &unchanged); diags.HasError() {
+		return false
+	}
+	return planned.Raw.Equal(current.Raw)
+}
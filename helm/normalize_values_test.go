@@ -0,0 +1,56 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizedValuesEqual(t *testing.T) {
+	list := func(docs ...string) types.List {
+		elems := make([]attr.Value, len(docs))
+		for i, d := range docs {
+			elems[i] = types.StringValue(d)
+		}
+		return types.ListValueMust(types.StringType, elems)
+	}
+	values := list("replicaCount: 2\nimage:\n  tag: \"1.0\"\n  pullPolicy: Always\n")
+
+	tests := map[string]struct {
+		other types.List
+		equal bool
+	}{
+		"formatting": {
+			other: list("# image settings\nimage: {pullPolicy: Always, tag: '1.0'}\n\nreplicaCount: 2.0\n"),
+			equal: true,
+		},
+		"value": {
+			other: list("replicaCount: 3\nimage:\n  tag: \"1.0\"\n  pullPolicy: Always\n"),
+		},
+		"type": {
+			other: list("replicaCount: 2\nimage:\n  tag: 1.0\n  pullPolicy: Always\n"),
+		},
+		"documents": {
+			other: list("replicaCount: 2\n", "image:\n  tag: \"1.0\"\n  pullPolicy: Always\n"),
+		},
+		"invalid": {
+			other: list("replicaCount: [2\n"),
+		},
+		"unknown": {
+			other: types.ListUnknown(types.StringType),
+		},
+		"null": {
+			other: types.ListNull(types.StringType),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.equal, normalizedValuesEqual(values, tt.other))
+		})
+	}
+}
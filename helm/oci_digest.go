@@ -0,0 +1,98 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/registry"
+)
+
+// ociDigestPattern matches digest pinned OCI references, e.g. oci://registry/charts/app@sha256:<hex>
+var ociDigestPattern = regexp.MustCompile(`^(oci://[^@]+)@(sha256:[a-f0-9]{64})$`)
+
+// splitOCIDigest splits a digest pinned OCI reference into the reference without the digest and the digest
+func splitOCIDigest(ref string) (string, string, bool) {
+	m := ociDigestPattern.FindStringSubmatch(ref)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// ociChartReference returns the OCI reference of the chart when the chart or the repository
+// attribute of the release is pinned by digest. The digest may be set on the chart, e.g.
+// chart = "app@sha256:<hex>", or on the repository, in which case the repository is the
+// full reference of the chart and the chart attribute must be its name.
+func ociChartReference(chart, repository string) (string, bool, error) {
+	if ref, _, ok := splitOCIDigest(repository); ok {
+		if chart != "" && pathpkg.Base(ref) != chart {
+			return "", false, fmt.Errorf("chart %q does not match the digest pinned repository %q", chart, repository)
+		}
+		return repository, true, nil
+	}
+	if registry.IsOCI(repository) {
+		chart = strings.TrimSuffix(repository, "/") + "/" + chart
+	}
+	if _, _, ok := splitOCIDigest(chart); ok {
+		return chart, true, nil
+	}
+	return "", false, nil
+}
+
+// chartDigest returns the digest the chart of the release is pinned to
+func chartDigest(model *HelmReleaseModel) types.String {
+	if model.Chart.IsUnknown() || model.Repository.IsUnknown() {
+		return types.StringUnknown()
+	}
+	ref, ok, err := ociChartReference(model.Chart.ValueString(), model.Repository.ValueString())
+	if err != nil || !ok {
+		return types.StringNull()
+	}
+	_, digest, _ := splitOCIDigest(ref)
+	return types.StringValue(digest)
+}
+
+// pullOCIDigest pulls a digest pinned chart into the repository cache and returns its path.
+// LocateChart only supports tags, and appends the version to the reference.
+func (m *Meta) pullOCIDigest(ctx context.Context, ref string, plainHTTP, insecureSkipTLSVerify bool) (string, error) {
+	base, digest, ok := splitOCIDigest(ref)
+	if !ok {
+		return "", fmt.Errorf("%q is not a digest pinned OCI reference", ref)
+	}
+	registryClient := m.ociRegistryClient(ref, plainHTTP, insecureSkipTLSVerify)
+	if registryClient == nil {
+		return "", fmt.Errorf("registry client is not configured")
+	}
+
+	subsystemDebug(ctx, logSubsystemDownload, fmt.Sprintf("Pulling chart %s by digest %s", base, digest))
+	result, err := registryClient.Pull(strings.TrimPrefix(ref, fmt.Sprintf("%s://", registry.OCIScheme)))
+	if err != nil {
+		return "", fmt.Errorf("failed to pull %s: %w", ref, err)
+	}
+	if result.Manifest == nil || result.Manifest.Digest != digest {
+		return "", fmt.Errorf("digest mismatch for %s: registry returned manifest %v", ref, result.Manifest)
+	}
+
+	dir := m.Settings.RepositoryCache
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	name := pathpkg.Base(base)
+	if result.Chart.Meta != nil {
+		name = result.Chart.Meta.Name
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.tgz", name, strings.TrimPrefix(digest, "sha256:")))
+	if err := os.WriteFile(path, result.Chart.Data, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
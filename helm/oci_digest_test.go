@@ -0,0 +1,60 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestSplitOCIDigest(t *testing.T) {
+	base, digest, ok := splitOCIDigest("oci://registry.example.com/charts/app@" + testDigest)
+	assert.True(t, ok)
+	assert.Equal(t, "oci://registry.example.com/charts/app", base)
+	assert.Equal(t, testDigest, digest)
+
+	for _, ref := range []string{
+		"oci://registry.example.com/charts/app",
+		"oci://registry.example.com/charts/app:1.2.3",
+		"oci://registry.example.com/charts/app@sha256:abc",
+		"https://charts.example.com/app@" + testDigest,
+	} {
+		_, _, ok := splitOCIDigest(ref)
+		assert.False(t, ok, ref)
+	}
+}
+
+func TestOCIChartReference(t *testing.T) {
+	tests := []struct {
+		chart      string
+		repository string
+		ref        string
+		pinned     bool
+		err        bool
+	}{
+		// digest on the chart
+		{chart: "app@" + testDigest, repository: "oci://registry.example.com/charts", ref: "oci://registry.example.com/charts/app@" + testDigest, pinned: true},
+		{chart: "oci://registry.example.com/charts/app@" + testDigest, ref: "oci://registry.example.com/charts/app@" + testDigest, pinned: true},
+		// digest on the repository
+		{chart: "app", repository: "oci://registry.example.com/charts/app@" + testDigest, ref: "oci://registry.example.com/charts/app@" + testDigest, pinned: true},
+		{chart: "other", repository: "oci://registry.example.com/charts/app@" + testDigest, err: true},
+		// not pinned
+		{chart: "app", repository: "oci://registry.example.com/charts"},
+		{chart: "app", repository: "https://charts.example.com"},
+	}
+
+	for _, tc := range tests {
+		ref, pinned, err := ociChartReference(tc.chart, tc.repository)
+		if tc.err {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, tc.pinned, pinned)
+		assert.Equal(t, tc.ref, ref)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// operationConflictMessage is the message of the unexported error returned by Helm when the
+// last revision of a release is pending
+const operationConflictMessage = "another operation (install/upgrade/rollback) is in progress"
+
+var (
+	operationConflictMinBackoff = time.Second
+	operationConflictMaxBackoff = 30 * time.Second
+)
+
+func isOperationConflict(err error) bool {
+	return err != nil && strings.Contains(err.Error(), operationConflictMessage)
+}
+
+// retryOperationConflict runs fn until it does not fail because another operation is in
+// progress on the release, with an exponential backoff, for at most timeout. A timeout of
+// zero runs fn once.
+func retryOperationConflict(ctx context.Context, name string, timeout time.Duration, fn func() (*release.Release, error)) (*release.Release, error) {
+	deadline := time.Now().Add(timeout)
+	backoff := operationConflictMinBackoff
+	for {
+		rel, err := fn()
+		if !isOperationConflict(err) {
+			return rel, err
+		}
+
+		wait := backoff
+		if remaining := time.Until(deadline); remaining < wait {
+			wait = remaining
+		}
+		if wait <= 0 {
+			if timeout > 0 {
+				return rel, fmt.Errorf("%w (gave up after %s)", err, timeout)
+			}
+			return rel, err
+		}
+
+		tflog.Info(ctx, fmt.Sprintf("Another operation is in progress on release %s, retrying in %s", name, wait))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return rel, err
+		}
+
+		backoff *= 2
+		if backoff > operationConflictMaxBackoff {
+			backoff = operationConflictMaxBackoff
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestRetryOperationConflict(t *testing.T) {
+	operationConflictMinBackoff = time.Millisecond
+	defer func() { operationConflictMinBackoff = time.Second }()
+
+	conflict := errors.New("UPGRADE FAILED: " + operationConflictMessage)
+	ctx := context.Background()
+
+	calls := 0
+	rel, err := retryOperationConflict(ctx, "test", time.Minute, func() (*release.Release, error) {
+		calls++
+		if calls < 3 {
+			return nil, conflict
+		}
+		return &release.Release{Name: "test"}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "test", rel.Name)
+	assert.Equal(t, 3, calls)
+
+	// without a timeout the conflict is returned immediately
+	calls = 0
+	_, err = retryOperationConflict(ctx, "test", 0, func() (*release.Release, error) {
+		calls++
+		return nil, conflict
+	})
+	assert.ErrorIs(t, err, conflict)
+	assert.Equal(t, 1, calls)
+
+	// other errors are not retried
+	calls = 0
+	_, err = retryOperationConflict(ctx, "test", time.Minute, func() (*release.Release, error) {
+		calls++
+		return nil, errors.New("chart not found")
+	})
+	assert.EqualError(t, err, "chart not found")
+	assert.Equal(t, 1, calls)
+
+	// the conflict is returned once the timeout elapsed
+	_, err = retryOperationConflict(ctx, "test", 20*time.Millisecond, func() (*release.Release, error) {
+		return nil, conflict
+	})
+	assert.ErrorIs(t, err, conflict)
+}
@@ -0,0 +1,53 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// outOfBandChanges lists the changes of the release made outside of Terraform, e.g. with the
+// helm CLI, since the state was saved: new revisions, and a chart version or values that do
+// not match the state. The values at the ignored paths are not compared.
+func outOfBandChanges(state *HelmReleaseModel, r *release.Release, ignoredPaths []string) []string {
+	var changes []string
+
+	if !state.Metadata.IsNull() && !state.Metadata.IsUnknown() {
+		if revision, ok := state.Metadata.Attributes()["revision"].(types.Int64); ok && !revision.IsNull() && int64(r.Version) > revision.ValueInt64() {
+			changes = append(changes, fmt.Sprintf("revision %d was deployed after revision %d", r.Version, revision.ValueInt64()))
+		}
+	}
+	if r.Chart != nil && r.Chart.Metadata != nil && !state.Version.IsNull() && !versionsEqual(state.Version.ValueString(), r.Chart.Metadata.Version) {
+		changes = append(changes, fmt.Sprintf("the chart version changed from %s to %s", state.Version.ValueString(), r.Chart.Metadata.Version))
+	}
+	if !state.ValuesChecksum.IsNull() && !state.ValuesChecksum.IsUnknown() {
+		if checksum, err := ignoredValuesChecksum(r.Config, ignoredPaths); err == nil && checksum != state.ValuesChecksum.ValueString() {
+			changes = append(changes, "the values changed")
+		}
+	}
+	return changes
+}
+
+// outOfBandChangeDiagnostic warns about the changes of the release made outside of Terraform,
+// identifying the last deployment with the details Helm records
+func outOfBandChangeDiagnostic(r *release.Release, changes []string) diag.Diagnostic {
+	deployed := "Its last deployment"
+	if r.Info != nil {
+		deployed = fmt.Sprintf("Revision %d was deployed at %s", r.Version, r.Info.LastDeployed.UTC().Format(time.RFC3339))
+		if r.Info.Description != "" {
+			deployed += fmt.Sprintf(" with the description %q", r.Info.Description)
+		}
+	}
+	return diag.NewWarningDiagnostic(
+		"Release changed outside of Terraform",
+		fmt.Sprintf("Helm release %s/%s was changed outside of Terraform: %s. %s. Applying the configuration overwrites these changes.",
+			r.Namespace, r.Name, strings.Join(changes, ", "), deployed),
+	)
+}
@@ -0,0 +1,77 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
+)
+
+func TestOutOfBandChanges(t *testing.T) {
+	config := map[string]interface{}{"foo": "bar"}
+	checksum, err := valuesChecksum(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata := func(revision int64) types.Object {
+		attrs := map[string]attr.Value{}
+		for k, v := range metadataAttrTypes() {
+			attrs[k] = types.StringNull()
+			if v == types.Int64Type {
+				attrs[k] = types.Int64Null()
+			}
+			if l, ok := v.(types.ListType); ok {
+				attrs[k] = types.ListNull(l.ElemType)
+			}
+		}
+		attrs["revision"] = types.Int64Value(revision)
+		return types.ObjectValueMust(metadataAttrTypes(), attrs)
+	}
+	state := &HelmReleaseModel{
+		Metadata:       metadata(1),
+		Version:        types.StringValue("1.2.3"),
+		ValuesChecksum: types.StringValue(checksum),
+	}
+	r := func(revision int, version string, config map[string]interface{}) *release.Release {
+		return &release.Release{
+			Name:      "test",
+			Namespace: "default",
+			Version:   revision,
+			Chart:     &chart.Chart{Metadata: &chart.Metadata{Name: "test-chart", Version: version}},
+			Config:    config,
+			Info: &release.Info{
+				LastDeployed: helmtime.Time{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+				Description:  "Upgrade complete",
+			},
+		}
+	}
+
+	assert.Empty(t, outOfBandChanges(state, r(1, "1.2.3", config), nil))
+	assert.Empty(t, outOfBandChanges(state, r(1, "v1.2.3", config), nil))
+
+	changes := outOfBandChanges(state, r(2, "2.0.0", map[string]interface{}{"foo": "baz"}), nil)
+	assert.Equal(t, []string{
+		"revision 2 was deployed after revision 1",
+		"the chart version changed from 1.2.3 to 2.0.0",
+		"the values changed",
+	}, changes)
+
+	d := outOfBandChangeDiagnostic(r(2, "2.0.0", config), changes)
+	assert.Equal(t, "Release changed outside of Terraform", d.Summary())
+	assert.Contains(t, d.Detail(), `Revision 2 was deployed at 2024-01-02T03:04:05Z with the description "Upgrade complete"`)
+
+	// a state without metadata or checksum, e.g. from an older version, is not compared
+	assert.Empty(t, outOfBandChanges(&HelmReleaseModel{
+		Metadata:       types.ObjectNull(metadataAttrTypes()),
+		Version:        types.StringValue("2.0.0"),
+		ValuesChecksum: types.StringNull(),
+	}, r(2, "2.0.0", config), nil))
+}
@@ -0,0 +1,137 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/kube"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// Annotations Helm records the owner release of an object with
+const (
+	releaseNameAnnotation      = "meta.helm.sh/release-name"
+	releaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
+)
+
+// ownershipConflict is an object of a release which belongs to another Helm release
+type ownershipConflict struct {
+	object           string
+	release          string
+	releaseNamespace string
+}
+
+func (c ownershipConflict) String() string {
+	return fmt.Sprintf("%s belongs to release %s in namespace %s", c.object, c.release, c.releaseNamespace)
+}
+
+// objectOwnershipConflict returns the release owning obj when it is not the release name in
+// namespace. Objects not annotated by Helm are left to Helm, which refuses to adopt them.
+func objectOwnershipConflict(obj runtime.Object, object, name, namespace string) (ownershipConflict, bool, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return ownershipConflict{}, false, err
+	}
+	annotations := accessor.GetAnnotations()
+	owner := annotations[releaseNameAnnotation]
+	ownerNamespace := annotations[releaseNamespaceAnnotation]
+	if owner == "" || (owner == name && ownerNamespace == namespace) {
+		return ownershipConflict{}, false, nil
+	}
+	return ownershipConflict{object: object, release: owner, releaseNamespace: ownerNamespace}, true, nil
+}
+
+// ownershipConflicts returns the objects of the manifest which exist in the cluster and belong
+// to another release than the release name in namespace
+func ownershipConflicts(kubeClient kube.Interface, manifest, name, namespace string) ([]ownershipConflict, error) {
+	resources, err := kubeClient.Build(bytes.NewBufferString(manifest), false)
+	if err != nil {
+		return nil, err
+	}
+	var conflicts []ownershipConflict
+	err = resources.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		existing, err := resource.NewHelper(info.Client, info.Mapping).Get(info.Namespace, info.Name)
+		if apierrors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("getting %s: %w", objectKey(info), err)
+		}
+		conflict, ok, err := objectOwnershipConflict(existing, objectKey(info), name, namespace)
+		if err != nil {
+			return fmt.Errorf("reading the metadata of %s: %w", objectKey(info), err)
+		}
+		if ok {
+			conflicts = append(conflicts, conflict)
+		}
+		return nil
+	})
+	return conflicts, err
+}
+
+// ownershipConflictsDiagnostics reports the objects of a release owned by other releases
+func ownershipConflictsDiagnostics(kubeClient kube.Interface, manifest, name, namespace string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	conflicts, err := ownershipConflicts(kubeClient, manifest, name, namespace)
+	if err != nil {
+		diags.AddError("Error checking ownership conflicts", fmt.Sprintf("Unable to check the objects of release %s: %s", name, err))
+		return diags
+	}
+	if len(conflicts) == 0 {
+		return diags
+	}
+	lines := make([]string, 0, len(conflicts))
+	for _, c := range conflicts {
+		lines = append(lines, c.String())
+	}
+	diags.AddError(
+		fmt.Sprintf("Release %s conflicts with other releases", name),
+		fmt.Sprintf("The following objects of release %s in namespace %s belong to other Helm releases and were left unchanged:\n\n- %s\n\nUninstall the other releases or remove the objects from one of the charts.", name, namespace, strings.Join(lines, "\n- ")),
+	)
+	return diags
+}
+
+// installOwnershipConflicts renders the release of the install client with a client-side dry
+// run and checks that its objects do not belong to other releases
+func installOwnershipConflicts(ctx context.Context, actionConfig *action.Configuration, client *action.Install, c *chart.Chart, values map[string]interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client.DryRun = true
+	client.DryRunOption = dryRunModeClient
+	dry, err := client.RunWithContext(ctx, c, values)
+	client.DryRun = false
+	client.DryRunOption = ""
+	if err != nil {
+		diags.AddError("Error checking ownership conflicts", fmt.Sprintf("Unable to render release %s: %s", client.ReleaseName, err))
+		return diags
+	}
+	return ownershipConflictsDiagnostics(actionConfig.KubeClient, dry.Manifest, client.ReleaseName, client.Namespace)
+}
+
+// upgradeOwnershipConflicts renders the release of the upgrade client with a client-side dry
+// run and checks that its objects do not belong to other releases
+func upgradeOwnershipConflicts(ctx context.Context, actionConfig *action.Configuration, client *action.Upgrade, name string, c *chart.Chart, values map[string]interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client.DryRun = true
+	client.DryRunOption = dryRunModeClient
+	dry, err := client.RunWithContext(ctx, name, c, values)
+	client.DryRun = false
+	client.DryRunOption = ""
+	if err != nil {
+		diags.AddError("Error checking ownership conflicts", fmt.Sprintf("Unable to render release %s: %s", name, err))
+		return diags
+	}
+	return ownershipConflictsDiagnostics(actionConfig.KubeClient, dry.Manifest, name, client.Namespace)
+}
@@ -0,0 +1,50 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestObjectOwnershipConflict(t *testing.T) {
+	object := func(annotations map[string]string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("v1")
+		u.SetKind("ConfigMap")
+		u.SetName("settings")
+		u.SetAnnotations(annotations)
+		return u
+	}
+
+	tests := map[string]struct {
+		annotations map[string]string
+		conflict    bool
+	}{
+		"unmanaged":       {annotations: nil},
+		"same release":    {annotations: map[string]string{releaseNameAnnotation: "web", releaseNamespaceAnnotation: "default"}},
+		"other release":   {annotations: map[string]string{releaseNameAnnotation: "api", releaseNamespaceAnnotation: "default"}, conflict: true},
+		"other namespace": {annotations: map[string]string{releaseNameAnnotation: "web", releaseNamespaceAnnotation: "staging"}, conflict: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			conflict, ok, err := objectOwnershipConflict(object(tt.annotations), "configmap/default/settings", "web", "default")
+			require.NoError(t, err)
+			assert.Equal(t, tt.conflict, ok)
+			if tt.conflict {
+				assert.Equal(t, ownershipConflict{
+					object:           "configmap/default/settings",
+					release:          tt.annotations[releaseNameAnnotation],
+					releaseNamespace: tt.annotations[releaseNamespaceAnnotation],
+				}, conflict)
+			}
+		})
+	}
+
+	c := ownershipConflict{object: "configmap/default/settings", release: "api", releaseNamespace: "default"}
+	assert.Equal(t, "configmap/default/settings belongs to release api in namespace default", c.String())
+}
@@ -0,0 +1,181 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// PatchModel is a patch of the objects of the rendered manifests of a release
+type PatchModel struct {
+	JSONPatch           types.String `tfsdk:"json_patch"`
+	Kind                types.String `tfsdk:"kind"`
+	Name                types.String `tfsdk:"name"`
+	Namespace           types.String `tfsdk:"namespace"`
+	StrategicMergePatch types.String `tfsdk:"strategic_merge_patch"`
+}
+
+func patchAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"json_patch":            types.StringType,
+		"kind":                  types.StringType,
+		"name":                  types.StringType,
+		"namespace":             types.StringType,
+		"strategic_merge_patch": types.StringType,
+	}
+}
+
+// manifestPatch is a patch of the objects of a kind, and optionally of a name and namespace
+type manifestPatch struct {
+	kind      string
+	name      string
+	namespace string
+	// RFC 6902 JSON patch
+	jsonPatch jsonpatch.Patch
+	// strategic merge patch, as JSON
+	strategicMergePatch []byte
+}
+
+// matches reports whether the patch targets the object of the given kind, name and namespace
+func (p manifestPatch) matches(kind, name, namespace string) bool {
+	return p.kind == kind && (p.name == "" || p.name == name) && (p.namespace == "" || p.namespace == namespace)
+}
+
+// apply patches the object, as JSON. Strategic merge patches of kinds unknown to the
+// Kubernetes client, e.g. custom resources, are applied as JSON merge patches, like kustomize.
+func (p manifestPatch) apply(object []byte, gvk schema.GroupVersionKind) ([]byte, error) {
+	if p.jsonPatch != nil {
+		return p.jsonPatch.Apply(object)
+	}
+	dataStruct, err := scheme.Scheme.New(gvk)
+	if runtime.IsNotRegisteredError(err) {
+		return jsonpatch.MergePatch(object, p.strategicMergePatch)
+	} else if err != nil {
+		return nil, err
+	}
+	return strategicpatch.StrategicMergePatch(object, p.strategicMergePatch, dataStruct)
+}
+
+// manifestPatches is a post-renderer applying the patches of a release to the objects of the
+// rendered manifests, like the patches of kustomize. Objects without a namespace are in the
+// namespace of the release.
+type manifestPatches struct {
+	namespace string
+	patches   []manifestPatch
+}
+
+func (m manifestPatches) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	manifests := releaseutil.SplitManifests(renderedManifests.String())
+	keys := make([]string, 0, len(manifests))
+	for k := range manifests {
+		keys = append(keys, k)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	matched := make([]bool, len(m.patches))
+	out := &bytes.Buffer{}
+	for _, k := range keys {
+		object, err := yaml.YAMLToJSON([]byte(manifests[k]))
+		if err != nil {
+			return nil, err
+		}
+		var meta struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+			Metadata   struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		}
+		if err := json.Unmarshal(object, &meta); err != nil || meta.Kind == "" {
+			// empty documents are dropped
+			continue
+		}
+		namespace := meta.Metadata.Namespace
+		if namespace == "" {
+			namespace = m.namespace
+		}
+		gvk := schema.FromAPIVersionAndKind(meta.APIVersion, meta.Kind)
+
+		for i, p := range m.patches {
+			if !p.matches(meta.Kind, meta.Metadata.Name, namespace) {
+				continue
+			}
+			matched[i] = true
+			if object, err = p.apply(object, gvk); err != nil {
+				return nil, fmt.Errorf("patch %d of %s %q: %w", i, meta.Kind, meta.Metadata.Name, err)
+			}
+		}
+
+		b, err := yaml.JSONToYAML(object)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString("---\n")
+		out.Write(b)
+	}
+	for i, ok := range matched {
+		if !ok {
+			return nil, fmt.Errorf("patch %d matches no object of kind %s", i, m.patches[i].kind)
+		}
+	}
+	return out, nil
+}
+
+// newManifestPatches decodes the patches attribute of the release of model
+func newManifestPatches(ctx context.Context, model *HelmReleaseModel) (*manifestPatches, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if model.Patches.IsNull() || model.Patches.IsUnknown() {
+		return nil, diags
+	}
+	var configs []PatchModel
+	diags.Append(model.Patches.ElementsAs(ctx, &configs, false)...)
+	if diags.HasError() || len(configs) == 0 {
+		return nil, diags
+	}
+
+	patches := &manifestPatches{namespace: model.Namespace.ValueString()}
+	for i, config := range configs {
+		p := manifestPatch{
+			kind:      config.Kind.ValueString(),
+			name:      config.Name.ValueString(),
+			namespace: config.Namespace.ValueString(),
+		}
+		if v := config.JSONPatch.ValueString(); v != "" {
+			b, err := yaml.YAMLToJSON([]byte(v))
+			if err == nil {
+				p.jsonPatch, err = jsonpatch.DecodePatch(b)
+			}
+			if err != nil {
+				diags.AddAttributeError(path.Root("patches").AtListIndex(i).AtName("json_patch"), "Invalid JSON patch", err.Error())
+				continue
+			}
+		} else {
+			b, err := yaml.YAMLToJSON([]byte(config.StrategicMergePatch.ValueString()))
+			if err != nil {
+				diags.AddAttributeError(path.Root("patches").AtListIndex(i).AtName("strategic_merge_patch"), "Invalid strategic merge patch", err.Error())
+				continue
+			}
+			p.strategicMergePatch = b
+		}
+		patches.patches = append(patches.patches, p)
+	}
+	return patches, diags
+}
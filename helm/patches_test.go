@@ -0,0 +1,127 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const patchesManifests = `---
+# Source: chart/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:1.0
+      - name: sidecar
+        image: sidecar:1.0
+---
+# Source: chart/templates/monitor.yaml
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: web
+  namespace: monitoring
+spec:
+  endpoints:
+  - port: http
+`
+
+func testManifestPatches(t *testing.T, patches ...PatchModel) *manifestPatches {
+	t.Helper()
+	ctx := context.Background()
+	list, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: patchAttrTypes()}, patches)
+	require.Empty(t, diags)
+	pr, diags := newManifestPatches(ctx, &HelmReleaseModel{Namespace: types.StringValue("default"), Patches: list})
+	require.Empty(t, diags)
+	return pr
+}
+
+func TestManifestPatches(t *testing.T) {
+	pr := testManifestPatches(t,
+		PatchModel{
+			Kind: types.StringValue("Deployment"),
+			Name: types.StringValue("web"),
+			// the containers are merged by name
+			StrategicMergePatch: types.StringValue("spec:\n  template:\n    spec:\n      containers:\n      - name: sidecar\n        image: sidecar:2.0\n"),
+		},
+		PatchModel{
+			Kind:      types.StringValue("ServiceMonitor"),
+			Namespace: types.StringValue("monitoring"),
+			JSONPatch: types.StringValue(`[{"op": "add", "path": "/spec/endpoints/0/interval", "value": "30s"}]`),
+		},
+	)
+	out, err := pr.Run(bytes.NewBufferString(patchesManifests))
+	require.NoError(t, err)
+	assert.Equal(t, `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - image: web:1.0
+        name: web
+      - image: sidecar:2.0
+        name: sidecar
+---
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: web
+  namespace: monitoring
+spec:
+  endpoints:
+  - interval: 30s
+    port: http
+`, out.String())
+}
+
+func TestManifestPatchesCustomResource(t *testing.T) {
+	// custom resources are merged as JSON merge patches, which replace lists
+	pr := testManifestPatches(t, PatchModel{
+		Kind:                types.StringValue("ServiceMonitor"),
+		StrategicMergePatch: types.StringValue("spec:\n  endpoints:\n  - port: metrics\n"),
+	})
+	out, err := pr.Run(bytes.NewBufferString(patchesManifests))
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "  endpoints:\n  - port: metrics\n")
+}
+
+func TestManifestPatchesErrors(t *testing.T) {
+	// objects without a namespace are in the namespace of the release
+	pr := testManifestPatches(t, PatchModel{
+		Kind:                types.StringValue("Deployment"),
+		Namespace:           types.StringValue("other"),
+		StrategicMergePatch: types.StringValue("metadata:\n  labels:\n    team: web\n"),
+	})
+	_, err := pr.Run(bytes.NewBufferString(patchesManifests))
+	assert.ErrorContains(t, err, "patch 0 matches no object of kind Deployment")
+
+	pr = testManifestPatches(t, PatchModel{
+		Kind:      types.StringValue("Deployment"),
+		JSONPatch: types.StringValue(`[{"op": "remove", "path": "/spec/replicas"}]`),
+	})
+	_, err = pr.Run(bytes.NewBufferString(patchesManifests))
+	assert.ErrorContains(t, err, `patch 0 of Deployment "web"`)
+
+	list, _ := types.ListValueFrom(context.Background(), types.ObjectType{AttrTypes: patchAttrTypes()}, []PatchModel{
+		{Kind: types.StringValue("Deployment"), JSONPatch: types.StringValue(`{"op": "add"}`)},
+	})
+	_, diags := newManifestPatches(context.Background(), &HelmReleaseModel{Patches: list})
+	assert.True(t, diags.HasError())
+}
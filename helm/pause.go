@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+// pausedState returns the state of a paused release after an update. The configuration is
+// recorded but the release is not upgraded, so the attributes computed from the release keep
+// their values, and the changes are rolled out by the update unpausing the release.
+func pausedState(plan, state HelmReleaseModel) HelmReleaseModel {
+	if plan.ID.IsUnknown() {
+		plan.ID = state.ID
+	}
+	if plan.Metadata.IsUnknown() {
+		plan.Metadata = state.Metadata
+	}
+	if plan.Manifest.IsUnknown() {
+		plan.Manifest = state.Manifest
+	}
+	if plan.ManifestSHA256.IsUnknown() {
+		plan.ManifestSHA256 = state.ManifestSHA256
+	}
+	if plan.HookLogs.IsUnknown() {
+		plan.HookLogs = state.HookLogs
+	}
+	if plan.SensitiveHookLogs.IsUnknown() {
+		plan.SensitiveHookLogs = state.SensitiveHookLogs
+	}
+	if plan.HooksManifest.IsUnknown() {
+		plan.HooksManifest = state.HooksManifest
+	}
+	if plan.ValuesChecksum.IsUnknown() {
+		plan.ValuesChecksum = state.ValuesChecksum
+	}
+	if plan.Namespaces.IsUnknown() {
+		plan.Namespaces = state.Namespaces
+	}
+	if plan.EnabledSubcharts.IsUnknown() {
+		plan.EnabledSubcharts = state.EnabledSubcharts
+	}
+	if plan.Endpoints.IsUnknown() {
+		plan.Endpoints = state.Endpoints
+	}
+	if plan.PrunedResources.IsUnknown() {
+		plan.PrunedResources = state.PrunedResources
+	}
+	if plan.ResourceHealth.IsUnknown() {
+		plan.ResourceHealth = state.ResourceHealth
+	}
+	if plan.Services.IsUnknown() {
+		plan.Services = state.Services
+	}
+	if plan.RepositoryMirror.IsUnknown() {
+		plan.RepositoryMirror = state.RepositoryMirror
+	}
+	if plan.ChartDigest.IsUnknown() {
+		plan.ChartDigest = state.ChartDigest
+	}
+	if plan.Verification.IsUnknown() {
+		plan.Verification = state.Verification
+	}
+	if plan.ChangeSummary.IsUnknown() {
+		plan.ChangeSummary = state.ChangeSummary
+	}
+	if plan.OutOfBandChange.IsUnknown() {
+		plan.OutOfBandChange = state.OutOfBandChange
+	}
+	if plan.Version.IsUnknown() {
+		plan.Version = state.Version
+	}
+	if plan.Status.IsUnknown() {
+		plan.Status = state.Status
+	}
+	if plan.Revision.IsUnknown() {
+		plan.Revision = state.Revision
+	}
+	if plan.RevisionHistory.IsUnknown() {
+		plan.RevisionHistory = state.RevisionHistory
+	}
+	if plan.StatusDetail.IsUnknown() {
+		plan.StatusDetail = state.StatusDetail
+	}
+	if plan.StorageNamespace.IsUnknown() {
+		plan.StorageNamespace = state.StorageNamespace
+	}
+	if plan.StorageObjectName.IsUnknown() {
+		plan.StorageObjectName = state.StorageObjectName
+	}
+	return plan
+}
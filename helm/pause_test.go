@@ -0,0 +1,34 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPausedState(t *testing.T) {
+	state := HelmReleaseModel{
+		ID:             types.StringValue("test"),
+		Version:        types.StringValue("1.2.3"),
+		ValuesChecksum: types.StringValue("checksum"),
+		Manifest:       types.StringNull(),
+		Timeout:        types.Int64Value(300),
+	}
+	plan := HelmReleaseModel{
+		ID:             types.StringValue("test"),
+		Version:        types.StringUnknown(),
+		ValuesChecksum: types.StringUnknown(),
+		Manifest:       types.StringUnknown(),
+		Timeout:        types.Int64Value(600),
+	}
+
+	paused := pausedState(plan, state)
+	assert.Equal(t, state.Version, paused.Version)
+	assert.Equal(t, state.ValuesChecksum, paused.ValuesChecksum)
+	assert.Equal(t, state.Manifest, paused.Manifest)
+	assert.Equal(t, plan.Timeout, paused.Timeout)
+}
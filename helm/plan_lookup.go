@@ -0,0 +1,49 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"helm.sh/helm/v3/pkg/action"
+)
+
+// planDryRunMode returns the mode of the dry runs rendering the manifest of model on plan.
+// enable_lookup_during_plan renders the manifest on the server, so that lookup sees the cluster.
+func planDryRunMode(model *HelmReleaseModel) string {
+	if model.EnableLookupDuringPlan.ValueBool() {
+		return dryRunModeServer
+	}
+	return model.DryRunMode.ValueString()
+}
+
+// readOnlyTransport rejects the requests that may modify the cluster, and counts the requests it
+// lets through
+type readOnlyTransport struct {
+	rt       http.RoundTripper
+	requests *atomic.Int64
+}
+
+func (t *readOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		t.requests.Add(1)
+		return t.rt.RoundTrip(req)
+	}
+	return nil, fmt.Errorf("%s %s is not allowed, the Kubernetes client of plan renders is read-only", req.Method, req.URL.Path)
+}
+
+// readOnlyConfiguration makes the Kubernetes clients of actionConfig created from now on
+// read-only, and returns the counter of their requests, nil when actionConfig does not use a
+// KubeConfig
+func readOnlyConfiguration(actionConfig *action.Configuration) *atomic.Int64 {
+	kc, ok := actionConfig.RESTClientGetter.(*KubeConfig)
+	if !ok {
+		return nil
+	}
+	kc.ReadOnlyRequests = new(atomic.Int64)
+	return kc.ReadOnlyRequests
+}
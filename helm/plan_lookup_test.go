@@ -0,0 +1,44 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanDryRunMode(t *testing.T) {
+	assert.Equal(t, dryRunModeClient, planDryRunMode(&HelmReleaseModel{DryRunMode: types.StringValue(dryRunModeClient), EnableLookupDuringPlan: types.BoolValue(false)}))
+	assert.Equal(t, dryRunModeServer, planDryRunMode(&HelmReleaseModel{DryRunMode: types.StringValue(dryRunModeServer), EnableLookupDuringPlan: types.BoolValue(false)}))
+	assert.Equal(t, dryRunModeServer, planDryRunMode(&HelmReleaseModel{DryRunMode: types.StringValue(dryRunModeClient), EnableLookupDuringPlan: types.BoolValue(true)}))
+}
+
+func TestReadOnlyTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	requests := new(atomic.Int64)
+	client := &http.Client{Transport: &readOnlyTransport{rt: http.DefaultTransport, requests: requests}}
+
+	resp, err := client.Get(server.URL + "/api/v1/namespaces/default/configmaps/web")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		req, err := http.NewRequest(method, server.URL+"/api/v1/namespaces/default/configmaps/web", nil)
+		require.NoError(t, err)
+		_, err = client.Do(req)
+		assert.ErrorContains(t, err, "read-only", method)
+	}
+	assert.Equal(t, int64(1), requests.Load())
+}
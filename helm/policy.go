@@ -0,0 +1,277 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"sigs.k8s.io/yaml"
+)
+
+// Enforcement levels of the policy of a release
+const (
+	policyEnforcementDeny = "deny"
+	policyEnforcementWarn = "warn"
+)
+
+// opaBinary is the binary the rego policies are evaluated with
+var opaBinary = "opa"
+
+// PolicyModel configures the checks of the rendered manifests of a release
+type PolicyModel struct {
+	CEL         types.List   `tfsdk:"cel"`
+	Enforcement types.String `tfsdk:"enforcement"`
+	RegoPackage types.String `tfsdk:"rego_package"`
+	RegoPath    types.String `tfsdk:"rego_path"`
+}
+
+// PolicyCELModel is a CEL expression the objects of a release must satisfy
+type PolicyCELModel struct {
+	Expression types.String `tfsdk:"expression"`
+	Message    types.String `tfsdk:"message"`
+}
+
+func policyAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"cel": types.ListType{ElemType: types.ObjectType{AttrTypes: map[string]attr.Type{
+			"expression": types.StringType,
+			"message":    types.StringType,
+		}}},
+		"enforcement":  types.StringType,
+		"rego_package": types.StringType,
+		"rego_path":    types.StringType,
+	}
+}
+
+// policyObject is an object of a rendered manifest the policy is evaluated against
+type policyObject struct {
+	name   string
+	object map[string]interface{}
+}
+
+// manifestPolicyObjects returns the objects of the manifest and of the hooks of a release
+func manifestPolicyObjects(manifest string, hooks []*release.Hook) ([]policyObject, error) {
+	documents := []string{manifest}
+	for _, h := range hooks {
+		documents = append(documents, h.Manifest)
+	}
+
+	var objects []policyObject
+	for _, document := range documents {
+		manifests := releaseutil.SplitManifests(document)
+		keys := make([]string, 0, len(manifests))
+		for k := range manifests {
+			keys = append(keys, k)
+		}
+		sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+		for _, k := range keys {
+			object := map[string]interface{}{}
+			if err := yaml.Unmarshal([]byte(manifests[k]), &object); err != nil {
+				return nil, err
+			}
+			if len(object) == 0 {
+				continue
+			}
+			objects = append(objects, policyObject{name: policyObjectName(object), object: object})
+		}
+	}
+	return objects, nil
+}
+
+// policyObjectName returns the kind/namespace/name of an object in violations
+func policyObjectName(object map[string]interface{}) string {
+	kind, _ := object["kind"].(string)
+	metadata, _ := object["metadata"].(map[string]interface{})
+	namespace, _ := metadata["namespace"].(string)
+	name, _ := metadata["name"].(string)
+	if namespace == "" {
+		return strings.ToLower(kind) + "/" + name
+	}
+	return strings.ToLower(kind) + "/" + namespace + "/" + name
+}
+
+// celRule is a compiled CEL expression of the policy
+type celRule struct {
+	expression string
+	message    string
+	program    cel.Program
+}
+
+// compileCELRules compiles the CEL expressions of the policy. The expressions access the
+// object with the object variable and must return true when the object is allowed.
+func compileCELRules(models []PolicyCELModel) ([]celRule, error) {
+	if len(models) == 0 {
+		return nil, nil
+	}
+	env, err := cel.NewEnv(cel.Variable("object", cel.DynType))
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]celRule, 0, len(models))
+	for _, m := range models {
+		expression := m.Expression.ValueString()
+		ast, issues := env.Compile(expression)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("invalid CEL expression %q: %w", expression, issues.Err())
+		}
+		if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+			return nil, fmt.Errorf("CEL expression %q must return a bool, not %s", expression, ast.OutputType())
+		}
+		program, err := env.Program(ast)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CEL expression %q: %w", expression, err)
+		}
+		message := m.Message.ValueString()
+		if message == "" {
+			message = fmt.Sprintf("failed expression: %s", expression)
+		}
+		rules = append(rules, celRule{expression: expression, message: message, program: program})
+	}
+	return rules, nil
+}
+
+// celViolations returns the messages of the rules the object does not satisfy
+func celViolations(rules []celRule, object policyObject) ([]string, error) {
+	var violations []string
+	for _, rule := range rules {
+		out, _, err := rule.program.Eval(map[string]interface{}{"object": object.object})
+		if err != nil {
+			return nil, fmt.Errorf("evaluating CEL expression %q against %s: %w", rule.expression, object.name, err)
+		}
+		allowed, ok := out.Value().(bool)
+		if !ok {
+			return nil, fmt.Errorf("CEL expression %q returned %v instead of a bool for %s", rule.expression, out.Value(), object.name)
+		}
+		if !allowed {
+			violations = append(violations, rule.message)
+		}
+	}
+	return violations, nil
+}
+
+// opaEvalOutput is the output of opa eval --format json
+type opaEvalOutput struct {
+	Result []struct {
+		Expressions []struct {
+			Value []interface{} `json:"value"`
+		} `json:"expressions"`
+	} `json:"result"`
+}
+
+// regoViolations evaluates the deny rule of the package of the rego policies with the opa
+// binary, with the object as input, the way conftest does. It returns the messages of the rule.
+func regoViolations(ctx context.Context, regoPath, pkg string, object policyObject) ([]string, error) {
+	input, err := json.Marshal(object.object)
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, opaBinary, "eval", "--format", "json", "--data", regoPath, "--stdin-input", fmt.Sprintf("data.%s.deny", pkg))
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("evaluating the rego policies %s against %s: %w: %s", regoPath, object.name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var output opaEvalOutput
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return nil, fmt.Errorf("parsing the output of opa eval: %w", err)
+	}
+	var violations []string
+	for _, result := range output.Result {
+		for _, expression := range result.Expressions {
+			for _, v := range expression.Value {
+				if s, ok := v.(string); ok {
+					violations = append(violations, s)
+				} else {
+					b, _ := json.Marshal(v)
+					violations = append(violations, string(b))
+				}
+			}
+		}
+	}
+	return violations, nil
+}
+
+// checkPolicy evaluates the policy of a release against its rendered manifest and hooks. The
+// violations are reported as errors or warnings depending on the enforcement of the policy.
+func checkPolicy(ctx context.Context, model *HelmReleaseModel, manifest string, hooks []*release.Hook) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if model.Policy.IsNull() || model.Policy.IsUnknown() {
+		return diags
+	}
+	var policy PolicyModel
+	diags.Append(model.Policy.As(ctx, &policy, basetypes.ObjectAsOptions{})...)
+	var celModels []PolicyCELModel
+	if !policy.CEL.IsNull() && !policy.CEL.IsUnknown() {
+		diags.Append(policy.CEL.ElementsAs(ctx, &celModels, false)...)
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	rules, err := compileCELRules(celModels)
+	if err != nil {
+		diags.AddAttributeError(path.Root("policy").AtName("cel"), "Invalid policy", err.Error())
+		return diags
+	}
+	objects, err := manifestPolicyObjects(manifest, hooks)
+	if err != nil {
+		diags.AddError("Error parsing the manifest for the policy", err.Error())
+		return diags
+	}
+
+	regoPath := policy.RegoPath.ValueString()
+	regoPackage := policy.RegoPackage.ValueString()
+	if regoPackage == "" {
+		regoPackage = "main"
+	}
+	var violations []string
+	for _, object := range objects {
+		messages, err := celViolations(rules, object)
+		if err != nil {
+			diags.AddError("Error evaluating the policy", err.Error())
+			return diags
+		}
+		if regoPath != "" {
+			regoMessages, err := regoViolations(ctx, regoPath, regoPackage, object)
+			if err != nil {
+				diags.AddError("Error evaluating the policy", err.Error())
+				return diags
+			}
+			messages = append(messages, regoMessages...)
+		}
+		for _, m := range messages {
+			violations = append(violations, fmt.Sprintf("%s: %s", object.name, m))
+		}
+	}
+	if len(violations) == 0 {
+		return diags
+	}
+
+	summary := fmt.Sprintf("Release %s violates its policy", model.Name.ValueString())
+	detail := "The rendered manifests violate the policy of the release:\n\n- " + strings.Join(violations, "\n- ")
+	if policy.Enforcement.ValueString() == policyEnforcementWarn {
+		diags.AddWarning(summary, detail)
+	} else {
+		diags.AddError(summary, detail)
+	}
+	return diags
+}
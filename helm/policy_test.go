@@ -0,0 +1,111 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+const policyTestManifest = `---
+# Source: chart/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: apps
+spec:
+  type: LoadBalancer
+---
+# Source: chart/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+`
+
+func testPolicyModel(t *testing.T, enforcement, regoPath string, cel ...[2]string) *HelmReleaseModel {
+	celType := policyAttrTypes()["cel"].(types.ListType).ElemType.(types.ObjectType)
+	var rules []attr.Value
+	for _, c := range cel {
+		rule, diags := types.ObjectValue(celType.AttrTypes, map[string]attr.Value{
+			"expression": types.StringValue(c[0]),
+			"message":    types.StringValue(c[1]),
+		})
+		require.False(t, diags.HasError())
+		rules = append(rules, rule)
+	}
+	celList, diags := types.ListValue(celType, rules)
+	require.False(t, diags.HasError())
+	policy, diags := types.ObjectValue(policyAttrTypes(), map[string]attr.Value{
+		"cel":          celList,
+		"enforcement":  types.StringValue(enforcement),
+		"rego_package": types.StringValue("main"),
+		"rego_path":    types.StringValue(regoPath),
+	})
+	require.False(t, diags.HasError())
+	return &HelmReleaseModel{Name: types.StringValue("web"), Policy: policy}
+}
+
+func TestCheckPolicyCEL(t *testing.T) {
+	ctx := context.Background()
+	noLoadBalancer := [2]string{"object.kind != 'Service' || object.spec.type != 'LoadBalancer'", "LoadBalancer services are not allowed"}
+	hooks := []*release.Hook{{Manifest: "apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: migrate\n"}}
+
+	diags := checkPolicy(ctx, testPolicyModel(t, policyEnforcementDeny, "", noLoadBalancer, [2]string{"has(object.metadata.labels)", ""}), policyTestManifest, hooks)
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.SeverityError, diags[0].Severity())
+	assert.Equal(t, "The rendered manifests violate the policy of the release:\n\n"+
+		"- service/apps/web: LoadBalancer services are not allowed\n"+
+		"- service/apps/web: failed expression: has(object.metadata.labels)\n"+
+		"- configmap/config: failed expression: has(object.metadata.labels)\n"+
+		"- job/migrate: failed expression: has(object.metadata.labels)", diags[0].Detail())
+
+	diags = checkPolicy(ctx, testPolicyModel(t, policyEnforcementWarn, "", noLoadBalancer), policyTestManifest, nil)
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.SeverityWarning, diags[0].Severity())
+
+	diags = checkPolicy(ctx, testPolicyModel(t, policyEnforcementDeny, "", [2]string{"object.kind != ", ""}), policyTestManifest, nil)
+	assert.True(t, diags.HasError())
+	diags = checkPolicy(ctx, testPolicyModel(t, policyEnforcementDeny, "", [2]string{"object.kind", ""}), policyTestManifest, nil)
+	require.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Detail(), "instead of a bool")
+
+	// no policy
+	assert.Empty(t, checkPolicy(ctx, &HelmReleaseModel{Policy: types.ObjectNull(policyAttrTypes())}, policyTestManifest, nil))
+}
+
+func TestCheckPolicyRego(t *testing.T) {
+	dir := t.TempDir()
+	// the fake opa binary denies the objects of kind Service
+	script := `#!/bin/sh
+if grep -q '"kind":"Service"' -; then
+  echo '{"result":[{"expressions":[{"value":["services are not allowed"]}]}]}'
+else
+  echo '{"result":[{"expressions":[{"value":[]}]}]}'
+fi
+`
+	opa := filepath.Join(dir, "opa")
+	require.NoError(t, os.WriteFile(opa, []byte(script), 0o755))
+	defer func(b string) { opaBinary = b }(opaBinary)
+	opaBinary = opa
+
+	diags := checkPolicy(context.Background(), testPolicyModel(t, policyEnforcementDeny, dir), policyTestManifest, nil)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "The rendered manifests violate the policy of the release:\n\n- service/apps/web: services are not allowed", diags[0].Detail())
+
+	opaBinary = filepath.Join(dir, "missing")
+	diags = checkPolicy(context.Background(), testPolicyModel(t, policyEnforcementDeny, dir), policyTestManifest, nil)
+	require.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Detail(), "evaluating the rego policies")
+}
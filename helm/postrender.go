@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/postrender"
+)
+
+// chainedPostRenderer runs a list of post-renderers in order, piping the
+// manifests produced by each post-renderer into the next one
+type chainedPostRenderer []postrender.PostRenderer
+
+func (c chainedPostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	out := renderedManifests
+	for i, pr := range c {
+		var err error
+		out, err = pr.Run(out)
+		if err != nil {
+			return nil, fmt.Errorf("post-renderer %d failed: %w", i, err)
+		}
+	}
+	return out, nil
+}
+
+// newPostRenderer builds the post-renderer for the postrender attribute of the release,
+// followed by the checks of the provider. It returns nil when there is nothing to run.
+func newPostRenderer(ctx context.Context, model *HelmReleaseModel) (postrender.PostRenderer, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var configs []PostRenderModel
+	if !model.PostRender.IsNull() && !model.PostRender.IsUnknown() {
+		diags.Append(model.PostRender.ElementsAs(ctx, &configs, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+	}
+
+	var chain chainedPostRenderer
+	for _, config := range configs {
+		binaryPath := config.BinaryPath.ValueString()
+		args := []string{}
+		if !config.Args.IsNull() && !config.Args.IsUnknown() {
+			args = expandStringSlice(config.Args.Elements())
+		}
+
+		tflog.Debug(ctx, fmt.Sprintf("Creating post-renderer with binary path: %s and args: %v", binaryPath, args))
+		pr, err := postrender.NewExec(binaryPath, args...)
+		if err != nil {
+			diags.AddError("Error creating post-renderer", fmt.Sprintf("Could not create post-renderer: %s", err))
+			return nil, diags
+		}
+		chain = append(chain, pr)
+	}
+
+	// the patches are applied to the output of the user defined post-renderers, as with kustomize
+	// used as a post-renderer
+	patches, patchesDiags := newManifestPatches(ctx, model)
+	diags.Append(patchesDiags...)
+	if diags.HasError() {
+		return nil, diags
+	}
+	if patches != nil {
+		chain = append(chain, patches)
+	}
+
+	// the common labels and annotations are set on the output of the user defined post-renderers,
+	// so that they cannot be removed by them
+	var common commonMetadata
+	if !model.CommonLabels.IsNull() && !model.CommonLabels.IsUnknown() {
+		diags.Append(model.CommonLabels.ElementsAs(ctx, &common.labels, false)...)
+	}
+	if !model.CommonAnnotations.IsNull() && !model.CommonAnnotations.IsUnknown() {
+		diags.Append(model.CommonAnnotations.ElementsAs(ctx, &common.annotations, false)...)
+	}
+	if diags.HasError() {
+		return nil, diags
+	}
+	if len(common.labels) > 0 || len(common.annotations) > 0 {
+		chain = append(chain, common)
+	}
+
+	// the namespaces are checked on the output of the user defined post-renderers, the hooks
+	// which are not passed to the post-renderers are checked by guardHookNamespaces
+	if crossNamespaceDenied(model) {
+		chain = append(chain, namespaceGuard{namespace: model.Namespace.ValueString()})
+	}
+
+	switch len(chain) {
+	case 0:
+		return nil, diags
+	case 1:
+		return chain[0], diags
+	}
+	return chain, diags
+}
@@ -0,0 +1,50 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type funcPostRenderer func(*bytes.Buffer) (*bytes.Buffer, error)
+
+func (f funcPostRenderer) Run(in *bytes.Buffer) (*bytes.Buffer, error) {
+	return f(in)
+}
+
+func TestChainedPostRenderer(t *testing.T) {
+	appendString := func(s string) funcPostRenderer {
+		return func(in *bytes.Buffer) (*bytes.Buffer, error) {
+			return bytes.NewBufferString(in.String() + s), nil
+		}
+	}
+
+	chain := chainedPostRenderer{appendString("a"), appendString("b"), appendString("c")}
+	out, err := chain.Run(bytes.NewBufferString("-"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "-abc", out.String())
+}
+
+func TestChainedPostRendererError(t *testing.T) {
+	failing := funcPostRenderer(func(in *bytes.Buffer) (*bytes.Buffer, error) {
+		return nil, errors.New("boom")
+	})
+	called := false
+	next := funcPostRenderer(func(in *bytes.Buffer) (*bytes.Buffer, error) {
+		called = true
+		return in, nil
+	})
+
+	_, err := chainedPostRenderer{failing, next}.Run(bytes.NewBufferString(""))
+
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "boom"))
+	assert.False(t, called)
+}
@@ -0,0 +1,40 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	pathpkg "path"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// defaultPreRenderedChartVersion is the version of the chart of a pre-rendered manifest when
+// the version attribute is not set
+const defaultPreRenderedChartVersion = "0.0.0"
+
+// preRenderedManifestFile is the file of the chart holding the pre-rendered manifest
+const preRenderedManifestFile = "manifest.yaml"
+
+// preRenderedChart wraps a manifest rendered beforehand, e.g. by the helm_template data
+// source, in a chart so that it is installed as a Helm release. The manifest is stored as a
+// file of the chart and included as is by its single template, so that it is not rendered a
+// second time. Hooks keep their annotations and are run in the order of their weights.
+func preRenderedChart(name, version, manifest string) *chart.Chart {
+	if version == "" {
+		version = defaultPreRenderedChartVersion
+	}
+	return &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       pathpkg.Base(name),
+			Version:    version,
+		},
+		Templates: []*chart.File{
+			{Name: "templates/" + preRenderedManifestFile, Data: []byte(`{{ .Files.Get "` + preRenderedManifestFile + `" }}`)},
+		},
+		Files: []*chart.File{
+			{Name: preRenderedManifestFile, Data: []byte(manifest)},
+		},
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+const testPreRenderedManifest = `---
+# Source: app/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+data:
+  template: "{{ .Values.notRendered }}"
+---
+# Source: app/templates/job.yaml
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: app-migrate
+  annotations:
+    helm.sh/hook: pre-install
+    helm.sh/hook-weight: "5"
+spec:
+  template:
+    spec:
+      restartPolicy: Never
+      containers:
+      - name: migrate
+        image: busybox
+`
+
+func TestPreRenderedChart(t *testing.T) {
+	cfg := &action.Configuration{
+		Releases:     storage.Init(driver.NewMemory()),
+		KubeClient:   &kubefake.PrintingKubeClient{Out: io.Discard},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log:          func(string, ...interface{}) {},
+	}
+	install := action.NewInstall(cfg)
+	install.DryRun = true
+	install.ClientOnly = true
+	install.ReleaseName = "app"
+	install.Namespace = "default"
+
+	c := preRenderedChart("charts/app", "", testPreRenderedManifest)
+	assert.NoError(t, c.Validate())
+	assert.Equal(t, "app", c.Metadata.Name)
+	assert.Equal(t, defaultPreRenderedChartVersion, c.Metadata.Version)
+
+	rel, err := install.Run(c, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, rel.Manifest, `template: "{{ .Values.notRendered }}"`)
+	assert.NotContains(t, rel.Manifest, "kind: Job")
+	if assert.Len(t, rel.Hooks, 1) {
+		assert.Equal(t, "app-migrate", rel.Hooks[0].Name)
+		assert.Equal(t, 5, rel.Hooks[0].Weight)
+	}
+}
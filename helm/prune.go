@@ -0,0 +1,80 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// pruneKubeClient records the objects an upgrade deletes because they were removed from the
+// chart. Without prune, the objects removed from the chart are left in the cluster instead.
+type pruneKubeClient struct {
+	forwardingKubeClient
+	prune bool
+	// kind/namespace/name of the objects deleted and of the objects kept
+	pruned []string
+	kept   []string
+}
+
+// watchPrune wraps the Kubernetes client of actionConfig to record or prevent the deletion of
+// the objects removed from the chart by an upgrade
+func watchPrune(actionConfig *action.Configuration, prune bool) *pruneKubeClient {
+	c := &pruneKubeClient{forwardingKubeClient: forwardingKubeClient{Interface: actionConfig.KubeClient}, prune: prune}
+	actionConfig.KubeClient = c
+	return c
+}
+
+// objectKey returns the key of an object in pruned_resources, e.g. deployment/default/web
+func objectKey(info *resource.Info) string {
+	return fmt.Sprintf("%s/%s/%s", strings.ToLower(info.Mapping.GroupVersionKind.Kind), info.Namespace, info.Name)
+}
+
+func (c *pruneKubeClient) Update(original, target kube.ResourceList, force bool) (*kube.Result, error) {
+	if !c.prune {
+		// Helm deletes the objects of original missing from target
+		var retained kube.ResourceList
+		for _, info := range original {
+			if target.Get(info) == nil {
+				c.kept = append(c.kept, objectKey(info))
+				continue
+			}
+			retained = append(retained, info)
+		}
+		original = retained
+	}
+	res, err := c.Interface.Update(original, target, force)
+	if res != nil {
+		for _, info := range res.Deleted {
+			c.pruned = append(c.pruned, objectKey(info))
+		}
+	}
+	return res, err
+}
+
+// setPrunedResources records the objects deleted by the upgrade in the pruned_resources
+// attribute of state, and warns about the objects kept in the cluster without prune
+func (c *pruneKubeClient) setPrunedResources(ctx context.Context, state *HelmReleaseModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	sort.Strings(c.pruned)
+	pruned, d := types.ListValueFrom(ctx, types.StringType, c.pruned)
+	diags.Append(d...)
+	state.PrunedResources = pruned
+	if len(c.kept) > 0 {
+		sort.Strings(c.kept)
+		diags.AddWarning(
+			"Objects removed from the chart were kept",
+			fmt.Sprintf("The objects removed from the chart of release %s were not deleted as prune is false, and are no longer managed by Helm: %s", state.Name.ValueString(), strings.Join(c.kept, ", ")),
+		)
+	}
+	return diags
+}
@@ -0,0 +1,68 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// deletingKubeClient deletes the objects of original missing from target on update, like Helm
+type deletingKubeClient struct {
+	kubefake.PrintingKubeClient
+}
+
+func (c *deletingKubeClient) Update(original, target kube.ResourceList, force bool) (*kube.Result, error) {
+	return &kube.Result{Updated: target, Deleted: original.Difference(target)}, nil
+}
+
+func TestWatchPrune(t *testing.T) {
+	deployment := &meta.RESTMapping{GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}}
+	service := &meta.RESTMapping{GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Service"}}
+	original := kube.ResourceList{
+		{Name: "web", Namespace: "default", Mapping: deployment},
+		{Name: "worker", Namespace: "default", Mapping: deployment},
+		{Name: "web", Namespace: "default", Mapping: service},
+	}
+	target := kube.ResourceList{original[0]}
+	ctx := context.Background()
+
+	t.Run("prune", func(t *testing.T) {
+		actionConfig := &action.Configuration{KubeClient: &deletingKubeClient{kubefake.PrintingKubeClient{Out: io.Discard}}}
+		pruning := watchPrune(actionConfig, true)
+		res, err := actionConfig.KubeClient.Update(original, target, false)
+		require.NoError(t, err)
+		assert.Len(t, res.Deleted, 2)
+
+		state := &HelmReleaseModel{Name: types.StringValue("web")}
+		diags := pruning.setPrunedResources(ctx, state)
+		assert.Empty(t, diags)
+		expected, _ := types.ListValueFrom(ctx, types.StringType, []string{"deployment/default/worker", "service/default/web"})
+		assert.Equal(t, expected, state.PrunedResources)
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		actionConfig := &action.Configuration{KubeClient: &deletingKubeClient{kubefake.PrintingKubeClient{Out: io.Discard}}}
+		pruning := watchPrune(actionConfig, false)
+		res, err := actionConfig.KubeClient.Update(original, target, false)
+		require.NoError(t, err)
+		assert.Empty(t, res.Deleted)
+
+		state := &HelmReleaseModel{Name: types.StringValue("web")}
+		diags := pruning.setPrunedResources(ctx, state)
+		require.Len(t, diags, 1)
+		assert.Contains(t, diags[0].Detail(), "deployment/default/worker, service/default/web")
+		assert.Empty(t, state.PrunedResources.Elements())
+	})
+}
@@ -0,0 +1,44 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var readRetryMaxBackoff = 30 * time.Second
+
+// retryReleaseExists runs exists until the release is found without error, at most attempts
+// more times, waiting backoff before the first retry and twice as long before each next one.
+// A release which is not found or cannot be read may be an outage of the Kubernetes API or of
+// its storage, after which the release shows up again. The result of the last run is returned.
+func retryReleaseExists(ctx context.Context, name string, attempts int, backoff time.Duration, exists func() (bool, diag.Diagnostics)) (bool, diag.Diagnostics) {
+	for attempt := 0; ; attempt++ {
+		found, diags := exists()
+		if (found && !diags.HasError()) || attempt >= attempts {
+			return found, diags
+		}
+
+		reason := "not found"
+		if diags.HasError() {
+			reason = "unreadable"
+		}
+		tflog.Info(ctx, fmt.Sprintf("Release %s %s, checking again in %s (%d/%d)", name, reason, backoff, attempt+1, attempts))
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return found, diags
+		}
+
+		backoff *= 2
+		if backoff > readRetryMaxBackoff {
+			backoff = readRetryMaxBackoff
+		}
+	}
+}
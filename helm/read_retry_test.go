@@ -0,0 +1,53 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryReleaseExists(t *testing.T) {
+	ctx := context.Background()
+	unreachable := diag.Diagnostics{diag.NewErrorDiagnostic("Error checking release existence", "connection refused")}
+
+	// the release shows up again after an outage
+	calls := 0
+	exists, diags := retryReleaseExists(ctx, "test", 3, time.Millisecond, func() (bool, diag.Diagnostics) {
+		calls++
+		switch calls {
+		case 1:
+			return false, unreachable
+		case 2:
+			return false, nil
+		}
+		return true, nil
+	})
+	assert.True(t, exists)
+	assert.Empty(t, diags)
+	assert.Equal(t, 3, calls)
+
+	// the last result is returned once the attempts are exhausted
+	calls = 0
+	exists, diags = retryReleaseExists(ctx, "test", 2, time.Millisecond, func() (bool, diag.Diagnostics) {
+		calls++
+		return false, unreachable
+	})
+	assert.False(t, exists)
+	assert.True(t, diags.HasError())
+	assert.Equal(t, 3, calls)
+
+	// without attempts the release is checked once
+	calls = 0
+	exists, _ = retryReleaseExists(ctx, "test", 0, time.Millisecond, func() (bool, diag.Diagnostics) {
+		calls++
+		return false, nil
+	})
+	assert.False(t, exists)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,153 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+
+	"helm.sh/helm/v3/pkg/registry"
+)
+
+// plainHTTPRegistries returns the hosts of the registries of the provider configured with
+// plain_http
+func plainHTTPRegistries(registryConfigs []RegistryConfigModel) map[string]bool {
+	hosts := map[string]bool{}
+	for _, r := range registryConfigs {
+		if !r.PlainHTTP.ValueBool() {
+			continue
+		}
+		if u, err := url.Parse(r.URL.ValueString()); err == nil && u.Host != "" {
+			hosts[u.Host] = true
+		}
+	}
+	return hosts
+}
+
+// usePlainHTTP reports whether the OCI reference ref is accessed over plain HTTP, either because
+// the release sets repository_plain_http or because its registry is configured with plain_http
+// in the provider
+func (m *Meta) usePlainHTTP(ref string, plainHTTP bool) bool {
+	if plainHTTP || !registry.IsOCI(ref) {
+		return plainHTTP
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return false
+	}
+	return m.PlainHTTPRegistries[u.Host]
+}
+
+// newInsecureRegistryClient returns a registry client that does not verify the certificates of
+// registries, like the registry client of helm --insecure-skip-tls-verify
+func newInsecureRegistryClient(credentialsFile string) (*registry.Client, error) {
+	return registry.NewClient(
+		registry.ClientOptCredentialsFile(credentialsFile),
+		registry.ClientOptHTTPClient(&http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // #nosec G402
+				Proxy:           http.ProxyFromEnvironment,
+			},
+		}),
+	)
+}
+
+// ociRegistryClient returns the registry client the OCI reference ref is pulled with. The
+// certificates of the registry are not verified with insecureSkipTLSVerify or with the
+// insecure_skip_tls_verify of the repository_tls of the provider.
+func (m *Meta) ociRegistryClient(ref string, plainHTTP, insecureSkipTLSVerify bool) *registry.Client {
+	if m.usePlainHTTP(ref, plainHTTP) && m.PlainHTTPRegistryClient != nil {
+		return m.PlainHTTPRegistryClient
+	}
+	if m.skipTLSVerify(insecureSkipTLSVerify) && m.InsecureRegistryClient != nil {
+		return m.InsecureRegistryClient
+	}
+	return m.RegistryClient
+}
+
+// skipTLSVerify reports whether the certificates of a repository or registry are not verified
+func (m *Meta) skipTLSVerify(insecureSkipTLSVerify bool) bool {
+	return insecureSkipTLSVerify || (m.RepositoryTLS != nil && m.RepositoryTLS.InsecureSkipTLSVerify)
+}
+
+// registryConfigPaths returns the config_path of the registries of the provider
+func registryConfigPaths(registryConfigs []RegistryConfigModel) []string {
+	var paths []string
+	for _, r := range registryConfigs {
+		if p := r.ConfigPath.ValueString(); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// readRegistryConfig reads the auths and credHelpers of a docker-style config file, keyed by
+// registry host
+func readRegistryConfig(path string) (map[string]json.RawMessage, map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var config struct {
+		Auths       map[string]json.RawMessage `json:"auths"`
+		CredHelpers map[string]json.RawMessage `json:"credHelpers"`
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, nil, fmt.Errorf("invalid registry config file %s: %w", path, err)
+		}
+	}
+	return config.Auths, config.CredHelpers, nil
+}
+
+// mergeRegistryConfigs merges the registry config file of the provider and the docker-style
+// config files of paths into a new config file, whose path is returned. The credentials of a
+// host are taken from the last file configuring it, so that the files of paths take precedence
+// over base, which may not exist. The inline credentials of the registries are written to the
+// merged file when the provider logs in, and take precedence over all files.
+func mergeRegistryConfigs(base string, paths []string) (string, error) {
+	auths := map[string]json.RawMessage{}
+	credHelpers := map[string]json.RawMessage{}
+	for i, path := range append([]string{base}, paths...) {
+		a, h, err := readRegistryConfig(path)
+		if i == 0 && errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+		for host, auth := range a {
+			auths[host] = auth
+		}
+		for host, helper := range h {
+			credHelpers[host] = helper
+		}
+	}
+
+	merged := map[string]interface{}{"auths": auths}
+	if len(credHelpers) > 0 {
+		merged["credHelpers"] = credHelpers
+	}
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return "", err
+	}
+	dir, err := temporaryDir()
+	if err != nil {
+		return "", err
+	}
+	// os.CreateTemp creates the file with 0600, the file holds credentials
+	f, err := os.CreateTemp(dir, "registry-config-*.json")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	_, err = f.Write(data)
+	return f.Name(), err
+}
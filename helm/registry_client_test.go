@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/registry"
+)
+
+func TestPlainHTTPRegistries(t *testing.T) {
+	hosts := plainHTTPRegistries([]RegistryConfigModel{
+		{URL: types.StringValue("oci://registry.lab:5000"), PlainHTTP: types.BoolValue(true)},
+		{URL: types.StringValue("oci://ghcr.io"), PlainHTTP: types.BoolValue(false)},
+		{URL: types.StringValue("oci://docker.io")},
+	})
+	assert.Equal(t, map[string]bool{"registry.lab:5000": true}, hosts)
+}
+
+func TestOCIRegistryClient(t *testing.T) {
+	client, err := registry.NewClient()
+	require.NoError(t, err)
+	plainClient, err := registry.NewClient(registry.ClientOptPlainHTTP())
+	require.NoError(t, err)
+	insecureClient, err := newInsecureRegistryClient("")
+	require.NoError(t, err)
+	meta := &Meta{
+		RegistryClient:          client,
+		PlainHTTPRegistryClient: plainClient,
+		InsecureRegistryClient:  insecureClient,
+		PlainHTTPRegistries:     map[string]bool{"registry.lab:5000": true},
+	}
+
+	tests := []struct {
+		ref       string
+		plainHTTP bool
+		insecure  bool
+		expected  *registry.Client
+	}{
+		{"oci://registry.lab:5000/charts/nginx", false, false, plainClient},
+		{"oci://registry.lab:5000/charts/nginx", false, true, plainClient},
+		{"oci://ghcr.io/charts/nginx", false, false, client},
+		{"oci://ghcr.io/charts/nginx", true, false, plainClient},
+		{"oci://ghcr.io/charts/nginx", false, true, insecureClient},
+		{"https://charts.example.com", false, false, client},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected == plainClient, meta.usePlainHTTP(tt.ref, tt.plainHTTP), tt.ref)
+		assert.Same(t, tt.expected, meta.ociRegistryClient(tt.ref, tt.plainHTTP, tt.insecure), tt.ref)
+	}
+
+	// insecure_skip_tls_verify of the repository_tls of the provider applies to every registry
+	meta.RepositoryTLS = &repositoryTLS{InsecureSkipTLSVerify: true}
+	assert.Same(t, insecureClient, meta.ociRegistryClient("oci://ghcr.io/charts/nginx", false, false))
+}
+
+func TestMergeRegistryConfigs(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+	base := write("base.json", `{"auths":{"ghcr.io":{"auth":"YmFzZTpiYXNl"},"quay.io":{"auth":"cXVheTpxdWF5"}}}`)
+	first := write("first.json", `{"auths":{"ghcr.io":{"auth":"Zmlyc3Q6Zmlyc3Q="}},"credHelpers":{"123.dkr.ecr.us-east-1.amazonaws.com":"ecr-login"}}`)
+	second := write("second.json", `{"auths":{"ghcr.io":{"username":"second","password":"second"}}}`)
+
+	merged, err := mergeRegistryConfigs(base, []string{first, second})
+	require.NoError(t, err)
+	auths, credHelpers, err := readRegistryConfig(merged)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"username":"second","password":"second"}`, string(auths["ghcr.io"]))
+	assert.JSONEq(t, `{"auth":"cXVheTpxdWF5"}`, string(auths["quay.io"]))
+	assert.JSONEq(t, `"ecr-login"`, string(credHelpers["123.dkr.ecr.us-east-1.amazonaws.com"]))
+
+	// the registry config file of the provider may not exist, the files of config_path must
+	_, err = mergeRegistryConfigs(filepath.Join(dir, "missing.json"), []string{first})
+	require.NoError(t, err)
+	_, err = mergeRegistryConfigs(base, []string{filepath.Join(dir, "missing.json")})
+	assert.Error(t, err)
+	_, err = mergeRegistryConfigs(base, []string{write("invalid.json", "{")})
+	assert.Error(t, err)
+}
+
+func TestRegistryConfigPaths(t *testing.T) {
+	paths := registryConfigPaths([]RegistryConfigModel{
+		{ConfigPath: types.StringValue("/etc/docker/config.json")},
+		{URL: types.StringValue("oci://ghcr.io"), ConfigPath: types.StringNull()},
+	})
+	assert.Equal(t, []string{"/etc/docker/config.json"}, paths)
+}
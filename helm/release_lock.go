@@ -0,0 +1,176 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	releaseLockPrefix = "terraform-helm-lock-"
+	// releaseLockGrace is added to the operation timeout to compute the lease duration,
+	// so that a lease left behind by a crashed run eventually expires
+	releaseLockGrace = 5 * time.Minute
+)
+
+// releaseLockHolder identifies this provider process as the holder of a lease
+var releaseLockHolder = func() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), rand.String(5))
+}()
+
+func releaseLockName(name string) string {
+	return releaseLockPrefix + name
+}
+
+// lockRelease acquires an advisory lock for the release when release locking is enabled.
+// With createNamespace, a missing namespace is created first so that concurrent installs
+// creating it are locked too, Helm then installs in the existing namespace.
+// The returned function releases the lock and is never nil.
+func (m *Meta) lockRelease(ctx context.Context, actionConfig *action.Configuration, namespace, name string, timeout time.Duration, createNamespace bool) (func(), diag.Diagnostics) {
+	var diags diag.Diagnostics
+	unlock := func() {}
+
+	if !m.ReleaseLocking {
+		return unlock, diags
+	}
+
+	clientset, err := actionConfig.KubernetesClientSet()
+	if err != nil {
+		diags.AddError("Error acquiring release lock", fmt.Sprintf("Unable to create Kubernetes client: %s", err))
+		return unlock, diags
+	}
+
+	locked, lockDiags := takeReleaseLock(ctx, clientset, namespace, name, timeout+releaseLockGrace, createNamespace)
+	diags.Append(lockDiags...)
+	if !locked {
+		return unlock, diags
+	}
+
+	unlock = func() {
+		if err := releaseReleaseLock(context.Background(), clientset, namespace, name); err != nil {
+			tflog.Warn(ctx, fmt.Sprintf("Unable to release lock for release %s/%s: %s", namespace, name, err))
+		}
+	}
+	return unlock, diags
+}
+
+// takeReleaseLock acquires the lease of the release, creating its namespace first with
+// createNamespace, and reports whether the lease is held. Locking is skipped with a warning
+// when the namespace does not exist.
+func takeReleaseLock(ctx context.Context, clientset kubernetes.Interface, namespace, name string, duration time.Duration, createNamespace bool) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	err := acquireReleaseLock(ctx, clientset, namespace, name, duration)
+	if apierrors.IsNotFound(err) && createNamespace {
+		if err := createReleaseNamespace(ctx, clientset, namespace); err != nil {
+			diags.AddError("Error acquiring release lock", fmt.Sprintf("Unable to create namespace %s: %s", namespace, err))
+			return false, diags
+		}
+		err = acquireReleaseLock(ctx, clientset, namespace, name, duration)
+	}
+	if apierrors.IsNotFound(err) {
+		// the namespace does not exist, the operation fails or has no release to protect
+		tflog.Warn(ctx, fmt.Sprintf("Release locking skipped for release %s: namespace %s not found", name, namespace))
+		return false, diags
+	}
+	if err != nil {
+		diags.AddError("Release locked by another operation", err.Error())
+		return false, diags
+	}
+	return true, diags
+}
+
+func acquireReleaseLock(ctx context.Context, clientset kubernetes.Interface, namespace, name string, duration time.Duration) error {
+	leases := clientset.CoordinationV1().Leases(namespace)
+	now := metav1.NewMicroTime(time.Now())
+	seconds := int32(duration.Seconds())
+
+	lease, err := leases.Get(ctx, releaseLockName(name), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      releaseLockName(name),
+				Namespace: namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/managed-by": "terraform-provider-helm",
+				},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &releaseLockHolder,
+				LeaseDurationSeconds: &seconds,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		_, err = leases.Create(ctx, lease, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("release %s/%s is locked by another operation", namespace, name)
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity != releaseLockHolder && !leaseExpired(lease, now.Time) {
+		return fmt.Errorf("release %s/%s is locked by another operation (holder %q, acquired at %s)",
+			namespace, name, *lease.Spec.HolderIdentity, lease.Spec.AcquireTime)
+	}
+
+	// the lease is expired or already ours, take it over
+	lease.Spec.HolderIdentity = &releaseLockHolder
+	lease.Spec.LeaseDurationSeconds = &seconds
+	lease.Spec.AcquireTime = &now
+	lease.Spec.RenewTime = &now
+	_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+	if apierrors.IsConflict(err) {
+		return fmt.Errorf("release %s/%s is locked by another operation", namespace, name)
+	}
+	return err
+}
+
+func releaseReleaseLock(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+	leases := clientset.CoordinationV1().Leases(namespace)
+	lease, err := leases.Get(ctx, releaseLockName(name), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != releaseLockHolder {
+		return nil
+	}
+	err = leases.Delete(ctx, lease.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &lease.UID, ResourceVersion: &lease.ResourceVersion},
+	})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func leaseExpired(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	expiry := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+	return now.After(expiry)
+}
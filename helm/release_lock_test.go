@@ -0,0 +1,115 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestReleaseLock(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset()
+
+	err := acquireReleaseLock(ctx, clientset, "default", "test", time.Minute)
+	assert.NoError(t, err)
+
+	// acquiring the lock again from the same process succeeds
+	err = acquireReleaseLock(ctx, clientset, "default", "test", time.Minute)
+	assert.NoError(t, err)
+
+	err = releaseReleaseLock(ctx, clientset, "default", "test")
+	assert.NoError(t, err)
+
+	_, err = clientset.CoordinationV1().Leases("default").Get(ctx, releaseLockName("test"), metav1.GetOptions{})
+	assert.Error(t, err)
+}
+
+func TestReleaseLockHeldByOtherHolder(t *testing.T) {
+	ctx := context.Background()
+	holder := "someone-else"
+	seconds := int32(60)
+	now := metav1.NewMicroTime(time.Now())
+	clientset := fake.NewSimpleClientset(&coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: releaseLockName("test"), Namespace: "default"},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &seconds,
+			AcquireTime:          &now,
+			RenewTime:            &now,
+		},
+	})
+
+	err := acquireReleaseLock(ctx, clientset, "default", "test", time.Minute)
+	assert.ErrorContains(t, err, "locked by another operation")
+
+	// releasing does not remove a lease held by another holder
+	err = releaseReleaseLock(ctx, clientset, "default", "test")
+	assert.NoError(t, err)
+	_, err = clientset.CoordinationV1().Leases("default").Get(ctx, releaseLockName("test"), metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func TestReleaseLockExpired(t *testing.T) {
+	ctx := context.Background()
+	holder := "someone-else"
+	seconds := int32(60)
+	past := metav1.NewMicroTime(time.Now().Add(-time.Hour))
+	clientset := fake.NewSimpleClientset(&coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: releaseLockName("test"), Namespace: "default"},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &seconds,
+			AcquireTime:          &past,
+			RenewTime:            &past,
+		},
+	})
+
+	err := acquireReleaseLock(ctx, clientset, "default", "test", time.Minute)
+	assert.NoError(t, err)
+
+	lease, err := clientset.CoordinationV1().Leases("default").Get(ctx, releaseLockName("test"), metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, releaseLockHolder, *lease.Spec.HolderIdentity)
+}
+
+func TestTakeReleaseLockMissingNamespace(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset()
+	// the API server rejects leases in namespaces which do not exist
+	clientset.PrependReactor("create", "leases", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		namespace := action.GetNamespace()
+		if _, err := clientset.Tracker().Get(v1.SchemeGroupVersion.WithResource("namespaces"), "", namespace); err != nil {
+			return true, nil, apierrors.NewNotFound(v1.Resource("namespaces"), namespace)
+		}
+		return false, nil, nil
+	})
+
+	// without create_namespace the lock is skipped
+	locked, diags := takeReleaseLock(ctx, clientset, "missing", "test", time.Minute, false)
+	assert.False(t, diags.HasError())
+	assert.False(t, locked)
+	_, err := clientset.CoreV1().Namespaces().Get(ctx, "missing", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+
+	// with create_namespace the namespace is created to hold the lease
+	locked, diags = takeReleaseLock(ctx, clientset, "missing", "test", time.Minute, true)
+	assert.False(t, diags.HasError())
+	assert.True(t, locked)
+	ns, err := clientset.CoreV1().Namespaces().Get(ctx, "missing", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"name": "missing"}, ns.Labels)
+	_, err = clientset.CoordinationV1().Leases("missing").Get(ctx, releaseLockName("test"), metav1.GetOptions{})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,60 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// maxObjectNameLength is the length of the names of the objects Kubernetes validates as DNS labels,
+// e.g. Services, and of the values of labels
+const maxObjectNameLength = 63
+
+var (
+	// releaseNameRegexp is the name Helm validates release names with, see chartutil.ValidateReleaseName
+	releaseNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	// namespaceRegexp is the RFC 1123 label namespaces are validated with
+	namespaceRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+)
+
+// releaseFullname returns the fullname of the objects of a chart generated by helm create, before
+// the chart truncates it to 63 characters. It honors the fullnameOverride and nameOverride values.
+func releaseFullname(releaseName string, c *chart.Chart, values map[string]interface{}) string {
+	if override, ok := values["fullnameOverride"].(string); ok && override != "" {
+		return override
+	}
+	name := c.Metadata.Name
+	if override, ok := values["nameOverride"].(string); ok && override != "" {
+		name = override
+	}
+	if strings.Contains(releaseName, name) {
+		return releaseName
+	}
+	return fmt.Sprintf("%s-%s", releaseName, name)
+}
+
+// checkReleaseFullname warns when the fullname of the objects of a release is longer than the
+// names Kubernetes accepts for Services and label values
+func checkReleaseFullname(releaseName string, c *chart.Chart, values map[string]interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	fullname := releaseFullname(releaseName, c, values)
+	if len(fullname) <= maxObjectNameLength {
+		return diags
+	}
+	diags.AddAttributeWarning(
+		path.Root("name"),
+		"Release fullname exceeds 63 characters",
+		fmt.Sprintf("The names of the objects of chart %s for release %s are usually derived from %q, which is %d characters long. "+
+			"Charts generated by helm create truncate it to %d characters, so that the objects of releases with a common prefix may collide, "+
+			"and other charts fail to create Services and to set labels. Use a shorter release name, or set the fullnameOverride or nameOverride values.",
+			c.Metadata.Name, releaseName, fullname, len(fullname), maxObjectNameLength),
+	)
+	return diags
+}
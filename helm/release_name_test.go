@@ -0,0 +1,57 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestReleaseNameRegexps(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"web":       true,
+		"web-1.app": true,
+		"Web":       false,
+		"web_1":     false,
+		"-web":      false,
+		"web.":      false,
+	} {
+		assert.Equal(t, valid, releaseNameRegexp.MatchString(name), name)
+	}
+	for namespace, valid := range map[string]bool{
+		"default":   true,
+		"kube-apps": true,
+		"apps.prod": false,
+		"Apps":      false,
+		"apps-":     false,
+	} {
+		assert.Equal(t, valid, namespaceRegexp.MatchString(namespace), namespace)
+	}
+}
+
+func TestReleaseFullname(t *testing.T) {
+	c := &chart.Chart{Metadata: &chart.Metadata{Name: "redis"}}
+
+	assert.Equal(t, "cache-redis", releaseFullname("cache", c, nil))
+	assert.Equal(t, "redis-cache", releaseFullname("redis-cache", c, nil))
+	assert.Equal(t, "cache-kv", releaseFullname("cache", c, map[string]interface{}{"nameOverride": "kv"}))
+	assert.Equal(t, "kv", releaseFullname("cache", c, map[string]interface{}{"fullnameOverride": "kv", "nameOverride": "store"}))
+}
+
+func TestCheckReleaseFullname(t *testing.T) {
+	c := &chart.Chart{Metadata: &chart.Metadata{Name: "kube-prometheus-stack"}}
+
+	assert.Empty(t, checkReleaseFullname("monitoring", c, nil))
+
+	name := strings.Repeat("a", 50)
+	diags := checkReleaseFullname(name, c, nil)
+	assert.Len(t, diags, 1)
+	assert.False(t, diags.HasError())
+	assert.Contains(t, diags[0].Detail(), "72 characters long")
+
+	assert.Empty(t, checkReleaseFullname(name, c, map[string]interface{}{"fullnameOverride": "monitoring"}))
+}
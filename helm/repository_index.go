@@ -0,0 +1,225 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// Values of the refresh_repository attribute of helm_release
+const (
+	refreshRepositoryAlways = "always"
+	refreshRepositoryNever  = "never"
+	refreshRepositoryAuto   = "auto"
+)
+
+var refreshRepositoryModes = []string{refreshRepositoryAuto, refreshRepositoryAlways, refreshRepositoryNever}
+
+// locateRepositoryChart locates a chart of a chart repository, refreshing the cached index of
+// the repository according to refresh:
+//   - always downloads the index before locating the chart
+//   - never uses the cached index, which is only downloaded when it is missing
+//   - auto downloads the index when it is older than the repository_cache_ttl of the provider,
+//     and once more when the chart version is not found in the cached index. Without a TTL,
+//     the indexes of repository URLs are downloaded every time, as Helm does.
+func (m *Meta) locateRepositoryChart(ctx context.Context, cpo *action.ChartPathOptions, name, refresh string) (string, error) {
+	if cpo.RepoURL != "" {
+		if refresh == refreshRepositoryAlways || refresh != refreshRepositoryNever && m.RepositoryCacheTTL == 0 {
+			return cpo.LocateChart(name, m.Settings)
+		}
+		return m.locateCachedRepositoryURLChart(ctx, cpo, name, refresh)
+	}
+
+	entry := m.namedRepository(name)
+	if entry == nil {
+		return cpo.LocateChart(name, m.Settings)
+	}
+
+	refreshed := false
+	if refresh == refreshRepositoryAlways || !m.repositoryIndexCached(entry.Name) || refresh == refreshRepositoryAuto && m.repositoryIndexExpired(entry.Name) {
+		if _, err := m.downloadRepositoryIndex(ctx, entry); err != nil {
+			return "", err
+		}
+		refreshed = true
+	}
+	path, err := cpo.LocateChart(name, m.Settings)
+	if err != nil && refresh == refreshRepositoryAuto && !refreshed && chartNotFound(err) {
+		subsystemDebug(ctx, logSubsystemDownload, fmt.Sprintf("Chart %s not found in the cached index of repository %s, refreshing the index", name, entry.Name))
+		if _, err := m.downloadRepositoryIndex(ctx, entry); err != nil {
+			return "", err
+		}
+		return cpo.LocateChart(name, m.Settings)
+	}
+	return path, err
+}
+
+// locateCachedRepositoryURLChart locates a chart of the repository URL of cpo with the cached
+// index of the repository, see locateRepositoryChart
+func (m *Meta) locateCachedRepositoryURLChart(ctx context.Context, cpo *action.ChartPathOptions, name, refresh string) (string, error) {
+	entry := repositoryURLEntry(cpo)
+
+	refreshed := false
+	if !m.repositoryIndexCached(entry.Name) || refresh == refreshRepositoryAuto && m.repositoryIndexExpired(entry.Name) {
+		if _, err := m.downloadRepositoryIndex(ctx, entry); err != nil {
+			return "", err
+		}
+		refreshed = true
+	}
+
+	indexPath := filepath.Join(m.Settings.RepositoryCache, helmpath.CacheIndexFile(entry.Name))
+	cv, err := findChartVersion(indexPath, name, cpo.Version)
+	if err != nil && refresh == refreshRepositoryAuto && !refreshed && chartNotFound(err) {
+		subsystemDebug(ctx, logSubsystemDownload, fmt.Sprintf("Chart %s not found in the cached index of repository %s, refreshing the index", name, cpo.RepoURL))
+		if _, err := m.downloadRepositoryIndex(ctx, entry); err != nil {
+			return "", err
+		}
+		cv, err = findChartVersion(indexPath, name, cpo.Version)
+	}
+	if err != nil {
+		return "", fmt.Errorf("chart %q version %q not found in %s repository: %w", name, cpo.Version, cpo.RepoURL, err)
+	}
+	if len(cv.URLs) == 0 {
+		return "", fmt.Errorf("chart %q version %q has no downloadable URLs", name, cv.Version)
+	}
+	chartURL, err := repo.ResolveReferenceURL(cpo.RepoURL, cv.URLs[0])
+	if err != nil {
+		return "", fmt.Errorf("failed to make chart URL absolute: %w", err)
+	}
+
+	direct := *cpo
+	direct.RepoURL = ""
+	direct.Version = ""
+	// as Helm does, the credentials of the repository are only passed to charts hosted
+	// elsewhere with pass_credentials
+	if !cpo.PassCredentialsAll && !sameHost(cpo.RepoURL, chartURL) {
+		direct.Username = ""
+		direct.Password = ""
+	}
+	return direct.LocateChart(chartURL, m.Settings)
+}
+
+// repositoryURLEntry returns the repository entry of the repository URL of cpo, whose index is
+// cached with the name of repositoryURLIndexName
+func repositoryURLEntry(cpo *action.ChartPathOptions) *repo.Entry {
+	return &repo.Entry{
+		Name:                  repositoryURLIndexName(cpo.RepoURL),
+		URL:                   cpo.RepoURL,
+		Username:              cpo.Username,
+		Password:              cpo.Password,
+		PassCredentialsAll:    cpo.PassCredentialsAll,
+		CertFile:              cpo.CertFile,
+		KeyFile:               cpo.KeyFile,
+		CAFile:                cpo.CaFile,
+		InsecureSkipTLSverify: cpo.InsecureSkipTLSverify,
+	}
+}
+
+// repositoryURLIndexName returns the name the index of a repository URL is cached with
+func repositoryURLIndexName(repoURL string) string {
+	sum := sha256.Sum256([]byte(repoURL))
+	return "url-" + hex.EncodeToString(sum[:])[:12]
+}
+
+// namedRepository returns the repository of the repository configuration file name refers to,
+// of the form repository/chart, or nil
+func (m *Meta) namedRepository(name string) *repo.Entry {
+	if registry.IsOCI(name) || strings.Contains(name, "://") {
+		return nil
+	}
+	if _, err := os.Stat(name); err == nil {
+		return nil
+	}
+	repoName, _, ok := strings.Cut(name, "/")
+	if !ok {
+		return nil
+	}
+	f, err := repo.LoadFile(m.Settings.RepositoryConfig)
+	if err != nil {
+		return nil
+	}
+	return f.Get(repoName)
+}
+
+// repositoryIndexCached reports whether the index of the repository name is in the repository cache
+func (m *Meta) repositoryIndexCached(name string) bool {
+	_, err := os.Stat(filepath.Join(m.Settings.RepositoryCache, helmpath.CacheIndexFile(name)))
+	return err == nil
+}
+
+// repositoryIndexExpired reports whether the cached index of the repository name is older than
+// the repository_cache_ttl of the provider. Indexes do not expire without a TTL.
+func (m *Meta) repositoryIndexExpired(name string) bool {
+	if m.RepositoryCacheTTL == 0 {
+		return false
+	}
+	fi, err := os.Stat(filepath.Join(m.Settings.RepositoryCache, helmpath.CacheIndexFile(name)))
+	if err != nil {
+		return true
+	}
+	return time.Since(fi.ModTime()) > m.RepositoryCacheTTL
+}
+
+// downloadRepositoryIndex downloads the index of the repository of entry to the repository
+// cache. Through the chart fetcher of the provider, an index is downloaded once per run.
+func (m *Meta) downloadRepositoryIndex(ctx context.Context, entry *repo.Entry) (string, error) {
+	download := func() (string, error) {
+		subsystemDebug(ctx, logSubsystemDownload, fmt.Sprintf("Downloading the index of repository %s", entry.URL))
+		r, err := repo.NewChartRepository(entry, getter.All(m.Settings))
+		if err != nil {
+			return "", err
+		}
+		r.CachePath = m.Settings.RepositoryCache
+		path, err := r.DownloadIndexFile()
+		if err != nil {
+			return "", fmt.Errorf("looks like %q is not a valid chart repository or cannot be reached: %w", entry.URL, err)
+		}
+		return path, nil
+	}
+	if m.ChartFetcher == nil {
+		return download()
+	}
+	return m.ChartFetcher.do(ctx, "index|"+entry.Name, download)
+}
+
+func findChartVersion(indexPath, name, version string) (*repo.ChartVersion, error) {
+	idx, err := repo.LoadIndexFile(indexPath)
+	if err != nil {
+		return nil, err
+	}
+	return idx.Get(name, version)
+}
+
+// chartNotFound reports whether err is returned for a chart or chart version missing from an
+// index. IndexFile.Get does not wrap ErrNoChartVersion when no version matches.
+func chartNotFound(err error) bool {
+	return errors.Is(err, repo.ErrNoChartName) || errors.Is(err, repo.ErrNoChartVersion) ||
+		strings.Contains(err.Error(), repo.ErrNoChartVersion.Error())
+}
+
+func sameHost(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return ua.Scheme == ub.Scheme && ua.Host == ub.Host
+}
@@ -0,0 +1,145 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
+)
+
+// testIndexServer serves a chart repository whose index lists the given versions of test-chart
+type testIndexServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	versions []string
+	requests int
+	dir      string
+}
+
+func newTestIndexServer(t *testing.T, versions ...string) *testIndexServer {
+	s := &testIndexServer{versions: versions, dir: t.TempDir()}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if r.URL.Path != "/index.yaml" {
+			http.ServeFile(w, r, filepath.Join(s.dir, filepath.Base(r.URL.Path)))
+			return
+		}
+		s.requests++
+		idx := repo.NewIndexFile()
+		for _, v := range s.versions {
+			c, err := loader.Load("testdata/charts/test-chart")
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.Metadata.Version = v
+			p, err := chartutil.Save(c, s.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			idx.MustAdd(c.Metadata, filepath.Base(p), s.URL, "")
+		}
+		b, _ := yaml.Marshal(idx)
+		w.Write(b)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testIndexServer) setVersions(versions ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.versions = versions
+}
+
+func testRepositoryIndexMeta(t *testing.T, ttl time.Duration) *Meta {
+	home := t.TempDir()
+	settings := cli.New()
+	settings.RepositoryCache = filepath.Join(home, "cache")
+	settings.RepositoryConfig = filepath.Join(home, "repositories.yaml")
+	return &Meta{Settings: settings, RepositoryCacheTTL: ttl}
+}
+
+func TestLocateRepositoryURLChartCachedIndex(t *testing.T) {
+	ctx := context.Background()
+	server := newTestIndexServer(t, "1.0.0")
+	m := testRepositoryIndexMeta(t, time.Hour)
+
+	locate := func(version, refresh string) error {
+		_, err := m.locateRepositoryChart(ctx, &action.ChartPathOptions{RepoURL: server.URL, Version: version}, "test-chart", refresh)
+		return err
+	}
+
+	assert.NoError(t, locate("1.0.0", refreshRepositoryAuto))
+	assert.NoError(t, locate("1.0.0", refreshRepositoryAuto))
+	assert.Equal(t, 1, server.requests, "the cached index is used within the TTL")
+
+	server.setVersions("1.0.0", "1.1.0")
+	assert.Error(t, locate("1.1.0", refreshRepositoryNever))
+	assert.Equal(t, 1, server.requests)
+	assert.NoError(t, locate("1.1.0", refreshRepositoryAuto))
+	assert.Equal(t, 2, server.requests, "the index is refreshed when the version is not found")
+
+	assert.NoError(t, locate("1.1.0", refreshRepositoryAlways))
+	assert.Equal(t, 3, server.requests)
+}
+
+func TestLocateRepositoryURLChartWithoutTTL(t *testing.T) {
+	ctx := context.Background()
+	server := newTestIndexServer(t, "1.0.0")
+	m := testRepositoryIndexMeta(t, 0)
+
+	for i := 0; i < 2; i++ {
+		_, err := m.locateRepositoryChart(ctx, &action.ChartPathOptions{RepoURL: server.URL, Version: "1.0.0"}, "test-chart", refreshRepositoryAuto)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, server.requests, "without a TTL, the index of a repository URL is downloaded every time")
+}
+
+func TestLocateNamedRepositoryChart(t *testing.T) {
+	ctx := context.Background()
+	server := newTestIndexServer(t, "1.0.0")
+	m := testRepositoryIndexMeta(t, 0)
+
+	f := repo.NewFile()
+	f.Add(&repo.Entry{Name: "test", URL: server.URL})
+	assert.NoError(t, f.WriteFile(m.Settings.RepositoryConfig, 0600))
+	assert.Nil(t, m.namedRepository("other/test-chart"))
+	assert.NotNil(t, m.namedRepository("test/test-chart"))
+
+	locate := func(version, refresh string) error {
+		_, err := m.locateRepositoryChart(ctx, &action.ChartPathOptions{Version: version}, "test/test-chart", refresh)
+		return err
+	}
+
+	assert.NoError(t, locate("1.0.0", refreshRepositoryAuto), "a missing index is downloaded")
+	assert.NoError(t, locate("1.0.0", refreshRepositoryAuto))
+	assert.Equal(t, 1, server.requests)
+
+	server.setVersions("1.0.0", "1.1.0")
+	assert.Error(t, locate("1.1.0", refreshRepositoryNever))
+	assert.NoError(t, locate("1.1.0", refreshRepositoryAuto))
+	assert.Equal(t, 2, server.requests)
+
+	// an expired index is downloaded before locating the chart
+	m.RepositoryCacheTTL = time.Minute
+	old := time.Now().Add(-time.Hour)
+	assert.NoError(t, os.Chtimes(filepath.Join(m.Settings.RepositoryCache, "test-index.yaml"), old, old))
+	assert.NoError(t, locate("1.0.0", refreshRepositoryAuto))
+	assert.Equal(t, 3, server.requests)
+}
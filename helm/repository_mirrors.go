@@ -0,0 +1,57 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/registry"
+)
+
+// locateMirroredChart tries the repository_mirrors of a release in order, after the chart could
+// not be downloaded from its repository with err, and records the mirror the chart is downloaded
+// from in repository_mirror. Mirrors equal to the repository are skipped.
+func locateMirroredChart(ctx context.Context, model *HelmReleaseModel, cpo *action.ChartPathOptions, locate func(*action.ChartPathOptions, string) (string, error), err error) (string, error) {
+	repository := model.Repository.ValueString()
+	errs := []string{err.Error()}
+	for _, e := range model.RepositoryMirrors.Elements() {
+		mirror, ok := e.(types.String)
+		if !ok || mirror.ValueString() == "" || mirror.ValueString() == repository {
+			continue
+		}
+		mirrorCPO, name := mirrorChartPathOptions(cpo, repository, mirror.ValueString(), strings.TrimSpace(model.Chart.ValueString()))
+		subsystemDebug(ctx, logSubsystemDownload, fmt.Sprintf("Downloading chart %s from mirror %s", name, mirror.ValueString()))
+		path, err := locate(mirrorCPO, name)
+		if err == nil {
+			model.RepositoryMirror = mirror
+			return path, nil
+		}
+		errs = append(errs, fmt.Sprintf("mirror %s: %s", mirror.ValueString(), err))
+	}
+	return "", errors.New(strings.Join(errs, "; "))
+}
+
+// mirrorChartPathOptions returns the chart path options and the chart name to download chart from
+// mirror in place of repository. As for the charts of a repository hosted elsewhere, the
+// credentials of the repository are only passed to mirrors on other hosts with pass_credentials.
+func mirrorChartPathOptions(cpo *action.ChartPathOptions, repository, mirror, chart string) (*action.ChartPathOptions, string) {
+	mirrorCPO := *cpo
+	if registry.IsOCI(mirror) {
+		// as for OCI repositories, LocateChart expects the chart name to contain the full OCI path
+		mirrorCPO.RepoURL = ""
+		chart = strings.TrimSuffix(mirror, "/") + "/" + chart
+	} else {
+		mirrorCPO.RepoURL, chart, _ = buildChartNameWithRepository(mirror, chart)
+	}
+	if !cpo.PassCredentialsAll && !sameHost(repository, mirror) {
+		mirrorCPO.Username = ""
+		mirrorCPO.Password = ""
+	}
+	return &mirrorCPO, chart
+}
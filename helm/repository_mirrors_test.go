@@ -0,0 +1,78 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+func TestLocateReleaseChartMirrors(t *testing.T) {
+	primary := newTestIndexServer(t, "1.0.0")
+	mirror := newTestIndexServer(t, "1.0.0")
+	m := testRepositoryIndexMeta(t, 0)
+
+	model := &HelmReleaseModel{
+		Name:             types.StringValue("test"),
+		Chart:            types.StringValue("test-chart"),
+		Repository:       types.StringValue(primary.URL),
+		DownloadProxyURL: types.StringNull(),
+		ResolveOverrides: types.MapNull(types.StringType),
+		RepositoryMirrors: types.ListValueMust(types.StringType, []attr.Value{
+			types.StringValue(primary.URL),
+			types.StringValue(mirror.URL),
+		}),
+	}
+	locate := func() (string, error) {
+		cpo := &action.ChartPathOptions{RepoURL: primary.URL, Version: "1.0.0"}
+		path, diags := locateReleaseChart(context.Background(), model, m, cpo, "test-chart")
+		if diags.HasError() {
+			return "", assert.AnError
+		}
+		return path, nil
+	}
+
+	_, err := locate()
+	require.NoError(t, err)
+	assert.True(t, model.RepositoryMirror.IsNull())
+	assert.Equal(t, 0, mirror.requests)
+
+	// the mirror is used when the chart cannot be downloaded from the repository, and the
+	// repository listed in the mirrors is not tried again
+	primary.Close()
+	requests := primary.requests
+	path, err := locate()
+	require.NoError(t, err)
+	assert.FileExists(t, path)
+	assert.Equal(t, mirror.URL, model.RepositoryMirror.ValueString())
+	assert.Equal(t, requests, primary.requests)
+
+	mirror.Close()
+	_, err = locate()
+	assert.Error(t, err)
+}
+
+func TestMirrorChartPathOptions(t *testing.T) {
+	cpo := &action.ChartPathOptions{RepoURL: "https://charts.example.com", Username: "user", Password: "secret", Version: "1.0.0"}
+
+	mirrorCPO, name := mirrorChartPathOptions(cpo, cpo.RepoURL, "https://mirror.example.com/charts", "app")
+	assert.Equal(t, "https://mirror.example.com/charts", mirrorCPO.RepoURL)
+	assert.Equal(t, "app", name)
+	assert.Equal(t, "1.0.0", mirrorCPO.Version)
+	assert.Empty(t, mirrorCPO.Username, "credentials are not passed to other hosts")
+
+	mirrorCPO, _ = mirrorChartPathOptions(cpo, cpo.RepoURL, "https://charts.example.com/mirror", "app")
+	assert.Equal(t, "user", mirrorCPO.Username)
+
+	mirrorCPO, name = mirrorChartPathOptions(cpo, cpo.RepoURL, "oci://registry.example.com/charts/", "app")
+	assert.Empty(t, mirrorCPO.RepoURL)
+	assert.Equal(t, "oci://registry.example.com/charts/app", name)
+	assert.Equal(t, "https://charts.example.com", cpo.RepoURL, "the options of the repository are not modified")
+}
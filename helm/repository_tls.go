@@ -0,0 +1,117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+// RepositoryTLSConfigModel configures the TLS connections to chart repositories
+type RepositoryTLSConfigModel struct {
+	CABundle              types.String `tfsdk:"ca_bundle"`
+	ClientCertificate     types.String `tfsdk:"client_certificate"`
+	ClientKey             types.String `tfsdk:"client_key"`
+	InsecureSkipTLSVerify types.Bool   `tfsdk:"insecure_skip_tls_verify"`
+}
+
+func repositoryTLSSchema() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"ca_bundle": schema.StringAttribute{
+			Optional:    true,
+			Description: "PEM encoded certificates of the authorities chart repositories are verified with.",
+		},
+		"client_certificate": schema.StringAttribute{
+			Optional:    true,
+			Description: "PEM encoded client certificate presented to chart repositories requiring mutual TLS.",
+		},
+		"client_key": schema.StringAttribute{
+			Optional:    true,
+			Sensitive:   true,
+			Description: "PEM encoded private key of client_certificate.",
+		},
+		"insecure_skip_tls_verify": schema.BoolAttribute{
+			Optional:    true,
+			Description: "Skip the verification of the certificates of chart repositories. This is insecure.",
+		},
+	}
+}
+
+// repositoryTLS holds the files the TLS configuration of the provider is written to, as Helm
+// only reads certificates and keys from files
+type repositoryTLS struct {
+	CAFile                string
+	CertFile              string
+	KeyFile               string
+	InsecureSkipTLSVerify bool
+}
+
+// newRepositoryTLS validates the TLS configuration of chart repositories and writes its
+// certificates and key to files of a new directory only readable by the user
+func newRepositoryTLS(config *RepositoryTLSConfigModel) (*repositoryTLS, error) {
+	caBundle := config.CABundle.ValueString()
+	cert := config.ClientCertificate.ValueString()
+	key := config.ClientKey.ValueString()
+	if (cert == "") != (key == "") {
+		return nil, errors.New("client_certificate and client_key must be set together")
+	}
+	if caBundle != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(caBundle)) {
+		return nil, errors.New("ca_bundle does not contain any PEM encoded certificate")
+	}
+	if cert != "" {
+		if _, err := tls.X509KeyPair([]byte(cert), []byte(key)); err != nil {
+			return nil, fmt.Errorf("invalid client_certificate or client_key: %w", err)
+		}
+	}
+
+	t := &repositoryTLS{InsecureSkipTLSVerify: config.InsecureSkipTLSVerify.ValueBool()}
+	if caBundle == "" && cert == "" {
+		return t, nil
+	}
+	dir, err := os.MkdirTemp("", "terraform-provider-helm-tls-")
+	if err != nil {
+		return nil, err
+	}
+	write := func(name, content string) (string, error) {
+		if content == "" {
+			return "", nil
+		}
+		path := filepath.Join(dir, name)
+		return path, os.WriteFile(path, []byte(content), 0o600)
+	}
+	if t.CAFile, err = write("ca.pem", caBundle); err != nil {
+		return nil, err
+	}
+	if t.CertFile, err = write("client.pem", cert); err != nil {
+		return nil, err
+	}
+	if t.KeyFile, err = write("client-key.pem", key); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// apply sets the TLS configuration of the provider in cpo, where a release does not set its own
+// repository_ca_file, repository_cert_file and repository_key_file
+func (t *repositoryTLS) apply(cpo *action.ChartPathOptions) {
+	if t == nil {
+		return
+	}
+	if cpo.CaFile == "" {
+		cpo.CaFile = t.CAFile
+	}
+	if cpo.CertFile == "" && cpo.KeyFile == "" {
+		cpo.CertFile = t.CertFile
+		cpo.KeyFile = t.KeyFile
+	}
+	cpo.InsecureSkipTLSverify = cpo.InsecureSkipTLSverify || t.InsecureSkipTLSVerify
+}
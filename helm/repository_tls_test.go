@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+// testCertificate returns a self-signed PEM encoded certificate and its key
+func testCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "charts.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestNewRepositoryTLS(t *testing.T) {
+	cert, key := testCertificate(t)
+
+	t.Run("files", func(t *testing.T) {
+		tlsConfig, err := newRepositoryTLS(&RepositoryTLSConfigModel{
+			CABundle:          types.StringValue(cert),
+			ClientCertificate: types.StringValue(cert),
+			ClientKey:         types.StringValue(key),
+		})
+		require.NoError(t, err)
+		defer os.RemoveAll(filepath.Dir(tlsConfig.CAFile))
+
+		for path, content := range map[string]string{tlsConfig.CAFile: cert, tlsConfig.CertFile: cert, tlsConfig.KeyFile: key} {
+			b, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, content, string(b))
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		}
+	})
+
+	t.Run("insecure", func(t *testing.T) {
+		tlsConfig, err := newRepositoryTLS(&RepositoryTLSConfigModel{InsecureSkipTLSVerify: types.BoolValue(true)})
+		require.NoError(t, err)
+		assert.Equal(t, &repositoryTLS{InsecureSkipTLSVerify: true}, tlsConfig)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := newRepositoryTLS(&RepositoryTLSConfigModel{ClientCertificate: types.StringValue(cert)})
+		assert.ErrorContains(t, err, "must be set together")
+		_, err = newRepositoryTLS(&RepositoryTLSConfigModel{CABundle: types.StringValue("not a certificate")})
+		assert.ErrorContains(t, err, "ca_bundle")
+		_, err = newRepositoryTLS(&RepositoryTLSConfigModel{ClientCertificate: types.StringValue(cert), ClientKey: types.StringValue(cert)})
+		assert.ErrorContains(t, err, "invalid client_certificate or client_key")
+	})
+}
+
+func TestRepositoryTLSApply(t *testing.T) {
+	tlsConfig := &repositoryTLS{CAFile: "/tls/ca.pem", CertFile: "/tls/client.pem", KeyFile: "/tls/client-key.pem", InsecureSkipTLSVerify: true}
+
+	cpo := &action.ChartPathOptions{}
+	tlsConfig.apply(cpo)
+	assert.Equal(t, &action.ChartPathOptions{CaFile: "/tls/ca.pem", CertFile: "/tls/client.pem", KeyFile: "/tls/client-key.pem", InsecureSkipTLSverify: true}, cpo)
+
+	// the files of a release take precedence
+	cpo = &action.ChartPathOptions{CaFile: "ca.pem", CertFile: "cert.pem", KeyFile: "key.pem"}
+	tlsConfig.apply(cpo)
+	assert.Equal(t, &action.ChartPathOptions{CaFile: "ca.pem", CertFile: "cert.pem", KeyFile: "key.pem", InsecureSkipTLSverify: true}, cpo)
+
+	var none *repositoryTLS
+	cpo = &action.ChartPathOptions{}
+	none.apply(cpo)
+	assert.Equal(t, &action.ChartPathOptions{}, cpo)
+}
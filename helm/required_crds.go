@@ -0,0 +1,140 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+)
+
+// requiredCRDsPollInterval is the interval the conditions of the required CRDs are polled at
+var requiredCRDsPollInterval = 2 * time.Second
+
+var crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// crdEstablished reports whether the CRD has the Established condition, i.e. its custom
+// resources are served by the API server
+func crdEstablished(crd *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == "Established" && condition["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
+
+// pendingCRDs returns the CRDs among names which do not exist or are not established yet
+func pendingCRDs(ctx context.Context, client dynamic.Interface, names []string) ([]string, error) {
+	var pending []string
+	for _, name := range names {
+		crd, err := client.Resource(crdResource).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			pending = append(pending, name)
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("getting CRD %s: %w", name, err)
+		}
+		if !crdEstablished(crd) {
+			pending = append(pending, name)
+		}
+	}
+	return pending, nil
+}
+
+// waitForCRDs waits until the CRDs of names are established
+func waitForCRDs(ctx context.Context, client dynamic.Interface, names []string, timeout time.Duration) error {
+	var pending []string
+	err := wait.PollUntilContextTimeout(ctx, requiredCRDsPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		var err error
+		if pending, err = pendingCRDs(ctx, client, names); err != nil {
+			return false, err
+		}
+		if len(pending) > 0 {
+			tflog.Debug(ctx, fmt.Sprintf("Waiting for CRDs %s to be established", strings.Join(pending, ", ")))
+		}
+		return len(pending) == 0, nil
+	})
+	if err != nil && len(pending) > 0 {
+		return fmt.Errorf("timed out waiting for CRDs %s to be established", strings.Join(pending, ", "))
+	}
+	return err
+}
+
+// requiredCRDsClient returns a dynamic client of the cluster of actionConfig
+func requiredCRDsClient(actionConfig *action.Configuration) (dynamic.Interface, error) {
+	config, err := actionConfig.RESTClientGetter.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	return dynamic.NewForConfig(config)
+}
+
+// invalidateDiscovery drops the cached API discovery of actionConfig, so that the kinds of
+// CRDs established since it was cached are known to Helm
+func invalidateDiscovery(actionConfig *action.Configuration) {
+	if dc, err := actionConfig.RESTClientGetter.ToDiscoveryClient(); err == nil {
+		dc.Invalidate()
+	}
+}
+
+// requiredCRDsPending reports whether a CRD of requires_crds is not established yet, in which case
+// the manifest of the release cannot be rendered at plan time
+func requiredCRDsPending(ctx context.Context, actionConfig *action.Configuration, model *HelmReleaseModel) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	names := expandStringSlice(model.RequiresCRDs.Elements())
+	if len(names) == 0 {
+		return false, diags
+	}
+	client, err := requiredCRDsClient(actionConfig)
+	if err != nil {
+		diags.AddError("Error checking required CRDs", fmt.Sprintf("Unable to create Kubernetes client: %s", err))
+		return false, diags
+	}
+	pending, err := pendingCRDs(ctx, client, names)
+	if err != nil {
+		diags.AddError("Error checking required CRDs", err.Error())
+		return false, diags
+	}
+	if len(pending) > 0 {
+		tflog.Debug(ctx, fmt.Sprintf("CRDs %s are not established yet", strings.Join(pending, ", ")))
+		return true, diags
+	}
+	invalidateDiscovery(actionConfig)
+	return false, diags
+}
+
+// waitForRequiredCRDs waits up to timeout for the CRDs of requires_crds to be established before
+// the release is installed or upgraded
+func waitForRequiredCRDs(ctx context.Context, actionConfig *action.Configuration, model *HelmReleaseModel, timeout time.Duration) diag.Diagnostics {
+	var diags diag.Diagnostics
+	names := expandStringSlice(model.RequiresCRDs.Elements())
+	if len(names) == 0 {
+		return diags
+	}
+	client, err := requiredCRDsClient(actionConfig)
+	if err != nil {
+		diags.AddError("Error waiting for required CRDs", fmt.Sprintf("Unable to create Kubernetes client: %s", err))
+		return diags
+	}
+	if err := waitForCRDs(ctx, client, names, timeout); err != nil {
+		diags.AddError("Error waiting for required CRDs", err.Error())
+		return diags
+	}
+	invalidateDiscovery(actionConfig)
+	return diags
+}
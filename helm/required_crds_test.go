@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func testRequiredCRD(name, established string) *unstructured.Unstructured {
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": name},
+	}}
+	if established != "" {
+		crd.Object["status"] = map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "NamesAccepted", "status": "True"},
+				map[string]interface{}{"type": "Established", "status": established},
+			},
+		}
+	}
+	return crd
+}
+
+func TestPendingCRDs(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{crdResource: "CustomResourceDefinitionList"},
+		testRequiredCRD("servicemonitors.monitoring.coreos.com", "True"),
+		testRequiredCRD("podmonitors.monitoring.coreos.com", "False"),
+		testRequiredCRD("probes.monitoring.coreos.com", ""),
+	)
+
+	pending, err := pendingCRDs(context.Background(), client, []string{
+		"servicemonitors.monitoring.coreos.com",
+		"podmonitors.monitoring.coreos.com",
+		"probes.monitoring.coreos.com",
+		"alertmanagers.monitoring.coreos.com",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"podmonitors.monitoring.coreos.com", "probes.monitoring.coreos.com", "alertmanagers.monitoring.coreos.com"}, pending)
+}
+
+func TestWaitForCRDs(t *testing.T) {
+	requiredCRDsPollInterval = 10 * time.Millisecond
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{crdResource: "CustomResourceDefinitionList"},
+		testRequiredCRD("servicemonitors.monitoring.coreos.com", "True"),
+		testRequiredCRD("podmonitors.monitoring.coreos.com", "False"),
+	)
+
+	assert.NoError(t, waitForCRDs(context.Background(), client, []string{"servicemonitors.monitoring.coreos.com"}, time.Second))
+	err := waitForCRDs(context.Background(), client, []string{"podmonitors.monitoring.coreos.com"}, 50*time.Millisecond)
+	assert.ErrorContains(t, err, "timed out waiting for CRDs podmonitors.monitoring.coreos.com")
+}
@@ -0,0 +1,151 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// Readiness states of the resource_health attribute of helm_release
+const (
+	resourceReady    = "Ready"
+	resourceNotReady = "NotReady"
+)
+
+// healthKinds are the kinds whose readiness the wait of Helm checks
+var healthKinds = map[string]bool{
+	"CustomResourceDefinition": true,
+	"DaemonSet":                true,
+	"Deployment":               true,
+	"Job":                      true,
+	"PersistentVolumeClaim":    true,
+	"Pod":                      true,
+	"ReplicaSet":               true,
+	"ReplicationController":    true,
+	"Service":                  true,
+	"StatefulSet":              true,
+}
+
+// readinessChecker checks the readiness of a resource, see kube.ReadyChecker
+type readinessChecker interface {
+	IsReady(ctx context.Context, v *resource.Info) (bool, error)
+}
+
+// resourceHealth returns the readiness of the resources whose readiness Helm waits for, keyed by
+// kind/namespace/name, e.g. `Ready` or `NotReady: Available: MinimumReplicasUnavailable`
+func resourceHealth(ctx context.Context, checker readinessChecker, resources kube.ResourceList) map[string]string {
+	health := map[string]string{}
+	for _, info := range resources {
+		kind := info.Mapping.GroupVersionKind.Kind
+		if !healthKinds[kind] {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s/%s", strings.ToLower(kind), info.Namespace, info.Name)
+
+		ready, err := checker.IsReady(ctx, info)
+		switch {
+		case err != nil:
+			health[key] = fmt.Sprintf("%s: %s", resourceNotReady, err)
+		case ready:
+			health[key] = resourceReady
+		default:
+			// the live object holds the status the reason is read from
+			if info.Client != nil {
+				if err := info.Get(); err != nil {
+					health[key] = fmt.Sprintf("%s: %s", resourceNotReady, err)
+					continue
+				}
+			}
+			health[key] = fmt.Sprintf("%s: %s", resourceNotReady, notReadyReason(info))
+		}
+	}
+	return health
+}
+
+// notReadyReason describes why a resource is not ready from its status: the waiting reason of
+// its containers, its failing conditions or its count of ready replicas
+func notReadyReason(info *resource.Info) string {
+	u, ok := info.Object.(*unstructured.Unstructured)
+	if !ok {
+		return "not ready"
+	}
+
+	statuses, _, _ := unstructured.NestedSlice(u.Object, "status", "containerStatuses")
+	for _, s := range statuses {
+		status, _ := s.(map[string]interface{})
+		reason, _, _ := unstructured.NestedString(status, "state", "waiting", "reason")
+		if reason != "" {
+			name, _, _ := unstructured.NestedString(status, "name")
+			return fmt.Sprintf("container %s: %s", name, reason)
+		}
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, _ := c.(map[string]interface{})
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		status, _, _ := unstructured.NestedString(condition, "status")
+		// these conditions report a failure when they are true
+		failing := status == "False"
+		if conditionType == "Failed" || conditionType == "ReplicaFailure" {
+			failing = status == "True"
+		}
+		if !failing {
+			continue
+		}
+		reason, _, _ := unstructured.NestedString(condition, "reason")
+		if reason == "" {
+			return conditionType
+		}
+		return fmt.Sprintf("%s: %s", conditionType, reason)
+	}
+
+	if replicas, found, _ := unstructured.NestedInt64(u.Object, "spec", "replicas"); found {
+		ready, _, _ := unstructured.NestedInt64(u.Object, "status", "readyReplicas")
+		return fmt.Sprintf("%d/%d replicas ready", ready, replicas)
+	}
+	return "not ready"
+}
+
+// releaseResourceHealth checks the readiness of the resources of the release r
+func releaseResourceHealth(ctx context.Context, actionConfig *action.Configuration, r *release.Release, checkJobs bool) (types.Map, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	resources, err := actionConfig.KubeClient.Build(bytes.NewBufferString(r.Manifest), false)
+	if err != nil {
+		diags.AddWarning("Error checking resource health", fmt.Sprintf("Unable to build the resources of Helm release %s: %s", r.Name, err))
+		return types.MapNull(types.StringType), diags
+	}
+	clientset, err := actionConfig.KubernetesClientSet()
+	if err != nil {
+		diags.AddWarning("Error checking resource health", fmt.Sprintf("Unable to create Kubernetes client: %s", err))
+		return types.MapNull(types.StringType), diags
+	}
+	checker := kube.NewReadyChecker(clientset, func(string, ...interface{}) {}, kube.PausedAsReady(true), kube.CheckJobs(checkJobs))
+	health, mapDiags := types.MapValueFrom(ctx, types.StringType, resourceHealth(ctx, &checker, resources))
+	diags.Append(mapDiags...)
+	return health, diags
+}
+
+// setResourceHealth records the readiness of the resources of the release in the resource_health
+// attribute of state after Helm waited for them
+func setResourceHealth(ctx context.Context, actionConfig *action.Configuration, state *HelmReleaseModel, r *release.Release) diag.Diagnostics {
+	if !state.Wait.ValueBool() {
+		state.ResourceHealth = types.MapNull(types.StringType)
+		return nil
+	}
+	health, diags := releaseResourceHealth(ctx, actionConfig, r, state.WaitForJobs.ValueBool())
+	state.ResourceHealth = health
+	return diags
+}
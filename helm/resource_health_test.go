@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/kube"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// staticReadinessChecker reports the readiness of resources by name
+type staticReadinessChecker map[string]error
+
+func (c staticReadinessChecker) IsReady(ctx context.Context, v *resource.Info) (bool, error) {
+	err, ok := c[v.Name]
+	if !ok {
+		return true, nil
+	}
+	return false, err
+}
+
+func testHealthInfo(kind, name string, obj map[string]interface{}) *resource.Info {
+	return &resource.Info{
+		Name:      name,
+		Namespace: "default",
+		Mapping:   &meta.RESTMapping{GroupVersionKind: schema.GroupVersionKind{Kind: kind}},
+		Object:    &unstructured.Unstructured{Object: obj},
+	}
+}
+
+func TestResourceHealth(t *testing.T) {
+	resources := kube.ResourceList{
+		testHealthInfo("Deployment", "web", map[string]interface{}{
+			"spec": map[string]interface{}{"replicas": int64(3)},
+			"status": map[string]interface{}{
+				"readyReplicas": int64(1),
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Progressing", "status": "True", "reason": "NewReplicaSetAvailable"},
+					map[string]interface{}{"type": "Available", "status": "False", "reason": "MinimumReplicasUnavailable"},
+				},
+			},
+		}),
+		testHealthInfo("Pod", "worker", map[string]interface{}{
+			"status": map[string]interface{}{
+				"containerStatuses": []interface{}{
+					map[string]interface{}{"name": "worker", "state": map[string]interface{}{"waiting": map[string]interface{}{"reason": "CrashLoopBackOff"}}},
+				},
+			},
+		}),
+		testHealthInfo("Job", "migrate", map[string]interface{}{
+			"status": map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Failed", "status": "True", "reason": "BackoffLimitExceeded"},
+				},
+			},
+		}),
+		testHealthInfo("StatefulSet", "db", map[string]interface{}{
+			"spec": map[string]interface{}{"replicas": int64(2)},
+		}),
+		testHealthInfo("Service", "api", nil),
+		testHealthInfo("PersistentVolumeClaim", "data", nil),
+		testHealthInfo("ConfigMap", "config", nil),
+	}
+	checker := staticReadinessChecker{
+		"web":     nil,
+		"worker":  nil,
+		"migrate": nil,
+		"db":      nil,
+		"data":    errors.New("persistentvolumeclaims \"data\" not found"),
+	}
+
+	assert.Equal(t, map[string]string{
+		"deployment/default/web":             "NotReady: Available: MinimumReplicasUnavailable",
+		"pod/default/worker":                 "NotReady: container worker: CrashLoopBackOff",
+		"job/default/migrate":                "NotReady: Failed: BackoffLimitExceeded",
+		"statefulset/default/db":             "NotReady: 0/2 replicas ready",
+		"service/default/api":                "Ready",
+		"persistentvolumeclaim/default/data": "NotReady: persistentvolumeclaims \"data\" not found",
+	}, resourceHealth(context.Background(), checker, resources))
+}
@@ -5,15 +5,18 @@ package helm
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
-	"maps"
 	"net/url"
 	"os"
 	pathpkg "path"
 	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
@@ -27,8 +30,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/pkg/errors"
 	"helm.sh/helm/v3/pkg/action"
@@ -37,17 +40,16 @@ import (
 
 	"helm.sh/helm/v3/pkg/downloader"
 	"helm.sh/helm/v3/pkg/getter"
-	"helm.sh/helm/v3/pkg/postrender"
 	"helm.sh/helm/v3/pkg/registry"
 	"helm.sh/helm/v3/pkg/release"
-	"k8s.io/helm/pkg/strvals"
-	"sigs.k8s.io/yaml"
 )
 
 var (
-	_ resource.Resource                = &HelmRelease{}
-	_ resource.ResourceWithModifyPlan  = &HelmRelease{}
-	_ resource.ResourceWithImportState = &HelmRelease{}
+	_ resource.Resource                 = &HelmRelease{}
+	_ resource.ResourceWithModifyPlan   = &HelmRelease{}
+	_ resource.ResourceWithImportState  = &HelmRelease{}
+	_ resource.ResourceWithUpgradeState = &HelmRelease{}
+	_ resource.ResourceWithMoveState    = &HelmRelease{}
 )
 
 type HelmRelease struct {
@@ -59,75 +61,191 @@ func NewHelmRelease() resource.Resource {
 }
 
 type HelmReleaseModel struct {
-	Atomic                   types.Bool       `tfsdk:"atomic"`
-	Chart                    types.String     `tfsdk:"chart"`
-	CleanupOnFail            types.Bool       `tfsdk:"cleanup_on_fail"`
-	CreateNamespace          types.Bool       `tfsdk:"create_namespace"`
-	DependencyUpdate         types.Bool       `tfsdk:"dependency_update"`
-	Description              types.String     `tfsdk:"description"`
-	Devel                    types.Bool       `tfsdk:"devel"`
-	DisableCrdHooks          types.Bool       `tfsdk:"disable_crd_hooks"`
-	DisableOpenapiValidation types.Bool       `tfsdk:"disable_openapi_validation"`
-	DisableWebhooks          types.Bool       `tfsdk:"disable_webhooks"`
-	ForceUpdate              types.Bool       `tfsdk:"force_update"`
-	ID                       types.String     `tfsdk:"id"`
-	Keyring                  types.String     `tfsdk:"keyring"`
-	Lint                     types.Bool       `tfsdk:"lint"`
-	Manifest                 types.String     `tfsdk:"manifest"`
-	MaxHistory               types.Int64      `tfsdk:"max_history"`
-	Metadata                 types.Object     `tfsdk:"metadata"`
-	Name                     types.String     `tfsdk:"name"`
-	Namespace                types.String     `tfsdk:"namespace"`
-	PassCredentials          types.Bool       `tfsdk:"pass_credentials"`
-	PostRender               *PostRenderModel `tfsdk:"postrender"`
-	RecreatePods             types.Bool       `tfsdk:"recreate_pods"`
-	Replace                  types.Bool       `tfsdk:"replace"`
-	RenderSubchartNotes      types.Bool       `tfsdk:"render_subchart_notes"`
-	Repository               types.String     `tfsdk:"repository"`
-	RepositoryCaFile         types.String     `tfsdk:"repository_ca_file"`
-	RepositoryCertFile       types.String     `tfsdk:"repository_cert_file"`
-	RepositoryKeyFile        types.String     `tfsdk:"repository_key_file"`
-	RepositoryPassword       types.String     `tfsdk:"repository_password"`
-	RepositoryUsername       types.String     `tfsdk:"repository_username"`
-	ResetValues              types.Bool       `tfsdk:"reset_values"`
-	ReuseValues              types.Bool       `tfsdk:"reuse_values"`
-	Set                      types.List       `tfsdk:"set"`
-	SetList                  types.List       `tfsdk:"set_list"`
-	SetSensitive             types.List       `tfsdk:"set_sensitive"`
-	SkipCrds                 types.Bool       `tfsdk:"skip_crds"`
-	Status                   types.String     `tfsdk:"status"`
-	Timeout                  types.Int64      `tfsdk:"timeout"`
-	Values                   types.List       `tfsdk:"values"`
-	Verify                   types.Bool       `tfsdk:"verify"`
-	Version                  types.String     `tfsdk:"version"`
-	Wait                     types.Bool       `tfsdk:"wait"`
-	WaitForJobs              types.Bool       `tfsdk:"wait_for_jobs"`
+	AllowCrossNamespace             types.Bool   `tfsdk:"allow_cross_namespace"`
+	AllowPrerelease                 types.Bool   `tfsdk:"allow_prerelease"`
+	Atomic                          types.Bool   `tfsdk:"atomic"`
+	AtomicInstall                   types.Bool   `tfsdk:"atomic_install"`
+	AtomicUpgrade                   types.Bool   `tfsdk:"atomic_upgrade"`
+	ChangeSummary                   types.String `tfsdk:"change_summary"`
+	Chart                           types.String `tfsdk:"chart"`
+	ChartDigest                     types.String `tfsdk:"chart_digest"`
+	CleanupOnFail                   types.Bool   `tfsdk:"cleanup_on_fail"`
+	CommonAnnotations               types.Map    `tfsdk:"common_annotations"`
+	CommonLabels                    types.Map    `tfsdk:"common_labels"`
+	ConflictCheck                   types.Bool   `tfsdk:"conflict_check"`
+	CreateNamespace                 types.Bool   `tfsdk:"create_namespace"`
+	CrdPolicy                       types.String `tfsdk:"crd_policy"`
+	DependencyRepositories          types.List   `tfsdk:"dependency_repositories"`
+	DependencyUpdate                types.Bool   `tfsdk:"dependency_update"`
+	DeployAsServiceAccount          types.String `tfsdk:"deploy_as_service_account"`
+	Description                     types.String `tfsdk:"description"`
+	Devel                           types.Bool   `tfsdk:"devel"`
+	DisableCrdHooks                 types.Bool   `tfsdk:"disable_crd_hooks"`
+	DisableHooksFor                 types.List   `tfsdk:"disable_hooks_for"`
+	DisableOpenapiValidation        types.Bool   `tfsdk:"disable_openapi_validation"`
+	DisableWebhooks                 types.Bool   `tfsdk:"disable_webhooks"`
+	DownloadProxyURL                types.String `tfsdk:"download_proxy_url"`
+	DriftDetection                  types.String `tfsdk:"drift_detection"`
+	DryRunMode                      types.String `tfsdk:"dry_run_mode"`
+	DuplicateSetPolicy              types.String `tfsdk:"duplicate_set_policy"`
+	EnableLookupDuringPlan          types.Bool   `tfsdk:"enable_lookup_during_plan"`
+	EnabledSubcharts                types.List   `tfsdk:"enabled_subcharts"`
+	Endpoints                       types.Map    `tfsdk:"endpoints"`
+	EnforceKubeVersion              types.String `tfsdk:"enforce_kube_version"`
+	FailureDumpDir                  types.String `tfsdk:"failure_dump_dir"`
+	ForceUpdate                     types.Bool   `tfsdk:"force_update"`
+	HelmDriver                      types.String `tfsdk:"helm_driver"`
+	HookLogs                        types.Map    `tfsdk:"hook_logs"`
+	HookLogsMaxBytes                types.Int64  `tfsdk:"hook_logs_max_bytes"`
+	HookLogsSensitive               types.Bool   `tfsdk:"hook_logs_sensitive"`
+	HooksManifest                   types.String `tfsdk:"hooks_manifest"`
+	ID                              types.String `tfsdk:"id"`
+	IgnoreValueChanges              types.List   `tfsdk:"ignore_value_changes"`
+	IgnoreMissingDependencies       types.Bool   `tfsdk:"ignore_missing_dependencies"`
+	Keyring                         types.String `tfsdk:"keyring"`
+	KubeContext                     types.String `tfsdk:"kube_context"`
+	Lint                            types.Bool   `tfsdk:"lint"`
+	Manifest                        types.String `tfsdk:"manifest"`
+	ManifestSHA256                  types.String `tfsdk:"manifest_sha256"`
+	ManifestStorage                 types.String `tfsdk:"manifest_storage"`
+	MaxHistory                      types.Int64  `tfsdk:"max_history"`
+	Metadata                        types.Object `tfsdk:"metadata"`
+	MissingNamespacePolicy          types.String `tfsdk:"missing_namespace_policy"`
+	Name                            types.String `tfsdk:"name"`
+	Namespace                       types.String `tfsdk:"namespace"`
+	Namespaces                      types.List   `tfsdk:"namespaces"`
+	NormalizeValues                 types.Bool   `tfsdk:"normalize_values"`
+	OperationConflictTimeout        types.Int64  `tfsdk:"operation_conflict_timeout"`
+	OutOfBandChange                 types.Bool   `tfsdk:"out_of_band_change"`
+	PassCredentials                 types.Bool   `tfsdk:"pass_credentials"`
+	Patches                         types.List   `tfsdk:"patches"`
+	Paused                          types.Bool   `tfsdk:"paused"`
+	Policy                          types.Object `tfsdk:"policy"`
+	PostRender                      types.List   `tfsdk:"postrender"`
+	PreRenderedManifest             types.String `tfsdk:"pre_rendered_manifest"`
+	Prune                           types.Bool   `tfsdk:"prune"`
+	PruneHistoryOnRead              types.Bool   `tfsdk:"prune_history_on_read"`
+	PrunedResources                 types.List   `tfsdk:"pruned_resources"`
+	ReadRetryAttempts               types.Int64  `tfsdk:"read_retry_attempts"`
+	ReadRetryBackoff                types.Int64  `tfsdk:"read_retry_backoff"`
+	RecreatePods                    types.Bool   `tfsdk:"recreate_pods"`
+	RefreshRepository               types.String `tfsdk:"refresh_repository"`
+	Replace                         types.Bool   `tfsdk:"replace"`
+	RenderSubchartNotes             types.Bool   `tfsdk:"render_subchart_notes"`
+	Repository                      types.String `tfsdk:"repository"`
+	RepositoryCaFile                types.String `tfsdk:"repository_ca_file"`
+	RepositoryCertFile              types.String `tfsdk:"repository_cert_file"`
+	RepositoryInsecureSkipTLSVerify types.Bool   `tfsdk:"repository_insecure_skip_tls_verify"`
+	RepositoryKeyFile               types.String `tfsdk:"repository_key_file"`
+	RepositoryMirror                types.String `tfsdk:"repository_mirror"`
+	RepositoryMirrors               types.List   `tfsdk:"repository_mirrors"`
+	RepositoryPassword              types.String `tfsdk:"repository_password"`
+	RepositoryPlainHTTP             types.Bool   `tfsdk:"repository_plain_http"`
+	RepositoryUsername              types.String `tfsdk:"repository_username"`
+	ResetValues                     types.Bool   `tfsdk:"reset_values"`
+	ResolveOverrides                types.Map    `tfsdk:"resolve_overrides"`
+	ResourceHealth                  types.Map    `tfsdk:"resource_health"`
+	RequiresCRDs                    types.List   `tfsdk:"requires_crds"`
+	ReuseValues                     types.Bool   `tfsdk:"reuse_values"`
+	Revision                        types.Int64  `tfsdk:"revision"`
+	RevisionHistory                 types.List   `tfsdk:"revision_history"`
+	Services                        types.Map    `tfsdk:"services"`
+	SensitiveHookLogs               types.Map    `tfsdk:"sensitive_hook_logs"`
+	Set                             types.List   `tfsdk:"set"`
+	SetList                         types.List   `tfsdk:"set_list"`
+	SetSensitive                    types.List   `tfsdk:"set_sensitive"`
+	SkipCrds                        types.Bool   `tfsdk:"skip_crds"`
+	SkipCRDSchemaValidation         types.Bool   `tfsdk:"skip_crd_schema_validation"`
+	SkipSchemaValidation            types.Bool   `tfsdk:"skip_schema_validation"`
+	Status                          types.String `tfsdk:"status"`
+	StatusDetail                    types.String `tfsdk:"status_detail"`
+	StorageNamespace                types.String `tfsdk:"storage_namespace"`
+	StorageObjectName               types.String `tfsdk:"storage_object_name"`
+	StrictVersionAvailability       types.Bool   `tfsdk:"strict_version_availability"`
+	Timeout                         types.Int64  `tfsdk:"timeout"`
+	UpgradeForceStrategy            types.String `tfsdk:"upgrade_force_strategy"`
+	Values                          types.List   `tfsdk:"values"`
+	ValuesChecksum                  types.String `tfsdk:"values_checksum"`
+	ValuesFrom                      types.List   `tfsdk:"values_from"`
+	ValuesMergeStrategy             types.String `tfsdk:"values_merge_strategy"`
+	ValuesURL                       types.List   `tfsdk:"values_url"`
+	Verification                    types.Object `tfsdk:"verification"`
+	Verify                          types.Bool   `tfsdk:"verify"`
+	Version                         types.String `tfsdk:"version"`
+	VersionConstraint               types.String `tfsdk:"version_constraint"`
+	Wait                            types.Bool   `tfsdk:"wait"`
+	WaitForJobs                     types.Bool   `tfsdk:"wait_for_jobs"`
+	WaitForLoadBalancer             types.Bool   `tfsdk:"wait_for_load_balancer"`
+	WaitOverrides                   types.Map    `tfsdk:"wait_overrides"`
+	WarnOnDeprecated                types.Bool   `tfsdk:"warn_on_deprecated"`
 }
 
 var defaultAttributes = map[string]interface{}{
-	"atomic":                     false,
-	"cleanup_on_fail":            false,
-	"create_namespace":           false,
-	"dependency_update":          false,
-	"disable_crd_hooks":          false,
-	"disable_openapi_validation": false,
-	"disable_webhooks":           false,
-	"force_update":               false,
-	"lint":                       false,
-	"max_history":                int64(0),
-	"pass_credentials":           false,
-	"recreate_pods":              false,
-	"render_subchart_notes":      true,
-	"replace":                    false,
-	"reset_values":               false,
-	"reuse_values":               false,
-	"skip_crds":                  false,
-	"timeout":                    int64(300),
-	"verify":                     false,
-	"wait":                       true,
-	"wait_for_jobs":              false,
+	"allow_cross_namespace":               true,
+	"allow_prerelease":                    false,
+	"atomic":                              false,
+	"cleanup_on_fail":                     false,
+	"conflict_check":                      false,
+	"create_namespace":                    false,
+	"dependency_update":                   false,
+	"disable_crd_hooks":                   false,
+	"disable_openapi_validation":          false,
+	"disable_webhooks":                    false,
+	"drift_detection":                     driftDetectionManifest,
+	"dry_run_mode":                        dryRunModeClient,
+	"duplicate_set_policy":                duplicateSetPolicyLast,
+	"enable_lookup_during_plan":           false,
+	"enforce_kube_version":                enforceKubeVersionWarn,
+	"force_update":                        false,
+	"hook_logs_max_bytes":                 int64(10240),
+	"hook_logs_sensitive":                 false,
+	"ignore_missing_dependencies":         false,
+	"lint":                                false,
+	"manifest_storage":                    manifestStorageFull,
+	"max_history":                         int64(0),
+	"missing_namespace_policy":            missingNamespaceRemove,
+	"normalize_values":                    false,
+	"operation_conflict_timeout":          int64(0),
+	"pass_credentials":                    false,
+	"paused":                              false,
+	"prune":                               true,
+	"prune_history_on_read":               false,
+	"read_retry_attempts":                 int64(0),
+	"read_retry_backoff":                  int64(1),
+	"refresh_repository":                  refreshRepositoryAuto,
+	"recreate_pods":                       false,
+	"render_subchart_notes":               true,
+	"replace":                             false,
+	"repository_insecure_skip_tls_verify": false,
+	"repository_plain_http":               false,
+	"reset_values":                        false,
+	"reuse_values":                        false,
+	"skip_crds":                           false,
+	"skip_crd_schema_validation":          false,
+	"skip_schema_validation":              false,
+	"strict_version_availability":         false,
+	"timeout":                             int64(300),
+	"upgrade_force_strategy":              upgradeForceNone,
+	"values_merge_strategy":               valuesMergeHelm,
+	"verify":                              false,
+	"wait":                                true,
+	"wait_for_jobs":                       false,
+	"wait_for_load_balancer":              false,
+	"warn_on_deprecated":                  true,
 }
 
+const (
+	driftDetectionNone     = "none"
+	driftDetectionMetadata = "metadata"
+	driftDetectionManifest = "manifest"
+)
+
+// Modes of the dry runs rendering the manifest on plan
+const (
+	dryRunModeClient = "client"
+	dryRunModeServer = "server"
+)
+
 type releaseMetaData struct {
 	AppVersion    types.String `tfsdk:"app_version"`
 	Chart         types.String `tfsdk:"chart"`
@@ -138,6 +256,10 @@ type releaseMetaData struct {
 	Values        types.String `tfsdk:"values"`
 	FirstDeployed types.Int64  `tfsdk:"first_deployed"`
 	LastDeployed  types.Int64  `tfsdk:"last_deployed"`
+	Home          types.String `tfsdk:"home"`
+	Icon          types.String `tfsdk:"icon"`
+	Sources       types.List   `tfsdk:"sources"`
+	Dependencies  types.List   `tfsdk:"dependencies"`
 }
 type setResourceModel struct {
 	Name  types.String `tfsdk:"name"`
@@ -250,37 +372,121 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 	resp.Schema = schema.Schema{
 		Description: "Schema to define attributes that are available in the resource",
 		Attributes: map[string]schema.Attribute{
+			"allow_cross_namespace": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(defaultAttributes["allow_cross_namespace"].(bool)),
+				Description: "Allow the chart to create objects in namespaces other than the namespace of the release. If false, the installation or upgrade fails when the rendered manifests contain such objects",
+			},
+			"allow_prerelease": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(defaultAttributes["allow_prerelease"].(bool)),
+				Description: "Match prerelease chart versions with version_constraint, e.g. 1.3.0-rc.1 with `>=1.2.0 <2.0.0`. Without version_constraint, the latest version, prereleases included, is installed",
+			},
 			"atomic": schema.BoolAttribute{
 				Optional:    true,
 				Computed:    true,
 				Default:     booldefault.StaticBool(defaultAttributes["atomic"].(bool)),
 				Description: "If set, installation process purges chart on fail. The wait flag will be set automatically if atomic is used",
 			},
+			"atomic_install": schema.BoolAttribute{
+				Optional:    true,
+				Description: "If set, the installation process purges the chart on fail, in place of atomic. Unset, atomic applies to installs",
+			},
+			"atomic_upgrade": schema.BoolAttribute{
+				Optional:    true,
+				Description: "If set, the upgrade process rolls back the release on fail, in place of atomic. Unset, atomic applies to upgrades",
+			},
+			"change_summary": schema.StringAttribute{
+				Computed:    true,
+				Description: "Summary of the planned change of the release: the action (install, upgrade or no-op), the chart version transition and, with the manifest experiment, the count of added, changed and removed manifest documents",
+			},
 			"chart": schema.StringAttribute{
 				Required:    true,
 				Description: "Chart name to be installed. A path may be used",
 			},
+			"chart_digest": schema.StringAttribute{
+				Computed:    true,
+				Description: "Digest of the chart when it is referenced by an OCI digest, e.g. `oci://registry/charts/app@sha256:<digest>`",
+			},
 			"cleanup_on_fail": schema.BoolAttribute{
 				Optional:    true,
 				Computed:    true,
 				Default:     booldefault.StaticBool(defaultAttributes["cleanup_on_fail"].(bool)),
 				Description: "Allow deletion of new resources created in this upgrade when upgrade fails",
 			},
+			"common_annotations": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Annotations set on every object rendered by the chart, hooks excluded, overriding the annotations set by the chart",
+			},
+			"common_labels": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Labels set on every object rendered by the chart, hooks excluded, overriding the labels set by the chart. Selectors are left unchanged",
+			},
+			"conflict_check": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(defaultAttributes["conflict_check"].(bool)),
+				Description: "Check that no object of the release belongs to another Helm release before installing or upgrading it",
+			},
 			"create_namespace": schema.BoolAttribute{
 				Optional:    true,
 				Computed:    true,
 				Default:     booldefault.StaticBool(defaultAttributes["create_namespace"].(bool)),
 				Description: "Create the namespace if it does not exist",
 			},
+			"crd_policy": schema.StringAttribute{
+				Optional:    true,
+				Description: "How the CRDs of the crds/ directories of the chart and of its dependencies are handled: `skip`, `install-once`, which installs the missing CRDs and never upgrades them, or `manage`, which also creates or replaces them on upgrade. Defaults to `skip` with skip_crds and to `install-once` otherwise",
+				Validators: []validator.String{
+					stringvalidator.OneOf(crdPolicies...),
+					stringvalidator.ConflictsWith(path.MatchRoot("skip_crds")),
+				},
+			},
+			"dependency_repositories": schema.ListNestedAttribute{
+				Optional:    true,
+				Description: "Credentials of the repositories of the dependencies of the chart, used to update the dependencies in place of the credentials of the repository config file",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"url": schema.StringAttribute{
+							Required:    true,
+							Description: "URL of the repository, as in the dependencies of the chart",
+						},
+						"username": schema.StringAttribute{
+							Optional:    true,
+							Description: "Username for HTTP basic authentication",
+						},
+						"password": schema.StringAttribute{
+							Optional:    true,
+							Sensitive:   true,
+							Description: "Password for HTTP basic authentication",
+						},
+						"ca_file": schema.StringAttribute{
+							Optional:    true,
+							Description: "The Repositories CA file",
+						},
+					},
+				},
+			},
 			"dependency_update": schema.BoolAttribute{
 				Optional:    true,
 				Computed:    true,
 				Default:     booldefault.StaticBool(defaultAttributes["dependency_update"].(bool)),
 				Description: "Run helm dependency update before installing the chart",
 			},
+			"deploy_as_service_account": schema.StringAttribute{
+				Optional:    true,
+				Description: "Service account, of the form namespace/name, the release is deployed as with a short-lived token requested with the TokenRequest API, in place of the credentials of the provider",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(serviceAccountPattern, "must be of the form namespace/name"),
+				},
+			},
 			"description": schema.StringAttribute{
 				Optional:    true,
-				Description: "Add a custom description",
+				Description: "Add a custom description. The placeholders `{{.Name}}`, `{{.Namespace}}`, `{{.Chart}}`, `{{.Version}}`, `{{.Revision}}`, `{{.Timestamp}}` (RFC 3339, UTC) and `{{.TerraformRunID}}` (the `TFC_RUN_ID` or `TF_RUN_ID` environment variable) are expanded on each install and upgrade, e.g. `deployed by run {{.TerraformRunID}}`",
 				PlanModifiers: []planmodifier.String{
 					suppressDescription(),
 				},
@@ -298,6 +504,15 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 				Default:     booldefault.StaticBool(defaultAttributes["disable_crd_hooks"].(bool)),
 				Description: "Prevent CRD hooks from running, but run other hooks. See helm install --no-crd-hook",
 			},
+			"disable_hooks_for": schema.ListAttribute{
+				Optional:    true,
+				Description: "Hook events whose hooks are not run, e.g. post-delete, while the hooks of the other events run. See disable_webhooks to disable all hooks",
+				ElementType: types.StringType,
+				Validators: []validator.List{
+					listvalidator.UniqueValues(),
+					listvalidator.ValueStringsAre(stringvalidator.OneOf(disableableHookEvents...)),
+				},
+			},
 			"disable_openapi_validation": schema.BoolAttribute{
 				Optional:    true,
 				Computed:    true,
@@ -310,15 +525,123 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 				Default:     booldefault.StaticBool(defaultAttributes["disable_webhooks"].(bool)),
 				Description: "Prevent hooks from running",
 			},
+			"download_proxy_url": schema.StringAttribute{
+				Optional:    true,
+				Description: "URL of the proxy the chart and repository index downloads of the release go through, e.g. http://proxy.example.com:3128. URLs with http, https and socks5 schemes are supported",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(downloadProxyURLPattern, "must be an http://, https:// or socks5:// URL"),
+				},
+			},
+			"drift_detection": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(defaultAttributes["drift_detection"].(string)),
+				Description: "How drift is detected on refresh. `manifest` refreshes the release and renders the manifest on plan, `metadata` only refreshes the Helm release record, `none` skips the refresh entirely",
+				Validators: []validator.String{
+					stringvalidator.OneOf(driftDetectionNone, driftDetectionMetadata, driftDetectionManifest),
+				},
+			},
+			"dry_run_mode": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(defaultAttributes["dry_run_mode"].(string)),
+				Description: "How the manifest is rendered on plan, like `helm --dry-run`. `server` lets templates `lookup` objects of the cluster, so that the planned manifest matches the manifest applied by charts using `lookup`. `client` renders the manifest without cluster access",
+				Validators: []validator.String{
+					stringvalidator.OneOf(dryRunModeClient, dryRunModeServer),
+				},
+			},
+			"duplicate_set_policy": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(defaultAttributes["duplicate_set_policy"].(string)),
+				Description: "How a name set more than once by set, set_list or set_sensitive is handled: `last` merges the entries in order, the last one winning, `error` fails the plan",
+				Validators: []validator.String{
+					stringvalidator.OneOf(duplicateSetPolicies...),
+				},
+			},
+			"enable_lookup_during_plan": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(defaultAttributes["enable_lookup_during_plan"].(bool)),
+				Description: "Render the manifest on plan with `dry_run_mode` `server`, so that the `lookup` template function returns the objects of the cluster. Plans then make additional API requests, with a client that is not allowed to modify the cluster",
+			},
+			"enabled_subcharts": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Dependencies of the chart enabled by their condition and tags with the values of the release, sorted by name. Subcharts of subcharts are prefixed with the name of their parent",
+			},
+			"endpoints": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "External IPs and hostnames of the Services of type LoadBalancer and the Ingresses of the release, comma separated and keyed by kind/namespace/name, e.g. service/default/web. Only set with wait_for_load_balancer.",
+			},
+			"enforce_kube_version": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(defaultAttributes["enforce_kube_version"].(string)),
+				Description: "What to do on plan when the Kubernetes version of the cluster does not satisfy the kubeVersion constraint of the chart: `warn`, `error` or `ignore`",
+				Validators: []validator.String{
+					stringvalidator.OneOf(enforceKubeVersionModes...),
+				},
+			},
+			"failure_dump_dir": schema.StringAttribute{
+				Optional:    true,
+				Description: "Directory the resources which are not ready and the recent events are written to when an install or upgrade waiting for the resources fails",
+			},
 			"force_update": schema.BoolAttribute{
 				Optional:    true,
 				Computed:    true,
 				Default:     booldefault.StaticBool(defaultAttributes["force_update"].(bool)),
 				Description: "Force resource update through delete/recreate if needed.",
 			},
+			"helm_driver": schema.StringAttribute{
+				Optional:    true,
+				Description: "The backend storage driver of the release. Values are: configmap, secret, memory, sql. Defaults to the helm_driver of the provider",
+				Validators: []validator.String{
+					stringvalidator.OneOf(helmDrivers...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"hook_logs": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Logs of the containers of the pods of the hook Jobs run by the last install or upgrade, keyed by namespace/name of the Job, e.g. default/db-migrate. Captured when the Jobs complete or fail, and limited to the last hook_logs_max_bytes bytes of each Job. Only set with wait_for_jobs, and in sensitive_hook_logs with hook_logs_sensitive.",
+			},
+			"hook_logs_max_bytes": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(defaultAttributes["hook_logs_max_bytes"].(int64)),
+				Description: "Maximum number of bytes of the logs of each hook Job recorded in hook_logs, the end of the logs being kept. Defaults to 10240.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"hook_logs_sensitive": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(defaultAttributes["hook_logs_sensitive"].(bool)),
+				Description: "Record the logs of the hook Jobs in the sensitive attribute sensitive_hook_logs in place of hook_logs, e.g. when migrations print credentials.",
+			},
+			"hooks_manifest": schema.StringAttribute{
+				Computed:    true,
+				Description: "JSON list of the hooks of the release, in the order they run, with their name, kind, path, events, weight, delete policies and rendered manifest. Rendered during plan when the manifest experiment is enabled",
+			},
 			"id": schema.StringAttribute{
 				Computed: true,
 			},
+			"ignore_value_changes": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Dot separated paths of values changed outside of Terraform, e.g. by operators writing back into the values of the release. Their live values are kept on upgrade and they are left out of the values checksum",
+			},
+			"ignore_missing_dependencies": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(defaultAttributes["ignore_missing_dependencies"].(bool)),
+				Description: "If set, dependencies of Chart.yaml missing from the charts/ directory are ignored when they are disabled by their condition or tags",
+			},
 			"keyring": schema.StringAttribute{
 				Optional:    true,
 				Description: "Location of public keys used for verification, Used only if 'verify is true'",
@@ -326,6 +649,13 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 					suppressKeyring(),
 				},
 			},
+			"kube_context": schema.StringAttribute{
+				Optional:    true,
+				Description: "Context of the provider kubeconfig to deploy the release to. The host and the credentials of the provider configuration are ignored. Defaults to the context of the provider configuration",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
 			"lint": schema.BoolAttribute{
 				Optional:    true,
 				Computed:    true,
@@ -333,9 +663,22 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 				Description: "Run helm lint when planning",
 			},
 			"manifest": schema.StringAttribute{
-				Description: "The rendered manifest as JSON.",
+				Description: "The rendered manifest as JSON, represented as `manifest_storage` sets.",
+				Computed:    true,
+			},
+			"manifest_sha256": schema.StringAttribute{
+				Description: "The SHA-256 of the rendered manifest as JSON, whatever `manifest_storage` is.",
 				Computed:    true,
 			},
+			"manifest_storage": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(defaultAttributes["manifest_storage"].(string)),
+				Description: "How the manifest is stored in the state. `full` stores the manifest as JSON, `compressed` stores it gzip compressed and base64 encoded, and `hash` only stores `manifest_sha256`, so that large manifests do not slow down plans. Changing it does not upgrade the release",
+				Validators: []validator.String{
+					stringvalidator.OneOf(manifestStorageFull, manifestStorageHash, manifestStorageCompressed),
+				},
+			},
 			"max_history": schema.Int64Attribute{
 				Optional:    true,
 				Computed:    true,
@@ -354,10 +697,51 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 						Computed:    true,
 						Description: "The name of the chart",
 					},
+					"dependencies": schema.ListNestedAttribute{
+						Computed:    true,
+						Description: "The subcharts of the chart, sorted by name",
+						NestedObject: schema.NestedAttributeObject{
+							Attributes: map[string]schema.Attribute{
+								"app_version": schema.StringAttribute{
+									Computed:    true,
+									Description: "The version number of the application of the subchart",
+								},
+								"home": schema.StringAttribute{
+									Computed:    true,
+									Description: "The URL of the home page of the subchart",
+								},
+								"name": schema.StringAttribute{
+									Computed:    true,
+									Description: "The name of the subchart, or its alias",
+								},
+								"repository": schema.StringAttribute{
+									Computed:    true,
+									Description: "The repository of the subchart in the dependencies of the chart",
+								},
+								"sources": schema.ListAttribute{
+									Computed:    true,
+									Description: "The URLs of the source code of the subchart",
+									ElementType: types.StringType,
+								},
+								"version": schema.StringAttribute{
+									Computed:    true,
+									Description: "The version of the subchart",
+								},
+							},
+						},
+					},
 					"first_deployed": schema.Int64Attribute{
 						Computed:    true,
 						Description: "FirstDeployed is an int64 which represents timestamp when the release was first deployed.",
 					},
+					"home": schema.StringAttribute{
+						Computed:    true,
+						Description: "The URL of the home page of the chart",
+					},
+					"icon": schema.StringAttribute{
+						Computed:    true,
+						Description: "The URL of the icon of the chart",
+					},
 					"last_deployed": schema.Int64Attribute{
 						Computed:    true,
 						Description: "LastDeployed is an int64 which represents timestamp when the release was last deployed.",
@@ -374,6 +758,11 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 						Computed:    true,
 						Description: "Version is an int32 which represents the version of the release",
 					},
+					"sources": schema.ListAttribute{
+						Computed:    true,
+						Description: "The URLs of the source code of the chart",
+						ElementType: types.StringType,
+					},
 					"values": schema.StringAttribute{
 						Computed:    true,
 						Description: "Set of extra values. added to the chart. The sensitive data is cloaked. JSON encoded.",
@@ -384,6 +773,15 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 					},
 				},
 			},
+			"missing_namespace_policy": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(defaultAttributes["missing_namespace_policy"].(string)),
+				Description: "What a refresh does when the namespace of the release was deleted. `remove` removes the release from the state so that it is installed again, `recreate` also creates the namespace again and requires `create_namespace`, `error` fails the refresh",
+				Validators: []validator.String{
+					stringvalidator.OneOf(missingNamespacePolicies...),
+				},
+			},
 			"name": schema.StringAttribute{
 				Required: true,
 				PlanModifiers: []planmodifier.String{
@@ -391,8 +789,9 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 				},
 				Validators: []validator.String{
 					stringvalidator.LengthBetween(1, 53),
+					stringvalidator.RegexMatches(releaseNameRegexp, "must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character"),
 				},
-				Description: "Release name. The length must not be longer than 53 characters",
+				Description: "Release name. The length must not be longer than 53 characters, and it must consist of lower case alphanumeric characters, '-' or '.'",
 			},
 			"namespace": schema.StringAttribute{
 				Optional: true,
@@ -401,21 +800,154 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
-				Description: "Namespace to install the release into",
+				Validators: []validator.String{
+					stringvalidator.LengthAtMost(maxObjectNameLength),
+					stringvalidator.RegexMatches(namespaceRegexp, "must be a valid RFC 1123 label: lower case alphanumeric characters or '-', starting and ending with an alphanumeric character"),
+				},
+				Description: "Namespace to install the release into. It must be a valid RFC 1123 label",
+			},
+			"namespaces": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Sorted list of the namespaces of the objects of the release, including the namespace of the release",
+			},
+			"normalize_values": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(defaultAttributes["normalize_values"].(bool)),
+				Description: "Compare the values documents once parsed, so that changes to their key order, comments, whitespace or number formats only update the state and do not upgrade the release",
+			},
+			"operation_conflict_timeout": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(defaultAttributes["operation_conflict_timeout"].(int64)),
+				Description: "Time in seconds to retry an upgrade while another operation is in progress on the release",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
 			},
 
+			"out_of_band_change": schema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether the release was changed outside of Terraform, e.g. upgraded with the helm CLI, since the last apply",
+			},
 			"pass_credentials": schema.BoolAttribute{
 				Optional:    true,
 				Description: "Pass credentials to all domains",
 				Computed:    true,
 				Default:     booldefault.StaticBool(defaultAttributes["pass_credentials"].(bool)),
 			},
+			"patches": schema.ListNestedAttribute{
+				Optional:    true,
+				Description: "Patches applied to the objects rendered by the chart, hooks excluded, after the postrender binaries",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"kind": schema.StringAttribute{
+							Required:    true,
+							Description: "Kind of the objects to patch",
+						},
+						"name": schema.StringAttribute{
+							Optional:    true,
+							Description: "Name of the object to patch. If not set, all the objects of the kind are patched",
+						},
+						"namespace": schema.StringAttribute{
+							Optional:    true,
+							Description: "Namespace of the objects to patch. Objects without a namespace are in the namespace of the release",
+						},
+						"json_patch": schema.StringAttribute{
+							Optional:    true,
+							Description: "RFC 6902 JSON patch, as JSON or YAML",
+							Validators: []validator.String{
+								stringvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("strategic_merge_patch")),
+							},
+						},
+						"strategic_merge_patch": schema.StringAttribute{
+							Optional:    true,
+							Description: "Strategic merge patch, as YAML. It is applied as a JSON merge patch to custom resources",
+						},
+					},
+				},
+			},
+			"paused": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(defaultAttributes["paused"].(bool)),
+				Description: "Stop reconciling the release: it is not upgraded on update and only its metadata is refreshed on read. The changes planned while paused are rolled out once it is unpaused",
+			},
+			"policy": schema.SingleNestedAttribute{
+				Optional:    true,
+				Description: "Checks of the rendered manifests and hooks of the release, evaluated at plan time. Requires the manifest experiment",
+				Attributes: map[string]schema.Attribute{
+					"cel": schema.ListNestedAttribute{
+						Optional:    true,
+						Description: "CEL expressions every object must satisfy. The object is accessed with the `object` variable, e.g. `object.kind != 'Service' || object.spec.type != 'LoadBalancer'`",
+						NestedObject: schema.NestedAttributeObject{
+							Attributes: map[string]schema.Attribute{
+								"expression": schema.StringAttribute{
+									Required:    true,
+									Description: "CEL expression returning true when the object is allowed",
+								},
+								"message": schema.StringAttribute{
+									Optional:    true,
+									Description: "Message of the violation when the expression returns false",
+								},
+							},
+						},
+					},
+					"enforcement": schema.StringAttribute{
+						Optional:    true,
+						Computed:    true,
+						Default:     stringdefault.StaticString(policyEnforcementDeny),
+						Description: "Report the violations as errors failing the plan with `deny`, or as warnings with `warn`",
+						Validators: []validator.String{
+							stringvalidator.OneOf(policyEnforcementDeny, policyEnforcementWarn),
+						},
+					},
+					"rego_package": schema.StringAttribute{
+						Optional:    true,
+						Computed:    true,
+						Default:     stringdefault.StaticString("main"),
+						Description: "Package of the rego policies whose deny rule is evaluated",
+					},
+					"rego_path": schema.StringAttribute{
+						Optional:    true,
+						Description: "Rego file or directory of rego files evaluated against every object with the opa binary, which must be in the PATH. The messages of the deny rule are the violations, as with conftest",
+					},
+				},
+			},
+			"read_retry_attempts": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(defaultAttributes["read_retry_attempts"].(int64)),
+				Description: "Number of times the release is read again on refresh when it is not found or cannot be read, before it is removed from the state or the refresh fails",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"read_retry_backoff": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(defaultAttributes["read_retry_backoff"].(int64)),
+				Description: "Time in seconds to wait before reading the release again, doubled for each next attempt up to 30 seconds",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
 			"recreate_pods": schema.BoolAttribute{
 				Optional:    true,
 				Computed:    true,
 				Default:     booldefault.StaticBool(defaultAttributes["recreate_pods"].(bool)),
 				Description: "Perform pods restart during upgrade/rollback",
 			},
+			"refresh_repository": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(defaultAttributes["refresh_repository"].(string)),
+				Description: "When the cached index of the chart repository is downloaded again: always, never, or auto, when it is older than the repository_cache_ttl of the provider or does not contain the chart version",
+				Validators: []validator.String{
+					stringvalidator.OneOf(refreshRepositoryModes...),
+				},
+			},
 			"render_subchart_notes": schema.BoolAttribute{
 				Optional:    true,
 				Computed:    true,
@@ -440,52 +972,254 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 				Optional:    true,
 				Description: "The repositories cert file",
 			},
+			"repository_insecure_skip_tls_verify": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Skip the verification of the certificate of the chart repository or OCI registry. This is insecure.",
+				Default:     booldefault.StaticBool(defaultAttributes["repository_insecure_skip_tls_verify"].(bool)),
+			},
 			"repository_key_file": schema.StringAttribute{
 				Optional:    true,
 				Description: "The repositories cert key file",
 			},
+			"repository_mirror": schema.StringAttribute{
+				Computed:    true,
+				Description: "The entry of repository_mirrors the chart was downloaded from on the last install or upgrade, null when it was downloaded from repository",
+			},
+			"repository_mirrors": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Repositories tried in order when the chart cannot be downloaded from repository, e.g. from a flaky or geo-restricted host",
+				Validators: []validator.List{
+					listvalidator.AlsoRequires(path.MatchRoot("repository")),
+					listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
 			"repository_password": schema.StringAttribute{
 				Optional:    true,
 				Sensitive:   true,
 				Description: "Password for HTTP basic authentication",
 			},
+			"repository_plain_http": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Use insecure HTTP connections to the OCI registry of the chart, for registries served without TLS",
+				Default:     booldefault.StaticBool(defaultAttributes["repository_plain_http"].(bool)),
+			},
 			"repository_username": schema.StringAttribute{
 				Optional:    true,
 				Description: "Username for HTTP basic authentication",
 			},
+			"requires_crds": schema.ListAttribute{
+				Optional:    true,
+				Description: "Names of the CRDs the custom resources of the release require, e.g. servicemonitors.monitoring.coreos.com. The manifest is not rendered at plan time until they are established, and the install or upgrade waits up to timeout for them",
+				ElementType: types.StringType,
+				Validators: []validator.List{
+					listvalidator.UniqueValues(),
+				},
+			},
+			"resolve_overrides": schema.MapAttribute{
+				Optional:    true,
+				Description: "IP addresses the chart and repository index downloads of the release connect to, by host name, in place of the addresses the host names resolve to",
+				ElementType: types.StringType,
+			},
 			"reset_values": schema.BoolAttribute{
 				Optional:    true,
 				Computed:    true,
 				Description: "When upgrading, reset the values to the ones built into the chart",
 				Default:     booldefault.StaticBool(defaultAttributes["reset_values"].(bool)),
 			},
+			"pruned_resources": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Sorted list of the objects deleted by the last upgrade because they were removed from the chart, as kind/namespace/name, e.g. deployment/default/web. Only set after an upgrade.",
+			},
+			"resource_health": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Readiness of the resources of the release Helm waits for, keyed by kind/namespace/name, e.g. deployment/default/web, as `Ready` or `NotReady: <reason>`. Only set with wait, after the release is installed or upgraded, including when the wait fails.",
+			},
 			"reuse_values": schema.BoolAttribute{
 				Optional:    true,
 				Computed:    true,
 				Description: "When upgrading, reuse the last release's values and merge in any overrides. If 'reset_values' is specified, this is ignored",
 				Default:     booldefault.StaticBool(defaultAttributes["reuse_values"].(bool)),
 			},
+			"revision": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Revision of the release, as metadata.revision, which is only unknown on plans changing the release",
+			},
+			"revision_history": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The latest revisions of the release, newest first, with their status, chart version and deployment time",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"chart_version": schema.StringAttribute{
+							Computed:    true,
+							Description: "The version of the chart of the revision",
+						},
+						"deployed": schema.StringAttribute{
+							Computed:    true,
+							Description: "The time the revision was deployed, in RFC 3339 format",
+						},
+						"revision": schema.Int64Attribute{
+							Computed:    true,
+							Description: "The number of the revision",
+						},
+						"status": schema.StringAttribute{
+							Computed:    true,
+							Description: "The status of the revision, e.g. deployed, superseded or failed",
+						},
+					},
+				},
+			},
+			"services": schema.MapNestedAttribute{
+				Computed:    true,
+				Description: "Services of the release, keyed by name, or by namespace/name when they are not in the namespace of the release, with their type, cluster IP, ports and load balancer addresses. Only set with wait, after the release is installed or upgraded.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"namespace": schema.StringAttribute{
+							Computed:    true,
+							Description: "Namespace of the Service",
+						},
+						"type": schema.StringAttribute{
+							Computed:    true,
+							Description: "Type of the Service, e.g. ClusterIP or LoadBalancer",
+						},
+						"cluster_ip": schema.StringAttribute{
+							Computed:    true,
+							Description: "Cluster IP of the Service, `None` for headless Services",
+						},
+						"ports": schema.ListNestedAttribute{
+							Computed:    true,
+							Description: "Ports of the Service",
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"name": schema.StringAttribute{
+										Computed:    true,
+										Description: "Name of the port",
+									},
+									"port": schema.Int64Attribute{
+										Computed:    true,
+										Description: "Port of the Service",
+									},
+									"protocol": schema.StringAttribute{
+										Computed:    true,
+										Description: "Protocol of the port, e.g. TCP",
+									},
+									"target_port": schema.StringAttribute{
+										Computed:    true,
+										Description: "Number or name of the port of the pods",
+									},
+									"node_port": schema.Int64Attribute{
+										Computed:    true,
+										Description: "Port of the nodes of NodePort and LoadBalancer Services, 0 otherwise",
+									},
+								},
+							},
+						},
+						"load_balancer_addresses": schema.ListAttribute{
+							Computed:    true,
+							ElementType: types.StringType,
+							Description: "External IPs and hostnames of the load balancer of the Service, empty until one is assigned",
+						},
+					},
+				},
+			},
+			"skip_schema_validation": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(defaultAttributes["skip_schema_validation"].(bool)),
+				Description: "If set, the values are not validated against the values.schema.json files of the chart and its dependencies",
+			},
+			"skip_crd_schema_validation": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(defaultAttributes["skip_crd_schema_validation"].(bool)),
+				Description: "If set, the custom resources of the rendered templates are not validated against the Kubernetes OpenAPI Schema, while the objects of the built-in kinds still are. Useful when the release installs the CRDs of its custom resources",
+			},
 			"skip_crds": schema.BoolAttribute{
 				Optional:    true,
 				Computed:    true,
 				Default:     booldefault.StaticBool(defaultAttributes["skip_crds"].(bool)),
-				Description: "If set, no CRDs will be installed. By default, CRDs are installed if not already present",
+				Description: "If set, no CRDs will be installed. By default, CRDs are installed if not already present. See crd_policy",
 			},
 			"status": schema.StringAttribute{
 				Computed:    true,
 				Description: "Status of the release",
 			},
+			"status_detail": schema.StringAttribute{
+				Computed:    true,
+				Description: "JSON object with the status, revision, description and first and last deployment times of the release, and the revision it superseded",
+			},
+			"storage_namespace": schema.StringAttribute{
+				Computed:    true,
+				Description: "Namespace of the storage records of the release",
+			},
+			"storage_object_name": schema.StringAttribute{
+				Computed:    true,
+				Description: "Name of the Secret, or ConfigMap with the configmap driver, storing the current revision of the release, e.g. `sh.helm.release.v1.web.v3`. Null with the memory and sql drivers",
+			},
+			"strict_version_availability": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(defaultAttributes["strict_version_availability"].(bool)),
+				Description: "Fail the plan, instead of warning, when the pinned version of the chart is no longer listed in the index of its repository",
+			},
 			"timeout": schema.Int64Attribute{
 				Optional:    true,
 				Computed:    true,
 				Default:     int64default.StaticInt64(defaultAttributes["timeout"].(int64)),
 				Description: "Time in seconds to wait for any individual kubernetes operation",
 			},
+			"upgrade_force_strategy": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(defaultAttributes["upgrade_force_strategy"].(string)),
+				Description: "How upgrades resolve the objects they fail to update: `none`, `force-delete-recreate` to delete and recreate the objects whose immutable fields change, or `ssa-force-conflicts` to apply the conflicting objects server-side, taking over the conflicting fields. Defaults to `none`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(upgradeForceStrategies...),
+				},
+			},
 			"values": schema.ListAttribute{
 				Optional:    true,
 				Description: "List of values in raw YAML format to pass to helm",
 				ElementType: types.StringType,
 			},
+			"values_checksum": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 checksum of the merged values of the release, computed from the values with sorted keys",
+			},
+			"values_from": valuesFromSchema(),
+			"values_merge_strategy": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(defaultAttributes["values_merge_strategy"].(string)),
+				Description: "How the values documents are merged: `helm` replaces lists, `strategic` merges the lists of objects by their name, mountPath, containerPort, port or key, e.g. env or containers",
+				Validators: []validator.String{
+					stringvalidator.OneOf(valuesMergeStrategies...),
+				},
+			},
+			"values_url": valuesURLSchema(),
+			"verification": schema.SingleNestedAttribute{
+				Computed:    true,
+				Description: "Result of the provenance verification of the chart with verify: the identities of the signer, the fingerprint of the signing key and the digest of the chart of the provenance file",
+				Attributes: map[string]schema.Attribute{
+					"signed_by": schema.ListAttribute{
+						Computed:    true,
+						ElementType: types.StringType,
+						Description: "Identities of the signer of the chart, e.g. `Helm Signer <signer@example.com>`",
+					},
+					"fingerprint": schema.StringAttribute{
+						Computed:    true,
+						Description: "Fingerprint of the key the chart is signed with",
+					},
+					"chart_digest": schema.StringAttribute{
+						Computed:    true,
+						Description: "Digest of the chart archive of the provenance file, e.g. `sha256:<hex>`",
+					},
+				},
+			},
 			"verify": schema.BoolAttribute{
 				Optional:    true,
 				Computed:    true,
@@ -495,7 +1229,14 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 			"version": schema.StringAttribute{
 				Optional:    true,
 				Computed:    true,
-				Description: "Specify the exact chart version to install. If this is not specified, the latest version is installed",
+				Description: "Specify the exact chart version to install. If this is not specified, the latest version matching version_constraint is installed",
+			},
+			"version_constraint": schema.StringAttribute{
+				Optional:    true,
+				Description: "Semver constraint the chart version is resolved from on every plan, e.g. `>=1.2.0 <2.0.0`. The resolved version is exported as version",
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("version")),
+				},
 			},
 			"wait": schema.BoolAttribute{
 				Optional:    true,
@@ -509,6 +1250,26 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 				Default:     booldefault.StaticBool(defaultAttributes["wait_for_jobs"].(bool)),
 				Description: "If wait is enabled, will wait until all Jobs have been completed before marking the release as successful.",
 			},
+			"wait_for_load_balancer": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(defaultAttributes["wait_for_load_balancer"].(bool)),
+				Description: "Wait until the Services of type LoadBalancer and the Ingresses of the release have an external address after it is deployed, and set them in endpoints.",
+			},
+			"wait_overrides": schema.MapAttribute{
+				Optional:    true,
+				Description: "Timeouts in seconds of the wait for the resources of a kind, e.g. StatefulSet, CustomResourceDefinition or Certificate, in place of timeout. With wait, these resources are waited for after the other resources, and checked again with an exponential backoff. Custom resources are ready when their Ready condition is true",
+				ElementType: types.Int64Type,
+				Validators: []validator.Map{
+					mapvalidator.ValueInt64sAre(int64validator.AtLeast(1)),
+				},
+			},
+			"warn_on_deprecated": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(defaultAttributes["warn_on_deprecated"].(bool)),
+				Description: "Warn at plan time when the chart is deprecated or when the cached index of its repository lists a newer major version",
+			},
 			"set": schema.ListNestedAttribute{
 				Description: "Custom values to be merged with the values",
 				Optional:    true,
@@ -525,7 +1286,7 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 							Computed: true,
 							Default:  stringdefault.StaticString(""),
 							Validators: []validator.String{
-								stringvalidator.OneOf("auto", "string"),
+								stringvalidator.OneOf(setValueTypes...),
 							},
 						},
 					},
@@ -546,6 +1307,12 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 					},
 				},
 			},
+			"sensitive_hook_logs": schema.MapAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				ElementType: types.StringType,
+				Description: "Logs of the hook Jobs, as hook_logs, when hook_logs_sensitive is true.",
+			},
 			"set_sensitive": schema.ListNestedAttribute{
 				Description: "Custom sensitive values to be merged with the values",
 				Optional:    true,
@@ -561,29 +1328,57 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 						"type": schema.StringAttribute{
 							Optional: true,
 							Validators: []validator.String{
-								stringvalidator.OneOf("auto", "string"),
+								stringvalidator.OneOf(setValueTypes...),
 							},
 						},
 					},
 				},
 			},
-			"postrender": schema.SingleNestedAttribute{
-				Description: "Postrender command config",
+			"pre_rendered_manifest": schema.StringAttribute{
 				Optional:    true,
-				Attributes: map[string]schema.Attribute{
-					"args": schema.ListAttribute{
-						Optional:    true,
-						Description: "An argument to the post-renderer (can specify multiple)",
-						ElementType: types.StringType,
-					},
-					"binary_path": schema.StringAttribute{
-						Required:    true,
-						Description: "The common binary path",
+				Description: "Manifest rendered beforehand, e.g. the manifest of the helm_template data source, installed as the release in place of the templates of a chart. The chart and version attributes are the name and version of the chart recorded in the release",
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(
+						path.MatchRoot("repository"),
+						path.MatchRoot("values"),
+						path.MatchRoot("set"),
+						path.MatchRoot("set_list"),
+						path.MatchRoot("set_sensitive"),
+						path.MatchRoot("values_from"),
+					),
+				},
+			},
+			"prune": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(defaultAttributes["prune"].(bool)),
+				Description: "Delete the objects removed from the chart when upgrading. If false, they are left in the cluster, no longer managed by Helm, and listed in a warning",
+			},
+			"prune_history_on_read": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(defaultAttributes["prune_history_on_read"].(bool)),
+				Description: "Delete the oldest revisions of the release on refresh when it has more than max_history revisions, e.g. after upgrades run outside of Terraform",
+			},
+			"postrender": schema.ListNestedAttribute{
+				Description: "Postrender command configs. Post-renderers are executed in the order they are declared, the output of each one being passed as the input of the next",
+				Optional:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"args": schema.ListAttribute{
+							Optional:    true,
+							Description: "An argument to the post-renderer (can specify multiple)",
+							ElementType: types.StringType,
+						},
+						"binary_path": schema.StringAttribute{
+							Required:    true,
+							Description: "The common binary path",
+						},
 					},
 				},
 			},
 		},
-		Version: 1,
+		Version: 2,
 	}
 }
 
@@ -606,40 +1401,20 @@ func (r *HelmRelease) Configure(ctx context.Context, req resource.ConfigureReque
 	r.meta = meta
 }
 
-const sensitiveContentValue = "(sensitive value)"
-
-func cloakSetValue(values map[string]interface{}, valuePath string) {
-	pathKeys := strings.Split(valuePath, ".")
-	sensitiveKey := pathKeys[len(pathKeys)-1]
-	parentPathKeys := pathKeys[:len(pathKeys)-1]
-	m := values
-	for _, key := range parentPathKeys {
-		v, ok := m[key].(map[string]interface{})
-		if !ok {
-			return
-		}
-		m = v
-	}
-	m[sensitiveKey] = sensitiveContentValue
-}
-
-func mergeMaps(a, b map[string]interface{}) map[string]interface{} {
-	out := make(map[string]interface{}, len(a))
-	for k, v := range a {
-		out[k] = v
-	}
-	for k, v := range b {
-		if vMap, ok := v.(map[string]interface{}); ok {
-			if bv, ok := out[k]; ok {
-				if bvMap, ok := bv.(map[string]interface{}); ok {
-					out[k] = mergeMaps(bvMap, vMap)
-					continue
-				}
-			}
-		}
-		out[k] = v
+// operationInterrupted warns when ctx was cancelled by Terraform during an install or
+// upgrade. Helm marks the release as failed but keeps applying the manifest in the background,
+// and a provider stopped before it finished leaves the release with the pending status, which
+// blocks later operations until it is rolled back.
+func operationInterrupted(ctx context.Context, namespace, name, status string) diag.Diagnostics {
+	if ctx.Err() == nil {
+		return nil
 	}
-	return out
+	return diag.Diagnostics{diag.NewWarningDiagnostic(
+		"Helm operation interrupted",
+		fmt.Sprintf("The operation on release %s/%s was cancelled before it completed. If the release is left with the %q status, "+
+			"run `helm rollback %s --namespace %s` or `helm uninstall %s --namespace %s` before running Terraform again.",
+			namespace, name, status, name, namespace, name, namespace),
+	)}
 }
 
 func (r *HelmRelease) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -657,19 +1432,39 @@ func (r *HelmRelease) Create(ctx context.Context, req resource.CreateRequest, re
 		resp.Diagnostics.AddError("Initialization Error", "Meta instance is not initialized")
 		return
 	}
+	ctx, span := meta.startSpan(ctx, "helm_release.create", releaseSpanAttributes(state.Namespace.ValueString(), state.Name.ValueString())...)
+	defer func() { meta.endOperationSpan(ctx, span, resp.Diagnostics) }()
+
+	if meta.Mock {
+		resp.Diagnostics.Append(mockApply(ctx, &state, nil, meta)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+		return
+	}
+
 	namespace := state.Namespace.ValueString()
-	actionConfig, err := meta.GetHelmConfiguration(ctx, namespace)
+	actionConfig, err := meta.GetHelmConfigurationAs(ctx, namespace, state.KubeContext.ValueString(), state.DeployAsServiceAccount.ValueString(), state.HelmDriver.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError("Error getting helm configuration", fmt.Sprintf("Unable to get Helm configuration for namespace %s: %s", namespace, err))
 		return
 	}
-	ociDiags := OCIRegistryLogin(ctx, meta, actionConfig, meta.RegistryClient, state.Repository.ValueString(), state.Chart.ValueString(), state.RepositoryUsername.ValueString(), state.RepositoryPassword.ValueString())
+	unlock, lockDiags := meta.lockRelease(ctx, actionConfig, namespace, state.Name.ValueString(), time.Duration(state.Timeout.ValueInt64())*time.Second, state.CreateNamespace.ValueBool())
+	resp.Diagnostics.Append(lockDiags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer unlock()
+
+	ociDiags := OCIRegistryLogin(ctx, meta, actionConfig, state.Repository.ValueString(), state.Chart.ValueString(), state.RepositoryUsername.ValueString(), state.RepositoryPassword.ValueString(), state.RepositoryPlainHTTP.ValueBool(), state.RepositoryInsecureSkipTLSVerify.ValueBool())
 	resp.Diagnostics.Append(ociDiags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	client := action.NewInstall(actionConfig)
+	skipCRDSchemaValidation(actionConfig, state.SkipCRDSchemaValidation.ValueBool())
 	cpo, chartName, cpoDiags := chartPathOptions(&state, meta, &client.ChartPathOptions)
 	resp.Diagnostics.Append(cpoDiags...)
 	if resp.Diagnostics.HasError() {
@@ -681,6 +1476,12 @@ func (r *HelmRelease) Create(ctx context.Context, req resource.CreateRequest, re
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	verification, verificationDiags := chartVerification(ctx, &state, path)
+	resp.Diagnostics.Append(verificationDiags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.Verification = verification
 
 	updated, depDiags := checkChartDependencies(ctx, &state, c, path, meta)
 	resp.Diagnostics.Append(depDiags...)
@@ -693,8 +1494,11 @@ func (r *HelmRelease) Create(ctx context.Context, req resource.CreateRequest, re
 			return
 		}
 	}
+	if state.SkipSchemaValidation.ValueBool() {
+		skipSchemaValidation(c)
+	}
 
-	values, valuesDiags := getValues(ctx, &state)
+	values, secretPaths, valuesDiags := getReleaseValues(ctx, &state, meta)
 	resp.Diagnostics.Append(valuesDiags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -716,66 +1520,96 @@ func (r *HelmRelease) Create(ctx context.Context, req resource.CreateRequest, re
 	client.Timeout = time.Duration(state.Timeout.ValueInt64()) * time.Second
 	client.Namespace = state.Namespace.ValueString()
 	client.ReleaseName = state.Name.ValueString()
-	client.Atomic = state.Atomic.ValueBool()
-	client.SkipCRDs = state.SkipCrds.ValueBool()
+	client.Atomic = atomicInstall(&state)
+	client.SkipCRDs = crdPolicy(&state) == crdPolicySkip
 	client.SubNotes = state.RenderSubchartNotes.ValueBool()
 	client.DisableOpenAPIValidation = state.DisableOpenapiValidation.ValueBool()
 	client.Replace = state.Replace.ValueBool()
-	client.Description = state.Description.ValueString()
 	client.CreateNamespace = state.CreateNamespace.ValueBool()
+	client.Description, err = releaseDescription(actionConfig, &state, c.Metadata.Name, c.Metadata.Version)
+	if err != nil {
+		resp.Diagnostics.AddError("Error expanding description", err.Error())
+		return
+	}
+
+	pr, prDiags := newPostRenderer(ctx, &state)
+	resp.Diagnostics.Append(prDiags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	client.PostRenderer = pr
 
-	if state.PostRender != nil {
-		binaryPath := state.PostRender.BinaryPath.ValueString()
-		argsList := state.PostRender.Args.Elements()
+	resp.Diagnostics.Append(waitForRequiredCRDs(ctx, actionConfig, &state, client.Timeout)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-		var args []string
-		for _, arg := range argsList {
-			args = append(args, arg.(basetypes.StringValue).ValueString())
-		}
-		tflog.Debug(ctx, fmt.Sprintf("Creating post-renderer with binary path: %s and args: %v", binaryPath, args))
-		pr, err := postrender.NewExec(binaryPath, args...)
-		if err != nil {
-			resp.Diagnostics.AddError("Error creating post-renderer", fmt.Sprintf("Could not create post-renderer: %s", err))
+	if state.ConflictCheck.ValueBool() {
+		resp.Diagnostics.Append(installOwnershipConflicts(ctx, actionConfig, client, c, values)...)
+		if resp.Diagnostics.HasError() {
 			return
 		}
-
-		client.PostRenderer = pr
 	}
 
-	rel, err := client.Run(c, values)
+	installCtx, installSpan := meta.startSpan(ctx, "helm.install", releaseSpanAttributes(namespace, client.ReleaseName)...)
+	meta.traceKubeClient(installCtx, actionConfig)
+	disableHookEvents(installCtx, actionConfig, &state, release.HookPreInstall, release.HookPostInstall)
+	guardHookNamespaces(actionConfig, &state)
+	applyLargeObjects(installCtx, actionConfig, c, values)
+	waitOverrides(installCtx, actionConfig, &state)
+	hookLogs := captureHookLogs(installCtx, actionConfig, &state)
+	installStart := time.Now()
+	rel, err := client.RunWithContext(ctx, c, values)
+	endSpan(installSpan, err)
+	meta.auditOperation(ctx, auditOperationInstall, namespace, client.ReleaseName, c.Metadata.Name, c.Metadata.Version, installStart, err)
+	resp.Diagnostics.Append(operationInterrupted(ctx, namespace, client.ReleaseName, "pending-install")...)
 	if err != nil && rel == nil {
-		resp.Diagnostics.AddError("installation failed", err.Error())
+		resp.Diagnostics.AddError("installation failed", meta.operationErrorDetail(err.Error(), err))
 		return
 	}
 
 	if err != nil && rel != nil {
-		exists, existsDiags := resourceReleaseExists(ctx, state.Name.ValueString(), state.Namespace.ValueString(), meta)
+		exists, existsDiags := resourceReleaseExists(ctx, state.Name.ValueString(), state.Namespace.ValueString(), state.KubeContext.ValueString(), state.HelmDriver.ValueString(), meta)
 		resp.Diagnostics.Append(existsDiags...)
 		if resp.Diagnostics.HasError() {
 			return
 		}
 		if !exists {
-			resp.Diagnostics.AddError("installation failed", err.Error())
+			resp.Diagnostics.AddError("installation failed", meta.operationErrorDetail(err.Error(), err))
 			return
 		}
 
-		diags := setReleaseAttributes(ctx, &state, rel, meta)
+		diags := setReleaseAttributes(ctx, &state, rel, meta, secretPaths)
 		resp.Diagnostics.Append(diags...)
 		if resp.Diagnostics.HasError() {
 			return
 		}
+		resp.Diagnostics.Append(hookLogs.setHookLogs(ctx, &state)...)
 
 		resp.Diagnostics.Append(diag.NewWarningDiagnostic("Helm release created with warnings", fmt.Sprintf("Helm release %q was created but has a failed status. Use the `helm` command to investigate the error, correct it, then run Terraform again.", client.ReleaseName)))
 		resp.Diagnostics.Append(diag.NewErrorDiagnostic("Helm release error", err.Error()))
+		resp.Diagnostics.Append(failureDumpDiagnostics(ctx, actionConfig, &state, rel, atomicInstall(&state))...)
+
+		// the failed release is saved with the health of its resources, so that it can be
+		// investigated from the state
+		resp.Diagnostics.Append(setResourceHealth(ctx, actionConfig, &state, rel)...)
+		resp.Diagnostics.Append(setValuesFromPaths(ctx, resp.Private, secretPaths)...)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 
 		return
 	}
 
-	diags = setReleaseAttributes(ctx, &state, rel, meta)
+	diags = setReleaseAttributes(ctx, &state, rel, meta, secretPaths)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	resp.Diagnostics.Append(hookLogs.setHookLogs(ctx, &state)...)
+	// the release is deployed, it is saved in the state even when its load balancers are not ready
+	resp.Diagnostics.Append(setResourceHealth(ctx, actionConfig, &state, rel)...)
+	resp.Diagnostics.Append(setLoadBalancerEndpoints(ctx, actionConfig, &state, rel)...)
+	resp.Diagnostics.Append(setServices(ctx, actionConfig, &state, rel)...)
+	resp.Diagnostics.Append(setValuesFromPaths(ctx, resp.Private, secretPaths)...)
 
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -803,20 +1637,25 @@ func (r *HelmRelease) Read(ctx context.Context, req resource.ReadRequest, resp *
 		return
 	}
 
-	exists, diags := resourceReleaseExists(ctx, state.Name.ValueString(), state.Namespace.ValueString(), meta)
-	if !exists {
-		resp.State.RemoveResource(ctx)
-		return
-	}
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
+	ctx, span := meta.startSpan(ctx, "helm_release.read", releaseSpanAttributes(state.Namespace.ValueString(), state.Name.ValueString())...)
+	defer func() { meta.endOperationSpan(ctx, span, resp.Diagnostics) }()
+
+	if meta.Mock {
+		// mock releases only exist in the state
 		return
 	}
 
 	logID := fmt.Sprintf("[resourceReleaseRead: %s]", state.Name.ValueString())
-	tflog.Debug(ctx, fmt.Sprintf("%s Started", logID))
+	if meta.ConfigUnknown {
+		tflog.Debug(ctx, fmt.Sprintf("%s Provider configuration unknown, skipping refresh", logID))
+		return
+	}
+	if releaseDriftDetection(meta, &state) == driftDetectionNone {
+		tflog.Debug(ctx, fmt.Sprintf("%s Drift detection disabled, skipping refresh", logID))
+		return
+	}
 
-	c, err := meta.GetHelmConfiguration(ctx, state.Namespace.ValueString())
+	c, err := meta.GetHelmConfigurationAs(ctx, state.Namespace.ValueString(), state.KubeContext.ValueString(), state.DeployAsServiceAccount.ValueString(), state.HelmDriver.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting helm configuration",
@@ -825,6 +1664,38 @@ func (r *HelmRelease) Read(ctx context.Context, req resource.ReadRequest, resp *
 		return
 	}
 
+	clientset, err := c.KubernetesClientSet()
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading release namespace", fmt.Sprintf("Unable to create Kubernetes client: %s", err))
+		return
+	}
+	removed, diags := handleMissingNamespace(ctx, clientset, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if removed {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	readRetryBackoff := time.Duration(state.ReadRetryBackoff.ValueInt64()) * time.Second
+	exists, diags := retryReleaseExists(ctx, state.Name.ValueString(), int(state.ReadRetryAttempts.ValueInt64()), readRetryBackoff, func() (bool, diag.Diagnostics) {
+		return resourceReleaseExists(ctx, state.Name.ValueString(), state.Namespace.ValueString(), state.KubeContext.ValueString(), state.HelmDriver.ValueString(), meta)
+	})
+	// the release is only removed from the state when it is not found, not when the cluster
+	// cannot be reached
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !exists {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("%s Started", logID))
+
 	release, err := getRelease(ctx, meta, c, state.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -834,7 +1705,34 @@ func (r *HelmRelease) Read(ctx context.Context, req resource.ReadRequest, resp *
 		return
 	}
 
-	diags = setReleaseAttributes(ctx, &state, release, meta)
+	if state.PruneHistoryOnRead.ValueBool() && !state.Paused.ValueBool() {
+		pruned, err := pruneReleaseHistory(ctx, c, state.Name.ValueString(), int(state.MaxHistory.ValueInt64()))
+		if err != nil {
+			resp.Diagnostics.AddWarning(
+				"Unable to prune release history",
+				fmt.Sprintf("Unable to prune the history of Helm release %s: %s", state.Name.ValueString(), err),
+			)
+		}
+		tflog.Debug(ctx, fmt.Sprintf("%s Pruned %d revisions", logID, pruned))
+	}
+
+	var outOfBand []string
+	if !state.Paused.ValueBool() {
+		// the state of a paused release records changes that are not rolled out yet
+		ignoredPaths, diags := ignoredValuePaths(ctx, &state)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		outOfBand = outOfBandChanges(&state, release, ignoredPaths)
+	}
+	manifest, manifestSHA256 := state.Manifest, state.ManifestSHA256
+	secretPaths, diags := recordedValuesFromPaths(ctx, meta, &state, resp.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = setReleaseAttributes(ctx, &state, release, meta, secretPaths)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		resp.Diagnostics.AddError(
@@ -843,6 +1741,15 @@ func (r *HelmRelease) Read(ctx context.Context, req resource.ReadRequest, resp *
 		)
 		return
 	}
+	if releaseDriftDetection(meta, &state) == driftDetectionMetadata || state.Paused.ValueBool() {
+		// only the release record is checked for drift, keep the manifest known from the last apply
+		state.Manifest = manifest
+		state.ManifestSHA256 = manifestSHA256
+	}
+	if len(outOfBand) > 0 {
+		state.OutOfBandChange = types.BoolValue(true)
+		resp.Diagnostics.Append(outOfBandChangeDiagnostic(release, outOfBand))
+	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
@@ -866,21 +1773,78 @@ func (r *HelmRelease) Update(ctx context.Context, req resource.UpdateRequest, re
 	logID := fmt.Sprintf("[resourceReleaseUpdate: %s]", state.Name.ValueString())
 	tflog.Debug(ctx, fmt.Sprintf("%s Started", logID))
 
+	if plan.Paused.ValueBool() {
+		tflog.Debug(ctx, fmt.Sprintf("%s Release is paused, skipping upgrade", logID))
+		resp.Diagnostics.AddWarning(
+			"Release is paused",
+			fmt.Sprintf("Helm release %s is paused, it was not upgraded. The changes are rolled out once paused is set to false.", plan.Name.ValueString()),
+		)
+		plan = pausedState(plan, state)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+		return
+	}
+
+	if cosmeticValuesChange(ctx, req.State, plan, state) {
+		tflog.Debug(ctx, fmt.Sprintf("%s Only the formatting of the values changed, skipping upgrade", logID))
+		plan = pausedState(plan, state)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+		return
+	}
+
 	meta := r.meta
 	namespace := state.Namespace.ValueString()
+	ctx, span := meta.startSpan(ctx, "helm_release.update", releaseSpanAttributes(namespace, plan.Name.ValueString())...)
+	defer func() { meta.endOperationSpan(ctx, span, resp.Diagnostics) }()
+
+	if meta.Mock {
+		resp.Diagnostics.Append(mockApply(ctx, &plan, &state, meta)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+		return
+	}
+
 	tflog.Debug(ctx, fmt.Sprintf("%s Getting helm configuration for namespace: %s", logID, namespace))
-	actionConfig, err := meta.GetHelmConfiguration(ctx, namespace)
+	// the release is upgraded as the service account of the plan, so that a change of
+	// deploy_as_service_account takes effect on the upgrade it causes
+	actionConfig, err := meta.GetHelmConfigurationAs(ctx, namespace, state.KubeContext.ValueString(), plan.DeployAsServiceAccount.ValueString(), state.HelmDriver.ValueString())
 	if err != nil {
 		tflog.Debug(ctx, fmt.Sprintf("%s Failed to get helm configuration: %v", logID, err))
 		resp.Diagnostics.AddError("Error getting helm configuration", fmt.Sprintf("Unable to get Helm configuration for namespace %s: %s", namespace, err))
 		return
 	}
-	ociDiags := OCIRegistryLogin(ctx, meta, actionConfig, meta.RegistryClient, state.Repository.ValueString(), state.Chart.ValueString(), state.RepositoryUsername.ValueString(), state.RepositoryPassword.ValueString())
+	unlock, lockDiags := meta.lockRelease(ctx, actionConfig, namespace, plan.Name.ValueString(), time.Duration(plan.Timeout.ValueInt64())*time.Second, false)
+	resp.Diagnostics.Append(lockDiags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer unlock()
+
+	if manifestStorageChange(ctx, req.State, plan, state) {
+		tflog.Debug(ctx, fmt.Sprintf("%s Only manifest_storage changed, skipping upgrade", logID))
+		plan = pausedState(plan, state)
+		secretPaths, diags := recordedValuesFromPaths(ctx, meta, &state, req.Private)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		resp.Diagnostics.Append(migrateManifestStorage(ctx, &plan, &state, meta, actionConfig, secretPaths)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+		return
+	}
+
+	// the registry client logged in is the one the chart of the plan is pulled with
+	ociDiags := OCIRegistryLogin(ctx, meta, actionConfig, plan.Repository.ValueString(), plan.Chart.ValueString(), plan.RepositoryUsername.ValueString(), plan.RepositoryPassword.ValueString(), plan.RepositoryPlainHTTP.ValueBool(), plan.RepositoryInsecureSkipTLSVerify.ValueBool())
 	resp.Diagnostics.Append(ociDiags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 	client := action.NewUpgrade(actionConfig)
+	skipCRDSchemaValidation(actionConfig, plan.SkipCRDSchemaValidation.ValueBool())
 
 	cpo, chartName, cpoDiags := chartPathOptions(&plan, meta, &client.ChartPathOptions)
 	resp.Diagnostics.Append(cpoDiags...)
@@ -893,6 +1857,12 @@ func (r *HelmRelease) Update(ctx context.Context, req resource.UpdateRequest, re
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	verification, verificationDiags := chartVerification(ctx, &plan, path)
+	resp.Diagnostics.Append(verificationDiags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.Verification = verification
 
 	// Check and update the chart's depenedcies if it's needed
 	updated, depDiags := checkChartDependencies(ctx, &plan, c, path, meta)
@@ -906,6 +1876,9 @@ func (r *HelmRelease) Update(ctx context.Context, req resource.UpdateRequest, re
 			return
 		}
 	}
+	if plan.SkipSchemaValidation.ValueBool() {
+		skipSchemaValidation(c)
+	}
 
 	client.Devel = plan.Devel.ValueBool()
 	client.Namespace = plan.Namespace.ValueString()
@@ -914,8 +1887,8 @@ func (r *HelmRelease) Update(ctx context.Context, req resource.UpdateRequest, re
 	client.WaitForJobs = plan.WaitForJobs.ValueBool()
 	client.DryRun = false
 	client.DisableHooks = plan.DisableWebhooks.ValueBool()
-	client.Atomic = plan.Atomic.ValueBool()
-	client.SkipCRDs = plan.SkipCrds.ValueBool()
+	client.Atomic = atomicUpgrade(&plan)
+	client.SkipCRDs = crdPolicy(&plan) == crdPolicySkip
 	client.SubNotes = plan.RenderSubchartNotes.ValueBool()
 	client.DisableOpenAPIValidation = plan.DisableOpenapiValidation.ValueBool()
 	client.Force = plan.ForceUpdate.ValueBool()
@@ -924,42 +1897,101 @@ func (r *HelmRelease) Update(ctx context.Context, req resource.UpdateRequest, re
 	client.Recreate = plan.RecreatePods.ValueBool()
 	client.MaxHistory = int(plan.MaxHistory.ValueInt64())
 	client.CleanupOnFail = plan.CleanupOnFail.ValueBool()
-	client.Description = plan.Description.ValueString()
+	client.Description, err = releaseDescription(actionConfig, &plan, c.Metadata.Name, c.Metadata.Version)
+	if err != nil {
+		resp.Diagnostics.AddError("Error expanding description", err.Error())
+		return
+	}
 
-	if plan.PostRender != nil {
-		binaryPath := plan.PostRender.BinaryPath.ValueString()
-		argsList := plan.PostRender.Args.Elements()
+	pr, prDiags := newPostRenderer(ctx, &plan)
+	resp.Diagnostics.Append(prDiags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	client.PostRenderer = pr
 
-		var args []string
-		for _, arg := range argsList {
-			args = append(args, arg.(basetypes.StringValue).ValueString())
-		}
-		tflog.Debug(ctx, fmt.Sprintf("Binary path update method: %s, Args: %v", binaryPath, args))
-		pr, err := postrender.NewExec(binaryPath, args...)
-		if err != nil {
-			resp.Diagnostics.AddError("Error creating post-renderer", fmt.Sprintf("Could not create post-renderer: %s", err))
+	values, secretPaths, valuesDiags := getReleaseValues(ctx, &plan, meta)
+	resp.Diagnostics.Append(valuesDiags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(preserveReleaseIgnoredValues(ctx, &plan, meta, actionConfig, values)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if crdPolicy(&plan) == crdPolicyManage {
+		tflog.Debug(ctx, fmt.Sprintf("%s Upgrading CRDs", logID))
+		if err := upgradeCRDs(ctx, actionConfig, c); err != nil {
+			resp.Diagnostics.AddError("Error upgrading CRDs", err.Error())
 			return
 		}
-		client.PostRenderer = pr
 	}
-	values, valuesDiags := getValues(ctx, &plan)
-	resp.Diagnostics.Append(valuesDiags...)
+
+	resp.Diagnostics.Append(waitForRequiredCRDs(ctx, actionConfig, &plan, client.Timeout)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	name := plan.Name.ValueString()
-	release, err := client.Run(name, c, values)
+	if plan.ConflictCheck.ValueBool() {
+		resp.Diagnostics.Append(upgradeOwnershipConflicts(ctx, actionConfig, client, name, c, values)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	conflictTimeout := time.Duration(plan.OperationConflictTimeout.ValueInt64()) * time.Second
+	upgradeCtx, upgradeSpan := meta.startSpan(ctx, "helm.upgrade", releaseSpanAttributes(namespace, name)...)
+	meta.traceKubeClient(upgradeCtx, actionConfig)
+	disableHookEvents(upgradeCtx, actionConfig, &plan, release.HookPreUpgrade, release.HookPostUpgrade)
+	guardHookNamespaces(actionConfig, &plan)
+	applyLargeObjects(upgradeCtx, actionConfig, c, values)
+	waitOverrides(upgradeCtx, actionConfig, &plan)
+	hookLogs := captureHookLogs(upgradeCtx, actionConfig, &plan)
+	forceUpgradeStrategy(upgradeCtx, actionConfig, plan.UpgradeForceStrategy.ValueString(), client.Timeout)
+	pruning := watchPrune(actionConfig, plan.Prune.ValueBool())
+	upgradeStart := time.Now()
+	release, err := retryOperationConflict(ctx, name, conflictTimeout, func() (*release.Release, error) {
+		return client.RunWithContext(ctx, name, c, values)
+	})
+	endSpan(upgradeSpan, err)
+	meta.auditOperation(ctx, auditOperationUpgrade, namespace, name, c.Metadata.Name, c.Metadata.Version, upgradeStart, err)
+	resp.Diagnostics.Append(operationInterrupted(ctx, namespace, name, "pending-upgrade")...)
 	if err != nil {
-		resp.Diagnostics.AddError("Error upgrading chart", fmt.Sprintf("Upgrade failed: %s", err))
+		resp.Diagnostics.AddError("Error upgrading chart", meta.operationErrorDetail(fmt.Sprintf("Upgrade failed: %s", err), err))
+		resp.Diagnostics.Append(failureDumpDiagnostics(ctx, actionConfig, &plan, release, atomicUpgrade(&plan))...)
+		// the health of the resources and the logs of the hooks of the failed upgrade are
+		// recorded in the prior state
+		save := false
+		if release != nil && plan.Wait.ValueBool() {
+			health, diags := releaseResourceHealth(ctx, actionConfig, release, plan.WaitForJobs.ValueBool())
+			resp.Diagnostics.Append(diags...)
+			state.ResourceHealth = health
+			save = true
+		}
+		if len(hookLogs.logs) > 0 {
+			resp.Diagnostics.Append(hookLogs.setHookLogs(ctx, &state)...)
+			save = true
+		}
+		if save {
+			resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+		}
 		return
 	}
 
-	diags = setReleaseAttributes(ctx, &plan, release, meta)
+	diags = setReleaseAttributes(ctx, &plan, release, meta, secretPaths)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	resp.Diagnostics.Append(pruning.setPrunedResources(ctx, &plan)...)
+	resp.Diagnostics.Append(hookLogs.setHookLogs(ctx, &plan)...)
+	// the release is deployed, it is saved in the state even when its load balancers are not ready
+	resp.Diagnostics.Append(setResourceHealth(ctx, actionConfig, &plan, release)...)
+	resp.Diagnostics.Append(setLoadBalancerEndpoints(ctx, actionConfig, &plan, release)...)
+	resp.Diagnostics.Append(setServices(ctx, actionConfig, &plan, release)...)
+	resp.Diagnostics.Append(setValuesFromPaths(ctx, resp.Private, secretPaths)...)
 
 	diags = resp.State.Set(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
@@ -997,8 +2029,14 @@ func (r *HelmRelease) Delete(ctx context.Context, req resource.DeleteRequest, re
 
 	name := state.Name.ValueString()
 	namespace := state.Namespace.ValueString()
+	ctx, span := meta.startSpan(ctx, "helm_release.delete", releaseSpanAttributes(namespace, name)...)
+	defer func() { meta.endOperationSpan(ctx, span, resp.Diagnostics) }()
+
+	if meta.Mock {
+		return
+	}
 
-	exists, diags := resourceReleaseExists(ctx, name, namespace, meta)
+	exists, diags := resourceReleaseExists(ctx, name, namespace, state.KubeContext.ValueString(), state.HelmDriver.ValueString(), meta)
 	if !exists {
 		return
 	}
@@ -1008,7 +2046,7 @@ func (r *HelmRelease) Delete(ctx context.Context, req resource.DeleteRequest, re
 	}
 
 	// Get Helm configuration
-	actionConfig, err := meta.GetHelmConfiguration(ctx, namespace)
+	actionConfig, err := meta.GetHelmConfigurationAs(ctx, namespace, state.KubeContext.ValueString(), state.DeployAsServiceAccount.ValueString(), state.HelmDriver.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting helm configuration",
@@ -1019,6 +2057,13 @@ func (r *HelmRelease) Delete(ctx context.Context, req resource.DeleteRequest, re
 	}
 	tflog.Debug(ctx, fmt.Sprintf("Retrieved Helm configuration for namespace: %s", namespace))
 
+	unlock, lockDiags := meta.lockRelease(ctx, actionConfig, namespace, name, time.Duration(state.Timeout.ValueInt64())*time.Second, false)
+	resp.Diagnostics.Append(lockDiags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer unlock()
+
 	// Initialize uninstall action
 	uninstall := action.NewUninstall(actionConfig)
 	uninstall.Wait = state.Wait.ValueBool()
@@ -1027,7 +2072,13 @@ func (r *HelmRelease) Delete(ctx context.Context, req resource.DeleteRequest, re
 
 	// Uninstall the release
 	tflog.Info(ctx, fmt.Sprintf("Uninstalling Helm release: %s", name))
+	uninstallCtx, uninstallSpan := meta.startSpan(ctx, "helm.uninstall", releaseSpanAttributes(namespace, name)...)
+	meta.traceKubeClient(uninstallCtx, actionConfig)
+	disableHookEvents(uninstallCtx, actionConfig, &state, release.HookPreDelete, release.HookPostDelete)
+	uninstallStart := time.Now()
 	res, err := uninstall.Run(name)
+	endSpan(uninstallSpan, err)
+	meta.auditOperation(ctx, auditOperationUninstall, namespace, name, state.Chart.ValueString(), state.Version.ValueString(), uninstallStart, err)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error uninstalling release",
@@ -1050,8 +2101,17 @@ func chartPathOptions(model *HelmReleaseModel, meta *Meta, cpo *action.ChartPath
 	chartName := model.Chart.ValueString()
 	repository := model.Repository.ValueString()
 
+	ociRef, pinned, err := ociChartReference(chartName, repository)
+	if err != nil {
+		diags.AddError("Invalid chart reference", err.Error())
+		return nil, "", diags
+	}
+
 	var repositoryURL string
-	if registry.IsOCI(repository) {
+	if pinned {
+		// digest pinned references are pulled as is, see Meta.locateChart
+		chartName = ociRef
+	} else if registry.IsOCI(repository) {
 		// LocateChart expects the chart name to contain the full OCI path
 		u, err := url.Parse(repository)
 		if err != nil {
@@ -1069,7 +2129,11 @@ func chartPathOptions(model *HelmReleaseModel, meta *Meta, cpo *action.ChartPath
 		}
 	}
 
-	version := getVersion(model)
+	version, err := resolveVersion(model)
+	if err != nil {
+		diags.AddError("Invalid version constraint", err.Error())
+		return nil, "", diags
+	}
 
 	cpo.CaFile = model.RepositoryCaFile.ValueString()
 	cpo.CertFile = model.RepositoryCertFile.ValueString()
@@ -1077,12 +2141,15 @@ func chartPathOptions(model *HelmReleaseModel, meta *Meta, cpo *action.ChartPath
 	cpo.Keyring = model.Keyring.ValueString()
 	cpo.RepoURL = repositoryURL
 	cpo.Verify = model.Verify.ValueBool()
-	if !useChartVersion(chartName, cpo.RepoURL) {
+	if !useChartVersion(chartName, cpo.RepoURL) && !pinned {
 		cpo.Version = version
 	}
 	cpo.Username = model.RepositoryUsername.ValueString()
 	cpo.Password = model.RepositoryPassword.ValueString()
 	cpo.PassCredentialsAll = model.PassCredentials.ValueBool()
+	cpo.PlainHTTP = meta.usePlainHTTP(chartName, model.RepositoryPlainHTTP.ValueBool())
+	cpo.InsecureSkipTLSverify = model.RepositoryInsecureSkipTLSVerify.ValueBool()
+	meta.RepositoryTLS.apply(cpo)
 
 	return cpo, chartName, diags
 }
@@ -1118,14 +2185,6 @@ func buildChartNameWithRepository(repository, name string) (string, string, erro
 	return "", name, nil
 }
 
-func getVersion(model *HelmReleaseModel) string {
-	version := model.Version.ValueString()
-	if version == "" && model.Devel.ValueBool() {
-		return ">0.0.0-0"
-	}
-	return strings.TrimSpace(version)
-}
-
 func isChartInstallable(ch *chart.Chart) error {
 	switch ch.Metadata.Type {
 	case "", "application":
@@ -1134,228 +2193,87 @@ func isChartInstallable(ch *chart.Chart) error {
 	return errors.Errorf("%s charts are not installable", ch.Metadata.Type)
 }
 
-func getChart(ctx context.Context, model *HelmReleaseModel, m *Meta, name string, cpo *action.ChartPathOptions) (*chart.Chart, string, diag.Diagnostics) {
-	var diags diag.Diagnostics
-
-	tflog.Debug(ctx, fmt.Sprintf("Helm settings: %+v", m.Settings))
-
-	path, err := cpo.LocateChart(name, m.Settings)
-	if err != nil {
-		diags.AddError("Error locating chart", fmt.Sprintf("Unable to locate chart %s: %s", name, err))
-		return nil, "", diags
-	}
-
-	c, err := loader.Load(path)
-	if err != nil {
-		diags.AddError("Error loading chart", fmt.Sprintf("Unable to load chart %s: %s", path, err))
-		return nil, "", diags
-	}
-
-	return c, path, diags
-}
-
-func getValues(ctx context.Context, model *HelmReleaseModel) (map[string]interface{}, diag.Diagnostics) {
-	base := map[string]interface{}{}
-	var diags diag.Diagnostics
-
-	// Processing "values" attribute
-	for _, raw := range model.Values.Elements() {
-		if raw.IsNull() {
-			continue
-		}
-
-		value, ok := raw.(types.String)
-		if !ok {
-			diags.AddError("Type Error", fmt.Sprintf("Expected types.String, got %T", raw))
-			return nil, diags
-		}
-
-		values := value.ValueString()
-		if values == "" {
-			continue
-		}
-
-		currentMap := map[string]interface{}{}
-		if err := yaml.Unmarshal([]byte(values), &currentMap); err != nil {
-			diags.AddError("Error unmarshaling values", fmt.Sprintf("---> %v %s", err, values))
-			return nil, diags
-		}
-
-		base = mergeMaps(base, currentMap)
-	}
-
-	// Processing "set" attribute
-	if !model.Set.IsNull() {
-		tflog.Debug(ctx, "Processing Set attribute")
-		var setList []setResourceModel
-		setDiags := model.Set.ElementsAs(ctx, &setList, false)
-		diags.Append(setDiags...)
-		if diags.HasError() {
-			return nil, diags
-		}
-
-		for i, set := range setList {
-			tflog.Debug(ctx, fmt.Sprintf("Processing Set element at index %d: %v", i, set))
-			setDiags := getValue(base, set)
-			diags.Append(setDiags...)
-			if diags.HasError() {
-				tflog.Debug(ctx, fmt.Sprintf("Error occurred while processing Set element at index %d", i))
-				return nil, diags
-			}
-		}
-	}
-
-	// Processing "set_list" attribute
-	if !model.SetList.IsUnknown() {
-		tflog.Debug(ctx, "Processing Set_list attribute")
-		var setListSlice []set_listResourceModel
-		setListDiags := model.SetList.ElementsAs(ctx, &setListSlice, false)
-		diags.Append(setListDiags...)
-		if diags.HasError() {
-			tflog.Debug(ctx, "Error occurred while processing Set_list attribute")
-			return nil, diags
-		}
-
-		for i, setList := range setListSlice {
-			tflog.Debug(ctx, fmt.Sprintf("Processing Set_list element at index %d: %v", i, setList))
-			setListDiags := getListValue(ctx, base, setList)
-			diags.Append(setListDiags...)
-			if diags.HasError() {
-				tflog.Debug(ctx, fmt.Sprintf("Error occurred while processing Set_list element at index %d", i))
-				return nil, diags
-			}
-		}
-	}
-
-	// Processing "set_sensitive" attribute
-	if !model.SetSensitive.IsNull() {
-		tflog.Debug(ctx, "Processing Set_Sensitive attribute")
-		var setSensitiveList []setResourceModel
-		setSensitiveDiags := model.SetSensitive.ElementsAs(ctx, &setSensitiveList, false)
-		diags.Append(setSensitiveDiags...)
-		if diags.HasError() {
-			tflog.Debug(ctx, "Error occurred while processing Set_Sensitive attribute")
-			return nil, diags
-		}
-
-		for i, setSensitive := range setSensitiveList {
-			tflog.Debug(ctx, fmt.Sprintf("Processing Set_Sensitive element at index %d: %v", i, setSensitive))
-			setSensitiveDiags := getValue(base, setSensitive)
-			diags.Append(setSensitiveDiags...)
-			if diags.HasError() {
-				tflog.Debug(ctx, fmt.Sprintf("Error occurred while processing Set_Sensitive element at index %d", i))
-				return nil, diags
-			}
-		}
-	}
-
-	tflog.Debug(ctx, fmt.Sprintf("Final merged values: %v", base))
-	logDiags := logValues(ctx, base, model)
-	diags.Append(logDiags...)
-	if diags.HasError() {
-		tflog.Debug(ctx, "Error occurred while logging values")
-		return nil, diags
-	}
-
-	return base, diags
-}
-
-func getValue(base map[string]interface{}, set setResourceModel) diag.Diagnostics {
-	var diags diag.Diagnostics
-
-	name := set.Name.ValueString()
-	value := set.Value.ValueString()
-	valueType := set.Type.ValueString()
-
-	switch valueType {
-	case "auto", "":
-		if err := strvals.ParseInto(fmt.Sprintf("%s=%s", name, value), base); err != nil {
-			diags.AddError("Failed parsing value", fmt.Sprintf("Failed parsing key %q with value %s: %s", name, value, err))
-			return diags
-		}
-	case "string":
-		if err := strvals.ParseIntoString(fmt.Sprintf("%s=%s", name, value), base); err != nil {
-			diags.AddError("Failed parsing string value", fmt.Sprintf("Failed parsing key %q with value %s: %s", name, value, err))
-			return diags
-		}
-	default:
-		diags.AddError("Unexpected type", fmt.Sprintf("Unexpected type: %s", valueType))
-		return diags
+// skipSchemaValidation removes the values schemas of a chart and its dependencies, which
+// Helm validates the values against when rendering
+func skipSchemaValidation(c *chart.Chart) {
+	c.Schema = nil
+	for _, d := range c.Dependencies() {
+		skipSchemaValidation(d)
 	}
-	return diags
 }
 
-func logValues(ctx context.Context, values map[string]interface{}, state *HelmReleaseModel) diag.Diagnostics {
+func getChart(ctx context.Context, model *HelmReleaseModel, m *Meta, name string, cpo *action.ChartPathOptions) (*chart.Chart, string, diag.Diagnostics) {
 	var diags diag.Diagnostics
-	// Cloning values map
-	c := maps.Clone(values)
-
-	cloakSetValues(c, state)
 
-	y, err := yaml.Marshal(c)
-	if err != nil {
-		diags.AddError("Error marshaling map to YAML", fmt.Sprintf("Failed to marshal map to YAML: %s", err))
-		return diags
-	}
-
-	tflog.Debug(ctx, fmt.Sprintf("---[ values.yaml ]-----------------------------------\n%s\n", string(y)))
-
-	return diags
-}
-
-func cloakSetValues(config map[string]interface{}, state *HelmReleaseModel) {
-	if !state.SetSensitive.IsNull() {
-		var setSensitiveList []setResourceModel
-		diags := state.SetSensitive.ElementsAs(context.Background(), &setSensitiveList, false)
-		if diags.HasError() {
-			// Handle diagnostics error
-			return
-		}
-
-		for _, set := range setSensitiveList {
-			cloakSetValue(config, set.Name.ValueString())
+	if !model.PreRenderedManifest.IsNull() {
+		subsystemDebug(ctx, logSubsystemRender, fmt.Sprintf("Using the pre-rendered manifest as chart %s", model.Chart.ValueString()))
+		c := preRenderedChart(model.Chart.ValueString(), model.Version.ValueString(), model.PreRenderedManifest.ValueString())
+		if err := c.Validate(); err != nil {
+			diags.AddError("Invalid pre-rendered manifest chart", fmt.Sprintf("Unable to use the pre-rendered manifest as chart %s: %s", model.Chart.ValueString(), err))
+			return nil, "", diags
 		}
+		return c, "", diags
 	}
-}
-
-func getListValue(ctx context.Context, base map[string]interface{}, set set_listResourceModel) diag.Diagnostics {
-	var diags diag.Diagnostics
 
-	name := set.Name.ValueString()
+	tflog.Debug(ctx, fmt.Sprintf("Helm settings: %+v", m.Settings))
 
-	if set.Value.IsNull() {
-		diags.AddError("Null List Value", "The list value is null.")
-		return diags
+	path, locateDiags := locateReleaseChart(ctx, model, m, cpo, name)
+	diags.Append(locateDiags...)
+	if diags.HasError() {
+		return nil, "", diags
 	}
 
-	// Get the elements of the ListValue
-	elements := set.Value.Elements()
-
-	// Convert elements to a list of strings
-	listStringArray := make([]string, 0, len(elements))
-	for _, element := range elements {
-		if !element.IsNull() {
-			strValue := element.(types.String).ValueString()
-			listStringArray = append(listStringArray, strValue)
-		}
+	c, err := loader.Load(path)
+	if err != nil {
+		diags.AddError("Error loading chart", fmt.Sprintf("Unable to load chart %s: %s", path, err))
+		return nil, "", diags
 	}
 
-	// Join the list into a single string
-	listString := strings.Join(listStringArray, ",")
+	return c, path, diags
+}
+
+func getValues(ctx context.Context, model *HelmReleaseModel, meta *Meta) (map[string]interface{}, diag.Diagnostics) {
+	values, _, diags := getReleaseValues(ctx, model, meta)
+	return values, diags
+}
 
-	if err := strvals.ParseInto(fmt.Sprintf("%s={%s}", name, listString), base); err != nil {
-		diags.AddError("Error parsing list value", fmt.Sprintf("Failed parsing key %q with value %s: %s", name, listString, err))
-		return diags
+// getReleaseValues returns the values of the release, and the paths of the values read from
+// Secrets by values_from, which are cloaked in the metadata
+func getReleaseValues(ctx context.Context, model *HelmReleaseModel, meta *Meta) (map[string]interface{}, [][]string, diag.Diagnostics) {
+	// Processing "values_from" attribute
+	documents, diags := readValuesFrom(ctx, meta, model)
+	if diags.HasError() {
+		return nil, nil, diags
+	}
+	in := valuesInput{
+		Values:             model.Values,
+		Set:                model.Set,
+		SetList:            model.SetList,
+		SetSensitive:       model.SetSensitive,
+		MergeStrategy:      model.ValuesMergeStrategy.ValueString(),
+		DuplicateSetPolicy: model.DuplicateSetPolicy.ValueString(),
+	}
+	for _, d := range documents {
+		in.Documents = append(in.Documents, d.values)
+	}
+	// Processing "values_url" attribute
+	urlDocuments, urlDiags := readValuesURL(ctx, meta, model)
+	diags.Append(urlDiags...)
+	if diags.HasError() {
+		return nil, nil, diags
 	}
+	in.Documents = append(in.Documents, urlDocuments...)
 
-	return diags
+	values, valuesDiags := mergeValues(ctx, in)
+	diags.Append(valuesDiags...)
+	return values, valuesFromPaths(documents), diags
 }
 
 func versionsEqual(a, b string) bool {
 	return strings.TrimPrefix(a, "v") == strings.TrimPrefix(b, "v")
 }
 
-func setReleaseAttributes(ctx context.Context, state *HelmReleaseModel, r *release.Release, meta *Meta) diag.Diagnostics {
+func setReleaseAttributes(ctx context.Context, state *HelmReleaseModel, r *release.Release, meta *Meta, secretPaths [][]string) diag.Diagnostics {
 	var diags diag.Diagnostics
 
 	// Update state with attributes from the helm release
@@ -1367,11 +2285,62 @@ func setReleaseAttributes(ctx context.Context, state *HelmReleaseModel, r *relea
 
 	state.Namespace = types.StringValue(r.Namespace)
 	state.Status = types.StringValue(r.Info.Status.String())
+	helmDriver := state.HelmDriver.ValueString()
+	if helmDriver == "" && meta != nil {
+		helmDriver = meta.HelmDriver
+	}
+	state.StorageNamespace = types.StringValue(r.Namespace)
+	state.StorageObjectName = storageObjectName(helmDriver, r)
 
 	state.ID = types.StringValue(r.Name)
+	state.ChartDigest = chartDigest(state)
+	// charts are only verified when they are downloaded, e.g. not in mock mode
+	if state.Verification.IsUnknown() {
+		state.Verification = types.ObjectNull(verificationAttrTypes())
+	}
+	// the release is up to date with the configuration after an apply, see Read
+	if state.OutOfBandChange.IsNull() || state.OutOfBandChange.IsUnknown() {
+		state.OutOfBandChange = types.BoolValue(false)
+	}
+
+	namespaces, err := manifestNamespaces(r.Manifest, r.Namespace)
+	if err != nil {
+		diags.AddError("Error reading release namespaces", fmt.Sprintf("Unable to read the namespaces of the manifest: %s", err))
+		return diags
+	}
+	namespacesList, listDiags := types.ListValueFrom(ctx, types.StringType, namespaces)
+	diags.Append(listDiags...)
+	if diags.HasError() {
+		return diags
+	}
+	state.Namespaces = namespacesList
+
+	subcharts, subchartDiags := releaseEnabledSubcharts(ctx, r.Chart, r.Config)
+	diags.Append(subchartDiags...)
+	if diags.HasError() {
+		return diags
+	}
+	state.EnabledSubcharts = subcharts
+
+	// The checksum is computed before cloaking so that changes to sensitive values are reflected
+	ignoredPaths, pathDiags := ignoredValuePaths(ctx, state)
+	diags.Append(pathDiags...)
+	if diags.HasError() {
+		return diags
+	}
+	checksum, err := ignoredValuesChecksum(r.Config, ignoredPaths)
+	if err != nil {
+		diags.AddError("Error computing values checksum", fmt.Sprintf("unable to compute values checksum: %s", err))
+		return diags
+	}
+	state.ValuesChecksum = types.StringValue(checksum)
+
+	// the values read from Secrets are redacted from the manifests before they are cloaked
+	sensitiveValues := releaseSensitiveValues(state, r.Config, secretPaths)
 
 	// Cloak sensitive values in the release config
-	cloakSetValues(r.Config, state)
+	cloakSetValues(r.Config, state.SetSensitive, meta.SensitiveValueHashKey)
+	cloakValuesFrom(r.Config, secretPaths, meta.SensitiveValueHashKey)
 	values := "{}"
 	if r.Config != nil {
 		v, err := json.Marshal(r.Config)
@@ -1385,9 +2354,62 @@ func setReleaseAttributes(ctx context.Context, state *HelmReleaseModel, r *relea
 		values = string(v)
 	}
 
+	hooks, err := hooksManifest(r.Hooks, sensitiveValues)
+	if err != nil {
+		diags.AddError("Error converting hooks to JSON", fmt.Sprintf("Unable to convert the hooks of the release to JSON: %s", err))
+		return diags
+	}
+	state.HooksManifest = hooks
+
+	// the endpoints are only set after an install or upgrade waiting for load balancers
+	if state.Endpoints.IsUnknown() {
+		state.Endpoints = types.MapNull(types.StringType)
+	}
+	// the logs of the hook Jobs are only captured by an install or upgrade waiting for them
+	if state.HookLogs.IsUnknown() {
+		state.HookLogs = types.MapNull(types.StringType)
+	}
+	if state.SensitiveHookLogs.IsUnknown() {
+		state.SensitiveHookLogs = types.MapNull(types.StringType)
+	}
+	// the services are only set after an install or upgrade waiting for them
+	if state.Services.IsUnknown() {
+		state.Services = types.MapNull(types.ObjectType{AttrTypes: serviceAttrTypes()})
+	}
+	// the mirror is only known after the chart is downloaded on an install or upgrade
+	if state.RepositoryMirror.IsUnknown() {
+		state.RepositoryMirror = types.StringNull()
+	}
+	// the objects pruned are only known after an upgrade
+	if state.PrunedResources.IsUnknown() {
+		state.PrunedResources = types.ListNull(types.StringType)
+	}
+	// the health of the resources is only checked after an install or upgrade waiting for them
+	if state.ResourceHealth.IsUnknown() {
+		state.ResourceHealth = types.MapNull(types.StringType)
+	}
+	// the change is not summarized by the plans which leave the summary unknown
+	if state.ChangeSummary.IsUnknown() {
+		state.ChangeSummary = types.StringValue(releaseChangeSummary(r))
+	}
+
+	// the superseded revision and the revision history only list r when the history of the
+	// release cannot be read
+	history, err := meta.releaseHistory(ctx, state, r)
+	if err != nil {
+		tflog.Warn(ctx, fmt.Sprintf("Unable to read the history of release %s: %s", r.Name, err))
+		history = []*release.Release{r}
+	}
+	if err := setStatusDetail(state, r, history); err != nil {
+		diags.AddError("Error converting status to JSON", fmt.Sprintf("Unable to convert the status of the release to JSON: %s", err))
+		return diags
+	}
+	state.Revision = types.Int64Value(int64(r.Version))
+	state.RevisionHistory = revisionHistory(r, history)
+
 	// Handling the helm release if manifest experiment is enabled
-	if meta.ExperimentEnabled("manifest") {
-		jsonManifest, err := convertYAMLManifestToJSON(r.Manifest)
+	if meta.FeatureEnabled(featureManifestDiff) {
+		manifest, err := releaseManifest(r, sensitiveValues)
 		if err != nil {
 			diags.AddError(
 				"Error converting manifest to JSON",
@@ -1395,9 +2417,15 @@ func setReleaseAttributes(ctx context.Context, state *HelmReleaseModel, r *relea
 			)
 			return diags
 		}
-		sensitiveValues := extractSensitiveValues(state)
-		manifest := redactSensitiveValues(string(jsonManifest), sensitiveValues)
-		state.Manifest = types.StringValue(manifest)
+		setManifest(state, types.StringValue(manifest))
+	}
+
+	sources, sourcesDiags := chartSources(ctx, r.Chart.Metadata)
+	diags.Append(sourcesDiags...)
+	dependencies, dependenciesDiags := chartDependenciesMetadata(ctx, r.Chart)
+	diags.Append(dependenciesDiags...)
+	if diags.HasError() {
+		return diags
 	}
 
 	// Create metadata as a slice of maps
@@ -1411,6 +2439,10 @@ func setReleaseAttributes(ctx context.Context, state *HelmReleaseModel, r *relea
 		"values":         types.StringValue(values),
 		"first_deployed": types.Int64Value(r.Info.FirstDeployed.Unix()),
 		"last_deployed":  types.Int64Value(r.Info.LastDeployed.Unix()),
+		"home":           types.StringValue(r.Chart.Metadata.Home),
+		"icon":           types.StringValue(r.Chart.Metadata.Icon),
+		"sources":        sources,
+		"dependencies":   dependencies,
 	}
 
 	// Convert the list of ObjectValues to a ListValue
@@ -1431,6 +2463,28 @@ func setReleaseAttributes(ctx context.Context, state *HelmReleaseModel, r *relea
 	return diags
 }
 
+// valuesChecksum returns the SHA-256 checksum of values. The values are normalized through
+// a JSON round trip, so the checksum is the same for the values passed to Helm and the
+// values read back from the release, and encoding/json sorts map keys.
+func valuesChecksum(values map[string]interface{}) (string, error) {
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(b, &normalized); err != nil {
+		return "", err
+	}
+	b, err = json.Marshal(normalized)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+}
+
 func metadataAttrTypes() map[string]attr.Type {
 	return map[string]attr.Type{
 		"name":           types.StringType,
@@ -1442,7 +2496,21 @@ func metadataAttrTypes() map[string]attr.Type {
 		"values":         types.StringType,
 		"first_deployed": types.Int64Type,
 		"last_deployed":  types.Int64Type,
+		"home":           types.StringType,
+		"icon":           types.StringType,
+		"sources":        types.ListType{ElemType: types.StringType},
+		"dependencies":   types.ListType{ElemType: types.ObjectType{AttrTypes: metadataDependencyAttrTypes()}},
+	}
+}
+
+// releaseSensitiveValues returns the values of set_sensitive of state and the values of config
+// read from Secrets at secretPaths, which are redacted from the manifests of the release
+func releaseSensitiveValues(state *HelmReleaseModel, config map[string]interface{}, secretPaths [][]string) map[string]string {
+	sensitiveValues := extractSensitiveValues(state)
+	for value, redacted := range valuesFromSecrets(config, secretPaths) {
+		sensitiveValues[value] = redacted
 	}
+	return sensitiveValues
 }
 
 func extractSensitiveValues(state *HelmReleaseModel) map[string]string {
@@ -1463,21 +2531,14 @@ func extractSensitiveValues(state *HelmReleaseModel) map[string]string {
 	return sensitiveValues
 }
 
-func (m *Meta) ExperimentEnabled(name string) bool {
-	if enabled, exists := m.Experiments[name]; exists {
-		return enabled
-	}
-	return false
-}
-
 // c
-func resourceReleaseExists(ctx context.Context, name, namespace string, meta *Meta) (bool, diag.Diagnostics) {
+func resourceReleaseExists(ctx context.Context, name, namespace, kubeContext, helmDriver string, meta *Meta) (bool, diag.Diagnostics) {
 	logID := fmt.Sprintf("[resourceReleaseExists: %s]", name)
 	tflog.Debug(ctx, fmt.Sprintf("%s Start", logID))
 
 	var diags diag.Diagnostics
 
-	c, err := meta.GetHelmConfiguration(ctx, namespace)
+	c, err := meta.GetHelmConfigurationAs(ctx, namespace, kubeContext, "", helmDriver)
 	if err != nil {
 		diags.AddError(
 			"Error getting helm configuration",
@@ -1540,28 +2601,42 @@ func checkChartDependencies(ctx context.Context, model *HelmReleaseModel, c *cha
 		err := action.CheckDependencies(c, req)
 		if err != nil {
 			if model.DependencyUpdate.ValueBool() {
+				repositoryConfig := m.Settings.RepositoryConfig
+				if !model.DependencyRepositories.IsNull() && !model.DependencyRepositories.IsUnknown() {
+					path, remove, repoDiags := writeDependencyRepositoryConfig(ctx, model, repositoryConfig)
+					diags.Append(repoDiags...)
+					if diags.HasError() {
+						return false, diags
+					}
+					defer remove()
+					repositoryConfig = path
+				}
 				man := &downloader.Manager{
 					Out:              os.Stdout,
 					ChartPath:        path,
 					Keyring:          model.Keyring.ValueString(),
 					SkipUpdate:       false,
 					Getters:          p,
-					RepositoryConfig: m.Settings.RepositoryConfig,
+					RepositoryConfig: repositoryConfig,
 					RepositoryCache:  m.Settings.RepositoryCache,
 					Debug:            m.Settings.Debug,
 				}
-				tflog.Debug(ctx, "Downloading chart dependencies...")
-				if err := man.Update(); err != nil {
+				subsystemDebug(ctx, logSubsystemDownload, "Downloading chart dependencies...")
+				if err := m.updateDependencies(ctx, man); err != nil {
 					diags.AddError("", fmt.Sprintf("Failed to update chart dependencies: %s", err))
 					return true, diags
 				}
 				return true, diags
 			}
+			if model.IgnoreMissingDependencies.ValueBool() {
+				diags.Append(checkMissingDependencies(ctx, model, c, m)...)
+				return false, diags
+			}
 			diags.AddError("", "Found in Chart.yaml, but missing in charts/ directory")
 			return false, diags
 		}
 	}
-	tflog.Debug(ctx, "Chart dependencies are up to date.")
+	subsystemDebug(ctx, logSubsystemDownload, "Chart dependencies are up to date.")
 	return false, diags
 }
 
@@ -1586,6 +2661,13 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	// the chart is downloaded on plan, but the mirror it is downloaded from is only recorded
+	// on apply, so that a change of mirror does not cause a diff on its own
+	repositoryMirror := plan.RepositoryMirror
+	resp.Diagnostics.Append(validateMissingNamespacePolicy(&plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	tflog.Debug(ctx, fmt.Sprintf("Plan state on ModifyPlan: %+v", plan))
 	tflog.Debug(ctx, fmt.Sprintf("Actual state on ModifyPlan: %+v", state))
@@ -1596,12 +2678,76 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 	meta := r.meta
 	name := plan.Name.ValueString()
 	namespace := plan.Namespace.ValueString()
+	ctx, span := meta.startSpan(ctx, "helm_release.plan", releaseSpanAttributes(namespace, name)...)
+	defer func() { meta.endOperationSpan(ctx, span, resp.Diagnostics) }()
+
+	if state != nil && cosmeticValuesChange(ctx, req.State, plan, *state) {
+		tflog.Debug(ctx, fmt.Sprintf("%s Only the formatting of the values changed, skipping upgrade", logID))
+		plan = pausedState(plan, *state)
+		resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+		return
+	}
+
+	if state != nil && manifestStorageChange(ctx, req.State, plan, *state) {
+		tflog.Debug(ctx, fmt.Sprintf("%s Only manifest_storage changed, skipping upgrade", logID))
+		plan = pausedState(plan, *state)
+		manifest, err := storedManifest(state)
+		if err != nil {
+			resp.Diagnostics.AddError("Error reading manifest", err.Error())
+			return
+		}
+		setManifest(&plan, manifest)
+		resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+		return
+	}
+
+	if meta.OfflinePlan || meta.ConfigUnknown {
+		// attributes computed from the chart or the release are left unknown by the framework
+		// whenever the configuration changes, and resolved at apply time. Without deferred actions,
+		// releases are planned offline until the configuration of the provider is known, as the
+		// cluster may not exist yet.
+		tflog.Debug(ctx, fmt.Sprintf("%s Offline plan, skipping chart repository and cluster access", logID))
+		plan.Status = types.StringValue(release.StatusDeployed.String())
+		plan.ChartDigest = chartDigest(&plan)
+		if !meta.FeatureEnabled(featureManifestDiff) {
+			setManifest(&plan, types.StringNull())
+		}
+		resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		// the chart is not downloaded, its name is taken from the chart attribute
+		setPlannedChangeSummary(ctx, req, resp, state, &plan, pathpkg.Base(plan.Chart.ValueString()))
+		return
+	}
 
-	actionConfig, err := meta.GetHelmConfiguration(ctx, namespace)
+	if meta.Mock {
+		tflog.Debug(ctx, fmt.Sprintf("%s Mock mode, fabricating the planned release", logID))
+		resp.Diagnostics.Append(mockPlan(ctx, &plan, state, meta)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+		return
+	}
+
+	actionConfig, err := meta.GetHelmConfigurationAs(ctx, namespace, plan.KubeContext.ValueString(), plan.DeployAsServiceAccount.ValueString(), plan.HelmDriver.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError("Error getting Helm configuration", err.Error())
 		return
 	}
+	dryRunMode := planDryRunMode(&plan)
+	if config.DryRunMode.IsNull() && meta.FeatureEnabled(featureServerDryRun) {
+		dryRunMode = dryRunModeServer
+	}
+	if dryRunMode == dryRunModeServer {
+		// templates look up the objects of the cluster, which plans must not modify
+		if requests := readOnlyConfiguration(actionConfig); requests != nil {
+			defer func() {
+				tflog.Info(ctx, fmt.Sprintf("%s %d read-only Kubernetes API requests made on plan", logID, requests.Load()))
+			}()
+		}
+	}
 	tflog.Debug(ctx, fmt.Sprintf("%s Initial Values: Name=%s, Namespace=%s, Repository=%s, Repository_Username=%s, Repository_Password=%s, Chart=%s", logID,
 		name, namespace, plan.Repository.ValueString(), plan.RepositoryUsername.ValueString(), plan.RepositoryPassword.ValueString(), plan.Chart.ValueString()))
 
@@ -1609,7 +2755,7 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 	repositoryUsername := plan.RepositoryUsername.ValueString()
 	repositoryPassword := plan.RepositoryPassword.ValueString()
 	chartName := plan.Chart.ValueString()
-	ociDiags := OCIRegistryLogin(ctx, meta, actionConfig, meta.RegistryClient, repositoryURL, chartName, repositoryUsername, repositoryPassword)
+	ociDiags := OCIRegistryLogin(ctx, meta, actionConfig, repositoryURL, chartName, repositoryUsername, repositoryPassword, plan.RepositoryPlainHTTP.ValueBool(), plan.RepositoryInsecureSkipTLSVerify.ValueBool())
 	resp.Diagnostics.Append(ociDiags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -1617,10 +2763,27 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 
 	// Always set desired state to DEPLOYED
 	plan.Status = types.StringValue(release.StatusDeployed.String())
+	plan.ChartDigest = chartDigest(&plan)
+	if !plan.WaitForLoadBalancer.ValueBool() {
+		plan.Endpoints = types.MapNull(types.StringType)
+	}
+	if !plan.WaitForJobs.ValueBool() || plan.HookLogsSensitive.ValueBool() {
+		plan.HookLogs = types.MapNull(types.StringType)
+	}
+	if !plan.WaitForJobs.ValueBool() || !plan.HookLogsSensitive.ValueBool() {
+		plan.SensitiveHookLogs = types.MapNull(types.StringType)
+	}
+	if !plan.Wait.ValueBool() {
+		plan.ResourceHealth = types.MapNull(types.StringType)
+		plan.Services = types.MapNull(types.ObjectType{AttrTypes: serviceAttrTypes()})
+	}
 
 	if recomputeMetadata(plan, state) {
 		tflog.Debug(ctx, fmt.Sprintf("%s Metadata has changes, setting to unknown", logID))
 		plan.Metadata = types.ObjectUnknown(metadataAttrTypes())
+		plan.ValuesChecksum = types.StringUnknown()
+		plan.Namespaces = types.ListUnknown(types.StringType)
+		plan.EnabledSubcharts = types.ListUnknown(types.StringType)
 	}
 
 	if !useChartVersion(plan.Chart.ValueString(), plan.Repository.ValueString()) {
@@ -1638,7 +2801,14 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 		}
 	}
 
+	if config.Version.IsNull() && !config.VersionConstraint.IsNull() {
+		// the version is resolved from the constraint on every plan, so that new matching
+		// versions are upgraded to
+		plan.Version = types.StringNull()
+	}
+
 	client := action.NewInstall(actionConfig)
+	skipCRDSchemaValidation(actionConfig, plan.SkipCRDSchemaValidation.ValueBool())
 	cpo, chartName, diags := chartPathOptions(&plan, meta, &client.ChartPathOptions)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -1650,6 +2820,11 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	plan.Verification, diags = chartVerification(ctx, &plan, path)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 	tflog.Debug(ctx, fmt.Sprintf("%s Got chart", logID))
 
 	updated, diags := checkChartDependencies(ctx, &plan, chart, path, meta)
@@ -1663,8 +2838,38 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 			return
 		}
 	}
+	if plan.SkipSchemaValidation.ValueBool() {
+		skipSchemaValidation(chart)
+	}
+
+	resp.Diagnostics.Append(chartDeprecationWarnings(meta, &plan, cpo, chartName, chart)...)
+	resp.Diagnostics.Append(chartVersionAvailability(ctx, meta, &plan, cpo, chartName)...)
+	resp.Diagnostics.Append(checkKubeVersion(ctx, actionConfig, chart, plan.EnforceKubeVersion.ValueString())...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// show the subcharts a change of the values enables or disables in the plan
+	if !valuesUnknown(plan) && !plan.ReuseValues.ValueBool() {
+		values, diags := getValues(ctx, &plan, meta)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		subcharts, diags := releaseEnabledSubcharts(ctx, chart, values)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		plan.EnabledSubcharts = subcharts
+
+		if state == nil || !req.Plan.Raw.Equal(req.State.Raw) {
+			resp.Diagnostics.Append(checkReleaseFullname(name, chart, values)...)
+		}
+	}
 
-	if plan.Lint.ValueBool() {
+	// a pre-rendered manifest has no chart to lint
+	if plan.Lint.ValueBool() && plan.PreRenderedManifest.IsNull() {
 		diags := resourceReleaseValidate(ctx, &plan, meta, cpo)
 		if diags.HasError() {
 			resp.Diagnostics.Append(diags...)
@@ -1673,36 +2878,51 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 	}
 	tflog.Debug(ctx, fmt.Sprintf("%s Release validated", logID))
 
-	if meta.ExperimentEnabled("manifest") {
+	// with a cheaper drift detection mode the manifest is not rendered for existing releases
+	skipManifestRender := state != nil && releaseDriftDetection(meta, &plan) != driftDetectionManifest
+	if meta.FeatureEnabled(featureManifestDiff) && skipManifestRender {
+		subsystemDebug(ctx, logSubsystemRender, fmt.Sprintf("%s drift detection is %q, skipping dry run to render manifest", logID, releaseDriftDetection(meta, &plan)))
+		plan.Manifest = state.Manifest
+		plan.ManifestSHA256 = state.ManifestSHA256
+		plan.HooksManifest = state.HooksManifest
+		if !req.Plan.Raw.Equal(req.State.Raw) {
+			setManifest(&plan, types.StringUnknown())
+			plan.HooksManifest = types.StringUnknown()
+		}
+	} else if meta.FeatureEnabled(featureManifestDiff) {
 		// Check if all necessary values are known
 		if valuesUnknown(plan) {
-			tflog.Debug(ctx, "not all values are known, skipping dry run to render manifest")
-			plan.Manifest = types.StringNull()
+			subsystemDebug(ctx, logSubsystemRender, "not all values are known, skipping dry run to render manifest")
+			setManifest(&plan, types.StringNull())
 			plan.Version = types.StringNull()
+			setPlannedChangeSummary(ctx, req, resp, state, &plan, chart.Metadata.Name)
+			return
+		}
+		pending, diags := requiredCRDsPending(ctx, actionConfig, &plan)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if pending || plan.RequiresCRDs.IsUnknown() {
+			subsystemDebug(ctx, logSubsystemRender, "required CRDs are not established yet, skipping dry run to render manifest")
+			setManifest(&plan, types.StringUnknown())
+			plan.HooksManifest = types.StringUnknown()
+			setPlannedChangeSummary(ctx, req, resp, state, &plan, chart.Metadata.Name)
 			return
 		}
 
-		if plan.PostRender != nil {
-			binaryPath := plan.PostRender.BinaryPath.ValueString()
-			argsList := plan.PostRender.Args.Elements()
-
-			var args []string
-			for _, arg := range argsList {
-				args = append(args, arg.(basetypes.StringValue).ValueString())
-			}
-
-			pr, err := postrender.NewExec(binaryPath, args...)
-			if err != nil {
-				resp.Diagnostics.AddError("Error creating post-renderer", fmt.Sprintf("Could not create post-renderer: %s", err))
-				return
-			}
-
-			client.PostRenderer = pr
+		pr, prDiags := newPostRenderer(ctx, &plan)
+		resp.Diagnostics.Append(prDiags...)
+		if resp.Diagnostics.HasError() {
+			return
 		}
+		client.PostRenderer = pr
+
 		if state == nil {
 			install := action.NewInstall(actionConfig)
 			install.ChartPathOptions = *cpo
 			install.DryRun = true
+			install.DryRunOption = dryRunMode
 			install.DisableHooks = plan.DisableWebhooks.ValueBool()
 			install.Wait = plan.Wait.ValueBool()
 			install.WaitForJobs = plan.WaitForJobs.ValueBool()
@@ -1711,22 +2931,27 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 			install.Timeout = time.Duration(plan.Timeout.ValueInt64()) * time.Second
 			install.Namespace = plan.Namespace.ValueString()
 			install.ReleaseName = plan.Name.ValueString()
-			install.Atomic = plan.Atomic.ValueBool()
-			install.SkipCRDs = plan.SkipCrds.ValueBool()
+			install.Atomic = atomicInstall(&plan)
+			install.SkipCRDs = crdPolicy(&plan) == crdPolicySkip
 			install.SubNotes = plan.RenderSubchartNotes.ValueBool()
 			install.DisableOpenAPIValidation = plan.DisableOpenapiValidation.ValueBool()
 			install.Replace = plan.Replace.ValueBool()
-			install.Description = plan.Description.ValueString()
+			description, err := releaseDescription(actionConfig, &plan, chart.Metadata.Name, chart.Metadata.Version)
+			if err != nil {
+				resp.Diagnostics.AddError("Error expanding description", err.Error())
+				return
+			}
+			install.Description = description
 			install.CreateNamespace = plan.CreateNamespace.ValueBool()
 			install.PostRenderer = client.PostRenderer
 
-			values, diags := getValues(ctx, &plan)
+			values, secretPaths, diags := getReleaseValues(ctx, &plan, meta)
 			resp.Diagnostics.Append(diags...)
 			if resp.Diagnostics.HasError() {
 				return
 			}
 
-			tflog.Debug(ctx, fmt.Sprintf("%s performing dry run install", logID))
+			subsystemDebug(ctx, logSubsystemRender, fmt.Sprintf("%s performing dry run install", logID))
 			dry, err := install.Run(chart, values)
 			if err != nil {
 				// NOTE if the cluster is not reachable then we can't run the install
@@ -1736,7 +2961,7 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 
 				if strings.Contains(err.Error(), "Kubernetes cluster unreachable") {
 					resp.Diagnostics.AddError("cluster was unreachable at create time, marking manifest as computed", err.Error())
-					plan.Manifest = types.StringNull()
+					setManifest(&plan, types.StringNull())
 					return
 				}
 				resp.Diagnostics.AddError("Error performing dry run install", err.Error())
@@ -1761,8 +2986,26 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 					valuesMap[set.Name.ValueString()] = set.Value.ValueString()
 				}
 			}
+			for value, redacted := range valuesFromSecrets(values, secretPaths) {
+				valuesMap[value] = redacted
+			}
 			manifest := redactSensitiveValues(string(jsonManifest), valuesMap)
-			plan.Manifest = types.StringValue(manifest)
+			setManifest(&plan, types.StringValue(manifest))
+			plan.HooksManifest, err = hooksManifest(dry.Hooks, valuesMap)
+			if err != nil {
+				resp.Diagnostics.AddError("Error converting hooks to JSON", err.Error())
+			}
+			if crossNamespaceDenied(&plan) {
+				if err := checkHookNamespaces(dry.Hooks, plan.Namespace.ValueString()); err != nil {
+					resp.Diagnostics.AddError("Hooks outside of the release namespace", err.Error())
+					return
+				}
+			}
+			resp.Diagnostics.Append(checkPolicy(ctx, &plan, dry.Manifest, dry.Hooks)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			setPlannedChangeSummary(ctx, req, resp, state, &plan, chart.Metadata.Name)
 			return
 		}
 
@@ -1771,7 +3014,8 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 			if len(chart.Metadata.Version) > 0 {
 				plan.Version = types.StringValue(chart.Metadata.Version)
 			}
-			plan.Manifest = types.StringNull()
+			setManifest(&plan, types.StringNull())
+			setPlannedChangeSummary(ctx, req, resp, state, &plan, chart.Metadata.Name)
 			return
 		} else if err != nil {
 			resp.Diagnostics.AddError("Error retrieving old release for a diff", err.Error())
@@ -1785,8 +3029,9 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 		upgrade.Timeout = time.Duration(plan.Timeout.ValueInt64()) * time.Second
 		upgrade.Wait = plan.Wait.ValueBool()
 		upgrade.DryRun = true
+		upgrade.DryRunOption = dryRunMode
 		upgrade.DisableHooks = plan.DisableWebhooks.ValueBool()
-		upgrade.Atomic = plan.Atomic.ValueBool()
+		upgrade.Atomic = atomicUpgrade(&plan)
 		upgrade.SubNotes = plan.RenderSubchartNotes.ValueBool()
 		upgrade.WaitForJobs = plan.WaitForJobs.ValueBool()
 		upgrade.Force = plan.ForceUpdate.ValueBool()
@@ -1795,23 +3040,38 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 		upgrade.Recreate = plan.RecreatePods.ValueBool()
 		upgrade.MaxHistory = int(plan.MaxHistory.ValueInt64())
 		upgrade.CleanupOnFail = plan.CleanupOnFail.ValueBool()
-		upgrade.Description = plan.Description.ValueString()
+		description, err := releaseDescription(actionConfig, &plan, chart.Metadata.Name, chart.Metadata.Version)
+		if err != nil {
+			resp.Diagnostics.AddError("Error expanding description", err.Error())
+			return
+		}
+		upgrade.Description = description
 		upgrade.PostRenderer = client.PostRenderer
 
-		values, diags := getValues(ctx, &plan)
+		values, secretPaths, diags := getReleaseValues(ctx, &plan, meta)
 		resp.Diagnostics.Append(diags...)
 		if resp.Diagnostics.HasError() {
 			return
 		}
+		resp.Diagnostics.Append(preserveReleaseIgnoredValues(ctx, &plan, meta, actionConfig, values)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
 
-		tflog.Debug(ctx, fmt.Sprintf("%s performing dry run upgrade", logID))
-		dry, err := upgrade.Run(name, chart, values)
+		subsystemDebug(ctx, logSubsystemRender, fmt.Sprintf("%s performing dry run upgrade", logID))
+		conflictTimeout := time.Duration(plan.OperationConflictTimeout.ValueInt64()) * time.Second
+		_, renderSpan := meta.startSpan(ctx, "helm.render", releaseSpanAttributes(namespace, name)...)
+		dry, err := retryOperationConflict(ctx, name, conflictTimeout, func() (*release.Release, error) {
+			return upgrade.Run(name, chart, values)
+		})
+		endSpan(renderSpan, err)
 		if err != nil && strings.Contains(err.Error(), "has no deployed releases") {
 			if len(chart.Metadata.Version) > 0 && cpo.Version != "" {
 				plan.Version = types.StringValue(chart.Metadata.Version)
 			}
 			plan.Version = types.StringNull()
-			plan.Manifest = types.StringNull()
+			setManifest(&plan, types.StringNull())
+			setPlannedChangeSummary(ctx, req, resp, state, &plan, chart.Metadata.Name)
 			return
 		} else if err != nil {
 			resp.Diagnostics.AddError("Error running dry run for a diff", err.Error())
@@ -1836,11 +3096,33 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 				valuesMap[set.Name.ValueString()] = set.Value.ValueString()
 			}
 		}
+		for value, redacted := range valuesFromSecrets(values, secretPaths) {
+			valuesMap[value] = redacted
+		}
 		manifest := redactSensitiveValues(string(jsonManifest), valuesMap)
-		plan.Manifest = types.StringValue(manifest)
+		setManifest(&plan, types.StringValue(manifest))
+		plan.HooksManifest, err = hooksManifest(dry.Hooks, valuesMap)
+		if err != nil {
+			resp.Diagnostics.AddError("Error converting hooks to JSON", err.Error())
+			return
+		}
 		tflog.Debug(ctx, fmt.Sprintf("%s set manifest: %s", logID, jsonManifest))
+		if crossNamespaceDenied(&plan) {
+			if err := checkHookNamespaces(dry.Hooks, plan.Namespace.ValueString()); err != nil {
+				resp.Diagnostics.AddError("Hooks outside of the release namespace", err.Error())
+				return
+			}
+		}
+		resp.Diagnostics.Append(checkPolicy(ctx, &plan, dry.Manifest, dry.Hooks)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
 	} else {
-		plan.Manifest = types.StringNull()
+		setManifest(&plan, types.StringNull())
+		if !plan.Policy.IsNull() {
+			resp.Diagnostics.AddWarning("Policy not evaluated",
+				"The policy of the release is evaluated against the manifests rendered at plan time, which requires the manifest experiment of the provider.")
+		}
 	}
 
 	tflog.Debug(ctx, fmt.Sprintf("%s Done", logID))
@@ -1851,6 +3133,10 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 		plan.Version = types.StringNull()
 	}
 
+	if state != nil && !config.VersionConstraint.IsNull() && !versionsEqual(state.Version.ValueString(), plan.Version.ValueString()) {
+		plan.Metadata = types.ObjectUnknown(metadataAttrTypes())
+	}
+
 	if !config.Version.IsNull() && !config.Version.Equal(plan.Version) {
 		if versionsEqual(config.Version.ValueString(), plan.Version.ValueString()) {
 			plan.Version = config.Version
@@ -1863,7 +3149,19 @@ You should update the version in your configuration to %[2]q, or remove the vers
 		}
 	}
 
-	resp.Plan.Set(ctx, &plan)
+	if plan.Paused.ValueBool() && state != nil && !req.Plan.Raw.Equal(req.State.Raw) {
+		resp.Diagnostics.AddWarning(
+			"Release is paused",
+			fmt.Sprintf("Helm release %s is paused, the planned changes are not rolled out until paused is set to false.", name),
+		)
+	}
+
+	plan.RepositoryMirror = repositoryMirror
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	setPlannedChangeSummary(ctx, req, resp, state, &plan, chart.Metadata.Name)
 }
 
 // TODO: write unit test, always returns true for recomputing the metadata
@@ -1879,6 +3177,9 @@ func recomputeMetadata(plan HelmReleaseModel, state *HelmReleaseModel) bool {
 	if !plan.Repository.Equal(state.Repository) {
 		return true
 	}
+	if !plan.PreRenderedManifest.Equal(state.PreRenderedManifest) {
+		return true
+	}
 	if !plan.Values.Equal(state.Values) {
 		return true
 	}
@@ -1891,6 +3192,15 @@ func recomputeMetadata(plan HelmReleaseModel, state *HelmReleaseModel) bool {
 	if !plan.SetList.Equal(state.SetList) {
 		return true
 	}
+	if !plan.ValuesFrom.Equal(state.ValuesFrom) {
+		return true
+	}
+	if !plan.ValuesMergeStrategy.Equal(state.ValuesMergeStrategy) {
+		return true
+	}
+	if !plan.ValuesURL.Equal(state.ValuesURL) {
+		return true
+	}
 	return false
 }
 
@@ -1904,13 +3214,19 @@ func resourceReleaseValidate(ctx context.Context, model *HelmReleaseModel, meta
 		return diags
 	}
 
-	values, valuesDiags := getValues(ctx, model)
+	values, valuesDiags := getValues(ctx, model, meta)
 	diags.Append(valuesDiags...)
 	if diags.HasError() {
 		return diags
 	}
 
-	lintDiags := lintChart(meta, name, cpo, values)
+	path, locateDiags := locateReleaseChart(ctx, model, meta, cpo, name)
+	diags.Append(locateDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	lintDiags := lintChart(path, values)
 	if lintDiags != nil {
 		diagnostic := diag.NewErrorDiagnostic("Lint Error", lintDiags.Error())
 		diags = append(diags, diagnostic)
@@ -1918,12 +3234,7 @@ func resourceReleaseValidate(ctx context.Context, model *HelmReleaseModel, meta
 	return diags
 }
 
-func lintChart(m *Meta, name string, cpo *action.ChartPathOptions, values map[string]interface{}) error {
-	path, err := cpo.LocateChart(name, m.Settings)
-	if err != nil {
-		return err
-	}
-
+func lintChart(path string, values map[string]interface{}) error {
 	l := action.NewLint()
 	result := l.Run([]string{path}, values)
 
@@ -1949,7 +3260,7 @@ func resultToError(r *action.LintResult) error {
 }
 
 func (r *HelmRelease) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	namespace, name, err := parseImportIdentifier(req.ID)
+	kubeContext, namespace, name, err := parseImportIdentifier(req.ID)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to parse import identifier",
@@ -1958,43 +3269,61 @@ func (r *HelmRelease) ImportState(ctx context.Context, req resource.ImportStateR
 		return
 	}
 
+	resp.Diagnostics.Append(r.importRelease(ctx, kubeContext, namespace, name, &resp.State)...)
+}
+
+// importRelease sets target to the state of the release name of namespace in the cluster of
+// kubeContext, as the release is imported
+func (r *HelmRelease) importRelease(ctx context.Context, kubeContext, namespace, name string, target *tfsdk.State) diag.Diagnostics {
+	var diags diag.Diagnostics
 	meta := r.meta
 	if meta == nil {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Meta not set",
 			"The meta information is not set for the resource",
 		)
-		return
+		return diags
+	}
+	if meta.Mock {
+		diags.AddError(
+			"Import is not supported in mock mode",
+			fmt.Sprintf("Helm release %s/%s cannot be imported, mock releases only exist in the state.", namespace, name),
+		)
+		return diags
 	}
 
-	actionConfig, err := meta.GetHelmConfiguration(ctx, namespace)
+	actionConfig, err := meta.GetHelmConfigurationForContext(ctx, namespace, kubeContext)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Error getting helm configuration",
 			fmt.Sprintf("Unable to get Helm configuration for namespace %s: %s", namespace, err),
 		)
-		return
+		return diags
 	}
 
 	release, err := getRelease(ctx, meta, actionConfig, name)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Error getting release",
 			fmt.Sprintf("Unable to get Helm release %s: %s", name, err.Error()),
 		)
-		return
+		return diags
 	}
 
 	var state HelmReleaseModel
 	state.Name = types.StringValue(release.Name)
 	state.Description = types.StringValue(release.Info.Description)
 	state.Chart = types.StringValue(release.Chart.Metadata.Name)
+	state.KubeContext = types.StringNull()
+	if kubeContext != "" {
+		state.KubeContext = types.StringValue(kubeContext)
+	}
 
 	// Set release-specific attributes using the helper function
-	diags := setReleaseAttributes(ctx, &state, release, meta)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	// values_from is not known on import
+	diags.Append(setReleaseAttributes(ctx, &state, release, meta, nil)...)
+	if diags.HasError() {
+		return diags
 	}
 
 	state.Set = types.ListNull(types.ObjectType{
@@ -2020,36 +3349,63 @@ func (r *HelmRelease) ImportState(ctx context.Context, req resource.ImportStateR
 		},
 	})
 	state.Values = types.ListNull(types.StringType)
+	state.ValuesFrom = types.ListNull(types.ObjectType{AttrTypes: valuesFromAttrTypes()})
+	state.ValuesURL = types.ListNull(types.ObjectType{AttrTypes: valuesURLAttrTypes()})
+	state.CommonAnnotations = types.MapNull(types.StringType)
+	state.CommonLabels = types.MapNull(types.StringType)
+	state.DependencyRepositories = types.ListNull(types.ObjectType{AttrTypes: dependencyRepositoryAttrTypes()})
+	state.IgnoreValueChanges = types.ListNull(types.StringType)
+	state.RequiresCRDs = types.ListNull(types.StringType)
+	state.DisableHooksFor = types.ListNull(types.StringType)
+	state.RepositoryMirrors = types.ListNull(types.StringType)
+	state.HookLogs = types.MapNull(types.StringType)
+	state.SensitiveHookLogs = types.MapNull(types.StringType)
+	state.ResolveOverrides = types.MapNull(types.StringType)
+	state.WaitOverrides = types.MapNull(types.Int64Type)
+	state.Patches = types.ListNull(types.ObjectType{AttrTypes: patchAttrTypes()})
+	state.Policy = types.ObjectNull(policyAttrTypes())
+	state.Verification = types.ObjectNull(verificationAttrTypes())
+	state.PostRender = types.ListNull(types.ObjectType{
+		AttrTypes: map[string]attr.Type{
+			"binary_path": types.StringType,
+			"args": types.ListType{
+				ElemType: types.StringType,
+			},
+		},
+	})
 
 	tflog.Debug(ctx, fmt.Sprintf("Setting final state: %+v", state))
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
+	diags.Append(target.Set(ctx, &state)...)
 	if diags.HasError() {
 		fmt.Println("DOH")
 		tflog.Error(ctx, "Error setting final state", map[string]interface{}{
 			"state":       state,
 			"diagnostics": diags,
 		})
-		return
+		return diags
 	}
 
 	// Set default attributes
 	for key, value := range defaultAttributes {
-		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(key), value)...)
-		if resp.Diagnostics.HasError() {
-			return
+		diags.Append(target.SetAttribute(ctx, path.Root(key), value)...)
+		if diags.HasError() {
+			return diags
 		}
 	}
+	return diags
 }
 
-func parseImportIdentifier(id string) (string, string, error) {
+// parseImportIdentifier parses import identifiers of the form namespace/name or
+// kube_context/namespace/name. Contexts may contain slashes, e.g. EKS cluster ARNs.
+func parseImportIdentifier(id string) (string, string, string, error) {
 	parts := strings.Split(id, "/")
-	if len(parts) != 2 {
-		err := errors.Errorf("Unexpected ID format (%q), expected namespace/name", id)
-		return "", "", err
+	if len(parts) < 2 {
+		err := errors.Errorf("Unexpected ID format (%q), expected namespace/name or kube_context/namespace/name", id)
+		return "", "", "", err
 	}
 
-	return parts[0], parts[1], nil
+	n := len(parts)
+	return strings.Join(parts[:n-2], "/"), parts[n-2], parts[n-1], nil
 }
 
 // returns true if any values, set_list, set, set_sensitive are unknown
@@ -2066,5 +3422,11 @@ func valuesUnknown(plan HelmReleaseModel) bool {
 	if plan.SetSensitive.IsUnknown() {
 		return true
 	}
+	if plan.ValuesFrom.IsUnknown() {
+		return true
+	}
+	if plan.ValuesURL.IsUnknown() {
+		return true
+	}
 	return false
 }
@@ -0,0 +1,547 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+var (
+	_ resource.Resource              = &HelmReleaseSet{}
+	_ resource.ResourceWithConfigure = &HelmReleaseSet{}
+)
+
+func NewHelmReleaseSet() resource.Resource {
+	return &HelmReleaseSet{}
+}
+
+// HelmReleaseSet installs the same chart as many releases sharing their default values, managed
+// as a single resource
+type HelmReleaseSet struct {
+	meta *Meta
+}
+
+// HelmReleaseSetModel holds the shared configuration and the instances of a helm_release_set
+type HelmReleaseSetModel struct {
+	Atomic          types.Bool   `tfsdk:"atomic"`
+	Chart           types.String `tfsdk:"chart"`
+	CreateNamespace types.Bool   `tfsdk:"create_namespace"`
+	ID              types.String `tfsdk:"id"`
+	Instances       types.Map    `tfsdk:"instances"`
+	Namespace       types.String `tfsdk:"namespace"`
+	Repository      types.String `tfsdk:"repository"`
+	Revisions       types.Map    `tfsdk:"revisions"`
+	Statuses        types.Map    `tfsdk:"statuses"`
+	Timeout         types.Int64  `tfsdk:"timeout"`
+	Values          types.List   `tfsdk:"values"`
+	Version         types.String `tfsdk:"version"`
+	Wait            types.Bool   `tfsdk:"wait"`
+}
+
+// ReleaseSetInstanceModel holds the overrides of an instance of a helm_release_set
+type ReleaseSetInstanceModel struct {
+	Namespace types.String `tfsdk:"namespace"`
+	Values    types.List   `tfsdk:"values"`
+}
+
+func (r *HelmReleaseSet) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_release_set"
+}
+
+func (r *HelmReleaseSet) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Installs the same chart as many releases sharing the chart and default values, managed as a single resource",
+		Attributes: map[string]schema.Attribute{
+			"atomic": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "If set, the installation of an instance purges it on fail. The wait flag will be set automatically if atomic is used",
+			},
+			"chart": schema.StringAttribute{
+				Required:    true,
+				Description: "Chart name to be installed. A path may be used",
+			},
+			"create_namespace": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Create the namespaces of the instances if they do not exist",
+			},
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"instances": schema.MapNestedAttribute{
+				Required:    true,
+				Description: "Instances of the chart, keyed by the name of their release",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"namespace": schema.StringAttribute{
+							Optional:    true,
+							Description: "Namespace of the release. Defaults to the namespace of the set",
+						},
+						"values": schema.ListAttribute{
+							Optional:    true,
+							ElementType: types.StringType,
+							Description: "List of values in raw YAML format merged over the values of the set",
+						},
+					},
+				},
+			},
+			"namespace": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     namespaceDefault(),
+				Description: "Namespace of the instances not setting their own",
+			},
+			"repository": schema.StringAttribute{
+				Optional:    true,
+				Description: "Repository where to locate the requested chart. If it is a URL, the chart is installed without installing the repository",
+			},
+			"revisions": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.Int64Type,
+				Description: "Revision of the release of each instance",
+			},
+			"statuses": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Status of the release of each instance",
+			},
+			"timeout": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(300),
+				Description: "Time in seconds to wait for any individual kubernetes operation",
+			},
+			"values": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "List of values in raw YAML format shared by the instances",
+			},
+			"version": schema.StringAttribute{
+				Optional:    true,
+				Description: "Specify the exact chart version to install. If this is not specified, the latest version is installed",
+			},
+			"wait": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+				Description: "Will wait until all resources of an instance are in a ready state before marking it as successful",
+			},
+		},
+	}
+}
+
+func (r *HelmReleaseSet) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	meta, ok := req.ProviderData.(*Meta)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *Meta, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	r.meta = meta
+}
+
+// releaseSetInstances returns the release models of the instances of a set, keyed by the name
+// of their release
+func releaseSetInstances(ctx context.Context, model *HelmReleaseSetModel) (map[string]*HelmReleaseModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	instances := map[string]ReleaseSetInstanceModel{}
+	diags.Append(model.Instances.ElementsAs(ctx, &instances, false)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	releases := make(map[string]*HelmReleaseModel, len(instances))
+	for name, instance := range instances {
+		namespace := model.Namespace
+		if !instance.Namespace.IsNull() {
+			namespace = instance.Namespace
+		}
+		var values []attr.Value
+		if !model.Values.IsNull() {
+			values = append(values, model.Values.Elements()...)
+		}
+		if !instance.Values.IsNull() {
+			values = append(values, instance.Values.Elements()...)
+		}
+		valuesList, valuesDiags := types.ListValue(types.StringType, values)
+		diags.Append(valuesDiags...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		releases[name] = &HelmReleaseModel{
+			Name:            types.StringValue(name),
+			Namespace:       namespace,
+			Repository:      model.Repository,
+			Chart:           model.Chart,
+			Version:         model.Version,
+			Values:          valuesList,
+			Atomic:          model.Atomic,
+			CreateNamespace: model.CreateNamespace,
+			Timeout:         model.Timeout,
+			Wait:            model.Wait,
+		}
+	}
+	return releases, diags
+}
+
+// releaseSetInstanceChanged reports whether the release of an instance must be upgraded
+func releaseSetInstanceChanged(plan, state *HelmReleaseModel) bool {
+	return !plan.Repository.Equal(state.Repository) ||
+		!plan.Chart.Equal(state.Chart) ||
+		!plan.Version.Equal(state.Version) ||
+		!plan.Values.Equal(state.Values)
+}
+
+// sortedReleaseNames returns the names of releases in order, so that instances are installed
+// and upgraded in a stable order
+func sortedReleaseNames(releases map[string]*HelmReleaseModel) []string {
+	names := make([]string, 0, len(releases))
+	for name := range releases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// releaseSetAction runs fn with the Helm configuration of the namespace of the release of an
+// instance, while holding the lock of the release, see lockRelease for createNamespace
+func (r *HelmReleaseSet) releaseSetAction(ctx context.Context, model *HelmReleaseModel, createNamespace bool, fn func(*action.Configuration) diag.Diagnostics) diag.Diagnostics {
+	var diags diag.Diagnostics
+	meta := r.meta
+	namespace := model.Namespace.ValueString()
+	name := model.Name.ValueString()
+
+	actionConfig, err := meta.GetHelmConfiguration(ctx, namespace)
+	if err != nil {
+		diags.AddError("Error getting helm configuration", fmt.Sprintf("Unable to get Helm configuration for namespace %s: %s", namespace, err))
+		return diags
+	}
+	diags.Append(OCIRegistryLogin(ctx, meta, actionConfig, model.Repository.ValueString(), model.Chart.ValueString(), "", "", false, false)...)
+	if diags.HasError() {
+		return diags
+	}
+	unlock, lockDiags := meta.lockRelease(ctx, actionConfig, namespace, name, time.Duration(model.Timeout.ValueInt64())*time.Second, createNamespace)
+	diags.Append(lockDiags...)
+	if diags.HasError() {
+		return diags
+	}
+	defer unlock()
+	diags.Append(fn(actionConfig)...)
+	return diags
+}
+
+// installOrUpgrade installs the release of an instance, or upgrades it when it exists, e.g.
+// after a failed installation
+func (r *HelmReleaseSet) installOrUpgrade(ctx context.Context, model *HelmReleaseModel) (*release.Release, diag.Diagnostics) {
+	meta := r.meta
+	namespace := model.Namespace.ValueString()
+	name := model.Name.ValueString()
+
+	var rel *release.Release
+	diags := r.releaseSetAction(ctx, model, model.CreateNamespace.ValueBool(), func(actionConfig *action.Configuration) diag.Diagnostics {
+		var diags diag.Diagnostics
+		cpo, chartName, cpoDiags := chartPathOptions(model, meta, &action.ChartPathOptions{})
+		diags.Append(cpoDiags...)
+		if diags.HasError() {
+			return diags
+		}
+		c, _, chartDiags := getChart(ctx, model, meta, chartName, cpo)
+		diags.Append(chartDiags...)
+		if diags.HasError() {
+			return diags
+		}
+		values, valuesDiags := getValues(ctx, model, meta)
+		diags.Append(valuesDiags...)
+		if diags.HasError() {
+			return diags
+		}
+		timeout := time.Duration(model.Timeout.ValueInt64()) * time.Second
+
+		_, err := getRelease(ctx, meta, actionConfig, name)
+		if err != nil && err != errReleaseNotFound {
+			diags.AddError("Error reading release", fmt.Sprintf("Unable to read release %s: %s", name, err))
+			return diags
+		}
+		upgrade := err == nil
+
+		operation := auditOperationInstall
+		start := time.Now()
+		if upgrade {
+			operation = auditOperationUpgrade
+			client := action.NewUpgrade(actionConfig)
+			client.ChartPathOptions = *cpo
+			client.Namespace = namespace
+			client.Timeout = timeout
+			client.Wait = model.Wait.ValueBool()
+			client.Atomic = model.Atomic.ValueBool()
+			tflog.Info(ctx, fmt.Sprintf("Upgrading Helm release %s of the release set", name))
+			rel, err = client.Run(name, c, values)
+		} else {
+			client := action.NewInstall(actionConfig)
+			client.ChartPathOptions = *cpo
+			client.ReleaseName = name
+			client.Namespace = namespace
+			client.CreateNamespace = model.CreateNamespace.ValueBool()
+			client.Timeout = timeout
+			client.Wait = model.Wait.ValueBool()
+			client.Atomic = model.Atomic.ValueBool()
+			tflog.Info(ctx, fmt.Sprintf("Installing Helm release %s of the release set", name))
+			rel, err = client.Run(c, values)
+		}
+		meta.auditOperation(ctx, operation, namespace, name, c.Metadata.Name, c.Metadata.Version, start, err)
+		if err != nil {
+			diags.AddError(fmt.Sprintf("Error running %s of release %s", operation, name), err.Error())
+		}
+		return diags
+	})
+	return rel, diags
+}
+
+// uninstall uninstalls the release of an instance, when it exists
+func (r *HelmReleaseSet) uninstall(ctx context.Context, model *HelmReleaseModel) diag.Diagnostics {
+	meta := r.meta
+	namespace := model.Namespace.ValueString()
+	name := model.Name.ValueString()
+	return r.releaseSetAction(ctx, model, false, func(actionConfig *action.Configuration) diag.Diagnostics {
+		var diags diag.Diagnostics
+		if _, err := getRelease(ctx, meta, actionConfig, name); err == errReleaseNotFound {
+			return diags
+		}
+		client := action.NewUninstall(actionConfig)
+		client.Wait = model.Wait.ValueBool()
+		client.Timeout = time.Duration(model.Timeout.ValueInt64()) * time.Second
+		tflog.Info(ctx, fmt.Sprintf("Uninstalling Helm release %s of the release set", name))
+		start := time.Now()
+		_, err := client.Run(name)
+		meta.auditOperation(ctx, auditOperationUninstall, namespace, name, model.Chart.ValueString(), model.Version.ValueString(), start, err)
+		if err != nil {
+			diags.AddError(fmt.Sprintf("Error uninstalling release %s", name), err.Error())
+		}
+		return diags
+	})
+}
+
+// releaseSetState is the state of a set being applied, holding the instances applied so far so
+// that the releases installed before a failure are kept in the state
+type releaseSetState struct {
+	model     HelmReleaseSetModel
+	instances map[string]attr.Value
+	statuses  map[string]attr.Value
+	revisions map[string]attr.Value
+}
+
+func newReleaseSetState(model HelmReleaseSetModel) *releaseSetState {
+	return &releaseSetState{
+		model:     model,
+		instances: map[string]attr.Value{},
+		statuses:  map[string]attr.Value{},
+		revisions: map[string]attr.Value{},
+	}
+}
+
+// set records the instance of the release, or removes it when the release is nil
+func (s *releaseSetState) set(name string, instance attr.Value, r *release.Release) {
+	if r == nil {
+		delete(s.instances, name)
+		delete(s.statuses, name)
+		delete(s.revisions, name)
+		return
+	}
+	s.instances[name] = instance
+	s.statuses[name] = types.StringValue(r.Info.Status.String())
+	s.revisions[name] = types.Int64Value(int64(r.Version))
+}
+
+// build returns the model of the set with the recorded instances
+func (s *releaseSetState) build() (HelmReleaseSetModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var d diag.Diagnostics
+	model := s.model
+	model.ID = types.StringValue(fmt.Sprintf("%s/%s", model.Namespace.ValueString(), model.Chart.ValueString()))
+	model.Instances, d = types.MapValue(model.Instances.ElementType(context.Background()), s.instances)
+	diags.Append(d...)
+	model.Statuses, d = types.MapValue(types.StringType, s.statuses)
+	diags.Append(d...)
+	model.Revisions, d = types.MapValue(types.Int64Type, s.revisions)
+	diags.Append(d...)
+	return model, diags
+}
+
+func (r *HelmReleaseSet) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan HelmReleaseSetModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	releases, diags := releaseSetInstances(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state := newReleaseSetState(plan)
+	instances := plan.Instances.Elements()
+	for _, name := range sortedReleaseNames(releases) {
+		rel, diags := r.installOrUpgrade(ctx, releases[name])
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			break
+		}
+		state.set(name, instances[name], rel)
+	}
+
+	model, diags := state.build()
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
+}
+
+func (r *HelmReleaseSet) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var model HelmReleaseSetModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &model)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	meta := r.meta
+	if meta.ConfigUnknown {
+		return
+	}
+	releases, diags := releaseSetInstances(ctx, &model)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the instances whose release is missing are removed from the state, to be installed again
+	state := newReleaseSetState(model)
+	instances := model.Instances.Elements()
+	for _, name := range sortedReleaseNames(releases) {
+		namespace := releases[name].Namespace.ValueString()
+		actionConfig, err := meta.GetHelmConfiguration(ctx, namespace)
+		if err != nil {
+			resp.Diagnostics.AddError("Error getting helm configuration", fmt.Sprintf("Unable to get Helm configuration for namespace %s: %s", namespace, err))
+			return
+		}
+		rel, err := getRelease(ctx, meta, actionConfig, name)
+		if err == errReleaseNotFound {
+			tflog.Debug(ctx, fmt.Sprintf("Release %s of the release set not found", name))
+			continue
+		} else if err != nil {
+			resp.Diagnostics.AddError("Error reading release", fmt.Sprintf("Unable to read release %s of the release set: %s", name, err))
+			return
+		}
+		state.set(name, instances[name], rel)
+	}
+
+	model, diags = state.build()
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
+}
+
+func (r *HelmReleaseSet) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, current HelmReleaseSetModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &current)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	planned, diags := releaseSetInstances(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	existing, diags := releaseSetInstances(ctx, &current)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the state starts from the current instances, and records every change as it is applied
+	state := newReleaseSetState(plan)
+	state.instances = current.Instances.Elements()
+	state.statuses = current.Statuses.Elements()
+	state.revisions = current.Revisions.Elements()
+	instances := plan.Instances.Elements()
+
+	// the removed instances are uninstalled first, as well as the instances moved to another
+	// namespace, so that their names can be reused
+	for _, name := range sortedReleaseNames(existing) {
+		if p, ok := planned[name]; ok && p.Namespace.Equal(existing[name].Namespace) {
+			continue
+		}
+		resp.Diagnostics.Append(r.uninstall(ctx, existing[name])...)
+		if resp.Diagnostics.HasError() {
+			break
+		}
+		state.set(name, nil, nil)
+	}
+
+	if !resp.Diagnostics.HasError() {
+		for _, name := range sortedReleaseNames(planned) {
+			if _, ok := state.instances[name]; ok && !releaseSetInstanceChanged(planned[name], existing[name]) {
+				// only the attributes of the set not affecting the releases changed
+				state.instances[name] = instances[name]
+				continue
+			}
+			rel, diags := r.installOrUpgrade(ctx, planned[name])
+			resp.Diagnostics.Append(diags...)
+			if resp.Diagnostics.HasError() {
+				break
+			}
+			state.set(name, instances[name], rel)
+		}
+	}
+
+	model, diags := state.build()
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
+}
+
+func (r *HelmReleaseSet) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var model HelmReleaseSetModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &model)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	releases, diags := releaseSetInstances(ctx, &model)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the instances uninstalled before a failure are removed from the state
+	state := newReleaseSetState(model)
+	state.instances = model.Instances.Elements()
+	state.statuses = model.Statuses.Elements()
+	state.revisions = model.Revisions.Elements()
+	for _, name := range sortedReleaseNames(releases) {
+		diags := r.uninstall(ctx, releases[name])
+		if diags.HasError() {
+			resp.Diagnostics.Append(diags...)
+			model, diags := state.build()
+			resp.Diagnostics.Append(diags...)
+			resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
+			return
+		}
+		state.set(name, nil, nil)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func testReleaseSetModel(t *testing.T, instances map[string][2]string) HelmReleaseSetModel {
+	instanceType := types.ObjectType{AttrTypes: map[string]attr.Type{
+		"namespace": types.StringType,
+		"values":    types.ListType{ElemType: types.StringType},
+	}}
+	elements := map[string]attr.Value{}
+	for name, instance := range instances {
+		namespace := types.StringNull()
+		if instance[0] != "" {
+			namespace = types.StringValue(instance[0])
+		}
+		values := types.ListNull(types.StringType)
+		if instance[1] != "" {
+			values = types.ListValueMust(types.StringType, []attr.Value{types.StringValue(instance[1])})
+		}
+		elements[name] = types.ObjectValueMust(instanceType.AttrTypes, map[string]attr.Value{"namespace": namespace, "values": values})
+	}
+	return HelmReleaseSetModel{
+		Chart:     types.StringValue("test-chart"),
+		Namespace: types.StringValue("apps"),
+		Version:   types.StringValue("1.2.3"),
+		Values:    types.ListValueMust(types.StringType, []attr.Value{types.StringValue("replicaCount: 2")}),
+		Instances: types.MapValueMust(instanceType, elements),
+		Statuses:  types.MapNull(types.StringType),
+		Revisions: types.MapNull(types.Int64Type),
+	}
+}
+
+func TestReleaseSetInstances(t *testing.T) {
+	model := testReleaseSetModel(t, map[string][2]string{
+		"a": {"", ""},
+		"b": {"other", "replicaCount: 3"},
+	})
+	releases, diags := releaseSetInstances(context.Background(), &model)
+	require.False(t, diags.HasError(), diags)
+	require.Len(t, releases, 2)
+	assert.Equal(t, []string{"a", "b"}, sortedReleaseNames(releases))
+
+	assert.Equal(t, "apps", releases["a"].Namespace.ValueString())
+	assert.Equal(t, "other", releases["b"].Namespace.ValueString())
+
+	values, diags := getValues(context.Background(), releases["b"], &Meta{})
+	require.False(t, diags.HasError(), diags)
+	assert.Equal(t, map[string]interface{}{"replicaCount": float64(3)}, values)
+
+	changed := testReleaseSetModel(t, map[string][2]string{
+		"a": {"", ""},
+		"b": {"other", "replicaCount: 4"},
+	})
+	updated, _ := releaseSetInstances(context.Background(), &changed)
+	assert.False(t, releaseSetInstanceChanged(updated["a"], releases["a"]))
+	assert.True(t, releaseSetInstanceChanged(updated["b"], releases["b"]))
+}
+
+func TestReleaseSetState(t *testing.T) {
+	model := testReleaseSetModel(t, map[string][2]string{"a": {"", ""}, "b": {"", ""}})
+	instances := model.Instances.Elements()
+
+	// only the instances applied are recorded, e.g. when the installation of b failed
+	state := newReleaseSetState(model)
+	state.set("a", instances["a"], &release.Release{Version: 1, Info: &release.Info{Status: release.StatusDeployed}})
+	built, diags := state.build()
+	require.False(t, diags.HasError(), diags)
+	assert.Equal(t, "apps/test-chart", built.ID.ValueString())
+	assert.Equal(t, []string{"a"}, mapKeys(built.Instances.Elements()))
+	assert.Equal(t, types.StringValue("deployed"), built.Statuses.Elements()["a"])
+	assert.Equal(t, types.Int64Value(1), built.Revisions.Elements()["a"])
+
+	state.set("a", nil, nil)
+	built, diags = state.build()
+	require.False(t, diags.HasError(), diags)
+	assert.Empty(t, built.Instances.Elements())
+}
+
+func mapKeys(m map[string]attr.Value) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func TestAccResourceReleaseSet_basic(t *testing.T) {
+	prefix := randName("set")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		CheckDestroy:             testAccCheckHelmReleaseDestroy(namespace),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseSetConfig(namespace, prefix, []string{"a", "b"}),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release_set.test", "statuses.%", "2"),
+					resource.TestCheckResourceAttr("helm_release_set.test", fmt.Sprintf("statuses.%s-a", prefix), release.StatusDeployed.String()),
+					resource.TestCheckResourceAttr("helm_release_set.test", fmt.Sprintf("revisions.%s-b", prefix), "1"),
+				),
+			},
+			{
+				// b is uninstalled and c installed, a is left unchanged
+				Config: testAccHelmReleaseSetConfig(namespace, prefix, []string{"a", "c"}),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release_set.test", "statuses.%", "2"),
+					resource.TestCheckResourceAttr("helm_release_set.test", fmt.Sprintf("revisions.%s-a", prefix), "1"),
+					resource.TestCheckResourceAttr("helm_release_set.test", fmt.Sprintf("statuses.%s-c", prefix), release.StatusDeployed.String()),
+					resource.TestCheckNoResourceAttr("helm_release_set.test", fmt.Sprintf("statuses.%s-b", prefix)),
+				),
+			},
+		},
+	})
+}
+
+func testAccHelmReleaseSetConfig(ns, prefix string, instances []string) string {
+	var config string
+	for _, i := range instances {
+		config += fmt.Sprintf("\t\t\t\t%q = { values = [\"service:\\n  port: 8080\"] }\n", prefix+"-"+i)
+	}
+	return fmt.Sprintf(`
+		resource "helm_release_set" "test" {
+			namespace  = %q
+			repository = %q
+			chart      = "test-chart"
+			version    = "1.2.3"
+			values     = ["replicaCount: 1"]
+
+			instances = {
+%s			}
+		}
+	`, ns, testRepositoryURL, config)
+}
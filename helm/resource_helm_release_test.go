@@ -6,6 +6,7 @@ package helm
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
@@ -14,19 +15,30 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/helmpath"
 	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/releaseutil"
 	"helm.sh/helm/v3/pkg/repo"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestAccResourceRelease_basic(t *testing.T) {
@@ -46,10 +58,19 @@ func TestAccResourceRelease_basic(t *testing.T) {
 					resource.TestCheckResourceAttr("helm_release.test", "metadata.namespace", namespace),
 					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
 					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+					resource.TestCheckResourceAttr("helm_release.test", "storage_namespace", namespace),
+					resource.TestCheckResourceAttr("helm_release.test", "storage_object_name", fmt.Sprintf("sh.helm.release.v1.%s.v1", name)),
 					resource.TestCheckResourceAttr("helm_release.test", "description", "Test"),
 					resource.TestCheckResourceAttr("helm_release.test", "metadata.chart", "test-chart"),
 					resource.TestCheckResourceAttr("helm_release.test", "metadata.version", "1.2.3"),
 					resource.TestCheckResourceAttr("helm_release.test", "metadata.app_version", "1.19.5"),
+					resource.TestCheckResourceAttrSet("helm_release.test", "values_checksum"),
+					resource.TestCheckResourceAttr("helm_release.test", "namespaces.#", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "namespaces.0", namespace),
+					testAccCheckHelmReleaseResourceNamespace(namespace, name),
+					resource.TestMatchResourceAttr("helm_release.test", "hooks_manifest", regexp.MustCompile(`"events":\["test"\]`)),
+					resource.TestMatchResourceAttr("helm_release.test", "status_detail", regexp.MustCompile(`"revision":1,.*"superseded":null`)),
+					resource.TestMatchResourceAttr("helm_release.test", "resource_health.%", regexp.MustCompile(`^[1-9]`)),
 				),
 			},
 			{
@@ -264,6 +285,7 @@ func TestAccResourceRelease_update(t *testing.T) {
 					resource.TestCheckResourceAttr("helm_release.test", "metadata.version", "1.2.3"),
 					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
 					resource.TestCheckResourceAttr("helm_release.test", "version", "1.2.3"),
+					resource.TestCheckResourceAttr("helm_release.test", "change_summary", "install test-chart 1.2.3"),
 				),
 			},
 			{
@@ -273,11 +295,126 @@ func TestAccResourceRelease_update(t *testing.T) {
 					resource.TestCheckResourceAttr("helm_release.test", "metadata.version", "2.0.0"),
 					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
 					resource.TestCheckResourceAttr("helm_release.test", "version", "2.0.0"),
+					resource.TestCheckResourceAttr("helm_release.test", "change_summary", "upgrade test-chart 1.2.3 -> 2.0.0"),
 				),
 			},
 		},
 	})
 }
+func TestAccResourceRelease_paused(t *testing.T) {
+	name := randName("paused")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigPaused(testResourceName, namespace, name, "1.2.3", false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "paused", "false"),
+				),
+			},
+			{
+				// the release is not upgraded, the refresh after the apply plans the upgrade again
+				Config: testAccHelmReleaseConfigPaused(testResourceName, namespace, name, "2.0.0", true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.version", "1.2.3"),
+					resource.TestCheckResourceAttr("helm_release.test", "paused", "true"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccHelmReleaseConfigPaused(testResourceName, namespace, name, "2.0.0", false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "2"),
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.version", "2.0.0"),
+					resource.TestCheckResourceAttr("helm_release.test", "paused", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceRelease_versionConstraint(t *testing.T) {
+	name := randName("version-constraint")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigVersionConstraint(testResourceName, namespace, name, ">=1.0.0 <2.0.0"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "version", "1.2.3"),
+				),
+			},
+			{
+				Config: testAccHelmReleaseConfigVersionConstraint(testResourceName, namespace, name, ">=1.0.0"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "2"),
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.version", "2.0.0"),
+					resource.TestCheckResourceAttr("helm_release.test", "version", "2.0.0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceRelease_outOfBandChange(t *testing.T) {
+	name := randName("out-of-band")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigBasic(testResourceName, namespace, name, "1.2.3"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "out_of_band_change", "false"),
+				),
+			},
+			{
+				PreConfig: func() {
+					if err := upgradeOutOfBand(namespace, name, map[string]interface{}{"foo": "changed"}); err != nil {
+						t.Fatalf("Failed to upgrade release: %s", err)
+					}
+				},
+				Config: testAccHelmReleaseConfigBasic(testResourceName, namespace, name, "1.2.3"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "2"),
+					resource.TestCheckResourceAttr("helm_release.test", "out_of_band_change", "true"),
+				),
+			},
+		},
+	})
+}
+
+// upgradeOutOfBand upgrades a release as the helm CLI would, merging values into its values
+func upgradeOutOfBand(namespace, name string, values map[string]interface{}) error {
+	actionConfig := &action.Configuration{}
+	if err := actionConfig.Init(kube.GetConfig(os.Getenv("KUBE_CONFIG_PATH"), "", namespace), namespace, os.Getenv("HELM_DRIVER"), func(format string, v ...interface{}) {
+		log.Printf(format, v...)
+	}); err != nil {
+		return err
+	}
+	rel, err := action.NewGet(actionConfig).Run(name)
+	if err != nil {
+		return err
+	}
+	upgrade := action.NewUpgrade(actionConfig)
+	upgrade.Namespace = namespace
+	upgrade.ReuseValues = true
+	_, err = upgrade.Run(name, rel.Chart, values)
+	return err
+}
+
 func TestAccResourceRelease_emptyValuesList(t *testing.T) {
 	name := randName("test-empty-values-list")
 	namespace := createRandomNamespace(t)
@@ -334,6 +471,38 @@ func TestAccResourceRelease_updateValues(t *testing.T) {
 		},
 	})
 }
+func TestAccResourceRelease_normalizeValues(t *testing.T) {
+	name := randName("test-normalize-values")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigNormalizeValues(testResourceName, namespace, name, "foo: bar\nreplicas: 2\n"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "normalize_values", "true"),
+				),
+			},
+			{
+				Config: testAccHelmReleaseConfigNormalizeValues(testResourceName, namespace, name, "# formatting only\nreplicas: 2.0\nfoo:   bar\n"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "values.0", "# formatting only\nreplicas: 2.0\nfoo:   bar\n"),
+				),
+			},
+			{
+				Config: testAccHelmReleaseConfigNormalizeValues(testResourceName, namespace, name, "foo: baz\nreplicas: 2\n"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "2"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccResourceRelease_cloakValues(t *testing.T) {
 	name := randName("test-update-values")
 	namespace := createRandomNamespace(t)
@@ -633,6 +802,13 @@ func TestAccResourceRelease_postrender(t *testing.T) {
 					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
 				),
 			},
+			{
+				Config: testAccHelmReleaseConfigPostrenderChain(testResourceName, namespace, testResourceName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+					resource.TestCheckResourceAttr("helm_release.test", "postrender.#", "2"),
+				),
+			},
 		},
 	})
 }
@@ -800,6 +976,31 @@ func testAccHelmReleaseConfigBasic(resource, ns, name, version string) string {
 	`, resource, name, ns, testRepositoryURL, version)
 }
 
+func testAccHelmReleaseConfigPaused(resource, ns, name, version string, paused bool) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name       = %q
+			namespace  = %q
+			repository = %q
+			chart      = "test-chart"
+			version    = %q
+			paused     = %t
+		}
+	`, resource, name, ns, testRepositoryURL, version, paused)
+}
+
+func testAccHelmReleaseConfigVersionConstraint(resource, ns, name, constraint string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name               = %q
+			namespace          = %q
+			repository         = %q
+			chart              = "test-chart"
+			version_constraint = %q
+		}
+	`, resource, name, ns, testRepositoryURL, constraint)
+}
+
 func testAccHelmReleaseConfigParallel(resource string, count int, ns, name, version string) string {
 	return fmt.Sprintf(`
 		resource "helm_release" "%s" {
@@ -855,6 +1056,20 @@ func testAccHelmReleaseConfigValues(resource, ns, name, chart, version string, v
 	`, resource, name, ns, testRepositoryURL, chart, version, strings.Join(vals, ","))
 }
 
+func testAccHelmReleaseConfigNormalizeValues(resource, ns, name, values string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name             = %q
+			namespace        = %q
+			repository       = %q
+			chart            = "test-chart"
+			version          = "1.2.3"
+			normalize_values = true
+			values           = [%q]
+		}
+	`, resource, name, ns, testRepositoryURL, values)
+}
+
 func testAccHelmReleaseConfigSensitiveValue(resource, ns, name, chart, version, key, value string) string {
 	return fmt.Sprintf(`
 		resource "helm_release" "%s" {
@@ -997,6 +1212,87 @@ func TestUseChartVersion(t *testing.T) {
 	}
 }
 
+func TestValuesChecksum(t *testing.T) {
+	a, err := valuesChecksum(map[string]interface{}{
+		"foo":  "bar",
+		"fizz": int64(1337),
+		"nested": map[string]interface{}{
+			"b": true,
+			"a": []interface{}{"x", "y"},
+		},
+	})
+	assert.NoError(t, err)
+
+	// values read back from the release are decoded from JSON
+	b, err := valuesChecksum(map[string]interface{}{
+		"nested": map[string]interface{}{
+			"a": []interface{}{"x", "y"},
+			"b": true,
+		},
+		"fizz": float64(1337),
+		"foo":  "bar",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, a, b)
+
+	c, err := valuesChecksum(map[string]interface{}{"foo": "baz"})
+	assert.NoError(t, err)
+	assert.NotEqual(t, a, c)
+
+	empty, err := valuesChecksum(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a", empty)
+}
+
+// blockingKubeClient blocks the creation of objects until unblock is closed, standing for an
+// install still applying its manifest when Terraform is interrupted
+type blockingKubeClient struct {
+	forwardingKubeClient
+	once    sync.Once
+	created chan struct{}
+	unblock chan struct{}
+}
+
+func (c *blockingKubeClient) Create(resources kube.ResourceList) (*kube.Result, error) {
+	c.once.Do(func() { close(c.created) })
+	<-c.unblock
+	return c.Interface.Create(resources)
+}
+
+func TestOperationInterrupted(t *testing.T) {
+	assert.Nil(t, operationInterrupted(context.Background(), "default", "app", "pending-install"))
+
+	kubeClient := &blockingKubeClient{
+		forwardingKubeClient: forwardingKubeClient{Interface: &kubefake.PrintingKubeClient{Out: io.Discard}},
+		created:              make(chan struct{}),
+		unblock:              make(chan struct{}),
+	}
+	defer close(kubeClient.unblock)
+	install := action.NewInstall(&action.Configuration{
+		Releases:     storage.Init(driver.NewMemory()),
+		KubeClient:   kubeClient,
+		Capabilities: chartutil.DefaultCapabilities,
+		Log:          func(string, ...interface{}) {},
+	})
+	install.ReleaseName = "app"
+	install.Namespace = "default"
+
+	// Terraform cancels the context while Helm is creating the objects of the release
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-kubeClient.created
+		cancel()
+	}()
+	_, err := install.RunWithContext(ctx, preRenderedChart("charts/app", "", testPreRenderedManifest), nil)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	diags := operationInterrupted(ctx, "default", "app", "pending-install")
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.SeverityWarning, diags[0].Severity())
+	assert.Contains(t, diags[0].Detail(), `left with the "pending-install" status`)
+	assert.Contains(t, diags[0].Detail(), "helm rollback app --namespace default")
+}
+
 //check for unit test documentation
 // func TestGetListValues(t *testing.T) {
 // 	ctx := context.Background()
@@ -1181,7 +1477,9 @@ func testAccCheckHelmReleaseDependencyUpdate(namespace string, name string, expe
 	}
 }
 
-func testAccCheckHelmReleaseDestroy(namespace string) resource.TestCheckFunc {
+// testAccCheckHelmReleaseDestroy checks that the test release is removed and that no release
+// is left in namespace, nor in the otherNamespaces a chart with allow_cross_namespace uses
+func testAccCheckHelmReleaseDestroy(namespace string, otherNamespaces ...string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		log.Printf("testMeta before checking: %+v\n", testMeta)
 		log.Printf("Terraform state: %+v\n", s)
@@ -1189,32 +1487,71 @@ func testAccCheckHelmReleaseDestroy(namespace string) resource.TestCheckFunc {
 			return fmt.Errorf("provider not properly initialized")
 		}
 		ctx := context.Background()
-		actionConfig, err := testMeta.GetHelmConfiguration(ctx, namespace)
-		if err != nil {
-			return err
-		}
+		for _, ns := range append([]string{namespace}, otherNamespaces...) {
+			actionConfig, err := testMeta.GetHelmConfiguration(ctx, ns)
+			if err != nil {
+				return err
+			}
 
-		client := action.NewList(actionConfig)
-		res, err := client.Run()
+			client := action.NewList(actionConfig)
+			res, err := client.Run()
 
-		if res == nil {
-			return nil
+			if res == nil {
+				continue
+			}
+
+			if err != nil {
+				return err
+			}
+
+			for _, r := range res {
+				if r.Name == testResourceName {
+					return fmt.Errorf("found %q release", testResourceName)
+				}
+
+				if r.Namespace == ns {
+					return fmt.Errorf("%q namespace should be empty", ns)
+				}
+			}
 		}
 
+		return nil
+	}
+}
+
+// testAccCheckHelmReleaseResourceNamespace checks that the objects and the hooks of the release
+// name are in namespace, or in one of the otherNamespaces the chart is allowed to use
+func testAccCheckHelmReleaseResourceNamespace(namespace, name string, otherNamespaces ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if testMeta == nil {
+			return fmt.Errorf("provider not properly initialized")
+		}
+		actionConfig, err := testMeta.GetHelmConfiguration(context.Background(), namespace)
+		if err != nil {
+			return err
+		}
+		res, err := action.NewGet(actionConfig).Run(name)
 		if err != nil {
 			return err
 		}
 
-		for _, r := range res {
-			if r.Name == testResourceName {
-				return fmt.Errorf("found %q release", testResourceName)
-			}
-
-			if r.Namespace == namespace {
-				return fmt.Errorf("%q namespace should be empty", namespace)
+		manifests := []string{res.Manifest}
+		for _, h := range res.Hooks {
+			manifests = append(manifests, h.Manifest)
+		}
+		namespaces, err := manifestNamespaces(strings.Join(manifests, "\n---\n"), namespace)
+		if err != nil {
+			return err
+		}
+		allowed := map[string]bool{namespace: true}
+		for _, ns := range otherNamespaces {
+			allowed[ns] = true
+		}
+		for _, ns := range namespaces {
+			if !allowed[ns] {
+				return fmt.Errorf("release %q has objects in namespace %q, expected %q", name, ns, append([]string{namespace}, otherNamespaces...))
 			}
 		}
-
 		return nil
 	}
 }
@@ -1228,10 +1565,12 @@ func testAccHelmReleaseConfigPostrender(resource, ns, name, binaryPath string, a
   			chart       = "test-chart"
 			version     = "1.2.3"
 
-			postrender = {
-				binary_path = %q
-				args        = [%s]
-			}
+			postrender = [
+				{
+					binary_path = %q
+					args        = [%s]
+				}
+			]
 
 			set = [
 				{
@@ -1247,6 +1586,28 @@ func testAccHelmReleaseConfigPostrender(resource, ns, name, binaryPath string, a
 	`, resource, name, ns, testRepositoryURL, binaryPath, fmt.Sprintf(`"%s"`, strings.Join(args, `", "`)))
 }
 
+func testAccHelmReleaseConfigPostrenderChain(resource, ns, name string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+ 			name        = %q
+			namespace   = %q
+			repository  = %q
+  			chart       = "test-chart"
+			version     = "1.2.3"
+
+			postrender = [
+				{
+					binary_path = "cat"
+				},
+				{
+					binary_path = "sed"
+					args        = ["s/app.kubernetes.io/app.k8s.io/g"]
+				}
+			]
+		}
+	`, resource, name, ns, testRepositoryURL)
+}
+
 func TestAccResourceRelease_LintFailValues(t *testing.T) {
 	namespace := createRandomNamespace(t)
 	defer deleteNamespace(t, namespace)
@@ -1361,6 +1722,9 @@ func TestAccResourceRelease_dependency(t *testing.T) {
 					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
 					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
 					resource.TestCheckResourceAttr("helm_release.test", "dependency_update", "true"),
+					resource.TestCheckResourceAttr("helm_release.test", "enabled_subcharts.#", "2"),
+					resource.TestCheckResourceAttr("helm_release.test", "enabled_subcharts.0", "dependency-bar"),
+					resource.TestCheckResourceAttr("helm_release.test", "enabled_subcharts.1", "dependency-foo"),
 				),
 			},
 			{
@@ -1479,6 +1843,67 @@ func TestAccResourceRelease_delete_regression(t *testing.T) {
 	})
 }
 
+func TestAccResourceRelease_valuesFrom(t *testing.T) {
+	name := randName("values-from")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	ctx := context.Background()
+	_, err := client.CoreV1().Secrets(namespace).Create(ctx, &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-values"},
+		StringData: map[string]string{"values.yaml": "service:\n  port: 1337\n"},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.CoreV1().ConfigMaps(namespace).Create(ctx, &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-values"},
+		Data:       map[string]string{"custom.yaml": "serviceAccount:\n  create: false\n"},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigValuesFrom(testResourceName, namespace, name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.values", `{"service":{"port":"(sensitive value)"},"serviceAccount":{"create":false}}`),
+				),
+			},
+		},
+	})
+}
+
+func testAccHelmReleaseConfigValuesFrom(resource, ns, name string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name        = %q
+			namespace   = %q
+			repository  = %q
+			chart       = "test-chart"
+			version     = "1.2.3"
+
+			values_from = [
+				{
+					secret_ref = {
+						name = "test-values"
+					}
+				},
+				{
+					config_map_ref = {
+						name = "test-values"
+						key  = "custom.yaml"
+					}
+				}
+			]
+		}
+	`, resource, name, ns, testRepositoryURL)
+}
+
 // Unsupported block type for experiements, might have to change it to block instead of listnested etc.
 func TestAccResourceRelease_manifest(t *testing.T) {
 	ctx := context.Background()
@@ -1513,13 +1938,69 @@ func TestAccResourceRelease_manifest(t *testing.T) {
 		},
 	})
 }
-func getReleaseJSONManifest(ctx context.Context, namespace, name string) (string, error) {
-	// Execute the Helm command to get the release manifest
-	cmd := exec.CommandContext(ctx, "helm", "get", "manifest", "--namespace", namespace, name)
-	manifest, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
+func TestAccResourceRelease_manifestStorage(t *testing.T) {
+	name := randName("manifest-storage")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigManifestStorage(testResourceName, namespace, name, "full"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+					resource.TestCheckResourceAttrSet("helm_release.test", "manifest"),
+					resource.TestCheckResourceAttrSet("helm_release.test", "manifest_sha256"),
+				),
+			},
+			{
+				// the release is not upgraded
+				Config: testAccHelmReleaseConfigManifestStorage(testResourceName, namespace, name, "hash"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+					resource.TestCheckNoResourceAttr("helm_release.test", "manifest"),
+					resource.TestCheckResourceAttrSet("helm_release.test", "manifest_sha256"),
+				),
+			},
+			{
+				Config: testAccHelmReleaseConfigManifestStorage(testResourceName, namespace, name, "compressed"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+					resource.TestMatchResourceAttr("helm_release.test", "manifest", regexp.MustCompile(`^H4sI`)),
+					resource.TestCheckResourceAttrSet("helm_release.test", "manifest_sha256"),
+				),
+			},
+		},
+	})
+}
+
+func testAccHelmReleaseConfigManifestStorage(resource, ns, name, storage string) string {
+	return fmt.Sprintf(`
+		provider "helm" {
+			experiments = {
+				manifest = true
+			}
+		}
+
+		resource "helm_release" "%s" {
+			name             = %q
+			namespace        = %q
+			repository       = %q
+			version          = "1.2.3"
+			chart            = "test-chart"
+			manifest_storage = %q
+		}
+	`, resource, name, ns, testRepositoryURL, storage)
+}
+
+func getReleaseJSONManifest(ctx context.Context, namespace, name string) (string, error) {
+	// Execute the Helm command to get the release manifest
+	cmd := exec.CommandContext(ctx, "helm", "get", "manifest", "--namespace", namespace, name)
+	manifest, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
 
 	// Convert the YAML manifest to JSON
 	jsonManifest, err := convertYAMLManifestToJSON(string(manifest))
@@ -2181,3 +2662,407 @@ func testAccHelmReleaseRecomputeMetadataSet(resource, ns, name string) string {
 		}
 `, resource, name, ns, resource)
 }
+
+func TestAccResourceRelease_driftDetection(t *testing.T) {
+	name := randName("drift-detection")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigDriftDetection(testResourceName, namespace, name, "metadata"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+					resource.TestCheckResourceAttr("helm_release.test", "drift_detection", "metadata"),
+				),
+			},
+			{
+				Config: testAccHelmReleaseConfigDriftDetection(testResourceName, namespace, name, "none"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+					resource.TestCheckResourceAttr("helm_release.test", "drift_detection", "none"),
+				),
+			},
+			{
+				Config:      testAccHelmReleaseConfigDriftDetection(testResourceName, namespace, name, "always"),
+				ExpectError: regexp.MustCompile(`Attribute drift_detection value must be one of`),
+			},
+		},
+	})
+}
+
+func testAccHelmReleaseConfigDriftDetection(resource, ns, name, mode string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name            = %q
+			namespace       = %q
+			repository      = %q
+			chart           = "test-chart"
+			version         = "1.2.3"
+			drift_detection = %q
+		}
+	`, resource, name, ns, testRepositoryURL, mode)
+}
+
+func TestAccResourceRelease_dryRunMode(t *testing.T) {
+	name := randName("dry-run-mode")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigDryRunMode(testResourceName, namespace, name, "server"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+					resource.TestCheckResourceAttr("helm_release.test", "dry_run_mode", "server"),
+				),
+			},
+			{
+				Config:      testAccHelmReleaseConfigDryRunMode(testResourceName, namespace, name, "none"),
+				ExpectError: regexp.MustCompile(`Attribute dry_run_mode value must be one of`),
+			},
+		},
+	})
+}
+
+func testAccHelmReleaseConfigDryRunMode(resource, ns, name, mode string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name         = %q
+			namespace    = %q
+			repository   = %q
+			chart        = "test-chart"
+			version      = "1.2.3"
+			dry_run_mode = %q
+		}
+	`, resource, name, ns, testRepositoryURL, mode)
+}
+
+func TestAccResourceRelease_offlinePlan(t *testing.T) {
+	name := randName("offline-plan")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigOfflinePlan(testResourceName, namespace, name, false, testRepositoryURL),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+				),
+			},
+			{
+				// the repository is not reachable, the plan must not access it
+				Config:             testAccHelmReleaseConfigOfflinePlan(testResourceName, namespace, name, true, "https://charts.invalid"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceRelease_changeSummaryCreate(t *testing.T) {
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	// the summary is known after the apply whether the chart is rendered on plan or not
+	for _, offline := range []bool{false, true} {
+		name := randName(fmt.Sprintf("change-summary-%t", offline))
+		resource.Test(t, resource.TestCase{
+			ProtoV6ProviderFactories: protoV6ProviderFactories(),
+			Steps: []resource.TestStep{
+				{
+					Config: testAccHelmReleaseConfigOfflinePlan(testResourceName, namespace, name, offline, testRepositoryURL),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+						resource.TestCheckResourceAttr("helm_release.test", "change_summary", "install test-chart 1.2.3"),
+					),
+				},
+			},
+		})
+	}
+}
+
+func TestAccResourceRelease_updateServiceAccount(t *testing.T) {
+	name := randName("service-account")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+	createServiceAccount(t, namespace, "deployer", "admin")
+	createServiceAccount(t, namespace, "other-deployer", "admin")
+	createServiceAccount(t, namespace, "reader", "")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigServiceAccount(testResourceName, namespace, name, namespace+"/deployer"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "deploy_as_service_account", namespace+"/deployer"),
+				),
+			},
+			{
+				// the upgrade runs as the new service account, which cannot read the release
+				Config:      testAccHelmReleaseConfigServiceAccount(testResourceName, namespace, name, namespace+"/reader"),
+				ExpectError: regexp.MustCompile(`(?i)forbidden`),
+			},
+			{
+				Config: testAccHelmReleaseConfigServiceAccount(testResourceName, namespace, name, namespace+"/other-deployer"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "2"),
+					resource.TestCheckResourceAttr("helm_release.test", "deploy_as_service_account", namespace+"/other-deployer"),
+				),
+			},
+		},
+	})
+}
+
+// createServiceAccount creates the service account name in namespace, bound to the cluster
+// role clusterRole in namespace unless it is empty
+func createServiceAccount(t *testing.T, namespace, name, clusterRole string) {
+	ctx := context.TODO()
+	sa := &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if _, err := client.CoreV1().ServiceAccounts(namespace).Create(ctx, sa, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Could not create service account %s/%s: %s", namespace, name, err)
+	}
+	if clusterRole == "" {
+		return
+	}
+	binding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: clusterRole},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: namespace}},
+	}
+	if _, err := client.RbacV1().RoleBindings(namespace).Create(ctx, binding, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Could not bind service account %s/%s to %s: %s", namespace, name, clusterRole, err)
+	}
+}
+
+func testAccHelmReleaseConfigServiceAccount(resource, ns, name, serviceAccount string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name                      = %q
+			namespace                 = %q
+			repository                = %q
+			chart                     = "test-chart"
+			version                   = "1.2.3"
+			deploy_as_service_account = %q
+		}
+	`, resource, name, ns, testRepositoryURL, serviceAccount)
+}
+
+func testAccHelmReleaseConfigOfflinePlan(resource, ns, name string, offline bool, repository string) string {
+	return fmt.Sprintf(`
+		provider "helm" {
+			offline_plan = %t
+		}
+
+		resource "helm_release" "%s" {
+			name       = %q
+			namespace  = %q
+			repository = %q
+			chart      = "test-chart"
+			version    = "1.2.3"
+		}
+	`, offline, resource, name, ns, repository)
+}
+
+func TestAccResourceRelease_unknownProviderConfig(t *testing.T) {
+	name := randName("unknown-config")
+
+	// the path of the kubeconfig is only known at apply time, like the credentials of a cluster
+	// created in the same configuration, the release is planned without accessing the cluster
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					resource "terraform_data" "cluster" {
+						input = "/nonexistent/kubeconfig"
+					}
+
+					provider "helm" {
+						kubernetes = {
+							config_path = terraform_data.cluster.output
+						}
+					}
+
+					resource "helm_release" "%s" {
+						name       = %q
+						namespace  = "default"
+						repository = "https://charts.invalid"
+						chart      = "test-chart"
+						version    = "1.2.3"
+					}
+				`, testResourceName, name),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceRelease_mock(t *testing.T) {
+	name := randName("mock")
+
+	// neither the repository nor the namespace exist, mock releases are not installed
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigMock(testResourceName, name, "1.2.3", "1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.chart", "test-chart"),
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.version", "1.2.3"),
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.values", `{"replicaCount":1}`),
+					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+				),
+			},
+			{
+				Config: testAccHelmReleaseConfigMock(testResourceName, name, "2.0.0", "2"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "2"),
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.version", "2.0.0"),
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.values", `{"replicaCount":2}`),
+				),
+			},
+		},
+	})
+}
+
+func testAccHelmReleaseConfigMock(resource, name, version, replicas string) string {
+	return fmt.Sprintf(`
+		provider "helm" {
+			mock = true
+		}
+
+		resource "helm_release" "%s" {
+			name       = %q
+			namespace  = "mock-namespace"
+			repository = "https://charts.invalid"
+			chart      = "test-chart"
+			version    = %q
+
+			set = [
+				{
+					name  = "replicaCount"
+					value = %q
+				},
+			]
+		}
+	`, resource, name, version, replicas)
+}
+
+func TestAccResourceRelease_repositoryCachePath(t *testing.T) {
+	name := randName("cache-path")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	cachePath := t.TempDir()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{{
+			Config: testAccHelmReleaseConfigRepositoryCachePath(testResourceName, namespace, name, cachePath),
+			Check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+				func(s *terraform.State) error {
+					charts, err := filepath.Glob(filepath.Join(cachePath, "test-chart-*.tgz"))
+					if err != nil {
+						return err
+					}
+					if len(charts) == 0 {
+						return fmt.Errorf("chart not downloaded into %s", cachePath)
+					}
+					return nil
+				},
+			),
+		}},
+	})
+}
+
+func testAccHelmReleaseConfigRepositoryCachePath(resource, ns, name, cachePath string) string {
+	return fmt.Sprintf(`
+		provider "helm" {
+			repository_cache_path = %q
+		}
+
+		resource "helm_release" "%s" {
+			name       = %q
+			namespace  = %q
+			repository = %q
+			chart      = "test-chart"
+			version    = "1.2.3"
+		}
+	`, cachePath, resource, name, ns, testRepositoryURL)
+}
+
+func TestAccResourceRelease_preRenderedManifest(t *testing.T) {
+	name := randName("pre-rendered")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{{
+			Config: testAccHelmReleaseConfigPreRenderedManifest(testResourceName, namespace, name),
+			Check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+				resource.TestCheckResourceAttr("helm_release.test", "metadata.chart", "test-chart"),
+				resource.TestCheckResourceAttr("helm_release.test", "metadata.version", "1.2.3"),
+			),
+		}},
+	})
+}
+
+func testAccHelmReleaseConfigPreRenderedManifest(resource, ns, name string) string {
+	return fmt.Sprintf(`
+		data "helm_template" "test" {
+			name       = %[2]q
+			namespace  = %[3]q
+			repository = %[4]q
+			chart      = "test-chart"
+			version    = "1.2.3"
+		}
+
+		resource "helm_release" "%[1]s" {
+			name                  = %[2]q
+			namespace             = %[3]q
+			chart                 = "test-chart"
+			version               = "1.2.3"
+			pre_rendered_manifest = data.helm_template.test.manifest
+		}
+	`, resource, name, ns, testRepositoryURL)
+}
+
+func TestParseImportIdentifier(t *testing.T) {
+	cases := []struct {
+		id          string
+		kubeContext string
+		namespace   string
+		name        string
+	}{
+		{"default/app", "", "default", "app"},
+		{"staging/default/app", "staging", "default", "app"},
+		{"arn:aws:eks:eu-west-1:123456789012:cluster/prod/default/app", "arn:aws:eks:eu-west-1:123456789012:cluster/prod", "default", "app"},
+	}
+	for _, c := range cases {
+		kubeContext, namespace, name, err := parseImportIdentifier(c.id)
+		assert.NoError(t, err)
+		assert.Equal(t, c.kubeContext, kubeContext)
+		assert.Equal(t, c.namespace, namespace)
+		assert.Equal(t, c.name, name)
+	}
+
+	_, _, _, err := parseImportIdentifier("app")
+	assert.Error(t, err)
+}
@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// revisionHistoryLength is the number of revisions listed by the revision_history attribute
+const revisionHistoryLength = 10
+
+func revisionHistoryAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"chart_version": types.StringType,
+		"deployed":      types.StringType,
+		"revision":      types.Int64Type,
+		"status":        types.StringType,
+	}
+}
+
+// revisionHistory returns the revision_history attribute of the release r: the latest
+// revisionHistoryLength revisions of history, newest first. r takes the place of its revision
+// in history, which may have been read before r was stored.
+func revisionHistory(r *release.Release, history []*release.Release) types.List {
+	revisions := map[int]*release.Release{r.Version: r}
+	for _, h := range history {
+		if _, ok := revisions[h.Version]; !ok {
+			revisions[h.Version] = h
+		}
+	}
+	sorted := make([]*release.Release, 0, len(revisions))
+	for _, h := range revisions {
+		sorted = append(sorted, h)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version > sorted[j].Version })
+	if len(sorted) > revisionHistoryLength {
+		sorted = sorted[:revisionHistoryLength]
+	}
+
+	elemType := types.ObjectType{AttrTypes: revisionHistoryAttrTypes()}
+	elems := make([]attr.Value, 0, len(sorted))
+	for _, h := range sorted {
+		chartVersion := types.StringNull()
+		if h.Chart != nil && h.Chart.Metadata != nil {
+			chartVersion = types.StringValue(h.Chart.Metadata.Version)
+		}
+		status, deployed := "", ""
+		if h.Info != nil {
+			status = h.Info.Status.String()
+			deployed = formatReleaseTime(h.Info.LastDeployed)
+		}
+		elems = append(elems, types.ObjectValueMust(elemType.AttrTypes, map[string]attr.Value{
+			"chart_version": chartVersion,
+			"deployed":      types.StringValue(deployed),
+			"revision":      types.Int64Value(int64(h.Version)),
+			"status":        types.StringValue(status),
+		}))
+	}
+	return types.ListValueMust(elemType, elems)
+}
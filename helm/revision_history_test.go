@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
+)
+
+func TestRevisionHistory(t *testing.T) {
+	r := func(revision int, status release.Status) *release.Release {
+		return &release.Release{
+			Name:    "test",
+			Version: revision,
+			Chart:   &chart.Chart{Metadata: &chart.Metadata{Name: "test-chart", Version: fmt.Sprintf("1.0.%d", revision)}},
+			Info: &release.Info{
+				LastDeployed: helmtime.Time{Time: time.Date(2024, 1, revision, 3, 4, 5, 0, time.UTC)},
+				Status:       status,
+			},
+		}
+	}
+
+	type revisionModel struct {
+		ChartVersion types.String `tfsdk:"chart_version"`
+		Deployed     types.String `tfsdk:"deployed"`
+		Revision     types.Int64  `tfsdk:"revision"`
+		Status       types.String `tfsdk:"status"`
+	}
+	revisions := func(l types.List) []revisionModel {
+		var m []revisionModel
+		require.False(t, l.ElementsAs(context.Background(), &m, false).HasError())
+		return m
+	}
+
+	// the history may be read before the current revision is stored as deployed
+	current := r(3, release.StatusDeployed)
+	history := []*release.Release{r(1, release.StatusSuperseded), r(3, release.StatusPendingUpgrade), r(2, release.StatusFailed)}
+	got := revisions(revisionHistory(current, history))
+	require.Len(t, got, 3)
+	assert.Equal(t, int64(3), got[0].Revision.ValueInt64())
+	assert.Equal(t, "deployed", got[0].Status.ValueString())
+	assert.Equal(t, "1.0.3", got[0].ChartVersion.ValueString())
+	assert.Equal(t, "2024-01-03T03:04:05Z", got[0].Deployed.ValueString())
+	assert.Equal(t, "failed", got[1].Status.ValueString())
+	assert.Equal(t, int64(1), got[2].Revision.ValueInt64())
+
+	// only the latest revisions are listed
+	history = nil
+	for i := 1; i <= 15; i++ {
+		history = append(history, r(i, release.StatusSuperseded))
+	}
+	got = revisions(revisionHistory(history[14], history))
+	require.Len(t, got, revisionHistoryLength)
+	assert.Equal(t, int64(15), got[0].Revision.ValueInt64())
+	assert.Equal(t, int64(6), got[9].Revision.ValueInt64())
+}
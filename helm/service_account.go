@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// serviceAccountTokenExpiration is the lifetime of the tokens requested for deploy_as_service_account,
+// it must outlast the operations run with the token
+const serviceAccountTokenExpiration = time.Hour
+
+// serviceAccountPattern matches the service account references of deploy_as_service_account
+var serviceAccountPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?/[a-z0-9]([-.a-z0-9]*[a-z0-9])?$`)
+
+// parseServiceAccount splits a service account reference of the form namespace/name
+func parseServiceAccount(ref string) (string, string, error) {
+	namespace, name, ok := strings.Cut(ref, "/")
+	if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid service account %q, expected namespace/name", ref)
+	}
+	return namespace, name, nil
+}
+
+// serviceAccountToken requests a short-lived token of the service account ref with the
+// TokenRequest API, using the credentials of kc
+func serviceAccountToken(ctx context.Context, kc *KubeConfig, ref string) (string, error) {
+	namespace, name, err := parseServiceAccount(ref)
+	if err != nil {
+		return "", err
+	}
+	config, err := kc.ToRESTConfig()
+	if err != nil {
+		return "", err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return "", err
+	}
+
+	expiration := int64(serviceAccountTokenExpiration.Seconds())
+	tr, err := clientset.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, name, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expiration},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("unable to request a token for service account %q: %w", ref, err)
+	}
+	return tr.Status.Token, nil
+}
@@ -0,0 +1,80 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+func TestParseServiceAccount(t *testing.T) {
+	namespace, name, err := parseServiceAccount("apps/deployer")
+	assert.NoError(t, err)
+	assert.Equal(t, "apps", namespace)
+	assert.Equal(t, "deployer", name)
+
+	for _, ref := range []string{"", "deployer", "apps/", "/deployer", "apps/deployer/extra"} {
+		_, _, err := parseServiceAccount(ref)
+		assert.Error(t, err, ref)
+		assert.False(t, serviceAccountPattern.MatchString(ref), ref)
+	}
+	assert.True(t, serviceAccountPattern.MatchString("apps/deployer"))
+}
+
+func TestServiceAccountToken(t *testing.T) {
+	t.Setenv("KUBE_CONFIG_PATHS", "")
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, fmt.Sprintf("%s %s", r.Method, r.URL.Path))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(authenticationv1.TokenRequest{
+			Status: authenticationv1.TokenRequestStatus{Token: "minted"},
+		})
+	}))
+	defer server.Close()
+
+	configPath := filepath.Join(t.TempDir(), "config")
+	kubeConfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+- name: test
+  cluster:
+    server: %s
+users:
+- name: admin
+  user:
+    token: admin
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: admin
+`, server.URL)
+	if err := os.WriteFile(configPath, []byte(kubeConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	kc, err := testKubeConfigMeta(t, configPath, "").NewKubeConfig(context.Background(), "default", "")
+	assert.NoError(t, err)
+	token, err := serviceAccountToken(context.Background(), kc, "apps/deployer")
+	assert.NoError(t, err)
+	assert.Equal(t, "minted", token)
+	assert.Equal(t, []string{"POST /api/v1/namespaces/apps/serviceaccounts/deployer/token"}, requests)
+
+	kc.BearerToken = token
+	config, err := kc.ToRESTConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "minted", config.BearerToken)
+	assert.Equal(t, server.URL, config.Host)
+}
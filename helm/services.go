@@ -0,0 +1,153 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+func servicePortAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"name":        types.StringType,
+		"port":        types.Int64Type,
+		"protocol":    types.StringType,
+		"target_port": types.StringType,
+		"node_port":   types.Int64Type,
+	}
+}
+
+func serviceAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"namespace":               types.StringType,
+		"type":                    types.StringType,
+		"cluster_ip":              types.StringType,
+		"ports":                   types.ListType{ElemType: types.ObjectType{AttrTypes: servicePortAttrTypes()}},
+		"load_balancer_addresses": types.ListType{ElemType: types.StringType},
+	}
+}
+
+// manifestServices returns the Services of a manifest, keyed by name when they are in the
+// namespace of the release and by namespace/name otherwise
+func manifestServices(manifest, releaseNamespace string) (map[string]loadBalancerObject, error) {
+	services := map[string]loadBalancerObject{}
+	for _, resource := range releaseutil.SplitManifests(manifest) {
+		var obj resourceMeta
+		if err := yaml.Unmarshal([]byte(resource), &obj); err != nil {
+			return nil, err
+		}
+		if obj.Kind != "Service" || obj.APIVersion != "v1" {
+			continue
+		}
+		namespace := obj.Metadata.Namespace
+		if namespace == "" {
+			namespace = releaseNamespace
+		}
+		key := obj.Metadata.Name
+		if namespace != releaseNamespace {
+			key = namespace + "/" + key
+		}
+		services[key] = loadBalancerObject{Kind: obj.Kind, Namespace: namespace, Name: obj.Metadata.Name}
+	}
+	return services, nil
+}
+
+// serviceObject returns the namespace, type, cluster IP, ports and load balancer addresses of
+// the live Service svc
+func serviceObject(ctx context.Context, svc *v1.Service) (types.Object, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	ports := make([]attr.Value, 0, len(svc.Spec.Ports))
+	for _, p := range svc.Spec.Ports {
+		port, d := types.ObjectValue(servicePortAttrTypes(), map[string]attr.Value{
+			"name":        types.StringValue(p.Name),
+			"port":        types.Int64Value(int64(p.Port)),
+			"protocol":    types.StringValue(string(p.Protocol)),
+			"target_port": types.StringValue(p.TargetPort.String()),
+			"node_port":   types.Int64Value(int64(p.NodePort)),
+		})
+		diags.Append(d...)
+		ports = append(ports, port)
+	}
+	portsList, d := types.ListValue(types.ObjectType{AttrTypes: servicePortAttrTypes()}, ports)
+	diags.Append(d...)
+	// the addresses are empty until a load balancer is assigned
+	lbAddresses := ingressAddresses(svc.Status.LoadBalancer.Ingress)
+	if lbAddresses == nil {
+		lbAddresses = []string{}
+	}
+	addresses, d := types.ListValueFrom(ctx, types.StringType, lbAddresses)
+	diags.Append(d...)
+	if diags.HasError() {
+		return types.ObjectNull(serviceAttrTypes()), diags
+	}
+	obj, d := types.ObjectValue(serviceAttrTypes(), map[string]attr.Value{
+		"namespace":               types.StringValue(svc.Namespace),
+		"type":                    types.StringValue(string(svc.Spec.Type)),
+		"cluster_ip":              types.StringValue(svc.Spec.ClusterIP),
+		"ports":                   portsList,
+		"load_balancer_addresses": addresses,
+	})
+	diags.Append(d...)
+	return obj, diags
+}
+
+// releaseServices returns the live Services of the release r, see manifestServices
+func releaseServices(ctx context.Context, clientset kubernetes.Interface, r *release.Release) (types.Map, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	mapType := types.ObjectType{AttrTypes: serviceAttrTypes()}
+	services, err := manifestServices(r.Manifest, r.Namespace)
+	if err != nil {
+		diags.AddWarning("Error reading services", fmt.Sprintf("Unable to parse the manifest of Helm release %s: %s", r.Name, err))
+		return types.MapNull(mapType), diags
+	}
+
+	objects := map[string]attr.Value{}
+	for key, s := range services {
+		svc, err := clientset.CoreV1().Services(s.Namespace).Get(ctx, s.Name, metav1.GetOptions{})
+		if err != nil {
+			diags.AddWarning("Error reading services", fmt.Sprintf("Unable to read Service %s/%s of Helm release %s: %s", s.Namespace, s.Name, r.Name, err))
+			return types.MapNull(mapType), diags
+		}
+		obj, d := serviceObject(ctx, svc)
+		diags.Append(d...)
+		objects[key] = obj
+	}
+	if diags.HasError() {
+		return types.MapNull(mapType), diags
+	}
+	m, d := types.MapValue(mapType, objects)
+	diags.Append(d...)
+	return m, diags
+}
+
+// setServices sets the services attribute of state to the live Services of the release after
+// Helm waited for them, so that their addresses are assigned
+func setServices(ctx context.Context, actionConfig *action.Configuration, state *HelmReleaseModel, r *release.Release) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if !state.Wait.ValueBool() {
+		state.Services = types.MapNull(types.ObjectType{AttrTypes: serviceAttrTypes()})
+		return diags
+	}
+	clientset, err := actionConfig.KubernetesClientSet()
+	if err != nil {
+		diags.AddWarning("Error reading services", fmt.Sprintf("Unable to create Kubernetes client: %s", err))
+		state.Services = types.MapNull(types.ObjectType{AttrTypes: serviceAttrTypes()})
+		return diags
+	}
+	services, d := releaseServices(ctx, clientset, r)
+	diags.Append(d...)
+	state.Services = services
+	return diags
+}
@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestReleaseServices(t *testing.T) {
+	ctx := context.Background()
+	r := &release.Release{Name: "test", Namespace: "default", Manifest: loadBalancerManifest + `---
+# Source: test/templates/metrics.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: metrics
+  namespace: monitoring
+`}
+	clientset := fake.NewSimpleClientset(
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: v1.ServiceSpec{
+				Type:      v1.ServiceTypeLoadBalancer,
+				ClusterIP: "10.0.0.10",
+				Ports:     []v1.ServicePort{{Name: "http", Port: 80, Protocol: v1.ProtocolTCP, TargetPort: intstr.FromString("http"), NodePort: 30080}},
+			},
+			Status: v1.ServiceStatus{LoadBalancer: v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "203.0.113.10"}}}},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "internal", Namespace: "default"},
+			Spec:       v1.ServiceSpec{Type: v1.ServiceTypeClusterIP, ClusterIP: "10.0.0.11", Ports: []v1.ServicePort{{Port: 8080, Protocol: v1.ProtocolTCP, TargetPort: intstr.FromInt32(8080)}}},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "metrics", Namespace: "monitoring"},
+			Spec:       v1.ServiceSpec{Type: v1.ServiceTypeClusterIP, ClusterIP: "None"},
+		},
+	)
+
+	services, diags := releaseServices(ctx, clientset, r)
+	require.False(t, diags.HasError(), diags)
+	elements := services.Elements()
+	require.Len(t, elements, 3)
+
+	web := elements["web"].(types.Object).Attributes()
+	assert.Equal(t, types.StringValue("LoadBalancer"), web["type"])
+	assert.Equal(t, types.StringValue("10.0.0.10"), web["cluster_ip"])
+	addresses, _ := types.ListValueFrom(ctx, types.StringType, []string{"203.0.113.10"})
+	assert.Equal(t, addresses, web["load_balancer_addresses"])
+	ports := web["ports"].(types.List).Elements()
+	require.Len(t, ports, 1)
+	port := ports[0].(types.Object).Attributes()
+	assert.Equal(t, types.Int64Value(80), port["port"])
+	assert.Equal(t, types.StringValue("http"), port["target_port"])
+	assert.Equal(t, types.Int64Value(30080), port["node_port"])
+
+	internal := elements["internal"].(types.Object).Attributes()
+	assert.Empty(t, internal["load_balancer_addresses"].(types.List).Elements())
+	assert.False(t, internal["load_balancer_addresses"].IsNull())
+
+	// services outside of the namespace of the release are keyed by namespace/name
+	metrics := elements["monitoring/metrics"].(types.Object).Attributes()
+	assert.Equal(t, types.StringValue("None"), metrics["cluster_ip"])
+
+	// a missing service is reported as a warning
+	_, diags = releaseServices(ctx, fake.NewSimpleClientset(), r)
+	assert.False(t, diags.HasError())
+	assert.Equal(t, 1, diags.WarningsCount())
+}
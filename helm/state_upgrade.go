@@ -0,0 +1,166 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// sdkBlockAttributes are the attributes of helm_release that were nested blocks in the SDKv2
+// provider. The SDKv2 stored an empty list when a block was not configured, which is null now.
+var sdkBlockAttributes = []string{"metadata", "postrender", "set", "set_list", "set_sensitive"}
+
+// UpgradeState converts the states written by the previous schema versions: version 0 and 1
+// of the SDKv2 provider (2.x), and version 1 of this provider before the postrender attribute
+// became a list.
+func (r *HelmRelease) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			StateUpgrader: r.upgradeState,
+		},
+		1: {
+			StateUpgrader: r.upgradeState,
+		},
+	}
+}
+
+// upgradeState converts the raw JSON prior state to the current schema. Blocks stored as lists of
+// one object become objects, single objects become lists where a list is expected, attributes
+// that no longer exist are removed and attributes added since get their default value, so that
+// the first plan after upgrading the provider shows no changes.
+func (r *HelmRelease) upgradeState(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	if req.RawState == nil || req.RawState.JSON == nil {
+		resp.Diagnostics.AddError("Unable to upgrade state", "The prior state of the helm_release resource could not be read")
+		return
+	}
+
+	var rawState map[string]interface{}
+	if err := json.Unmarshal(req.RawState.JSON, &rawState); err != nil {
+		resp.Diagnostics.AddError("Unable to upgrade state", fmt.Sprintf("Could not unmarshal prior state: %s", err))
+		return
+	}
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	stateType, ok := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	if !ok {
+		resp.Diagnostics.AddError("Unable to upgrade state", "The schema of the helm_release resource is not an object")
+		return
+	}
+
+	upgraded, err := json.Marshal(upgradeRawState(ctx, rawState, stateType))
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to upgrade state", fmt.Sprintf("Could not marshal upgraded state: %s", err))
+		return
+	}
+	resp.DynamicValue = &tfprotov6.DynamicValue{JSON: upgraded}
+}
+
+// isSDKState returns true if the raw state was written by the SDKv2 provider, which stored
+// metadata as a list of one object
+func isSDKState(rawState map[string]interface{}) bool {
+	_, ok := rawState["metadata"].([]interface{})
+	return ok
+}
+
+// upgradeRawState converts a raw prior state to the given type of the current schema
+func upgradeRawState(ctx context.Context, rawState map[string]interface{}, stateType tftypes.Object) map[string]interface{} {
+	if isSDKState(rawState) {
+		tflog.Debug(ctx, "Upgrading the state of the SDKv2 provider")
+		for _, k := range sdkBlockAttributes {
+			if l, ok := rawState[k].([]interface{}); ok && len(l) == 0 {
+				rawState[k] = nil
+			}
+		}
+	}
+
+	for k := range rawState {
+		if _, ok := stateType.AttributeTypes[k]; !ok {
+			tflog.Debug(ctx, fmt.Sprintf("Removing unknown attribute %q from prior state", k))
+			delete(rawState, k)
+		}
+	}
+	for k, v := range rawState {
+		rawState[k] = upgradeRawValue(ctx, v, stateType.AttributeTypes[k])
+	}
+
+	for k, v := range defaultAttributes {
+		if _, ok := rawState[k]; !ok {
+			rawState[k] = v
+		}
+	}
+	// The type of set is computed with a default, the SDKv2 omitted it when not configured
+	if set, ok := rawState["set"].([]interface{}); ok {
+		for _, e := range set {
+			if m, ok := e.(map[string]interface{}); ok && m["type"] == nil {
+				m["type"] = ""
+			}
+		}
+	}
+	return rawState
+}
+
+// upgradeRawValue converts a raw prior value to the given type of the current schema
+func upgradeRawValue(ctx context.Context, v interface{}, typ tftypes.Type) interface{} {
+	switch typ := typ.(type) {
+	case tftypes.Object:
+		if l, ok := v.([]interface{}); ok {
+			if len(l) == 0 {
+				return nil
+			}
+			v = l[0]
+		}
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		for k, e := range m {
+			attrType, ok := typ.AttributeTypes[k]
+			if !ok {
+				tflog.Debug(ctx, fmt.Sprintf("Removing unknown nested attribute %q from prior state", k))
+				delete(m, k)
+				continue
+			}
+			m[k] = upgradeRawValue(ctx, e, attrType)
+		}
+		return m
+	case tftypes.List:
+		return upgradeRawElements(ctx, v, typ.ElementType)
+	case tftypes.Set:
+		return upgradeRawElements(ctx, v, typ.ElementType)
+	case tftypes.Map:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		for k, e := range m {
+			m[k] = upgradeRawValue(ctx, e, typ.ElementType)
+		}
+		return m
+	}
+	return v
+}
+
+// upgradeRawElements converts the elements of a raw prior list or set, wrapping a single object
+// into a list
+func upgradeRawElements(ctx context.Context, v interface{}, elemType tftypes.Type) interface{} {
+	if m, ok := v.(map[string]interface{}); ok {
+		v = []interface{}{m}
+	}
+	l, ok := v.([]interface{})
+	if !ok {
+		return v
+	}
+	for i, e := range l {
+		l[i] = upgradeRawValue(ctx, e, elemType)
+	}
+	return l
+}
@@ -0,0 +1,115 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpgradeState(t *testing.T) {
+	ctx := context.Background()
+	r := &HelmRelease{}
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	stateType := schemaResp.Schema.Type().TerraformType(ctx)
+
+	upgrade := func(version int64, state string) map[string]tftypes.Value {
+		upgrader, ok := r.UpgradeState(ctx)[version]
+		require.True(t, ok)
+		resp := &resource.UpgradeStateResponse{}
+		upgrader.StateUpgrader(ctx, resource.UpgradeStateRequest{RawState: &tfprotov6.RawState{JSON: []byte(state)}}, resp)
+		require.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+
+		// the upgraded state must be readable with the current schema
+		value, err := resp.DynamicValue.Unmarshal(stateType)
+		require.NoError(t, err)
+		var attrs map[string]tftypes.Value
+		require.NoError(t, value.As(&attrs))
+		return attrs
+	}
+
+	t.Run("sdk", func(t *testing.T) {
+		attrs := upgrade(1, `{
+			"id": "app",
+			"name": "app",
+			"namespace": "default",
+			"chart": "nginx",
+			"timeout": 300,
+			"metadata": [{"name": "app", "namespace": "default", "revision": 2, "chart": "nginx", "version": "1.0.0", "app_version": "1.25", "values": "{}"}],
+			"set": [{"name": "replicas", "value": "2"}],
+			"set_sensitive": [],
+			"set_list": [{"name": "hosts", "value": ["a", "b"]}],
+			"postrender": [],
+			"removed_in_3x": true
+		}`)
+
+		var metadata map[string]tftypes.Value
+		require.NoError(t, attrs["metadata"].As(&metadata))
+		var version string
+		require.NoError(t, metadata["version"].As(&version))
+		assert.Equal(t, "1.0.0", version)
+
+		var set []tftypes.Value
+		require.NoError(t, attrs["set"].As(&set))
+		require.Len(t, set, 1)
+		var setAttrs map[string]tftypes.Value
+		require.NoError(t, set[0].As(&setAttrs))
+		var setType string
+		require.NoError(t, setAttrs["type"].As(&setType))
+		assert.Equal(t, "", setType)
+
+		var setList []tftypes.Value
+		require.NoError(t, attrs["set_list"].As(&setList))
+		assert.Len(t, setList, 1)
+
+		assert.True(t, attrs["set_sensitive"].IsNull())
+		assert.True(t, attrs["postrender"].IsNull())
+
+		// attributes added since the SDKv2 provider get their defaults
+		var wait bool
+		require.NoError(t, attrs["wait"].As(&wait))
+		assert.True(t, wait)
+	})
+
+	t.Run("sdk v0", func(t *testing.T) {
+		attrs := upgrade(0, `{
+			"name": "app",
+			"chart": "nginx",
+			"metadata": [],
+			"postrender": [{"binary_path": "kustomize", "args": ["build"]}]
+		}`)
+		assert.True(t, attrs["metadata"].IsNull())
+
+		var postrender []tftypes.Value
+		require.NoError(t, attrs["postrender"].As(&postrender))
+		assert.Len(t, postrender, 1)
+	})
+
+	t.Run("framework v1", func(t *testing.T) {
+		attrs := upgrade(1, `{
+			"name": "app",
+			"chart": "nginx",
+			"metadata": {"name": "app", "revision": 1},
+			"postrender": {"binary_path": "kustomize"},
+			"set": []
+		}`)
+
+		var postrender []tftypes.Value
+		require.NoError(t, attrs["postrender"].As(&postrender))
+		assert.Len(t, postrender, 1)
+
+		// empty lists are kept in the states of this provider, they were configured
+		var set []tftypes.Value
+		require.NoError(t, attrs["set"].As(&set))
+		assert.Empty(t, set)
+	})
+}
@@ -0,0 +1,101 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
+)
+
+// releaseStatusDetail is the JSON of the status_detail attribute of helm_release
+type releaseStatusDetail struct {
+	Status        string              `json:"status"`
+	Revision      int                 `json:"revision"`
+	Description   string              `json:"description"`
+	FirstDeployed string              `json:"first_deployed"`
+	LastDeployed  string              `json:"last_deployed"`
+	Superseded    *supersededRevision `json:"superseded"`
+}
+
+// supersededRevision describes the revision of a release preceding the current one
+type supersededRevision struct {
+	Revision     int    `json:"revision"`
+	Status       string `json:"status"`
+	Description  string `json:"description"`
+	LastDeployed string `json:"last_deployed"`
+}
+
+// statusDetail converts the status of r, and of the revision it superseded if any, to the
+// JSON of the status_detail attribute
+func statusDetail(r, superseded *release.Release) (types.String, error) {
+	detail := releaseStatusDetail{
+		Revision: r.Version,
+	}
+	if r.Info != nil {
+		detail.Status = r.Info.Status.String()
+		detail.Description = r.Info.Description
+		detail.FirstDeployed = formatReleaseTime(r.Info.FirstDeployed)
+		detail.LastDeployed = formatReleaseTime(r.Info.LastDeployed)
+	}
+	if superseded != nil {
+		detail.Superseded = &supersededRevision{Revision: superseded.Version}
+		if superseded.Info != nil {
+			detail.Superseded.Status = superseded.Info.Status.String()
+			detail.Superseded.Description = superseded.Info.Description
+			detail.Superseded.LastDeployed = formatReleaseTime(superseded.Info.LastDeployed)
+		}
+	}
+
+	b, err := json.Marshal(detail)
+	if err != nil {
+		return types.StringNull(), err
+	}
+	return types.StringValue(string(b)), nil
+}
+
+func formatReleaseTime(t helmtime.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// releaseHistory returns the revisions of the release of model, only r when r is the first
+// revision. The history is not read in mock mode.
+func (m *Meta) releaseHistory(ctx context.Context, model *HelmReleaseModel, r *release.Release) ([]*release.Release, error) {
+	if r.Version <= 1 || m.Mock {
+		return []*release.Release{r}, nil
+	}
+	cfg, err := m.GetHelmConfigurationAs(ctx, r.Namespace, model.KubeContext.ValueString(), model.DeployAsServiceAccount.ValueString(), model.HelmDriver.ValueString())
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Releases.History(r.Name)
+}
+
+// previousRevision returns the latest revision of history older than revision
+func previousRevision(history []*release.Release, revision int) *release.Release {
+	var previous *release.Release
+	for _, h := range history {
+		if h.Version < revision && (previous == nil || h.Version > previous.Version) {
+			previous = h
+		}
+	}
+	return previous
+}
+
+// setStatusDetail sets the status_detail attribute of state from r and the history of its release
+func setStatusDetail(state *HelmReleaseModel, r *release.Release, history []*release.Release) error {
+	detail, err := statusDetail(r, previousRevision(history, r.Version))
+	if err != nil {
+		return err
+	}
+	state.StatusDetail = detail
+	return nil
+}
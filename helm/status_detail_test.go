@@ -0,0 +1,67 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
+)
+
+func TestStatusDetail(t *testing.T) {
+	deployed := func(day int) helmtime.Time {
+		return helmtime.Time{Time: time.Date(2024, 1, day, 3, 4, 5, 0, time.UTC)}
+	}
+	r := func(revision int, status release.Status, description string) *release.Release {
+		return &release.Release{
+			Name:    "test",
+			Version: revision,
+			Info: &release.Info{
+				FirstDeployed: deployed(1),
+				LastDeployed:  deployed(revision),
+				Status:        status,
+				Description:   description,
+			},
+		}
+	}
+
+	detail, err := statusDetail(r(1, release.StatusDeployed, "Install complete"), nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"status": "deployed",
+		"revision": 1,
+		"description": "Install complete",
+		"first_deployed": "2024-01-01T03:04:05Z",
+		"last_deployed": "2024-01-01T03:04:05Z",
+		"superseded": null
+	}`, detail.ValueString())
+
+	history := []*release.Release{
+		r(1, release.StatusSuperseded, "Install complete"),
+		r(3, release.StatusDeployed, "Upgrade complete"),
+		r(2, release.StatusFailed, "Upgrade failed"),
+	}
+	current := history[1]
+	detail, err = statusDetail(current, previousRevision(history, current.Version))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"status": "deployed",
+		"revision": 3,
+		"description": "Upgrade complete",
+		"first_deployed": "2024-01-01T03:04:05Z",
+		"last_deployed": "2024-01-03T03:04:05Z",
+		"superseded": {
+			"revision": 2,
+			"status": "failed",
+			"description": "Upgrade failed",
+			"last_deployed": "2024-01-02T03:04:05Z"
+		}
+	}`, detail.ValueString())
+
+	assert.Nil(t, previousRevision(history, 1))
+}
@@ -0,0 +1,277 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/action"
+	rspb "helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// helmDrivers are the values of the helm_driver attributes of the provider and of helm_release
+var helmDrivers = []string{
+	strings.ToLower(driver.MemoryDriverName),
+	strings.ToLower(driver.ConfigMapsDriverName),
+	strings.ToLower(driver.SecretsDriverName),
+	strings.ToLower(driver.SQLDriverName),
+}
+
+// sqlSSLModes are the values of the ssl_mode attribute of helm_driver_sql, see
+// https://www.postgresql.org/docs/current/libpq-ssl.html
+var sqlSSLModes = []string{"disable", "require", "verify-ca", "verify-full"}
+
+// SQLConfigModel configures the connection to the database of the sql storage driver
+type SQLConfigModel struct {
+	Host        types.String `tfsdk:"host"`
+	Port        types.Int64  `tfsdk:"port"`
+	Database    types.String `tfsdk:"database"`
+	Username    types.String `tfsdk:"username"`
+	Password    types.String `tfsdk:"password"`
+	SSLMode     types.String `tfsdk:"ssl_mode"`
+	SSLRootCert types.String `tfsdk:"ssl_root_cert"`
+	SSLCert     types.String `tfsdk:"ssl_cert"`
+	SSLKey      types.String `tfsdk:"ssl_key"`
+	MaxConns    types.Int64  `tfsdk:"max_conns"`
+}
+
+func sqlSchema() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"host": schema.StringAttribute{
+			Required:    true,
+			Description: "Host of the PostgreSQL server.",
+		},
+		"port": schema.Int64Attribute{
+			Optional:    true,
+			Description: "Port of the PostgreSQL server. Defaults to 5432.",
+			Validators: []validator.Int64{
+				int64validator.Between(1, 65535),
+			},
+		},
+		"database": schema.StringAttribute{
+			Required:    true,
+			Description: "Name of the database the releases are stored in.",
+		},
+		"username": schema.StringAttribute{
+			Optional:    true,
+			Description: "Username to connect as.",
+		},
+		"password": schema.StringAttribute{
+			Optional:    true,
+			Sensitive:   true,
+			Description: "Password of the user.",
+		},
+		"ssl_mode": schema.StringAttribute{
+			Optional:    true,
+			Description: "SSL mode of the connection, one of disable, require, verify-ca or verify-full. Defaults to require.",
+			Validators: []validator.String{
+				stringvalidator.OneOf(sqlSSLModes...),
+			},
+		},
+		"ssl_root_cert": schema.StringAttribute{
+			Optional:    true,
+			Description: "Path to the PEM encoded certificate authorities the server certificate is verified against.",
+		},
+		"ssl_cert": schema.StringAttribute{
+			Optional:    true,
+			Description: "Path to the PEM encoded client certificate.",
+			Validators: []validator.String{
+				stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("ssl_key")),
+			},
+		},
+		"ssl_key": schema.StringAttribute{
+			Optional:    true,
+			Description: "Path to the PEM encoded private key of the client certificate.",
+			Validators: []validator.String{
+				stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("ssl_cert")),
+			},
+		},
+		"max_conns": schema.Int64Attribute{
+			Optional:    true,
+			Description: "Maximum number of concurrent connections to the database, shared by all the releases of the provider. Unlimited by default.",
+			Validators: []validator.Int64{
+				int64validator.AtLeast(1),
+			},
+		},
+	}
+}
+
+// connectionString builds the PostgreSQL connection URL of the configuration
+func (c *SQLConfigModel) connectionString() string {
+	host := c.Host.ValueString()
+	if !c.Port.IsNull() {
+		host = net.JoinHostPort(host, strconv.FormatInt(c.Port.ValueInt64(), 10))
+	}
+	u := url.URL{
+		Scheme: "postgres",
+		Host:   host,
+		Path:   "/" + c.Database.ValueString(),
+	}
+	if username := c.Username.ValueString(); username != "" {
+		u.User = url.User(username)
+		if !c.Password.IsNull() {
+			u.User = url.UserPassword(username, c.Password.ValueString())
+		}
+	}
+	q := url.Values{}
+	for key, value := range map[string]types.String{
+		"sslmode":     c.SSLMode,
+		"sslrootcert": c.SSLRootCert,
+		"sslcert":     c.SSLCert,
+		"sslkey":      c.SSLKey,
+	} {
+		if value.ValueString() != "" {
+			q.Set(key, value.ValueString())
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// sqlStorage shares the connections to the database of the sql storage driver between the
+// resources of the provider. Helm opens a connection pool for every driver, so a driver is
+// opened once per namespace, and the operations of all the drivers are bounded by maxConns.
+type sqlStorage struct {
+	connectionString string
+	// nil when the number of connections is not bounded
+	conns chan struct{}
+
+	mu      sync.Mutex
+	drivers map[string]*driver.SQL
+}
+
+func newSQLStorage(connectionString string, maxConns int64) *sqlStorage {
+	s := &sqlStorage{
+		connectionString: connectionString,
+		drivers:          map[string]*driver.SQL{},
+	}
+	if maxConns > 0 {
+		s.conns = make(chan struct{}, maxConns)
+	}
+	return s
+}
+
+// driver returns the driver of namespace, connecting to the database on first use
+func (s *sqlStorage) driver(namespace string, log action.DebugLog) (driver.Driver, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.drivers[namespace]
+	if !ok {
+		var err error
+		d, err = driver.NewSQL(s.connectionString, log, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("unable to connect to the SQL release storage: %w", err)
+		}
+		s.drivers[namespace] = d
+	}
+	if s.conns == nil {
+		return d, nil
+	}
+	return &boundedDriver{Driver: d, conns: s.conns}, nil
+}
+
+// boundedDriver holds one of conns for the duration of every operation of Driver
+type boundedDriver struct {
+	driver.Driver
+	conns chan struct{}
+}
+
+func (d *boundedDriver) acquire() func() {
+	d.conns <- struct{}{}
+	return func() { <-d.conns }
+}
+
+func (d *boundedDriver) Create(key string, rls *rspb.Release) error {
+	defer d.acquire()()
+	return d.Driver.Create(key, rls)
+}
+
+func (d *boundedDriver) Update(key string, rls *rspb.Release) error {
+	defer d.acquire()()
+	return d.Driver.Update(key, rls)
+}
+
+func (d *boundedDriver) Delete(key string) (*rspb.Release, error) {
+	defer d.acquire()()
+	return d.Driver.Delete(key)
+}
+
+func (d *boundedDriver) Get(key string) (*rspb.Release, error) {
+	defer d.acquire()()
+	return d.Driver.Get(key)
+}
+
+func (d *boundedDriver) List(filter func(*rspb.Release) bool) ([]*rspb.Release, error) {
+	defer d.acquire()()
+	return d.Driver.List(filter)
+}
+
+func (d *boundedDriver) Query(labels map[string]string) ([]*rspb.Release, error) {
+	defer d.acquire()()
+	return d.Driver.Query(labels)
+}
+
+// storageObjectName returns the name of the Secret or ConfigMap the revision r is stored in by
+// the storage driver helmDriver, null for the drivers not storing releases in Kubernetes objects
+func storageObjectName(helmDriver string, r *rspb.Release) types.String {
+	switch helmDriver {
+	case "", "secret", "secrets", "configmap", "configmaps":
+		return types.StringValue(fmt.Sprintf("sh.helm.release.v1.%s.v%d", r.Name, r.Version))
+	}
+	return types.StringNull()
+}
+
+// validHelmDriver reports whether Helm supports the storage driver name, which also accepts
+// the plural names of the configmap and secret drivers
+func validHelmDriver(name string) bool {
+	switch name {
+	case "", "secrets", "configmaps":
+		return true
+	}
+	for _, d := range helmDrivers {
+		if name == d {
+			return true
+		}
+	}
+	return false
+}
+
+// initActionConfig initializes actionConfig with the storage driver helmDriver. Helm reads the
+// connection string of the sql driver from HELM_DRIVER_SQL_CONNECTION_STRING and panics when it
+// cannot connect, so the SQL storage is set up here with sqlStorage instead.
+func initActionConfig(actionConfig *action.Configuration, kc *KubeConfig, namespace, helmDriver string, sqlStorage *sqlStorage, log action.DebugLog) error {
+	if !validHelmDriver(helmDriver) {
+		return fmt.Errorf("unknown Helm driver %q, expected one of %s", helmDriver, strings.Join(helmDrivers, ", "))
+	}
+	if helmDriver != strings.ToLower(driver.SQLDriverName) {
+		return actionConfig.Init(kc, namespace, helmDriver, log)
+	}
+
+	if err := actionConfig.Init(kc, namespace, strings.ToLower(driver.MemoryDriverName), log); err != nil {
+		return err
+	}
+	if sqlStorage == nil {
+		return fmt.Errorf("the sql Helm driver requires helm_driver_sql, helm_driver_sql_connection_string or the HELM_DRIVER_SQL_CONNECTION_STRING environment variable")
+	}
+	d, err := sqlStorage.driver(namespace, log)
+	if err != nil {
+		return err
+	}
+	actionConfig.Releases = storage.Init(d)
+	return nil
+}
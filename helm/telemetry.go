@@ -0,0 +1,176 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+)
+
+const tracerName = "github.com/hashicorp/terraform-provider-helm"
+
+// TelemetryConfigModel configures the export of traces of Helm operations
+type TelemetryConfigModel struct {
+	Endpoint types.String `tfsdk:"endpoint"`
+	Headers  types.Map    `tfsdk:"headers"`
+}
+
+func telemetrySchema() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"endpoint": schema.StringAttribute{
+			Optional:    true,
+			Description: "URL of the OTLP/HTTP endpoint traces are sent to, e.g. http://localhost:4318. Defaults to the OTEL_EXPORTER_OTLP_ENDPOINT environment variable.",
+		},
+		"headers": schema.MapAttribute{
+			Optional:    true,
+			Sensitive:   true,
+			ElementType: types.StringType,
+			Description: "Headers sent with every export request, e.g. for authentication.",
+		},
+	}
+}
+
+// newTracerProvider creates the tracer provider exporting spans to the configured OTLP endpoint
+func newTracerProvider(ctx context.Context, config *TelemetryConfigModel, version string) (*sdktrace.TracerProvider, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	opts := []otlptracehttp.Option{}
+	if endpoint := config.Endpoint.ValueString(); endpoint != "" {
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Host == "" {
+			diags.AddError("Invalid telemetry endpoint", fmt.Sprintf("Invalid telemetry endpoint %q, expected a URL such as http://localhost:4318", endpoint))
+			return nil, diags
+		}
+		opts = append(opts, otlptracehttp.WithEndpoint(u.Host))
+		if u.Scheme == "http" {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if p := strings.TrimSuffix(u.Path, "/"); p != "" {
+			opts = append(opts, otlptracehttp.WithURLPath(p))
+		}
+	}
+	if !config.Headers.IsNull() && !config.Headers.IsUnknown() {
+		headers := map[string]string{}
+		diags.Append(config.Headers.ElementsAs(ctx, &headers, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		opts = append(opts, otlptracehttp.WithHeaders(headers))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		diags.AddError("Telemetry initialization failed", fmt.Sprintf("Unable to create the OTLP exporter: %s", err))
+		return nil, diags
+	}
+
+	res := sdkresource.NewSchemaless(
+		attribute.String("service.name", "terraform-provider-helm"),
+		attribute.String("service.version", version),
+	)
+	return sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res)), diags
+}
+
+// releaseSpanAttributes are the attributes of the spans of a release
+func releaseSpanAttributes(namespace, name string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("helm.release.namespace", namespace),
+		attribute.String("helm.release.name", name),
+	}
+}
+
+// startSpan starts a span of a Helm operation. Spans are only exported when the telemetry
+// block of the provider is set.
+func (m *Meta) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	var tracer trace.Tracer = noop.NewTracerProvider().Tracer(tracerName)
+	if m != nil && m.TracerProvider != nil {
+		tracer = m.TracerProvider.Tracer(tracerName)
+	}
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends a span, recording err
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// endOperationSpan ends the span of a resource or data source operation and exports the
+// spans of the operation, as Terraform may stop the provider once the operation returned
+func (m *Meta) endOperationSpan(ctx context.Context, span trace.Span, diags diag.Diagnostics) {
+	if diags.HasError() {
+		for _, d := range diags.Errors() {
+			span.RecordError(fmt.Errorf("%s: %s", d.Summary(), d.Detail()))
+		}
+		span.SetStatus(codes.Error, diags.Errors()[0].Summary())
+	}
+	span.End()
+
+	if m == nil || m.TracerProvider == nil {
+		return
+	}
+	if err := m.TracerProvider.ForceFlush(ctx); err != nil {
+		tflog.Warn(ctx, fmt.Sprintf("Unable to export traces: %s", err))
+	}
+}
+
+// tracingKubeClient records the waits of Helm actions as spans
+type tracingKubeClient struct {
+	*kube.Client
+	ctx  context.Context
+	meta *Meta
+}
+
+func (c *tracingKubeClient) wait(name string, resources kube.ResourceList, timeout time.Duration, fn func(kube.ResourceList, time.Duration) error) error {
+	_, span := c.meta.startSpan(c.ctx, name,
+		attribute.Int("helm.wait.resources", len(resources)),
+		attribute.String("helm.wait.timeout", timeout.String()),
+	)
+	err := fn(resources, timeout)
+	endSpan(span, err)
+	return err
+}
+
+func (c *tracingKubeClient) Wait(resources kube.ResourceList, timeout time.Duration) error {
+	return c.wait("helm.wait", resources, timeout, c.Client.Wait)
+}
+
+func (c *tracingKubeClient) WaitWithJobs(resources kube.ResourceList, timeout time.Duration) error {
+	return c.wait("helm.wait", resources, timeout, c.Client.WaitWithJobs)
+}
+
+func (c *tracingKubeClient) WaitForDelete(resources kube.ResourceList, timeout time.Duration) error {
+	return c.wait("helm.wait_for_delete", resources, timeout, c.Client.WaitForDelete)
+}
+
+// traceKubeClient wraps the Kubernetes client of actionConfig so that the waits of the
+// actions run with it are recorded as children of the span of ctx
+func (m *Meta) traceKubeClient(ctx context.Context, actionConfig *action.Configuration) {
+	if m == nil || m.TracerProvider == nil {
+		return
+	}
+	if c, ok := actionConfig.KubeClient.(*kube.Client); ok {
+		actionConfig.KubeClient = &tracingKubeClient{Client: c, ctx: ctx, meta: m}
+	}
+}
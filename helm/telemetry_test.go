@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+func TestTelemetrySpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	m := &Meta{
+		Settings:       cli.New(),
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)),
+	}
+
+	ctx, span := m.startSpan(context.Background(), "helm_release.create", releaseSpanAttributes("default", "test")...)
+	_, err := m.locateChart(ctx, &action.ChartPathOptions{}, "testdata/charts/test-chart", refreshRepositoryAuto)
+	assert.NoError(t, err)
+
+	var diags diag.Diagnostics
+	diags.AddError("installation failed", "timed out waiting for the condition")
+	m.endOperationSpan(ctx, span, diags)
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, "helm.chart_download", spans[0].Name)
+		assert.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
+		assert.Equal(t, "helm_release.create", spans[1].Name)
+		assert.Equal(t, codes.Error, spans[1].Status.Code)
+		assert.Equal(t, "installation failed", spans[1].Status.Description)
+	}
+}
+
+func TestTelemetryDisabled(t *testing.T) {
+	m := &Meta{}
+	_, span := m.startSpan(context.Background(), "helm_release.read")
+	assert.False(t, span.SpanContext().IsValid())
+	m.endOperationSpan(context.Background(), span, nil)
+}
+
+func TestNewTracerProviderInvalidEndpoint(t *testing.T) {
+	_, diags := newTracerProvider(context.Background(), &TelemetryConfigModel{
+		Endpoint: types.StringValue("localhost:4318"),
+		Headers:  types.MapNull(types.StringType),
+	}, "test")
+	assert.True(t, diags.HasError())
+}
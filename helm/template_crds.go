@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"sigs.k8s.io/yaml"
+)
+
+// crdMeta holds the fields identifying the kind a CRD defines
+type crdMeta struct {
+	Spec struct {
+		Group string `json:"group"`
+		Names struct {
+			Kind string `json:"kind"`
+		} `json:"names"`
+	} `json:"spec"`
+}
+
+// templateCRDs returns the CRD files of the crds/ directories of the chart and of its
+// dependencies, recursively. With deduplicate, the CRDs of the files are returned one by one,
+// keeping the first CRD of every group and kind, as umbrella charts often pull the same CRDs
+// from several subcharts.
+func templateCRDs(c *chart.Chart, deduplicate bool) ([]string, error) {
+	var crds []string
+	seen := map[string]bool{}
+	for _, obj := range c.CRDObjects() {
+		if !deduplicate {
+			crds = append(crds, string(obj.File.Data))
+			continue
+		}
+
+		documents := releaseutil.SplitManifests(string(obj.File.Data))
+		keys := make([]string, 0, len(documents))
+		for k := range documents {
+			keys = append(keys, k)
+		}
+		sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+		for _, k := range keys {
+			document := documents[k]
+			if strings.TrimSpace(document) == "" {
+				continue
+			}
+			var m crdMeta
+			if err := yaml.Unmarshal([]byte(document), &m); err != nil {
+				return nil, fmt.Errorf("failed to parse CRD of %s: %w", obj.Filename, err)
+			}
+			if m.Spec.Group != "" && m.Spec.Names.Kind != "" {
+				key := m.Spec.Group + "/" + m.Spec.Names.Kind
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+			}
+			crds = append(crds, document)
+		}
+	}
+	return crds, nil
+}
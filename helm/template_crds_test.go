@@ -0,0 +1,51 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func testCRD(kind string) string {
+	return fmt.Sprintf("apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nspec:\n  group: example.com\n  names:\n    kind: %s\n", kind)
+}
+
+func TestTemplateCRDs(t *testing.T) {
+	nested := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "nested"},
+		Files:    []*chart.File{{Name: "crds/pears.yaml", Data: []byte(testCRD("Pear"))}},
+	}
+	sub := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "sub"},
+		Files:    []*chart.File{{Name: "crds/apples.yaml", Data: []byte(testCRD("Apple"))}},
+	}
+	sub.AddDependency(nested)
+	umbrella := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "umbrella"},
+		Files: []*chart.File{
+			{Name: "crds/fruits.yaml", Data: []byte(testCRD("Apple") + "---\n" + testCRD("Orange"))},
+			{Name: "crds/README.md", Data: []byte("not a CRD")},
+		},
+	}
+	umbrella.AddDependency(sub)
+
+	crds, err := templateCRDs(umbrella, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		testCRD("Apple") + "---\n" + testCRD("Orange"),
+		testCRD("Apple"),
+		testCRD("Pear"),
+	}, crds)
+
+	crds, err = templateCRDs(umbrella, true)
+	assert.NoError(t, err)
+	assert.Len(t, crds, 3)
+	assert.Contains(t, crds[0], "kind: Apple")
+	assert.Contains(t, crds[1], "kind: Orange")
+	assert.Contains(t, crds[2], "kind: Pear")
+}
@@ -0,0 +1,30 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// setTemplateRevision sets the .Release.Revision the templates of the chart c and of its
+// dependencies are rendered with. Helm renders installs with the revision 1, each template sets
+// the revision before it is executed. The action writes nothing, so that the output and the line
+// numbers of the errors of the templates do not change.
+func setTemplateRevision(c *chart.Chart, revision int64) {
+	action := []byte(fmt.Sprintf(`{{- $_ := set .Release "Revision" %d }}`, revision))
+	for _, t := range c.Templates {
+		// partials are only executed by the templates including them
+		if strings.HasPrefix(path.Base(t.Name), "_") {
+			continue
+		}
+		t.Data = append(append([]byte{}, action...), t.Data...)
+	}
+	for _, dep := range c.Dependencies() {
+		setTemplateRevision(dep, revision)
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestSetTemplateRevision(t *testing.T) {
+	release := []byte("revision: {{ .Release.Revision }}\nupgrade: {{ .Release.IsUpgrade }}\n{{ include \"sub.name\" . }}\n")
+	sub := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: "v2", Name: "sub", Version: "0.1.0"},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{ define "sub.name" }}sub: {{ .Release.Revision }}{{ end }}`)},
+			{Name: "templates/sub.yaml", Data: release},
+		},
+	}
+	c := &chart.Chart{
+		Metadata:  &chart.Metadata{APIVersion: "v2", Name: "app", Version: "1.0.0"},
+		Templates: []*chart.File{{Name: "templates/_helpers.tpl", Data: sub.Templates[0].Data}, {Name: "templates/app.yaml", Data: release}},
+	}
+	c.AddDependency(sub)
+
+	setTemplateRevision(c, 7)
+	assert.Equal(t, sub.Templates[0].Data, c.Templates[0].Data, "partials are not changed")
+
+	client := action.NewInstall(&action.Configuration{})
+	client.ReleaseName = "app"
+	client.Namespace = "default"
+	client.DryRun = true
+	client.ClientOnly = true
+	client.IsUpgrade = true
+	rel, err := client.Run(c, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Contains(t, rel.Manifest, "# Source: app/templates/app.yaml\nrevision: 7\nupgrade: true\nsub: 7\n")
+	assert.Contains(t, rel.Manifest, "# Source: app/charts/sub/templates/sub.yaml\nrevision: 7\nupgrade: true\nsub: 7\n")
+}
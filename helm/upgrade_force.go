@@ -0,0 +1,137 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// Values of the upgrade_force_strategy attribute of helm_release
+const (
+	upgradeForceNone                = "none"
+	upgradeForceDeleteRecreate      = "force-delete-recreate"
+	upgradeForceServerSideConflicts = "ssa-force-conflicts"
+)
+
+var upgradeForceStrategies = []string{upgradeForceNone, upgradeForceDeleteRecreate, upgradeForceServerSideConflicts}
+
+// upgradeForceFieldManager is the field manager of the objects applied server-side with
+// ssa-force-conflicts
+const upgradeForceFieldManager = "terraform-provider-helm"
+
+// forceStrategyKubeClient resolves the objects an upgrade fails to update according to the
+// upgrade_force_strategy of the release:
+//   - force-delete-recreate deletes and creates again the objects whose update changes
+//     immutable fields, e.g. the selector of a Deployment
+//   - ssa-force-conflicts applies the objects failing with a conflict server-side, forcing the
+//     conflicts so that the fields managed by other field managers are taken over
+type forceStrategyKubeClient struct {
+	forwardingKubeClient
+	ctx      context.Context
+	strategy string
+	timeout  time.Duration
+}
+
+// forceUpgradeStrategy wraps the Kubernetes client of actionConfig to resolve the update
+// failures of an upgrade with strategy
+func forceUpgradeStrategy(ctx context.Context, actionConfig *action.Configuration, strategy string, timeout time.Duration) {
+	if strategy == "" || strategy == upgradeForceNone {
+		return
+	}
+	actionConfig.KubeClient = &forceStrategyKubeClient{
+		forwardingKubeClient: forwardingKubeClient{Interface: actionConfig.KubeClient},
+		ctx:                  ctx,
+		strategy:             strategy,
+		timeout:              timeout,
+	}
+}
+
+// resolves reports whether the strategy of the client resolves the update error err
+func (c *forceStrategyKubeClient) resolves(err error) bool {
+	msg := strings.ToLower(err.Error())
+	switch c.strategy {
+	case upgradeForceDeleteRecreate:
+		return strings.Contains(msg, "field is immutable") || strings.Contains(msg, "immutable field")
+	case upgradeForceServerSideConflicts:
+		return strings.Contains(msg, "conflict") || strings.Contains(msg, "the object has been modified")
+	}
+	return false
+}
+
+func (c *forceStrategyKubeClient) Update(original, target kube.ResourceList, force bool) (*kube.Result, error) {
+	res, err := c.Interface.Update(original, target, force)
+	if err == nil || !c.resolves(err) {
+		return res, err
+	}
+	tflog.Debug(c.ctx, fmt.Sprintf("Resolving update failure with %s: %s", c.strategy, err))
+
+	// the failing objects are updated one by one to find the ones to resolve
+	for _, info := range target {
+		var originalInfo kube.ResourceList
+		if o := original.Get(info); o != nil {
+			originalInfo = kube.ResourceList{o}
+		}
+		_, err := c.Interface.Update(originalInfo, kube.ResourceList{info}, force)
+		if err == nil {
+			continue
+		}
+		if !c.resolves(err) {
+			return res, err
+		}
+		if err := c.resolve(info); err != nil {
+			return res, fmt.Errorf("unable to resolve the update of %s %s with %s: %w", info.Mapping.GroupVersionKind.Kind, info.ObjectName(), c.strategy, err)
+		}
+	}
+
+	// the objects are up to date, update again to delete the objects removed from the release
+	return c.Interface.Update(original, target, force)
+}
+
+func (c *forceStrategyKubeClient) resolve(info *resource.Info) error {
+	kind := info.Mapping.GroupVersionKind.Kind
+	switch c.strategy {
+	case upgradeForceDeleteRecreate:
+		tflog.Warn(c.ctx, fmt.Sprintf("Deleting and recreating %s %s to change immutable fields", kind, info.ObjectName()))
+		resources := kube.ResourceList{info}
+		if _, errs := c.Interface.Delete(resources); len(errs) > 0 {
+			return errs[0]
+		}
+		if err := c.WaitForDelete(resources, c.timeout); err != nil {
+			return err
+		}
+		_, err := c.Interface.Create(resources)
+		return err
+	case upgradeForceServerSideConflicts:
+		tflog.Warn(c.ctx, fmt.Sprintf("Applying %s %s server-side, forcing conflicts", kind, info.ObjectName()))
+		return applyServerSide(info)
+	}
+	return nil
+}
+
+// applyServerSide applies the object of info server-side with the field manager of the provider,
+// forcing the conflicts with other field managers
+func applyServerSide(info *resource.Info) error {
+	data, err := json.Marshal(info.Object)
+	if err != nil {
+		return err
+	}
+	force := true
+	helper := resource.NewHelper(info.Client, info.Mapping).WithFieldManager(upgradeForceFieldManager)
+	obj, err := helper.Patch(info.Namespace, info.Name, k8stypes.ApplyPatchType, data, &metav1.PatchOptions{Force: &force})
+	if err != nil {
+		return err
+	}
+	return info.Refresh(obj, true)
+}
@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// immutableKubeClient fails to update the objects with immutable fields until they are recreated
+type immutableKubeClient struct {
+	kubefake.PrintingKubeClient
+	immutable map[string]bool
+	deleted   []string
+	created   []string
+}
+
+func (c *immutableKubeClient) Update(original, target kube.ResourceList, force bool) (*kube.Result, error) {
+	for _, info := range target {
+		if c.immutable[info.Name] {
+			return nil, errors.New(`Deployment.apps "` + info.Name + `" is invalid: spec.selector: Invalid value: field is immutable`)
+		}
+	}
+	return &kube.Result{Updated: target}, nil
+}
+
+func (c *immutableKubeClient) Delete(resources kube.ResourceList) (*kube.Result, []error) {
+	for _, info := range resources {
+		c.deleted = append(c.deleted, info.Name)
+	}
+	return &kube.Result{Deleted: resources}, nil
+}
+
+func (c *immutableKubeClient) Create(resources kube.ResourceList) (*kube.Result, error) {
+	for _, info := range resources {
+		c.created = append(c.created, info.Name)
+		delete(c.immutable, info.Name)
+	}
+	return &kube.Result{Created: resources}, nil
+}
+
+func TestForceUpgradeStrategy(t *testing.T) {
+	mapping := &meta.RESTMapping{GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}}
+	target := kube.ResourceList{
+		{Name: "web", Namespace: "default", Mapping: mapping},
+		{Name: "api", Namespace: "default", Mapping: mapping},
+	}
+	ctx := context.Background()
+
+	t.Run("none", func(t *testing.T) {
+		inner := &immutableKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}, immutable: map[string]bool{"web": true}}
+		actionConfig := &action.Configuration{KubeClient: inner}
+		forceUpgradeStrategy(ctx, actionConfig, upgradeForceNone, time.Second)
+		assert.Same(t, inner, actionConfig.KubeClient)
+		_, err := actionConfig.KubeClient.Update(target, target, false)
+		assert.ErrorContains(t, err, "field is immutable")
+	})
+
+	t.Run("force-delete-recreate", func(t *testing.T) {
+		inner := &immutableKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}, immutable: map[string]bool{"web": true}}
+		actionConfig := &action.Configuration{KubeClient: inner}
+		forceUpgradeStrategy(ctx, actionConfig, upgradeForceDeleteRecreate, time.Second)
+		_, err := actionConfig.KubeClient.Update(target, target, false)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"web"}, inner.deleted)
+		assert.Equal(t, []string{"web"}, inner.created)
+	})
+
+	t.Run("ssa-force-conflicts ignores immutable fields", func(t *testing.T) {
+		inner := &immutableKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}, immutable: map[string]bool{"web": true}}
+		actionConfig := &action.Configuration{KubeClient: inner}
+		forceUpgradeStrategy(ctx, actionConfig, upgradeForceServerSideConflicts, time.Second)
+		_, err := actionConfig.KubeClient.Update(target, target, false)
+		assert.ErrorContains(t, err, "field is immutable")
+		assert.Empty(t, inner.deleted)
+	})
+}
@@ -0,0 +1,405 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/strvals"
+	"sigs.k8s.io/yaml"
+)
+
+const sensitiveContentValue = "(sensitive value)"
+
+// sensitiveValue returns the value stored in place of a sensitive value. With a hash key, it is an
+// HMAC of the value, so that changes to the value are detectable without storing it.
+func sensitiveValue(value interface{}, hashKey string) string {
+	if hashKey == "" {
+		return sensitiveContentValue
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return sensitiveContentValue
+	}
+	mac := hmac.New(sha256.New, []byte(hashKey))
+	mac.Write(b)
+	return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// setValueTypes are the supported values of the type attribute of set and set_sensitive
+var setValueTypes = []string{"auto", "string", "literal"}
+
+// Values of the duplicate_set_policy attribute of helm_release
+const (
+	duplicateSetPolicyLast  = "last"
+	duplicateSetPolicyError = "error"
+)
+
+var duplicateSetPolicies = []string{duplicateSetPolicyLast, duplicateSetPolicyError}
+
+// valuesCollection is a list or a set attribute holding values to merge
+type valuesCollection interface {
+	IsNull() bool
+	IsUnknown() bool
+	ElementsAs(ctx context.Context, target interface{}, allowUnhandled bool) diag.Diagnostics
+}
+
+// valuesInput holds the attributes that make up the values of a release. helm_release and
+// helm_template both build their values with mergeValues, so that they are merged identically.
+type valuesInput struct {
+	// Values are raw YAML documents
+	Values types.List
+	// Documents are merged after Values, e.g. the documents read by values_from
+	Documents    []map[string]interface{}
+	Set          valuesCollection
+	SetList      valuesCollection
+	SetSensitive valuesCollection
+	// MergeStrategy is how the documents are merged, see valuesMergeStrategies
+	MergeStrategy string
+	// DuplicateSetPolicy is how names set more than once by set, set_list or set_sensitive are
+	// handled, see duplicateSetPolicies. The last entry wins by default.
+	DuplicateSetPolicy string
+}
+
+// mergeValues merges the values in the same order as the Helm CLI: values documents,
+// then set, set_list and set_sensitive. The entries of set and set_sensitive are merged in
+// the order of the list, or sorted when they are sets, see sortSetEntries.
+func mergeValues(ctx context.Context, in valuesInput) (map[string]interface{}, diag.Diagnostics) {
+	base := map[string]interface{}{}
+	var diags diag.Diagnostics
+	merge := mergeMaps
+	if in.MergeStrategy == valuesMergeStrategic {
+		merge = strategicMergeMaps
+	}
+
+	// Processing "values" attribute
+	for _, raw := range in.Values.Elements() {
+		if raw.IsNull() {
+			continue
+		}
+
+		value, ok := raw.(types.String)
+		if !ok {
+			diags.AddError("Type Error", fmt.Sprintf("Expected types.String, got %T", raw))
+			return nil, diags
+		}
+
+		currentMap, err := parseValuesDocument(value.ValueString())
+		if err != nil {
+			diags.AddError("Error unmarshaling values", fmt.Sprintf("---> %v %s", err, value.ValueString()))
+			return nil, diags
+		}
+		base = merge(base, currentMap)
+	}
+
+	for _, d := range in.Documents {
+		base = merge(base, d)
+	}
+
+	// Processing "set" attribute
+	if isKnown(in.Set) {
+		tflog.Debug(ctx, "Processing Set attribute")
+		var setList []setResourceModel
+		diags.Append(in.Set.ElementsAs(ctx, &setList, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		sortSetEntries(in.Set, setList)
+		diags.Append(checkDuplicateSetNames("set", setEntryNames(setList), in.DuplicateSetPolicy)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+
+		for i, set := range setList {
+			tflog.Debug(ctx, fmt.Sprintf("Processing Set element at index %d: %v", i, set))
+			diags.Append(applySetValue(base, set)...)
+			if diags.HasError() {
+				return nil, diags
+			}
+		}
+	}
+
+	// Processing "set_list" attribute
+	if isKnown(in.SetList) {
+		tflog.Debug(ctx, "Processing Set_list attribute")
+		var setListSlice []set_listResourceModel
+		diags.Append(in.SetList.ElementsAs(ctx, &setListSlice, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		names := make([]string, 0, len(setListSlice))
+		for _, setList := range setListSlice {
+			names = append(names, setList.Name.ValueString())
+		}
+		diags.Append(checkDuplicateSetNames("set_list", names, in.DuplicateSetPolicy)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+
+		for i, setList := range setListSlice {
+			tflog.Debug(ctx, fmt.Sprintf("Processing Set_list element at index %d: %v", i, setList))
+			diags.Append(applySetListValue(base, setList)...)
+			if diags.HasError() {
+				return nil, diags
+			}
+		}
+	}
+
+	// Processing "set_sensitive" attribute
+	if isKnown(in.SetSensitive) {
+		tflog.Debug(ctx, "Processing Set_Sensitive attribute")
+		var setSensitiveList []setResourceModel
+		diags.Append(in.SetSensitive.ElementsAs(ctx, &setSensitiveList, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		sortSetEntries(in.SetSensitive, setSensitiveList)
+		diags.Append(checkDuplicateSetNames("set_sensitive", setEntryNames(setSensitiveList), in.DuplicateSetPolicy)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+
+		for i, setSensitive := range setSensitiveList {
+			// the value is not logged
+			tflog.Debug(ctx, fmt.Sprintf("Processing Set_Sensitive element at index %d: %s", i, setSensitive.Name.ValueString()))
+			diags.Append(applySetValue(base, setSensitive)...)
+			if diags.HasError() {
+				return nil, diags
+			}
+		}
+	}
+
+	diags.Append(logValues(ctx, base, in.SetSensitive)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return base, diags
+}
+
+func isKnown(c valuesCollection) bool {
+	return c != nil && !c.IsNull() && !c.IsUnknown()
+}
+
+// sortSetEntries sorts the entries of a set attribute, e.g. set of helm_template, whose order
+// is not defined, by name, then value and type, so that entries setting the same values are
+// merged in a stable order. The entries of list attributes keep their order.
+func sortSetEntries(c valuesCollection, entries []setResourceModel) {
+	if _, ok := c.(types.Set); !ok {
+		return
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Name.ValueString() != b.Name.ValueString() {
+			return a.Name.ValueString() < b.Name.ValueString()
+		}
+		if a.Value.ValueString() != b.Value.ValueString() {
+			return a.Value.ValueString() < b.Value.ValueString()
+		}
+		return a.Type.ValueString() < b.Type.ValueString()
+	})
+}
+
+func setEntryNames(entries []setResourceModel) []string {
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name.ValueString())
+	}
+	return names
+}
+
+// checkDuplicateSetNames reports the names set more than once by the entries of attribute when
+// policy is error. Otherwise the last entry wins, as with the Helm CLI.
+func checkDuplicateSetNames(attribute string, names []string, policy string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if policy != duplicateSetPolicyError {
+		return diags
+	}
+	seen := map[string]int{}
+	var duplicates []string
+	for _, name := range names {
+		seen[name]++
+		if seen[name] == 2 {
+			duplicates = append(duplicates, fmt.Sprintf("%q", name))
+		}
+	}
+	if len(duplicates) > 0 {
+		diags.AddAttributeError(path.Root(attribute), "Duplicate set entries",
+			fmt.Sprintf("%s sets %s more than once, e.g. from dynamic blocks. Remove the duplicate entries, or set duplicate_set_policy to %q for the last entry to win.",
+				attribute, strings.Join(duplicates, ", "), duplicateSetPolicyLast))
+	}
+	return diags
+}
+
+// parseValuesDocument parses a raw YAML values document, an empty document has no values
+func parseValuesDocument(values string) (map[string]interface{}, error) {
+	currentMap := map[string]interface{}{}
+	if strings.TrimSpace(values) == "" {
+		return currentMap, nil
+	}
+	if err := yaml.Unmarshal([]byte(values), &currentMap); err != nil {
+		return nil, err
+	}
+	return currentMap, nil
+}
+
+// applySetValue merges a set or set_sensitive entry into base
+func applySetValue(base map[string]interface{}, set setResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	name := set.Name.ValueString()
+	value := set.Value.ValueString()
+	valueType := set.Type.ValueString()
+
+	var err error
+	switch valueType {
+	case "auto", "":
+		err = strvals.ParseInto(fmt.Sprintf("%s=%s", name, value), base)
+	case "string":
+		err = strvals.ParseIntoString(fmt.Sprintf("%s=%s", name, value), base)
+	case "literal":
+		err = strvals.ParseLiteralInto(fmt.Sprintf("%s=%s", name, value), base)
+	default:
+		diags.AddError("Unexpected type", fmt.Sprintf("Unexpected type: %s", valueType))
+		return diags
+	}
+	if err != nil {
+		// the value is not echoed back as it may be sensitive
+		diags.AddError("Failed parsing value", fmt.Sprintf("Failed parsing key %q: %s", name, err))
+	}
+	return diags
+}
+
+// applySetListValue merges a set_list entry into base. Null elements are skipped, and an
+// empty list is an empty list.
+func applySetListValue(base map[string]interface{}, set set_listResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	name := set.Name.ValueString()
+
+	if set.Value.IsNull() {
+		diags.AddError("Null List Value", "The list value is null.")
+		return diags
+	}
+
+	elements := set.Value.Elements()
+	listStringArray := make([]string, 0, len(elements))
+	for _, element := range elements {
+		if element.IsNull() {
+			continue
+		}
+		s, ok := element.(types.String)
+		if !ok {
+			diags.AddError("Type Error", fmt.Sprintf("Expected types.String, got %T", element))
+			return diags
+		}
+		listStringArray = append(listStringArray, s.ValueString())
+	}
+
+	listString := strings.Join(listStringArray, ",")
+	if err := strvals.ParseInto(fmt.Sprintf("%s={%s}", name, listString), base); err != nil {
+		diags.AddError("Error parsing list value", fmt.Sprintf("Failed parsing key %q with value %s: %s", name, listString, err))
+		return diags
+	}
+
+	// strvals parses an empty list as a list holding an empty string
+	if len(listStringArray) == 0 {
+		if err := strvals.ParseJSON(fmt.Sprintf("%s=[]", name), base); err != nil {
+			diags.AddError("Error parsing list value", fmt.Sprintf("Failed parsing key %q with an empty list: %s", name, err))
+			return diags
+		}
+	}
+
+	return diags
+}
+
+// logValues logs the merged values with the set_sensitive values cloaked
+func logValues(ctx context.Context, values map[string]interface{}, setSensitive valuesCollection) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	// deep copy the values, cloaking must not modify the values passed to Helm
+	asJSON, err := json.Marshal(values)
+	if err != nil {
+		diags.AddError("Error marshaling values to JSON", fmt.Sprintf("Failed to marshal values to JSON: %s", err))
+		return diags
+	}
+	c := map[string]interface{}{}
+	if err := json.Unmarshal(asJSON, &c); err != nil {
+		diags.AddError("Error unmarshaling JSON to map", fmt.Sprintf("Failed to unmarshal JSON to map: %s", err))
+		return diags
+	}
+
+	cloakSetValues(c, setSensitive, "")
+
+	y, err := yaml.Marshal(c)
+	if err != nil {
+		diags.AddError("Error marshaling map to YAML", fmt.Sprintf("Failed to marshal map to YAML: %s", err))
+		return diags
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("---[ values.yaml ]-----------------------------------\n%s\n", string(y)))
+
+	return diags
+}
+
+// cloakSetValues cloaks every set_sensitive value of config, see sensitiveValue
+func cloakSetValues(config map[string]interface{}, setSensitive valuesCollection, hashKey string) {
+	if !isKnown(setSensitive) {
+		return
+	}
+	var setSensitiveList []setResourceModel
+	diags := setSensitive.ElementsAs(context.Background(), &setSensitiveList, false)
+	if diags.HasError() {
+		return
+	}
+
+	for _, set := range setSensitiveList {
+		cloakSetValue(config, set.Name.ValueString(), hashKey)
+	}
+}
+
+func cloakSetValue(values map[string]interface{}, valuePath, hashKey string) {
+	pathKeys := strings.Split(valuePath, ".")
+	sensitiveKey := pathKeys[len(pathKeys)-1]
+	parentPathKeys := pathKeys[:len(pathKeys)-1]
+	m := values
+	for _, key := range parentPathKeys {
+		v, ok := m[key].(map[string]interface{})
+		if !ok {
+			return
+		}
+		m = v
+	}
+	m[sensitiveKey] = sensitiveValue(m[sensitiveKey], hashKey)
+}
+
+func mergeMaps(a, b map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(a))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		if vMap, ok := v.(map[string]interface{}); ok {
+			if bv, ok := out[k]; ok {
+				if bvMap, ok := bv.(map[string]interface{}); ok {
+					out[k] = mergeMaps(bvMap, vMap)
+					continue
+				}
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
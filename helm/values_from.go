@@ -0,0 +1,322 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const defaultValuesFromKey = "values.yaml"
+
+type valuesFromModel struct {
+	SecretRef    types.Object `tfsdk:"secret_ref"`
+	ConfigMapRef types.Object `tfsdk:"config_map_ref"`
+}
+
+type valuesFromRefModel struct {
+	Namespace types.String `tfsdk:"namespace"`
+	Name      types.String `tfsdk:"name"`
+	Key       types.String `tfsdk:"key"`
+}
+
+// valuesFromDocument is a values document read from a Secret or a ConfigMap
+type valuesFromDocument struct {
+	values    map[string]interface{}
+	sensitive bool
+}
+
+func valuesFromRefSchema(kind string) map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"namespace": schema.StringAttribute{
+			Optional:    true,
+			Description: fmt.Sprintf("Namespace of the %s. Defaults to the namespace of the release", kind),
+		},
+		"name": schema.StringAttribute{
+			Required:    true,
+			Description: fmt.Sprintf("Name of the %s", kind),
+		},
+		"key": schema.StringAttribute{
+			Optional:    true,
+			Description: fmt.Sprintf("Key of the %s containing the values in raw YAML format. Defaults to %q", kind, defaultValuesFromKey),
+		},
+	}
+}
+
+func valuesFromSchema() schema.ListNestedAttribute {
+	return schema.ListNestedAttribute{
+		Optional:    true,
+		Description: "Values in raw YAML format read from Kubernetes Secrets or ConfigMaps at plan and apply time. They are merged after `values` and before `set`, `set_list` and `set_sensitive`. Values read from Secrets are cloaked in the metadata and redacted from the manifests",
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"secret_ref": schema.SingleNestedAttribute{
+					Optional:    true,
+					Description: "Reference to a key of a Secret",
+					Attributes:  valuesFromRefSchema("Secret"),
+					Validators: []validator.Object{
+						objectvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("config_map_ref")),
+					},
+				},
+				"config_map_ref": schema.SingleNestedAttribute{
+					Optional:    true,
+					Description: "Reference to a key of a ConfigMap",
+					Attributes:  valuesFromRefSchema("ConfigMap"),
+				},
+			},
+		},
+	}
+}
+
+func valuesFromAttrTypes() map[string]attr.Type {
+	refType := types.ObjectType{AttrTypes: map[string]attr.Type{
+		"namespace": types.StringType,
+		"name":      types.StringType,
+		"key":       types.StringType,
+	}}
+	return map[string]attr.Type{
+		"secret_ref":     refType,
+		"config_map_ref": refType,
+	}
+}
+
+// readValuesFrom reads the values documents referenced by the values_from attribute
+func readValuesFrom(ctx context.Context, m *Meta, model *HelmReleaseModel) ([]valuesFromDocument, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if model.ValuesFrom.IsNull() || model.ValuesFrom.IsUnknown() || len(model.ValuesFrom.Elements()) == 0 {
+		return nil, diags
+	}
+	if m.Mock {
+		tflog.Debug(ctx, "Skipping values_from in mock mode")
+		return nil, diags
+	}
+
+	var refs []valuesFromModel
+	diags.Append(model.ValuesFrom.ElementsAs(ctx, &refs, false)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	actionConfig, err := m.GetHelmConfigurationAs(ctx, model.Namespace.ValueString(), model.KubeContext.ValueString(), model.DeployAsServiceAccount.ValueString(), model.HelmDriver.ValueString())
+	if err != nil {
+		diags.AddError("Error getting helm configuration", fmt.Sprintf("Unable to get Helm configuration for namespace %s: %s", model.Namespace.ValueString(), err))
+		return nil, diags
+	}
+	clientset, err := actionConfig.KubernetesClientSet()
+	if err != nil {
+		diags.AddError("Error reading values_from", fmt.Sprintf("Unable to create Kubernetes client: %s", err))
+		return nil, diags
+	}
+
+	documents := make([]valuesFromDocument, 0, len(refs))
+	for _, r := range refs {
+		sensitive := !r.SecretRef.IsNull()
+		refObject := r.ConfigMapRef
+		if sensitive {
+			refObject = r.SecretRef
+		}
+
+		var ref valuesFromRefModel
+		diags.Append(refObject.As(ctx, &ref, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return nil, diags
+		}
+
+		namespace := ref.Namespace.ValueString()
+		if namespace == "" {
+			namespace = model.Namespace.ValueString()
+		}
+		key := ref.Key.ValueString()
+		if key == "" {
+			key = defaultValuesFromKey
+		}
+		name := ref.Name.ValueString()
+
+		var raw string
+		if sensitive {
+			tflog.Debug(ctx, fmt.Sprintf("Reading values from Secret %s/%s", namespace, name))
+			secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				diags.AddError("Error reading values_from", fmt.Sprintf("Unable to read Secret %s/%s: %s", namespace, name, err))
+				return nil, diags
+			}
+			data, ok := secret.Data[key]
+			if !ok {
+				diags.AddError("Error reading values_from", fmt.Sprintf("Key %q not found in Secret %s/%s", key, namespace, name))
+				return nil, diags
+			}
+			raw = string(data)
+		} else {
+			tflog.Debug(ctx, fmt.Sprintf("Reading values from ConfigMap %s/%s", namespace, name))
+			cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				diags.AddError("Error reading values_from", fmt.Sprintf("Unable to read ConfigMap %s/%s: %s", namespace, name, err))
+				return nil, diags
+			}
+			data, ok := cm.Data[key]
+			if !ok {
+				diags.AddError("Error reading values_from", fmt.Sprintf("Key %q not found in ConfigMap %s/%s", key, namespace, name))
+				return nil, diags
+			}
+			raw = data
+		}
+
+		values := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(raw), &values); err != nil {
+			// the content is not echoed back as it may be sensitive
+			diags.AddError("Error unmarshaling values", fmt.Sprintf("Key %q of %s/%s does not contain valid YAML: %s", key, namespace, name, err))
+			return nil, diags
+		}
+		documents = append(documents, valuesFromDocument{values: values, sensitive: sensitive})
+	}
+
+	return documents, diags
+}
+
+// valuesFromPathsKey is the key of the private state of helm_release recording the paths of the
+// values read from Secrets by values_from on the last apply
+const valuesFromPathsKey = "values_from_sensitive_paths"
+
+// privateState is the private state of a resource, as exposed by the requests and responses
+// of the framework
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// valuesFromPaths returns the sorted paths of the values of the documents read from Secrets
+func valuesFromPaths(documents []valuesFromDocument) [][]string {
+	var paths [][]string
+	for _, d := range documents {
+		if d.sensitive {
+			paths = append(paths, valuePaths(d.values, nil)...)
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return strings.Join(paths[i], "\x00") < strings.Join(paths[j], "\x00")
+	})
+	return paths
+}
+
+// setValuesFromPaths records the paths of the values read from Secrets on apply in the private
+// state, so that they are cloaked on refresh without reading the Secrets again
+func setValuesFromPaths(ctx context.Context, private privateState, paths [][]string) diag.Diagnostics {
+	if paths == nil {
+		paths = [][]string{}
+	}
+	b, err := json.Marshal(paths)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Error recording values_from", fmt.Sprintf("Unable to marshal the paths of the values read from Secrets: %s", err))
+		return diags
+	}
+	return private.SetKey(ctx, valuesFromPathsKey, b)
+}
+
+// recordedValuesFromPaths returns the paths of the values read from Secrets recorded on the
+// last apply. The states recorded before the paths were are given the paths of the values the
+// Secrets have now, and no paths when they cannot be read, as a refresh does not fail on an
+// unreadable Secret.
+func recordedValuesFromPaths(ctx context.Context, m *Meta, model *HelmReleaseModel, private privateState) ([][]string, diag.Diagnostics) {
+	b, diags := private.GetKey(ctx, valuesFromPathsKey)
+	if diags.HasError() {
+		return nil, diags
+	}
+	if len(b) > 0 {
+		var paths [][]string
+		if err := json.Unmarshal(b, &paths); err != nil {
+			diags.AddError("Error reading values_from", fmt.Sprintf("Unable to unmarshal the paths of the values read from Secrets: %s", err))
+		}
+		return paths, diags
+	}
+
+	documents, readDiags := readValuesFrom(ctx, m, model)
+	if readDiags.HasError() {
+		tflog.Warn(ctx, fmt.Sprintf("Unable to read values_from of release %s, its values are not cloaked until the next apply: %v", model.Name.ValueString(), readDiags))
+		return nil, diags
+	}
+	paths := valuesFromPaths(documents)
+	diags.Append(setValuesFromPaths(ctx, private, paths)...)
+	return paths, diags
+}
+
+// cloakValuesFrom cloaks the values of config at paths, the paths of the values read from Secrets
+func cloakValuesFrom(config map[string]interface{}, paths [][]string, hashKey string) {
+	for _, p := range paths {
+		cloakValuePath(config, p, hashKey)
+	}
+}
+
+// valuesFromSecrets returns the string values read from Secrets at paths of values, plain and
+// base64 encoded as charts put them in the data of Secrets, keyed as redactSensitiveValues
+// expects. The other values, e.g. booleans, are not redacted from the manifests, which they
+// would make unreadable.
+func valuesFromSecrets(values map[string]interface{}, paths [][]string) map[string]string {
+	secrets := map[string]string{}
+	for _, p := range paths {
+		s, ok := valueAtPath(values, p).(string)
+		if !ok || s == "" {
+			continue
+		}
+		secrets[s] = "(sensitive value)"
+		secrets[base64.StdEncoding.EncodeToString([]byte(s))] = "(sensitive value)"
+	}
+	return secrets
+}
+
+func valueAtPath(values map[string]interface{}, keys []string) interface{} {
+	m := values
+	for _, key := range keys[:len(keys)-1] {
+		v, ok := m[key].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		m = v
+	}
+	return m[keys[len(keys)-1]]
+}
+
+// valuePaths returns the path of every leaf of the values map
+func valuePaths(values map[string]interface{}, prefix []string) [][]string {
+	var paths [][]string
+	for k, v := range values {
+		p := append(append([]string{}, prefix...), k)
+		if child, ok := v.(map[string]interface{}); ok && len(child) > 0 {
+			paths = append(paths, valuePaths(child, p)...)
+			continue
+		}
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+func cloakValuePath(values map[string]interface{}, keys []string, hashKey string) {
+	m := values
+	for _, key := range keys[:len(keys)-1] {
+		v, ok := m[key].(map[string]interface{})
+		if !ok {
+			return
+		}
+		m = v
+	}
+	if v, ok := m[keys[len(keys)-1]]; ok {
+		m[keys[len(keys)-1]] = sensitiveValue(v, hashKey)
+	}
+}
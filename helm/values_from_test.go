@@ -0,0 +1,145 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// testPrivateState is the private state of a resource kept in memory
+type testPrivateState map[string][]byte
+
+func (p testPrivateState) GetKey(_ context.Context, key string) ([]byte, diag.Diagnostics) {
+	return p[key], nil
+}
+
+func (p testPrivateState) SetKey(_ context.Context, key string, value []byte) diag.Diagnostics {
+	p[key] = value
+	return nil
+}
+
+func TestCloakValuesFromPaths(t *testing.T) {
+	secretValues := map[string]interface{}{
+		"password": "hunter2",
+		"database": map[string]interface{}{
+			"user":  "admin",
+			"hosts": []interface{}{"a", "b"},
+		},
+	}
+
+	config := map[string]interface{}{
+		"password": "hunter2",
+		"replicas": 3,
+		"database": map[string]interface{}{
+			"user":  "admin",
+			"hosts": []interface{}{"a", "b"},
+			"port":  5432,
+		},
+	}
+
+	for _, p := range valuePaths(secretValues, nil) {
+		cloakValuePath(config, p, "")
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"password": sensitiveContentValue,
+		"replicas": 3,
+		"database": map[string]interface{}{
+			"user":  sensitiveContentValue,
+			"hosts": sensitiveContentValue,
+			"port":  5432,
+		},
+	}, config)
+}
+
+func TestCloakValuePathMissing(t *testing.T) {
+	config := map[string]interface{}{
+		"foo": "bar",
+	}
+
+	cloakValuePath(config, []string{"foo", "qux"}, "")
+	cloakValuePath(config, []string{"baz"}, "")
+
+	assert.Equal(t, map[string]interface{}{"foo": "bar"}, config)
+}
+
+func TestRecordedValuesFromPaths(t *testing.T) {
+	ctx := context.Background()
+	documents := []valuesFromDocument{
+		{values: map[string]interface{}{"replicas": 3}},
+		{values: map[string]interface{}{"password": "hunter2", "database": map[string]interface{}{"user": "admin"}}, sensitive: true},
+	}
+	paths := valuesFromPaths(documents)
+	assert.Equal(t, [][]string{{"database", "user"}, {"password"}}, paths)
+
+	// the paths recorded on apply are cloaked on refresh, even when the key was since removed
+	// from the Secret or the Secret cannot be read
+	private := testPrivateState{}
+	require.False(t, setValuesFromPaths(ctx, private, paths).HasError())
+	model := &HelmReleaseModel{Name: types.StringValue("test"), ValuesFrom: types.ListNull(types.ObjectType{AttrTypes: valuesFromAttrTypes()})}
+	recorded, diags := recordedValuesFromPaths(ctx, &Meta{}, model, private)
+	require.False(t, diags.HasError())
+	assert.Equal(t, paths, recorded)
+
+	config := map[string]interface{}{"password": "hunter2", "replicas": 3}
+	cloakValuesFrom(config, recorded, "")
+	assert.Equal(t, map[string]interface{}{"password": sensitiveContentValue, "replicas": 3}, config)
+
+	// the states recorded before the paths were are given the paths of the Secrets
+	private = testPrivateState{}
+	recorded, diags = recordedValuesFromPaths(ctx, &Meta{}, model, private)
+	require.False(t, diags.HasError())
+	assert.Empty(t, recorded)
+	assert.Equal(t, "[]", string(private[valuesFromPathsKey]))
+}
+
+func TestValuesFromSecretsRedacted(t *testing.T) {
+	ctx := context.Background()
+	// "aHVudGVyMg==" is the base64 encoding of the password read from a Secret
+	r := &release.Release{
+		Name:      "test",
+		Namespace: "default",
+		Version:   1,
+		Info:      &release.Info{Status: release.StatusDeployed},
+		Chart:     &chart.Chart{Metadata: &chart.Metadata{Name: "test-chart", Version: "1.2.3"}},
+		Config: map[string]interface{}{
+			"replicas": 3,
+			"database": map[string]interface{}{"password": "hunter2", "enabled": true},
+		},
+		Manifest: "apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\ndata:\n  password: aHVudGVyMg==\n",
+		Hooks: []*release.Hook{{
+			Name:     "migrate",
+			Kind:     "Job",
+			Manifest: "apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: migrate\nspec:\n  template:\n    spec:\n      containers:\n      - name: migrate\n        args: [\"--password=hunter2\"]\n",
+			Events:   []release.HookEvent{release.HookPreInstall},
+		}},
+	}
+	secretPaths := [][]string{{"database", "enabled"}, {"database", "password"}}
+	assert.Equal(t, map[string]string{"hunter2": "(sensitive value)", "aHVudGVyMg==": "(sensitive value)"}, valuesFromSecrets(r.Config, secretPaths))
+
+	model := &HelmReleaseModel{
+		Name:      types.StringValue("test"),
+		Namespace: types.StringValue("default"),
+		Chart:     types.StringValue("test-chart"),
+	}
+	meta := &Meta{Features: map[string]bool{featureManifestDiff: true}}
+	require.False(t, setReleaseAttributes(ctx, model, r, meta, secretPaths).HasError())
+
+	manifest, err := storedManifest(model)
+	require.NoError(t, err)
+	assert.Contains(t, manifest.ValueString(), `"kind":"Secret"`)
+	assert.Contains(t, model.HooksManifest.ValueString(), `"kind":"Job"`)
+	for _, value := range []string{"hunter2", "aHVudGVyMg=="} {
+		assert.NotContains(t, manifest.ValueString(), value)
+		assert.NotContains(t, model.HooksManifest.ValueString(), value)
+	}
+}
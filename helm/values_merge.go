@@ -0,0 +1,112 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+// Values of the values_merge_strategy attribute of helm_release and helm_template
+const (
+	valuesMergeHelm      = "helm"
+	valuesMergeStrategic = "strategic"
+)
+
+var valuesMergeStrategies = []string{valuesMergeHelm, valuesMergeStrategic}
+
+// strategicMergeKeys are the keys lists of objects are merged by, in order of precedence, e.g.
+// the name of containers and env entries, the mountPath of volumeMounts or the containerPort of
+// ports
+var strategicMergeKeys = []string{"name", "mountPath", "containerPort", "port", "key"}
+
+// strategicPatchDirective is the key of the directive removing an element from a merged list,
+// as in Kubernetes strategic merge patches: `{name: DEBUG, $patch: delete}`
+const strategicPatchDirective = "$patch"
+
+// strategicMergeMaps merges b into a like mergeMaps, except that the lists of objects sharing
+// one of the strategicMergeKeys are merged element by element instead of being replaced
+func strategicMergeMaps(a, b map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(a))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if am, ok := out[k].(map[string]interface{}); ok {
+				out[k] = strategicMergeMaps(am, v)
+				continue
+			}
+		case []interface{}:
+			if al, ok := out[k].([]interface{}); ok {
+				if merged, ok := strategicMergeLists(al, v); ok {
+					out[k] = merged
+					continue
+				}
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// strategicMergeLists merges the elements of b into the elements of a with the same merge key,
+// and appends the others. It returns false when the lists have no common merge key, in which
+// case b replaces a.
+func strategicMergeLists(a, b []interface{}) ([]interface{}, bool) {
+	key := listMergeKey(a, b)
+	if key == "" {
+		return nil, false
+	}
+
+	out := make([]interface{}, len(a))
+	copy(out, a)
+	for _, e := range b {
+		em := e.(map[string]interface{})
+		i := -1
+		for j, o := range out {
+			if o.(map[string]interface{})[key] == em[key] {
+				i = j
+				break
+			}
+		}
+		if em[strategicPatchDirective] == "delete" {
+			if i >= 0 {
+				out = append(out[:i], out[i+1:]...)
+			}
+			continue
+		}
+		if i >= 0 {
+			out[i] = strategicMergeMaps(out[i].(map[string]interface{}), em)
+		} else {
+			out = append(out, em)
+		}
+	}
+	return out, true
+}
+
+// listMergeKey returns the first of the strategicMergeKeys that every element of a and b holds a
+// scalar value for, or an empty string when the lists are not lists of objects sharing one
+func listMergeKey(a, b []interface{}) string {
+	if len(a) == 0 || len(b) == 0 {
+		return ""
+	}
+	for _, key := range strategicMergeKeys {
+		if listHasMergeKey(a, key) && listHasMergeKey(b, key) {
+			return key
+		}
+	}
+	return ""
+}
+
+func listHasMergeKey(l []interface{}, key string) bool {
+	for _, e := range l {
+		m, ok := e.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		switch m[key].(type) {
+		case string, float64, int64, bool:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	strategicBaseValues = `
+env:
+  - name: LOG_LEVEL
+    value: info
+  - name: DEBUG
+    value: "false"
+sidecars:
+  - name: proxy
+    image: proxy:1.0
+    ports:
+      - containerPort: 8080
+args: [--verbose]
+`
+	strategicOverlayValues = `
+env:
+  - name: LOG_LEVEL
+    value: debug
+  - name: DEBUG
+    $patch: delete
+  - name: REGION
+    value: eu
+sidecars:
+  - name: proxy
+    image: proxy:1.1
+args: [--quiet]
+`
+)
+
+func TestMergeValuesStrategic(t *testing.T) {
+	ctx := context.Background()
+	in := valuesInput{
+		Values: types.ListValueMust(types.StringType, []attr.Value{
+			types.StringValue(strategicBaseValues),
+			types.StringValue(strategicOverlayValues),
+		}),
+	}
+
+	values, diags := mergeValues(ctx, in)
+	assert.False(t, diags.HasError(), diags)
+	assert.Len(t, values["env"], 3, "helm merge replaces the lists")
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "proxy", "image": "proxy:1.1"}}, values["sidecars"])
+
+	in.MergeStrategy = valuesMergeStrategic
+	values, diags = mergeValues(ctx, in)
+	assert.False(t, diags.HasError(), diags)
+	assert.Equal(t, map[string]interface{}{
+		"env": []interface{}{
+			map[string]interface{}{"name": "LOG_LEVEL", "value": "debug"},
+			map[string]interface{}{"name": "REGION", "value": "eu"},
+		},
+		"sidecars": []interface{}{
+			map[string]interface{}{
+				"name":  "proxy",
+				"image": "proxy:1.1",
+				"ports": []interface{}{map[string]interface{}{"containerPort": float64(8080)}},
+			},
+		},
+		"args": []interface{}{"--quiet"},
+	}, values)
+}
+
+func TestListMergeKey(t *testing.T) {
+	named := []interface{}{map[string]interface{}{"name": "a", "port": float64(80)}}
+	ported := []interface{}{map[string]interface{}{"port": float64(80)}}
+
+	assert.Equal(t, "name", listMergeKey(named, named))
+	assert.Equal(t, "port", listMergeKey(named, ported))
+	assert.Empty(t, listMergeKey(named, []interface{}{"a"}))
+	assert.Empty(t, listMergeKey(named, nil))
+	assert.Empty(t, listMergeKey([]interface{}{map[string]interface{}{"name": map[string]interface{}{}}}, named))
+}
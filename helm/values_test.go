@@ -0,0 +1,189 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testSetType = types.ObjectType{AttrTypes: map[string]attr.Type{
+		"name":  types.StringType,
+		"type":  types.StringType,
+		"value": types.StringType,
+	}}
+	testSetListType = types.ObjectType{AttrTypes: map[string]attr.Type{
+		"name":  types.StringType,
+		"value": types.ListType{ElemType: types.StringType},
+	}}
+)
+
+func testSetValue(name, valueType, value string) attr.Value {
+	return types.ObjectValueMust(testSetType.AttrTypes, map[string]attr.Value{
+		"name":  types.StringValue(name),
+		"type":  types.StringValue(valueType),
+		"value": types.StringValue(value),
+	})
+}
+
+func testSetListValue(name string, values ...attr.Value) attr.Value {
+	return types.ObjectValueMust(testSetListType.AttrTypes, map[string]attr.Value{
+		"name":  types.StringValue(name),
+		"value": types.ListValueMust(types.StringType, values),
+	})
+}
+
+func TestMergeValues(t *testing.T) {
+	ctx := context.Background()
+	in := valuesInput{
+		Values: types.ListValueMust(types.StringType, []attr.Value{
+			types.StringValue("foo: values\nbar: values\nnested:\n  a: 1\n"),
+			types.StringValue(""),
+			types.StringNull(),
+		}),
+		Documents: []map[string]interface{}{
+			{"bar": "document", "nested": map[string]interface{}{"b": 2}},
+		},
+		Set: types.ListValueMust(testSetType, []attr.Value{
+			testSetValue("foo", "", "set"),
+			testSetValue("number", "string", "1337"),
+			testSetValue("literal", "literal", "a,b.c"),
+		}),
+		SetList: types.ListValueMust(testSetListType, []attr.Value{
+			testSetListValue("list", types.StringValue("a"), types.StringValue(""), types.StringNull(), types.StringValue("b")),
+			testSetListValue("empty"),
+		}),
+		SetSensitive: types.ListValueMust(testSetType, []attr.Value{
+			testSetValue("secret", "", "s3cr3t"),
+		}),
+	}
+
+	values, diags := mergeValues(ctx, in)
+	assert.False(t, diags.HasError(), diags)
+	assert.Equal(t, map[string]interface{}{
+		"foo":     "set",
+		"bar":     "document",
+		"nested":  map[string]interface{}{"a": float64(1), "b": 2},
+		"number":  "1337",
+		"literal": "a,b.c",
+		"list":    []interface{}{"a", "", "b"},
+		"empty":   []interface{}{},
+		"secret":  "s3cr3t",
+	}, values)
+}
+
+func TestMergeValuesSetAttributes(t *testing.T) {
+	ctx := context.Background()
+
+	// helm_template declares set and set_sensitive as sets, helm_release as lists
+	fromList, diags := mergeValues(ctx, valuesInput{
+		Values: types.ListNull(types.StringType),
+		Set:    types.ListValueMust(testSetType, []attr.Value{testSetValue("foo", "auto", "1")}),
+	})
+	assert.False(t, diags.HasError(), diags)
+
+	fromSet, diags := mergeValues(ctx, valuesInput{
+		Values: types.ListNull(types.StringType),
+		Set:    types.SetValueMust(testSetType, []attr.Value{testSetValue("foo", "auto", "1")}),
+	})
+	assert.False(t, diags.HasError(), diags)
+
+	assert.Equal(t, fromList, fromSet)
+	assert.Equal(t, map[string]interface{}{"foo": int64(1)}, fromList)
+}
+
+func TestMergeValuesDuplicateSet(t *testing.T) {
+	ctx := context.Background()
+	in := valuesInput{
+		Values: types.ListNull(types.StringType),
+		Set: types.ListValueMust(testSetType, []attr.Value{
+			testSetValue("replicas", "", "1"),
+			testSetValue("image.tag", "", "v1"),
+			testSetValue("replicas", "", "3"),
+		}),
+		SetList: types.ListValueMust(testSetListType, []attr.Value{
+			testSetListValue("hosts", types.StringValue("a")),
+			testSetListValue("hosts", types.StringValue("b")),
+		}),
+	}
+
+	// the last entry wins by default
+	values, diags := mergeValues(ctx, in)
+	assert.False(t, diags.HasError(), diags)
+	assert.Equal(t, int64(3), values["replicas"])
+	assert.Equal(t, []interface{}{"b"}, values["hosts"])
+
+	in.DuplicateSetPolicy = duplicateSetPolicyError
+	_, diags = mergeValues(ctx, in)
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Detail(), `set sets "replicas" more than once`)
+
+	in.Set = types.ListNull(testSetType)
+	_, diags = mergeValues(ctx, in)
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Detail(), `set_list sets "hosts" more than once`)
+}
+
+func TestMergeValuesSetOrder(t *testing.T) {
+	ctx := context.Background()
+
+	// the elements of sets are merged in a stable order, whatever the order they are read in
+	a, diags := mergeValues(ctx, valuesInput{
+		Values: types.ListNull(types.StringType),
+		Set:    types.SetValueMust(testSetType, []attr.Value{testSetValue("foo", "", "1"), testSetValue("foo", "", "2")}),
+	})
+	assert.False(t, diags.HasError(), diags)
+	b, diags := mergeValues(ctx, valuesInput{
+		Values: types.ListNull(types.StringType),
+		Set:    types.SetValueMust(testSetType, []attr.Value{testSetValue("foo", "", "2"), testSetValue("foo", "", "1")}),
+	})
+	assert.False(t, diags.HasError(), diags)
+	assert.Equal(t, a, b)
+	assert.Equal(t, int64(2), a["foo"])
+}
+
+func TestApplySetValueInvalidType(t *testing.T) {
+	diags := applySetValue(map[string]interface{}{}, setResourceModel{
+		Name:  types.StringValue("foo"),
+		Type:  types.StringValue("json"),
+		Value: types.StringValue("{}"),
+	})
+	assert.True(t, diags.HasError())
+}
+
+func TestCloakSetValues(t *testing.T) {
+	config := map[string]interface{}{
+		"a": map[string]interface{}{"secret": "s3cr3t", "public": "value"},
+	}
+	cloakSetValues(config, types.ListValueMust(testSetType, []attr.Value{
+		testSetValue("a.secret", "", "s3cr3t"),
+		testSetValue("missing.secret", "", "s3cr3t"),
+	}), "")
+	assert.Equal(t, map[string]interface{}{
+		"a": map[string]interface{}{"secret": sensitiveContentValue, "public": "value"},
+	}, config)
+}
+
+func TestCloakSetValuesHashKey(t *testing.T) {
+	setSensitive := types.ListValueMust(testSetType, []attr.Value{testSetValue("secret", "", "s3cr3t")})
+
+	a := map[string]interface{}{"secret": "s3cr3t"}
+	cloakSetValues(a, setSensitive, "key")
+	b := map[string]interface{}{"secret": "s3cr3t"}
+	cloakSetValues(b, setSensitive, "key")
+	changed := map[string]interface{}{"secret": "changed"}
+	cloakSetValues(changed, setSensitive, "key")
+	otherKey := map[string]interface{}{"secret": "s3cr3t"}
+	cloakSetValues(otherKey, setSensitive, "other")
+
+	assert.Regexp(t, `^hmac-sha256:[a-f0-9]{64}$`, a["secret"])
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, changed)
+	assert.NotEqual(t, a, otherKey)
+}
@@ -0,0 +1,199 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"sigs.k8s.io/yaml"
+)
+
+// valuesURLTimeout is the timeout of the download of a values document
+const valuesURLTimeout = 30 * time.Second
+
+// valuesURLMaxSize is the maximum size of a values document
+const valuesURLMaxSize = 10 << 20
+
+var (
+	valuesURLPattern = regexp.MustCompile(`^https?://`)
+	sha256Pattern    = regexp.MustCompile(`^[A-Fa-f0-9]{64}$`)
+)
+
+type valuesURLModel struct {
+	URL      types.String `tfsdk:"url"`
+	Headers  types.Map    `tfsdk:"headers"`
+	Username types.String `tfsdk:"username"`
+	Password types.String `tfsdk:"password"`
+	SHA256   types.String `tfsdk:"sha256"`
+}
+
+func valuesURLSchema() schema.ListNestedAttribute {
+	return schema.ListNestedAttribute{
+		Optional:    true,
+		Description: "Values in raw YAML format downloaded from HTTP(S) URLs at plan and apply time. They are merged after `values` and `values_from` and before `set`, `set_list` and `set_sensitive`",
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"url": schema.StringAttribute{
+					Required:    true,
+					Description: "HTTP or HTTPS URL of the values document",
+					Validators: []validator.String{
+						stringvalidator.RegexMatches(valuesURLPattern, "must be an http:// or https:// URL"),
+					},
+				},
+				"headers": schema.MapAttribute{
+					Optional:    true,
+					Sensitive:   true,
+					Description: "Headers of the request, e.g. an Authorization or a PRIVATE-TOKEN header",
+					ElementType: types.StringType,
+				},
+				"username": schema.StringAttribute{
+					Optional:    true,
+					Description: "Username of the basic authentication of the request",
+					Validators: []validator.String{
+						stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("password")),
+					},
+				},
+				"password": schema.StringAttribute{
+					Optional:    true,
+					Sensitive:   true,
+					Description: "Password of the basic authentication of the request",
+					Validators: []validator.String{
+						stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("username")),
+					},
+				},
+				"sha256": schema.StringAttribute{
+					Optional:    true,
+					Description: "Expected hex-encoded SHA-256 checksum of the document. The plan fails when the downloaded document does not match",
+					Validators: []validator.String{
+						stringvalidator.RegexMatches(sha256Pattern, "must be a hex-encoded SHA-256 checksum"),
+					},
+				},
+			},
+		},
+	}
+}
+
+func valuesURLAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"url":      types.StringType,
+		"headers":  types.MapType{ElemType: types.StringType},
+		"username": types.StringType,
+		"password": types.StringType,
+		"sha256":   types.StringType,
+	}
+}
+
+// fetchValuesURL downloads the values document of ref and checks its checksum
+func fetchValuesURL(ctx context.Context, client *http.Client, ref valuesURLModel) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ref.URL.ValueString(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range ref.Headers.Elements() {
+		if s, ok := v.(types.String); ok {
+			req.Header.Set(k, s.ValueString())
+		}
+	}
+	if ref.Username.ValueString() != "" {
+		req.SetBasicAuth(ref.Username.ValueString(), ref.Password.ValueString())
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// the error of the client repeats the URL, which may contain credentials
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return nil, urlErr.Err
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, valuesURLMaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > valuesURLMaxSize {
+		return nil, fmt.Errorf("document larger than %d bytes", valuesURLMaxSize)
+	}
+
+	if expected := ref.SHA256.ValueString(); expected != "" {
+		sum := sha256.Sum256(data)
+		if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, expected) {
+			return nil, fmt.Errorf("SHA-256 checksum %s does not match the expected checksum %s", actual, expected)
+		}
+	}
+	return data, nil
+}
+
+// readValuesURL downloads the values documents of the values_url attribute
+func readValuesURL(ctx context.Context, m *Meta, model *HelmReleaseModel) ([]map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if model.ValuesURL.IsNull() || model.ValuesURL.IsUnknown() || len(model.ValuesURL.Elements()) == 0 {
+		return nil, diags
+	}
+	if m.Mock {
+		tflog.Debug(ctx, "Skipping values_url in mock mode")
+		return nil, diags
+	}
+
+	var refs []valuesURLModel
+	diags.Append(model.ValuesURL.ElementsAs(ctx, &refs, false)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	client := &http.Client{Timeout: valuesURLTimeout}
+	documents := make([]map[string]interface{}, 0, len(refs))
+	for i, ref := range refs {
+		// the URL may contain credentials in its user info or query
+		location := redactURL(ref.URL.ValueString())
+		tflog.Debug(ctx, fmt.Sprintf("Reading values from %s", location))
+		data, err := fetchValuesURL(ctx, client, ref)
+		if err != nil {
+			diags.AddAttributeError(path.Root("values_url").AtListIndex(i), "Error reading values_url", fmt.Sprintf("Unable to download values from %s: %s", location, err))
+			return nil, diags
+		}
+
+		values := map[string]interface{}{}
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			diags.AddAttributeError(path.Root("values_url").AtListIndex(i), "Error unmarshaling values", fmt.Sprintf("The document of %s does not contain valid YAML: %s", location, err))
+			return nil, diags
+		}
+		documents = append(documents, values)
+	}
+	return documents, diags
+}
+
+// redactURL removes the user info and the query of a URL
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	u.User = nil
+	u.RawQuery = ""
+	return u.String()
+}
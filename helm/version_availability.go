@@ -0,0 +1,68 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// chartVersionAvailability reports a pinned chart version that is no longer listed in the index
+// of its repository, e.g. a yanked version still installed from a cached index or chart, which
+// a rebuild of the release could not fetch. It is a warning, or an error with
+// strict_version_availability. The index is downloaded once per run, unless refresh_repository
+// is never, and the check is skipped when it cannot be downloaded.
+func chartVersionAvailability(ctx context.Context, m *Meta, model *HelmReleaseModel, cpo *action.ChartPathOptions, name string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if !model.PreRenderedManifest.IsNull() || model.Version.ValueString() == "" || cpo.Version == "" {
+		return diags
+	}
+
+	var entry *repo.Entry
+	chartName := name
+	if cpo.RepoURL != "" {
+		if registry.IsOCI(cpo.RepoURL) {
+			return diags
+		}
+		entry = repositoryURLEntry(cpo)
+	} else if entry = m.namedRepository(name); entry != nil {
+		_, chartName, _ = strings.Cut(name, "/")
+	} else {
+		return diags
+	}
+
+	if model.RefreshRepository.ValueString() != refreshRepositoryNever {
+		if _, err := m.downloadRepositoryIndex(ctx, entry); err != nil {
+			subsystemDebug(ctx, logSubsystemDownload, fmt.Sprintf("Skipping the availability check of chart %s %s: %s", name, cpo.Version, err))
+			return diags
+		}
+	} else if !m.repositoryIndexCached(entry.Name) {
+		return diags
+	}
+
+	indexPath := filepath.Join(m.Settings.RepositoryCache, helmpath.CacheIndexFile(entry.Name))
+	_, err := findChartVersion(indexPath, chartName, cpo.Version)
+	if err == nil || !chartNotFound(err) {
+		return diags
+	}
+
+	summary := "Chart version no longer available"
+	detail := fmt.Sprintf("Version %s of chart %s is no longer listed in the index of repository %s. "+
+		"The installed release is not affected, but the chart could not be fetched to install the release again, e.g. when rebuilding a cluster.", cpo.Version, chartName, entry.URL)
+	if model.StrictVersionAvailability.ValueBool() {
+		diags.AddAttributeError(path.Root("version"), summary, detail)
+	} else {
+		diags.AddAttributeWarning(path.Root("version"), summary, detail)
+	}
+	return diags
+}
@@ -0,0 +1,54 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+func TestChartVersionAvailability(t *testing.T) {
+	ctx := context.Background()
+	server := newTestIndexServer(t, "1.0.0", "1.1.0")
+	m := testRepositoryIndexMeta(t, 0)
+
+	check := func(version, refresh string, strict bool) diag.Diagnostics {
+		model := &HelmReleaseModel{
+			PreRenderedManifest:       types.StringNull(),
+			Version:                   types.StringValue(version),
+			RefreshRepository:         types.StringValue(refresh),
+			StrictVersionAvailability: types.BoolValue(strict),
+		}
+		return chartVersionAvailability(ctx, m, model, &action.ChartPathOptions{RepoURL: server.URL, Version: version}, "test-chart")
+	}
+
+	assert.Empty(t, check("1.0.0", refreshRepositoryAuto, false))
+
+	// the version is yanked from the index
+	server.setVersions("1.1.0")
+	diags := check("1.0.0", refreshRepositoryAuto, false)
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.SeverityWarning, diags[0].Severity())
+	assert.Contains(t, diags[0].Detail(), "Version 1.0.0 of chart test-chart is no longer listed")
+
+	diags = check("1.0.0", refreshRepositoryAuto, true)
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.SeverityError, diags[0].Severity())
+
+	// with refresh_repository never, the cached index is used
+	server.setVersions("1.0.0", "1.1.0")
+	requests := server.requests
+	assert.Len(t, check("1.0.0", refreshRepositoryNever, false), 1)
+	assert.Equal(t, requests, server.requests)
+
+	// the check is skipped when the index cannot be downloaded
+	server.Close()
+	assert.Empty(t, check("2.0.0", refreshRepositoryAuto, true))
+}
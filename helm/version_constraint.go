@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// anyPrereleaseConstraint matches every version, prereleases included
+const anyPrereleaseConstraint = ">0.0.0-0"
+
+// constraintComparatorPattern matches the comparators of a constraint on a fully specified version
+var constraintComparatorPattern = regexp.MustCompile(`(>=|=>|<=|=<|!=|~>|>|<|=|~|\^)?(\s*)v?(\d+)\.(\d+)\.(\d+)(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?`)
+
+// prereleaseConstraint rewrites the comparators of constraint on versions without a prerelease
+// so that they match prereleases too. Semver constraints only match the prereleases of the
+// versions they name, e.g. ">=1.2.0 <2.0.0" matches neither 1.3.0-rc.1 nor 1.2.0-rc.1, while the
+// rewritten ">=1.2.0-0 <2.0.0-0" matches both but not 2.0.0-rc.1. Exact versions and wildcards
+// are left as is.
+func prereleaseConstraint(constraint string) string {
+	return constraintComparatorPattern.ReplaceAllStringFunc(constraint, func(comparator string) string {
+		m := constraintComparatorPattern.FindStringSubmatch(comparator)
+		op, space, prerelease, build := m[1], m[2], m[6], m[7]
+		if prerelease != "" {
+			return comparator
+		}
+		major, minor, patch := m[3], m[4], m[5]
+		switch op {
+		case "", "=", "!=":
+			return comparator
+		case ">":
+			// the lowest version greater than major.minor.patch
+			return fmt.Sprintf(">=%s%s.%s.%s-0", space, major, minor, nextPatch(patch))
+		case "<=", "=<":
+			return fmt.Sprintf("<%s%s.%s.%s-0", space, major, minor, nextPatch(patch))
+		}
+		return fmt.Sprintf("%s%s%s.%s.%s-0%s", op, space, major, minor, patch, build)
+	})
+}
+
+func nextPatch(patch string) string {
+	p, err := strconv.ParseUint(patch, 10, 64)
+	if err != nil {
+		return patch
+	}
+	return strconv.FormatUint(p+1, 10)
+}
+
+// resolveVersion returns the version or constraint the chart of model is located with: the
+// version, else the version_constraint, matching prereleases with allow_prerelease or devel
+func resolveVersion(model *HelmReleaseModel) (string, error) {
+	version := strings.TrimSpace(model.Version.ValueString())
+	if version != "" {
+		return version, nil
+	}
+
+	constraint := strings.TrimSpace(model.VersionConstraint.ValueString())
+	prerelease := model.AllowPrerelease.ValueBool()
+	if constraint == "" {
+		if prerelease || model.Devel.ValueBool() {
+			return anyPrereleaseConstraint, nil
+		}
+		return "", nil
+	}
+
+	if _, err := semver.NewConstraint(constraint); err != nil {
+		return "", fmt.Errorf("invalid version_constraint %q: %w", constraint, err)
+	}
+	if prerelease {
+		constraint = prereleaseConstraint(constraint)
+	}
+	return constraint, nil
+}
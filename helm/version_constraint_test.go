@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrereleaseConstraint(t *testing.T) {
+	cases := map[string]string{
+		">=1.2.0 <2.0.0":   ">=1.2.0-0 <2.0.0-0",
+		">1.2.3":           ">=1.2.4-0",
+		"<= 1.2.3":         "< 1.2.4-0",
+		"~1.2.3, ^1.0.0":   "~1.2.3-0, ^1.0.0-0",
+		">=1.2.0-rc.0 <2":  ">=1.2.0-rc.0 <2",
+		"1.2.3":            "1.2.3",
+		"!=1.2.3 || 1.4.x": "!=1.2.3 || 1.4.x",
+	}
+	for in, expected := range cases {
+		assert.Equal(t, expected, prereleaseConstraint(in), in)
+	}
+}
+
+func TestPrereleaseConstraintMatches(t *testing.T) {
+	c, err := semver.NewConstraint(prereleaseConstraint(">=1.2.0 <2.0.0"))
+	assert.NoError(t, err)
+	for version, expected := range map[string]bool{
+		"1.1.9":       false,
+		"1.2.0-rc.1":  true,
+		"1.2.0":       true,
+		"1.3.0-rc.1":  true,
+		"1.9.9":       true,
+		"2.0.0-rc.1":  false,
+		"2.0.0":       false,
+		"0.9.0-alpha": false,
+	} {
+		assert.Equal(t, expected, c.Check(semver.MustParse(version)), version)
+	}
+
+	c, err = semver.NewConstraint(prereleaseConstraint(">1.2.3"))
+	assert.NoError(t, err)
+	assert.False(t, c.Check(semver.MustParse("1.2.3")))
+	assert.True(t, c.Check(semver.MustParse("1.2.4-rc.1")))
+}
+
+func TestResolveVersion(t *testing.T) {
+	model := func(version, constraint string, prerelease, devel bool) *HelmReleaseModel {
+		return &HelmReleaseModel{
+			Version:           types.StringValue(version),
+			VersionConstraint: types.StringValue(constraint),
+			AllowPrerelease:   types.BoolValue(prerelease),
+			Devel:             types.BoolValue(devel),
+		}
+	}
+	cases := []struct {
+		model    *HelmReleaseModel
+		expected string
+	}{
+		{model(" 1.2.3 ", ">=1.0.0", true, true), "1.2.3"},
+		{model("", "", false, false), ""},
+		{model("", "", false, true), ">0.0.0-0"},
+		{model("", "", true, false), ">0.0.0-0"},
+		{model("", ">=1.2.0 <2.0.0", false, true), ">=1.2.0 <2.0.0"},
+		{model("", ">=1.2.0 <2.0.0", true, false), ">=1.2.0-0 <2.0.0-0"},
+	}
+	for _, c := range cases {
+		version, err := resolveVersion(c.model)
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, version)
+	}
+
+	_, err := resolveVersion(model("", "not a constraint", false, false))
+	assert.Error(t, err)
+}
@@ -0,0 +1,172 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// The readiness of the resources of the kinds of wait_overrides is checked again after
+// waitOverrideMinBackoff, then twice as long each time up to waitOverrideMaxBackoff
+var (
+	waitOverrideMinBackoff = time.Second
+	waitOverrideMaxBackoff = 30 * time.Second
+)
+
+// waitOverridesKubeClient waits for the resources of the kinds of overrides for their own
+// timeout, e.g. the Certificates of a slow operator, and for the other resources for the timeout
+// of the release
+type waitOverridesKubeClient struct {
+	forwardingKubeClient
+	ctx       context.Context
+	overrides map[string]time.Duration
+	// newChecker returns the readiness checker of the resources of the kinds Helm waits for
+	newChecker func(checkJobs bool) (readinessChecker, error)
+}
+
+// waitOverrides wraps the Kubernetes client of actionConfig to wait for the resources of the
+// kinds of the wait_overrides of model for their own timeout
+func waitOverrides(ctx context.Context, actionConfig *action.Configuration, model *HelmReleaseModel) {
+	overrides := map[string]time.Duration{}
+	for kind, v := range model.WaitOverrides.Elements() {
+		if seconds, ok := v.(types.Int64); ok && !seconds.IsNull() && !seconds.IsUnknown() {
+			overrides[kind] = time.Duration(seconds.ValueInt64()) * time.Second
+		}
+	}
+	if len(overrides) == 0 {
+		return
+	}
+	actionConfig.KubeClient = &waitOverridesKubeClient{
+		forwardingKubeClient: forwardingKubeClient{Interface: actionConfig.KubeClient},
+		ctx:                  ctx,
+		overrides:            overrides,
+		newChecker: func(checkJobs bool) (readinessChecker, error) {
+			clientset, err := actionConfig.KubernetesClientSet()
+			if err != nil {
+				return nil, err
+			}
+			checker := kube.NewReadyChecker(clientset, func(string, ...interface{}) {}, kube.PausedAsReady(true), kube.CheckJobs(checkJobs))
+			return &checker, nil
+		},
+	}
+}
+
+func (c *waitOverridesKubeClient) Wait(resources kube.ResourceList, timeout time.Duration) error {
+	return c.wait(resources, timeout, false, c.Interface.Wait)
+}
+
+func (c *waitOverridesKubeClient) WaitWithJobs(resources kube.ResourceList, timeout time.Duration) error {
+	return c.wait(resources, timeout, true, c.Interface.WaitWithJobs)
+}
+
+// wait waits with fn for the resources of the kinds without override for timeout, then for the
+// resources of each kind of the overrides for its own timeout
+func (c *waitOverridesKubeClient) wait(resources kube.ResourceList, timeout time.Duration, checkJobs bool, fn func(kube.ResourceList, time.Duration) error) error {
+	var rest kube.ResourceList
+	byKind := map[string]kube.ResourceList{}
+	for _, info := range resources {
+		kind := info.Mapping.GroupVersionKind.Kind
+		if _, ok := c.overrides[kind]; ok {
+			byKind[kind] = append(byKind[kind], info)
+		} else {
+			rest = append(rest, info)
+		}
+	}
+	if len(byKind) == 0 {
+		return fn(resources, timeout)
+	}
+	if len(rest) > 0 {
+		if err := fn(rest, timeout); err != nil {
+			return err
+		}
+	}
+
+	checker, err := c.newChecker(checkJobs)
+	if err != nil {
+		return err
+	}
+	kinds := make([]string, 0, len(byKind))
+	for kind := range byKind {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		if err := c.waitKind(checker, kind, byKind[kind], c.overrides[kind]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitKind waits for the resources of kind to be ready for timeout, checking them again with an
+// exponential backoff
+func (c *waitOverridesKubeClient) waitKind(checker readinessChecker, kind string, resources kube.ResourceList, timeout time.Duration) error {
+	tflog.Debug(c.ctx, fmt.Sprintf("Waiting for %d %s resources for %s", len(resources), kind, timeout))
+	deadline := time.Now().Add(timeout)
+	backoff := waitOverrideMinBackoff
+	for {
+		var pending []string
+		for _, info := range resources {
+			ready, err := c.ready(checker, info)
+			if err != nil {
+				return err
+			}
+			if !ready {
+				pending = append(pending, info.ObjectName())
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("timed out after %s waiting for %s to be ready, see wait_overrides", timeout, strings.Join(pending, ", "))
+		}
+		select {
+		case <-time.After(min(backoff, remaining)):
+		case <-c.ctx.Done():
+			return c.ctx.Err()
+		}
+		backoff = min(backoff*2, waitOverrideMaxBackoff)
+	}
+}
+
+// ready reports whether info is ready. The readiness of the kinds Helm waits for is checked as
+// Helm does, the other kinds, e.g. custom resources, are ready when their Ready condition is
+// true or when they have no Ready condition.
+func (c *waitOverridesKubeClient) ready(checker readinessChecker, info *resource.Info) (bool, error) {
+	if healthKinds[info.Mapping.GroupVersionKind.Kind] {
+		return checker.IsReady(c.ctx, info)
+	}
+	if info.Client != nil {
+		if err := info.Get(); err != nil {
+			return false, err
+		}
+	}
+	u, ok := info.Object.(*unstructured.Unstructured)
+	if !ok {
+		return true, nil
+	}
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, _ := c.(map[string]interface{})
+		if conditionType, _, _ := unstructured.NestedString(condition, "type"); conditionType == "Ready" {
+			status, _, _ := unstructured.NestedString(condition, "status")
+			return status == "True", nil
+		}
+	}
+	return true, nil
+}
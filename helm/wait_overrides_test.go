@@ -0,0 +1,101 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// waitRecordingKubeClient records the resources Helm waits for and their timeout
+type waitRecordingKubeClient struct {
+	kubefake.PrintingKubeClient
+	waited   []string
+	timeouts []time.Duration
+}
+
+func (c *waitRecordingKubeClient) Wait(resources kube.ResourceList, timeout time.Duration) error {
+	for _, info := range resources {
+		c.waited = append(c.waited, info.Name)
+	}
+	c.timeouts = append(c.timeouts, timeout)
+	return nil
+}
+
+// countingReadinessChecker reports resources ready after they were checked readyAfter times
+type countingReadinessChecker struct {
+	checks     map[string]int
+	readyAfter int
+}
+
+func (c *countingReadinessChecker) IsReady(ctx context.Context, info *resource.Info) (bool, error) {
+	c.checks[info.Name]++
+	return c.checks[info.Name] >= c.readyAfter, nil
+}
+
+func testCustomResource(kind, name, ready string) *resource.Info {
+	info := testHookObject(kind, name, "")
+	if ready != "" {
+		_ = unstructured.SetNestedSlice(info.Object.(*unstructured.Unstructured).Object, []interface{}{
+			map[string]interface{}{"type": "Ready", "status": ready},
+		}, "status", "conditions")
+	}
+	return info
+}
+
+func TestWaitOverrides(t *testing.T) {
+	ctx := context.Background()
+	minBackoff := waitOverrideMinBackoff
+	waitOverrideMinBackoff = time.Millisecond
+	t.Cleanup(func() { waitOverrideMinBackoff = minBackoff })
+
+	newClient := func(checker readinessChecker, overrides map[string]int64) (kube.Interface, *waitRecordingKubeClient) {
+		recorder := &waitRecordingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}}
+		actionConfig := &action.Configuration{KubeClient: recorder}
+		m, _ := types.MapValueFrom(ctx, types.Int64Type, overrides)
+		waitOverrides(ctx, actionConfig, &HelmReleaseModel{WaitOverrides: m})
+		if c, ok := actionConfig.KubeClient.(*waitOverridesKubeClient); ok {
+			c.newChecker = func(bool) (readinessChecker, error) { return checker, nil }
+		}
+		return actionConfig.KubeClient, recorder
+	}
+	resources := kube.ResourceList{
+		testHookObject("Deployment", "web", ""),
+		testHookObject("StatefulSet", "db", ""),
+		testCustomResource("Certificate", "tls", "True"),
+	}
+
+	t.Run("overridden kinds", func(t *testing.T) {
+		checker := &countingReadinessChecker{checks: map[string]int{}, readyAfter: 3}
+		client, recorder := newClient(checker, map[string]int64{"StatefulSet": 600, "Certificate": 900})
+		require.NoError(t, client.Wait(resources, time.Minute))
+		assert.Equal(t, []string{"web"}, recorder.waited)
+		assert.Equal(t, []time.Duration{time.Minute}, recorder.timeouts)
+		assert.Equal(t, map[string]int{"db": 3}, checker.checks)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		checker := &countingReadinessChecker{checks: map[string]int{}, readyAfter: 1}
+		client, _ := newClient(checker, map[string]int64{"Certificate": 1})
+		err := client.Wait(kube.ResourceList{testCustomResource("Certificate", "pending", "False")}, time.Minute)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pending")
+	})
+
+	t.Run("no overrides", func(t *testing.T) {
+		client, recorder := newClient(nil, nil)
+		require.Same(t, recorder, client)
+	})
+}
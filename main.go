@@ -39,6 +39,9 @@ func main() {
 	}
 
 	serveErr := providerserver.Serve(context.Background(), helm.New(Version), opts)
+	if err := helm.RemoveTemporaryFiles(); err != nil {
+		log.Printf("Unable to remove the temporary files of the provider: %s", err)
+	}
 	if serveErr != nil {
 		log.Fatal(serveErr.Error())
 	}